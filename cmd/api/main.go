@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	_ "time/tzdata"
 
 	"github.com/knjname/go-todo-api/internal/di"
 	"github.com/knjname/go-todo-api/internal/server"
@@ -19,7 +20,7 @@ func main() {
 	}
 	defer components.Pool.Close()
 
-	if err := server.Run(ctx, components.Config, components.UseCase, components.Logger); err != nil {
+	if err := server.Run(ctx, components.Config, components.UseCase, components.TagUseCase, components.ProjectUseCase, components.UserUseCase, components.AuditUseCase, components.WebhookUseCase, components.WebhookWorker, components.EventStream, components.Presence, components.Idempotency, components.APIKeyUseCase, components.Verifier, components.Logger); err != nil {
 		components.Logger.Error("server error", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
@@ -1,11 +1,27 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
 	"github.com/knjname/go-todo-api/internal/di"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/tenant"
+	"github.com/knjname/go-todo-api/internal/usecase"
 	"github.com/pressly/goose/v3"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +31,9 @@ func main() {
 		Use:   "batch",
 		Short: "Todo API batch tool",
 	}
+	var workspace string
+	rootCmd.PersistentFlags().StringVar(&workspace, "workspace", os.Getenv("WORKSPACE_ID"),
+		"ID of the workspace to work in (default $WORKSPACE_ID); required by all commands but migrate, outbox, webhook and idempotency")
 
 	migrateCmd := &cobra.Command{
 		Use:   "migrate",
@@ -69,11 +88,27 @@ func main() {
 
 	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd)
 
+	var (
+		listPageSize int
+		listPages    int
+		listCursor   string
+		listSort     string
+		listTree     bool
+		listProject  string
+	)
 	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all todos",
 		RunE: func(_ *cobra.Command, _ []string) error {
-			ctx := context.Background()
+			projectID, err := parseProjectID(listProject)
+			if err != nil {
+				return fmt.Errorf("invalid --project: %w", err)
+			}
+
+			ctx, err := workspaceContext(workspace)
+			if err != nil {
+				return err
+			}
 			components, err := di.InitializeBatch(ctx)
 			if err != nil {
 				return fmt.Errorf("initialize: %w", err)
@@ -81,32 +116,127 @@ func main() {
 			defer components.Pool.Close()
 			defer func() { _ = components.DB.Close() }()
 
-			todos, err := components.UseCase.ListTodos(ctx)
-			if err != nil {
-				return fmt.Errorf("list todos: %w", err)
+			list := components.UseCase.ListTodos
+			if projectID != nil {
+				list = func(ctx context.Context, params usecase.ListTodosParams) (*usecase.TodoPage, error) {
+					return components.UseCase.ListProjectTodos(ctx, *projectID, params)
+				}
 			}
 
-			if len(todos) == 0 {
+			params := usecase.ListTodosParams{Sort: listSort, Cursor: listCursor, Limit: listPageSize}
+			var (
+				found bool
+				next  string
+				// tree collects the todos of every page, as a subtask may
+				// be listed on a different page than its parent.
+				tree []domain.Todo
+			)
+			for n := 1; ; n++ {
+				result, err := list(ctx, params)
+				if err != nil {
+					return fmt.Errorf("list todos: %w", err)
+				}
+
+				for i := range result.Todos {
+					found = true
+					if listTree {
+						tree = append(tree, result.Todos[i])
+					} else {
+						fmt.Println(todoLine(&result.Todos[i]))
+					}
+				}
+
+				if result.Next == "" {
+					break
+				}
+				if listPages > 0 && n >= listPages {
+					next = result.Next
+					break
+				}
+				params.Cursor = result.Next
+			}
+
+			if !found {
 				fmt.Println("No todos found.")
-				return nil
 			}
+			printTree(tree)
+			if next != "" {
+				fmt.Printf("Next cursor: %s\n", next)
+			}
+			return nil
+		},
+	}
+	listCmd.Flags().IntVar(&listPageSize, "page-size", 100, "number of todos fetched per page")
+	listCmd.Flags().IntVar(&listPages, "pages", 0, "maximum number of pages to print (0 = all)")
+	listCmd.Flags().StringVar(&listCursor, "cursor", "", "resume listing after this cursor")
+	listCmd.Flags().BoolVar(&listTree, "tree", false, "indent subtasks under their parent todo")
+	listCmd.Flags().StringVar(&listProject, "project", "", "only list the todos of this project ID")
+	listCmd.Flags().StringVar(&listSort, "sort", "", "sort order, e.g. \"-due,title\" (default \"-priority,due,-created_at\")")
 
-			for _, t := range todos {
-				status := "[ ]"
-				if t.Completed {
-					status = "[x]"
+	var (
+		searchMode    string
+		searchLimit   int
+		searchProject string
+		searchStatus  []string
+	)
+	searchCmd := &cobra.Command{
+		Use:   "search <query>...",
+		Short: "Search the titles and descriptions of todos",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			projectID, err := parseProjectID(searchProject)
+			if err != nil {
+				return fmt.Errorf("invalid --project: %w", err)
+			}
+
+			ctx, err := workspaceContext(workspace)
+			if err != nil {
+				return err
+			}
+			components, err := di.InitializeBatch(ctx)
+			if err != nil {
+				return fmt.Errorf("initialize: %w", err)
+			}
+			defer components.Pool.Close()
+			defer func() { _ = components.DB.Close() }()
+
+			hits, err := components.UseCase.SearchTodos(ctx, usecase.SearchTodosParams{
+				Query: strings.Join(args, " "), Mode: searchMode, Statuses: searchStatus, ProjectID: projectID, Limit: searchLimit,
+			})
+			if err != nil {
+				return fmt.Errorf("search todos: %w", err)
+			}
+			if len(hits) == 0 {
+				fmt.Println("No todos found.")
+			}
+			for i := range hits {
+				fmt.Printf("%.3f %s\n", hits[i].Rank, todoLine(&hits[i].Todo))
+				if hits[i].Snippet != "" {
+					fmt.Println("      " + plainHighlight(hits[i].Snippet))
 				}
-				fmt.Printf("%s %s %s\n", status, t.ID, t.Title)
 			}
 			return nil
 		},
 	}
+	searchCmd.Flags().StringVar(&searchMode, "mode", "auto", "how to match the query: simple (words), ngram (substrings, for Japanese) or auto")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 20, "maximum number of todos printed")
+	searchCmd.Flags().StringVar(&searchProject, "project", "", "only search the todos of this project ID")
+	searchCmd.Flags().StringSliceVar(&searchStatus, "status", nil, "only search todos in these states, e.g. \"open,in_progress\"")
 
+	var completeAllProject string
 	completeAllCmd := &cobra.Command{
 		Use:   "complete-all",
-		Short: "Mark all todos as complete",
+		Short: "Mark all open and in-progress todos as complete",
 		RunE: func(_ *cobra.Command, _ []string) error {
-			ctx := context.Background()
+			projectID, err := parseProjectID(completeAllProject)
+			if err != nil {
+				return fmt.Errorf("invalid --project: %w", err)
+			}
+
+			ctx, err := workspaceContext(workspace)
+			if err != nil {
+				return err
+			}
 			components, err := di.InitializeBatch(ctx)
 			if err != nil {
 				return fmt.Errorf("initialize: %w", err)
@@ -114,19 +244,527 @@ func main() {
 			defer components.Pool.Close()
 			defer func() { _ = components.DB.Close() }()
 
-			count, err := components.UseCase.CompleteAllTodos(ctx)
+			count, err := components.UseCase.CompleteAllTodos(ctx, projectID)
 			if err != nil {
 				return fmt.Errorf("complete all: %w", err)
 			}
 
-			fmt.Printf("Marked %d todos as complete.\n", count)
+			fmt.Printf("Marked %d active todos as complete.\n", count)
+			return nil
+		},
+	}
+
+	completeAllCmd.Flags().StringVar(&completeAllProject, "project", "", "only complete the todos of this project ID")
+
+	var purgeOlderThan string
+	purgeCmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Permanently delete todos that have been in the trash for too long",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			olderThan, err := parseAge(purgeOlderThan)
+			if err != nil {
+				return fmt.Errorf("invalid --older-than: %w", err)
+			}
+
+			ctx, err := workspaceContext(workspace)
+			if err != nil {
+				return err
+			}
+			components, err := di.InitializeBatch(ctx)
+			if err != nil {
+				return fmt.Errorf("initialize: %w", err)
+			}
+			defer components.Pool.Close()
+			defer func() { _ = components.DB.Close() }()
+
+			count, err := components.UseCase.PurgeTrash(ctx, olderThan)
+			if err != nil {
+				return fmt.Errorf("purge: %w", err)
+			}
+
+			fmt.Printf("Purged %d todos from the trash.\n", count)
+			return nil
+		},
+	}
+	purgeCmd.Flags().StringVar(&purgeOlderThan, "older-than", "30d", "minimum time in the trash, e.g. \"30d\" or \"12h\"")
+
+	recurCmd := &cobra.Command{
+		Use:   "recur",
+		Short: "Manage recurring todos",
+	}
+
+	var materializeHorizon string
+	recurMaterializeCmd := &cobra.Command{
+		Use:   "materialize",
+		Short: "Create the upcoming occurrences of recurring todos",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			horizon, err := parseAge(materializeHorizon)
+			if err != nil {
+				return fmt.Errorf("invalid --horizon: %w", err)
+			}
+
+			ctx, err := workspaceContext(workspace)
+			if err != nil {
+				return err
+			}
+			components, err := di.InitializeBatch(ctx)
+			if err != nil {
+				return fmt.Errorf("initialize: %w", err)
+			}
+			defer components.Pool.Close()
+			defer func() { _ = components.DB.Close() }()
+
+			count, err := components.UseCase.MaterializeRecurrences(ctx, horizon)
+			if err != nil {
+				return fmt.Errorf("materialize: %w", err)
+			}
+
+			fmt.Printf("Created %d upcoming occurrences.\n", count)
+			return nil
+		},
+	}
+	recurMaterializeCmd.Flags().StringVar(&materializeHorizon, "horizon", "14d", "how far ahead to create occurrences, e.g. \"14d\" or \"36h\"")
+	recurCmd.AddCommand(recurMaterializeCmd)
+
+	apiKeyCmd := &cobra.Command{
+		Use:   "apikey",
+		Short: "Manage the API keys of service clients",
+	}
+
+	var (
+		apiKeyName    string
+		apiKeyScopes  []string
+		apiKeyExpires string
+	)
+	apiKeyCreateCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an API key and print its token",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			scopes := make([]domain.Scope, 0, len(apiKeyScopes))
+			for _, s := range apiKeyScopes {
+				scope, err := domain.ParseScope(s)
+				if err != nil {
+					return fmt.Errorf("invalid --scope %q: %w", s, err)
+				}
+				scopes = append(scopes, scope)
+			}
+			var expiresAt *time.Time
+			if apiKeyExpires != "" {
+				ttl, err := parseAge(apiKeyExpires)
+				if err != nil {
+					return fmt.Errorf("invalid --expires: %w", err)
+				}
+				t := time.Now().Add(ttl).UTC()
+				expiresAt = &t
+			}
+
+			ctx, err := workspaceContext(workspace)
+			if err != nil {
+				return err
+			}
+			components, err := di.InitializeBatch(ctx)
+			if err != nil {
+				return fmt.Errorf("initialize: %w", err)
+			}
+			defer components.Pool.Close()
+			defer func() { _ = components.DB.Close() }()
+
+			key, token, err := components.APIKeyUseCase.CreateAPIKey(ctx, apiKeyName, scopes, expiresAt)
+			if err != nil {
+				return fmt.Errorf("create api key: %w", err)
+			}
+
+			fmt.Printf("Created API key %s (%s).\n", key.ID, key.Name)
+			fmt.Println("Send it as \"Authorization: ApiKey <token>\". The token is shown only once:")
+			fmt.Println(token)
+			return nil
+		},
+	}
+	apiKeyCreateCmd.Flags().StringVar(&apiKeyName, "name", "", "name of the client the key is for")
+	apiKeyCreateCmd.Flags().StringSliceVar(&apiKeyScopes, "scope", []string{"read"}, "scopes granted to the key: read, write, admin or impersonate")
+	apiKeyCreateCmd.Flags().StringVar(&apiKeyExpires, "expires", "", "lifetime of the key, e.g. \"90d\" (default never expires)")
+
+	apiKeyListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List API keys",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			ctx, err := workspaceContext(workspace)
+			if err != nil {
+				return err
+			}
+			components, err := di.InitializeBatch(ctx)
+			if err != nil {
+				return fmt.Errorf("initialize: %w", err)
+			}
+			defer components.Pool.Close()
+			defer func() { _ = components.DB.Close() }()
+
+			keys, err := components.APIKeyUseCase.ListAPIKeys(ctx)
+			if err != nil {
+				return fmt.Errorf("list api keys: %w", err)
+			}
+			if len(keys) == 0 {
+				fmt.Println("No API keys found.")
+			}
+			for i := range keys {
+				fmt.Println(apiKeyLine(&keys[i]))
+			}
+			return nil
+		},
+	}
+
+	apiKeyRevokeCmd := &cobra.Command{
+		Use:   "revoke <id>",
+		Short: "Revoke an API key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid API key ID: %w", err)
+			}
+
+			ctx, err := workspaceContext(workspace)
+			if err != nil {
+				return err
+			}
+			components, err := di.InitializeBatch(ctx)
+			if err != nil {
+				return fmt.Errorf("initialize: %w", err)
+			}
+			defer components.Pool.Close()
+			defer func() { _ = components.DB.Close() }()
+
+			if err := components.APIKeyUseCase.RevokeAPIKey(ctx, id); err != nil {
+				return fmt.Errorf("revoke api key: %w", err)
+			}
+
+			fmt.Printf("Revoked API key %s.\n", id)
 			return nil
 		},
 	}
+	apiKeyCmd.AddCommand(apiKeyCreateCmd, apiKeyListCmd, apiKeyRevokeCmd)
+
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Work with the audit trail of todo changes",
+	}
+
+	var (
+		auditSince  string
+		auditOutput string
+	)
+	auditExportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the audit trail as NDJSON, one entry per line",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			since, err := parseSince(auditSince, time.Now())
+			if err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
 
-	rootCmd.AddCommand(migrateCmd, listCmd, completeAllCmd)
+			ctx, err := workspaceContext(workspace)
+			if err != nil {
+				return err
+			}
+			components, err := di.InitializeBatch(ctx)
+			if err != nil {
+				return fmt.Errorf("initialize: %w", err)
+			}
+			defer components.Pool.Close()
+			defer func() { _ = components.DB.Close() }()
+
+			out := os.Stdout
+			if auditOutput != "" && auditOutput != "-" {
+				if out, err = os.Create(auditOutput); err != nil {
+					return fmt.Errorf("create output: %w", err)
+				}
+			}
+			w := bufio.NewWriter(out)
+			enc := json.NewEncoder(w)
+			count, err := components.AuditUseCase.ExportAudit(ctx, since, func(e *domain.AuditEntry) error {
+				return enc.Encode(e)
+			})
+			if err == nil {
+				err = w.Flush()
+			}
+			if out != os.Stdout {
+				if cerr := out.Close(); err == nil {
+					err = cerr
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("export audit trail: %w", err)
+			}
+
+			fmt.Fprintf(os.Stderr, "Exported %d audit entries.\n", count)
+			return nil
+		},
+	}
+	auditExportCmd.Flags().StringVar(&auditSince, "since", "", "only export entries recorded since this time: RFC 3339, a date or an age such as \"7d\" (default everything)")
+	auditExportCmd.Flags().StringVarP(&auditOutput, "output", "o", "-", "file to write to, \"-\" for standard output")
+	auditCmd.AddCommand(auditExportCmd)
+
+	outboxCmd := &cobra.Command{
+		Use:   "outbox",
+		Short: "Deliver the domain events of todo changes",
+	}
+
+	var (
+		relayOnce      bool
+		relayBatchSize int
+		relayInterval  time.Duration
+	)
+	outboxRelayCmd := &cobra.Command{
+		Use:   "relay",
+		Short: "Deliver outbox events of every workspace to their webhooks and the sinks in OUTBOX_SINKS",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if relayBatchSize < 1 {
+				return errors.New("invalid --batch-size: must be positive")
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			components, err := di.InitializeBatch(ctx)
+			if err != nil {
+				return fmt.Errorf("initialize: %w", err)
+			}
+			defer components.Pool.Close()
+			defer func() { _ = components.DB.Close() }()
+
+			if !relayOnce {
+				components.Logger.Info("outbox relay started", slog.Any("sinks", components.Config.OutboxSinks))
+				return components.OutboxRelay.Run(ctx, relayBatchSize, relayInterval)
+			}
+			delivered, failed, err := components.OutboxRelay.RelayBatch(ctx, relayBatchSize)
+			if err != nil {
+				return fmt.Errorf("relay: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Delivered %d events, %d failed.\n", delivered, failed)
+			return nil
+		},
+	}
+	outboxRelayCmd.Flags().BoolVar(&relayOnce, "once", false, "deliver a single batch and exit instead of running until interrupted")
+	outboxRelayCmd.Flags().IntVar(&relayBatchSize, "batch-size", 100, "maximum number of events delivered per transaction")
+	outboxRelayCmd.Flags().DurationVar(&relayInterval, "interval", time.Second, "how long to wait when no event is due")
+	outboxCmd.AddCommand(outboxRelayCmd)
+
+	webhookCmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Send webhook deliveries",
+	}
+
+	var (
+		deliverOnce      bool
+		deliverBatchSize int
+		deliverInterval  time.Duration
+	)
+	webhookDeliverCmd := &cobra.Command{
+		Use:   "deliver",
+		Short: "Send the queued webhook deliveries of every workspace, retrying failed ones",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if deliverBatchSize < 1 {
+				return errors.New("invalid --batch-size: must be positive")
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			components, err := di.InitializeBatch(ctx)
+			if err != nil {
+				return fmt.Errorf("initialize: %w", err)
+			}
+			defer components.Pool.Close()
+			defer func() { _ = components.DB.Close() }()
+
+			if !deliverOnce {
+				components.Logger.Info("webhook worker started")
+				return components.WebhookWorker.Run(ctx, deliverBatchSize, deliverInterval)
+			}
+			succeeded, failed, err := components.WebhookWorker.SendBatch(ctx, deliverBatchSize)
+			if err != nil {
+				return fmt.Errorf("deliver: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Sent %d webhook deliveries, %d failed.\n", succeeded, failed)
+			return nil
+		},
+	}
+	webhookDeliverCmd.Flags().BoolVar(&deliverOnce, "once", false, "send a single batch and exit instead of running until interrupted")
+	webhookDeliverCmd.Flags().IntVar(&deliverBatchSize, "batch-size", 50, "maximum number of deliveries sent per transaction")
+	webhookDeliverCmd.Flags().DurationVar(&deliverInterval, "interval", time.Second, "how long to wait when no delivery is due")
+	webhookCmd.AddCommand(webhookDeliverCmd)
+
+	idempotencyCmd := &cobra.Command{
+		Use:   "idempotency",
+		Short: "Manage the keys of idempotent requests",
+	}
+
+	idempotencyGCCmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Delete the expired idempotency keys of every workspace",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			components, err := di.InitializeBatch(ctx)
+			if err != nil {
+				return fmt.Errorf("initialize: %w", err)
+			}
+			defer components.Pool.Close()
+			defer func() { _ = components.DB.Close() }()
+
+			count, err := components.Idempotency.PurgeExpired(ctx)
+			if err != nil {
+				return fmt.Errorf("gc: %w", err)
+			}
+
+			fmt.Printf("Deleted %d expired idempotency keys.\n", count)
+			return nil
+		},
+	}
+	idempotencyCmd.AddCommand(idempotencyGCCmd)
+
+	rootCmd.AddCommand(migrateCmd, listCmd, searchCmd, completeAllCmd, purgeCmd, recurCmd, apiKeyCmd, auditCmd, outboxCmd, webhookCmd, idempotencyCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
+
+// todoLine renders a todo as one line of the list command.
+func todoLine(t *domain.Todo) string {
+	line := fmt.Sprintf("%s %s %-6s %s", statusMark(t.Status), t.ID, t.Priority, t.Title)
+	for _, tag := range t.Tags {
+		line += " #" + tag
+	}
+	return line
+}
+
+// plainHighlight renders highlighted search text for the terminal, with
+// the matches in brackets.
+func plainHighlight(s string) string {
+	s = strings.NewReplacer(domain.HighlightStart, "[", domain.HighlightEnd, "]").Replace(s)
+	return html.UnescapeString(s)
+}
+
+// apiKeyLine renders an API key as one line of the apikey list command.
+func apiKeyLine(k *domain.APIKey) string {
+	scopes := make([]string, len(k.Scopes))
+	for i, s := range k.Scopes {
+		scopes[i] = string(s)
+	}
+	line := fmt.Sprintf("%s %-20s %s", k.ID, k.Name, strings.Join(scopes, ","))
+	switch now := time.Now(); {
+	case k.RevokedAt != nil:
+		line += " revoked " + k.RevokedAt.Format(time.RFC3339)
+	case k.ExpiresAt != nil && !now.Before(*k.ExpiresAt):
+		line += " expired " + k.ExpiresAt.Format(time.RFC3339)
+	case k.ExpiresAt != nil:
+		line += " expires " + k.ExpiresAt.Format(time.RFC3339)
+	}
+	if k.LastUsedAt != nil {
+		line += " last used " + k.LastUsedAt.Format(time.RFC3339)
+	} else {
+		line += " never used"
+	}
+	return line
+}
+
+// printTree prints todos with each subtask indented under its parent,
+// keeping the list order among siblings. Subtasks whose parent is not in
+// todos are printed at the top level.
+func printTree(todos []domain.Todo) {
+	listed := make(map[uuid.UUID]bool, len(todos))
+	for _, t := range todos {
+		listed[t.ID] = true
+	}
+	children := make(map[uuid.UUID][]*domain.Todo)
+	var roots []*domain.Todo
+	for i := range todos {
+		t := &todos[i]
+		if t.ParentID != nil && listed[*t.ParentID] {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	var printNode func(t *domain.Todo, depth int)
+	printNode = func(t *domain.Todo, depth int) {
+		fmt.Println(strings.Repeat("    ", depth) + todoLine(t))
+		for _, child := range children[t.ID] {
+			printNode(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		printNode(root, 0)
+	}
+}
+
+// statusMark renders a todo status as a checkbox for the list command.
+func statusMark(status domain.Status) string {
+	switch status {
+	case domain.StatusInProgress:
+		return "[~]"
+	case domain.StatusDone:
+		return "[x]"
+	case domain.StatusCancelled:
+		return "[-]"
+	default:
+		return "[ ]"
+	}
+}
+
+// workspaceContext returns a context acting in the workspace named by the
+// --workspace flag, for the batch service rather than a user.
+func workspaceContext(workspace string) (context.Context, error) {
+	if workspace == "" {
+		return nil, fmt.Errorf("--workspace is required")
+	}
+	id, err := uuid.Parse(workspace)
+	if err != nil || id == uuid.Nil {
+		return nil, fmt.Errorf("invalid --workspace %q", workspace)
+	}
+	return actor.AsService(tenant.WithWorkspace(context.Background(), id)), nil
+}
+
+// parseProjectID parses the value of a --project flag; empty means no project.
+func parseProjectID(s string) (*uuid.UUID, error) {
+	if s == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// parseSince parses the value of a --since flag: an RFC 3339 time, a date
+// ("2006-01-02", midnight UTC) or an age before now as accepted by parseAge.
+// Empty means the beginning of time.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	age, err := parseAge(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a time, a date nor an age", s)
+	}
+	return now.Add(-age), nil
+}
+
+// parseAge parses a duration such as "30d" or "12h". In addition to the
+// time.ParseDuration units it accepts whole days ("d").
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
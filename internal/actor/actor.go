@@ -0,0 +1,43 @@
+// Package actor carries the user a request acts as through a
+// context.Context. Requests of service clients and batch jobs act as no
+// user, but for a service instead.
+package actor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoUser reports an operation that needs the acting user, such as
+// listing "my" todos, without a user in its context.
+var ErrNoUser = errors.New("no acting user")
+
+type contextKey struct{}
+
+// WithUser returns a copy of ctx acting as user id.
+func WithUser(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// UserID returns the user ctx acts as, if any.
+func UserID(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(contextKey{}).(uuid.UUID)
+	return id, ok && id != uuid.Nil
+}
+
+type serviceKey struct{}
+
+// AsService returns a copy of ctx acting for a trusted service, such as a
+// batch job or an API key client, rather than a user. Roles do not apply
+// to services, which their API key scopes limit instead.
+func AsService(ctx context.Context) context.Context {
+	return context.WithValue(ctx, serviceKey{}, true)
+}
+
+// IsService reports whether ctx acts for a trusted service.
+func IsService(ctx context.Context) bool {
+	ok, _ := ctx.Value(serviceKey{}).(bool)
+	return ok
+}
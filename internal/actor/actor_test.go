@@ -0,0 +1,28 @@
+package actor_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserID(t *testing.T) {
+	_, ok := actor.UserID(context.Background())
+	assert.False(t, ok)
+
+	_, ok = actor.UserID(actor.WithUser(context.Background(), uuid.Nil))
+	assert.False(t, ok)
+
+	id := uuid.New()
+	got, ok := actor.UserID(actor.WithUser(context.Background(), id))
+	assert.True(t, ok)
+	assert.Equal(t, id, got)
+}
+
+func TestIsService(t *testing.T) {
+	assert.False(t, actor.IsService(context.Background()))
+	assert.True(t, actor.IsService(actor.AsService(context.Background())))
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// APIKeyStore looks up the API key a token belongs to.
+type APIKeyStore interface {
+	Authenticate(ctx context.Context, token string) (*domain.APIKey, error)
+}
+
+// APIKeyAuthenticator authenticates API key tokens. Its callers act in the
+// workspace of their key and are limited to the key's scopes.
+type APIKeyAuthenticator struct {
+	keys APIKeyStore
+}
+
+func NewAPIKeyAuthenticator(keys APIKeyStore) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{keys: keys}
+}
+
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	key, err := a.keys.Authenticate(ctx, token)
+	if errors.Is(err, domain.ErrUnauthenticated) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Principal{
+		Subject: "apikey:" + key.ID.String(),
+		Claims: map[string]any{
+			WorkspaceClaim: key.WorkspaceID.String(),
+			"name":         key.Name,
+		},
+		Scopes: key.Scopes,
+		Client: true,
+	}, nil
+}
@@ -0,0 +1,47 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/auth"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type keyStore map[string]*domain.APIKey
+
+func (s keyStore) Authenticate(_ context.Context, token string) (*domain.APIKey, error) {
+	if token == "broken" {
+		return nil, errors.New("db down")
+	}
+	if key, ok := s[token]; ok {
+		return key, nil
+	}
+	return nil, domain.ErrUnauthenticated
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	key := &domain.APIKey{ID: uuid.New(), WorkspaceID: uuid.New(), Name: "ci", Scopes: []domain.Scope{domain.ScopeWrite}}
+	a := auth.NewAPIKeyAuthenticator(keyStore{"tdk_ok": key})
+
+	p, err := a.Authenticate(context.Background(), "tdk_ok")
+	require.NoError(t, err)
+	assert.Equal(t, "apikey:"+key.ID.String(), p.Subject)
+	assert.True(t, p.Client)
+	ws, _ := p.StringClaim(auth.WorkspaceClaim)
+	assert.Equal(t, key.WorkspaceID.String(), ws)
+	assert.True(t, p.HasScope(domain.ScopeRead))
+	assert.True(t, p.HasScope(domain.ScopeWrite))
+	assert.False(t, p.HasScope(domain.ScopeAdmin))
+
+	_, err = a.Authenticate(context.Background(), "tdk_unknown")
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	_, err = a.Authenticate(context.Background(), "broken")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, auth.ErrInvalidToken, "store failures are not the caller's fault")
+}
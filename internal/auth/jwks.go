@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksRefreshInterval limits how often a remote key set is fetched again
+// when a token names a key it does not contain.
+const jwksRefreshInterval = 5 * time.Minute
+
+// KeySet holds the public keys that verify bearer tokens, by key ID. A key
+// set loaded from a URL is fetched again when a token names an unknown key,
+// so that rotated keys are picked up.
+type KeySet struct {
+	source string
+	client *http.Client
+
+	mu   sync.Mutex
+	keys map[string]crypto.PublicKey
+	// fetched is when the key set was last fetched, or attempted to be.
+	fetched time.Time
+	// refreshing is closed once the fetch in progress, if any, is done.
+	refreshing chan struct{}
+}
+
+// NewKeySet returns a fixed key set.
+func NewKeySet(keys map[string]crypto.PublicKey) *KeySet {
+	return &KeySet{keys: keys}
+}
+
+// LoadKeySet reads a JSON Web Key Set (RFC 7517) from a file path or an
+// http(s) URL. RSA, EC and Ed25519 signature keys are used; other keys are
+// ignored.
+func LoadKeySet(ctx context.Context, source string) (*KeySet, error) {
+	s := &KeySet{source: source, client: &http.Client{Timeout: 10 * time.Second}}
+	keys, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.keys, s.fetched = keys, time.Now()
+	return s, nil
+}
+
+// key returns the key with the given ID. An empty ID selects the only key
+// of a set with a single key.
+//
+// An unknown ID fetches a remote key set again, at most once per
+// jwksRefreshInterval whether or not the fetch succeeds. Lookups of other
+// unknown IDs wait for the fetch; those of known keys do not.
+func (s *KeySet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	if key, ok := s.lookup(kid); ok {
+		s.mu.Unlock()
+		return key, nil
+	}
+	done := s.refreshing
+	if done == nil && s.isRemote() && time.Since(s.fetched) >= jwksRefreshInterval {
+		done = make(chan struct{})
+		s.refreshing, s.fetched = done, time.Now()
+		s.mu.Unlock()
+
+		keys, err := s.fetch(ctx)
+		s.mu.Lock()
+		if err == nil {
+			s.keys = keys
+		}
+		s.refreshing = nil
+		close(done)
+		s.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		s.mu.Unlock()
+		if done != nil {
+			select {
+			case <-done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key, ok := s.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+func (s *KeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *KeySet) isRemote() bool {
+	return strings.HasPrefix(s.source, "http://") || strings.HasPrefix(s.source, "https://")
+}
+
+// fetch loads the keys from the source of the key set.
+func (s *KeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	data, err := s.read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read JWKS %s: %w", s.source, err)
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse JWKS %s: %w", s.source, err)
+	}
+	return keys, nil
+}
+
+func (s *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !s.isRemote() {
+		return os.ReadFile(s.source)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// jwk is a JSON Web Key (RFC 7517, RFC 7518 and RFC 8037).
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS decodes the signature keys of a JSON Web Key Set by key ID.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if errors.Is(err, errUnsupportedKey) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no usable signature keys")
+	}
+	return keys, nil
+}
+
+var errUnsupportedKey = errors.New("unsupported key type")
+
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("n: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errUnsupportedKey
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		size := (curve.Params().BitSize + 7) / 8
+		if errX != nil || errY != nil || len(x) != size || len(y) != size {
+			return nil, errors.New("invalid point")
+		}
+		return ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, errUnsupportedKey
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, errUnsupportedKey
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySet_RefreshFails(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	var fetches atomic.Int32
+	entered, release := make(chan struct{}, 1), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		entered <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	s := &KeySet{source: srv.URL, client: srv.Client(), keys: map[string]crypto.PublicKey{"known": pub}}
+	ctx := context.Background()
+
+	failed := make(chan error, 1)
+	go func() {
+		_, err := s.key(ctx, "bogus")
+		failed <- err
+	}()
+	<-entered
+
+	// Known keys are not held up by the fetch.
+	key, err := s.key(ctx, "known")
+	require.NoError(t, err)
+	assert.Equal(t, pub, key)
+
+	// Other unknown keys wait for it, as long as their request does.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = s.key(cancelled, "bogus")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+	select {
+	case err := <-failed:
+		assert.ErrorContains(t, err, "500")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fetch did not end")
+	}
+
+	// The failed attempt counts as a fetch, so unknown keys do not fetch
+	// again until the interval has passed.
+	for range 3 {
+		_, err = s.key(ctx, "bogus")
+		assert.ErrorContains(t, err, `unknown key "bogus"`)
+	}
+	assert.Equal(t, int32(1), fetches.Load())
+	_, err = s.key(ctx, "known")
+	assert.NoError(t, err, "the keys are kept")
+}
@@ -0,0 +1,57 @@
+// Package auth authenticates API callers and carries who they are through a
+// context.Context.
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// WorkspaceClaim names the claim that binds a caller to a workspace.
+const WorkspaceClaim = "workspace_id"
+
+// Authenticator authenticates the credentials of one Authorization header
+// scheme. Credentials that do not authenticate anyone are reported as
+// ErrInvalidToken.
+type Authenticator interface {
+	Authenticate(ctx context.Context, credentials string) (*Principal, error)
+}
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Subject identifies the caller; it is the "sub" claim of its token.
+	Subject string
+	// Claims holds every claim of the caller's token.
+	Claims map[string]any
+	// Scopes limits what the caller may do; nil means no limit.
+	Scopes []domain.Scope
+	// Client marks a trusted client, such as an API key, rather than a
+	// user: it names the user it acts for in a request header, if any.
+	Client bool
+}
+
+// HasScope reports whether the caller may do what scope grants.
+func (p *Principal) HasScope(scope domain.Scope) bool {
+	return p.Scopes == nil || slices.ContainsFunc(p.Scopes, func(s domain.Scope) bool { return s.Includes(scope) })
+}
+
+// StringClaim returns the named claim if it is a non-empty string.
+func (p *Principal) StringClaim(name string) (string, bool) {
+	s, ok := p.Claims[name].(string)
+	return s, ok && s != ""
+}
+
+type contextKey struct{}
+
+// WithPrincipal returns a copy of ctx authenticated as p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// PrincipalFrom returns the caller ctx is authenticated as, if any.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken reports a bearer token that does not authenticate anyone.
+var ErrInvalidToken = errors.New("invalid token")
+
+// tokenLeeway tolerates clock skew between the issuer and the API.
+const tokenLeeway = 30 * time.Second
+
+// Verifier authenticates JWT bearer tokens signed with RS256, ES256 or
+// EdDSA by a key of its key set.
+type Verifier struct {
+	keys   *KeySet
+	parser *jwt.Parser
+}
+
+// NewVerifier returns a Verifier for tokens signed by keys. Non-empty
+// issuer and audience must match the token's "iss" and "aud" claims.
+func NewVerifier(keys *KeySet, issuer, audience string) *Verifier {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(tokenLeeway),
+	}
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+	return &Verifier{keys: keys, parser: jwt.NewParser(opts...)}
+}
+
+// Authenticate checks the signature and the registered claims of token and
+// returns the caller it authenticates. Tokens without a subject are
+// rejected. All failures wrap ErrInvalidToken.
+func (v *Verifier) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+	return &Principal{Subject: subject, Claims: claims}, nil
+}
@@ -0,0 +1,167 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/knjname/go-todo-api/internal/auth"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKeys struct {
+	rsa     *rsa.PrivateKey
+	ecdsa   *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}
+
+func generateKeys(t *testing.T) testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return testKeys{rsa: rsaKey, ecdsa: ecKey, ed25519: edKey}
+}
+
+// jwks encodes the public halves of keys as a JSON Web Key Set.
+func (k testKeys) jwks(t *testing.T) []byte {
+	t.Helper()
+	b64 := base64.RawURLEncoding.EncodeToString
+	ecPoint, err := k.ecdsa.PublicKey.Bytes()
+	require.NoError(t, err)
+	data, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecPoint[1:33]), "y": b64(ecPoint[33:])},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(k.ed25519.Public().(ed25519.PublicKey))},
+		{"kty": "oct", "kid": "hmac", "k": b64([]byte("secret"))},
+	}})
+	require.NoError(t, err)
+	return data
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key crypto.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	require.NoError(t, err)
+	return s
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": "user-1",
+		"iss": "https://issuer.example",
+		"aud": "todo-api",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	keys := generateKeys(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, keys.jwks(t), 0o600))
+	set, err := auth.LoadKeySet(context.Background(), path)
+	require.NoError(t, err)
+	v := auth.NewVerifier(set, "https://issuer.example", "todo-api")
+
+	with := func(name string, value any) jwt.MapClaims {
+		c := validClaims()
+		if value == nil {
+			delete(c, name)
+		} else {
+			c[name] = value
+		}
+		return c
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{name: "RS256", token: sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, validClaims()), valid: true},
+		{name: "ES256", token: sign(t, jwt.SigningMethodES256, "ec", keys.ecdsa, validClaims()), valid: true},
+		{name: "EdDSA", token: sign(t, jwt.SigningMethodEdDSA, "ed", keys.ed25519, validClaims()), valid: true},
+		{name: "wrong key", token: sign(t, jwt.SigningMethodRS256, "ec", keys.rsa, validClaims())},
+		{name: "unknown kid", token: sign(t, jwt.SigningMethodRS256, "other", keys.rsa, validClaims())},
+		{name: "no kid with several keys", token: sign(t, jwt.SigningMethodRS256, "", keys.rsa, validClaims())},
+		{name: "HS256", token: sign(t, jwt.SigningMethodHS256, "hmac", []byte("secret"), validClaims())},
+		{name: "expired", token: sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, with("exp", time.Now().Add(-time.Hour).Unix()))},
+		{name: "no expiry", token: sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, with("exp", nil))},
+		{name: "wrong issuer", token: sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, with("iss", "https://evil.example"))},
+		{name: "wrong audience", token: sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, with("aud", "other"))},
+		{name: "no subject", token: sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, with("sub", nil))},
+		{name: "garbage", token: "not.a.token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := v.Authenticate(context.Background(), tt.token)
+			if !tt.valid {
+				assert.ErrorIs(t, err, auth.ErrInvalidToken)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", p.Subject)
+			assert.Equal(t, "https://issuer.example", p.Claims["iss"])
+		})
+	}
+}
+
+func TestVerifier_SingleKeyWithoutKid(t *testing.T) {
+	keys := generateKeys(t)
+	v := auth.NewVerifier(auth.NewKeySet(map[string]crypto.PublicKey{"only": keys.ed25519.Public()}), "", "")
+
+	p, err := v.Authenticate(context.Background(), sign(t, jwt.SigningMethodEdDSA, "", keys.ed25519, jwt.MapClaims{
+		"sub": "user-2", "exp": time.Now().Add(time.Minute).Unix(), "workspace_id": "ws",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "user-2", p.Subject)
+	assert.True(t, p.HasScope(domain.ScopeAdmin), "token callers are not limited by scopes")
+	ws, ok := p.StringClaim("workspace_id")
+	assert.True(t, ok)
+	assert.Equal(t, "ws", ws)
+}
+
+func TestLoadKeySet(t *testing.T) {
+	keys := generateKeys(t)
+	data := keys.jwks(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	set, err := auth.LoadKeySet(context.Background(), srv.URL)
+	require.NoError(t, err)
+	v := auth.NewVerifier(set, "", "")
+	_, err = v.Authenticate(context.Background(), sign(t, jwt.SigningMethodES256, "ec", keys.ecdsa, validClaims()))
+	assert.NoError(t, err)
+
+	_, err = auth.LoadKeySet(context.Background(), filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	_, err = auth.ParseJWKS([]byte(`{"keys":[{"kty":"oct","kid":"hmac","k":"c2VjcmV0"}]}`))
+	assert.Error(t, err, "a key set without signature keys is rejected")
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knjname/go-todo-api/internal/auth"
 	"github.com/knjname/go-todo-api/internal/config"
 	"github.com/knjname/go-todo-api/internal/repository/postgres"
 	"github.com/knjname/go-todo-api/internal/usecase"
@@ -13,18 +14,40 @@ import (
 )
 
 type APIComponents struct {
-	Config  *config.Config
-	UseCase *usecase.TodoUseCase
-	Logger  *slog.Logger
-	Pool    *pgxpool.Pool
+	Config         *config.Config
+	UseCase        *usecase.TodoUseCase
+	TagUseCase     *usecase.TagUseCase
+	ProjectUseCase *usecase.ProjectUseCase
+	APIKeyUseCase  *usecase.APIKeyUseCase
+	UserUseCase    *usecase.UserUseCase
+	AuditUseCase   *usecase.AuditUseCase
+	WebhookUseCase *usecase.WebhookUseCase
+	WebhookWorker  *usecase.WebhookWorker
+	EventStream    *usecase.EventStream
+	Presence       *usecase.PresenceTracker
+	Idempotency    *usecase.IdempotencyUseCase
+	Verifier       *auth.Verifier
+	Logger         *slog.Logger
+	Pool           *pgxpool.Pool
 }
 
-func NewAPIComponents(cfg *config.Config, uc *usecase.TodoUseCase, logger *slog.Logger, pool *pgxpool.Pool) *APIComponents {
+func NewAPIComponents(cfg *config.Config, uc *usecase.TodoUseCase, tagUC *usecase.TagUseCase, projectUC *usecase.ProjectUseCase, apiKeyUC *usecase.APIKeyUseCase, userUC *usecase.UserUseCase, auditUC *usecase.AuditUseCase, webhookUC *usecase.WebhookUseCase, webhookWorker *usecase.WebhookWorker, eventStream *usecase.EventStream, presence *usecase.PresenceTracker, idempotency *usecase.IdempotencyUseCase, verifier *auth.Verifier, logger *slog.Logger, pool *pgxpool.Pool) *APIComponents {
 	return &APIComponents{
-		Config:  cfg,
-		UseCase: uc,
-		Logger:  logger,
-		Pool:    pool,
+		Config:         cfg,
+		UseCase:        uc,
+		TagUseCase:     tagUC,
+		ProjectUseCase: projectUC,
+		APIKeyUseCase:  apiKeyUC,
+		UserUseCase:    userUC,
+		AuditUseCase:   auditUC,
+		WebhookUseCase: webhookUC,
+		WebhookWorker:  webhookWorker,
+		EventStream:    eventStream,
+		Presence:       presence,
+		Idempotency:    idempotency,
+		Verifier:       verifier,
+		Logger:         logger,
+		Pool:           pool,
 	}
 }
 
@@ -32,7 +55,30 @@ var _ = kessoku.Inject[*APIComponents]("InitializeAPI",
 	kessoku.Provide(config.Load),
 	kessoku.Provide(NewLogger),
 	kessoku.Async(kessoku.Provide(NewPool)),
+	kessoku.Provide(NewVerifier),
 	kessoku.Bind[usecase.TodoRepository](kessoku.Provide(postgres.NewTodoRepository)),
+	kessoku.Bind[usecase.ProjectRepository](kessoku.Provide(postgres.NewProjectRepository)),
+	kessoku.Bind[usecase.UserRepository](kessoku.Provide(postgres.NewUserRepository)),
+	kessoku.Bind[usecase.Policy](kessoku.Provide(usecase.NewRolePolicy)),
+	kessoku.Bind[usecase.AuditRepository](kessoku.Provide(postgres.NewAuditRepository)),
+	kessoku.Provide(usecase.NewAuditUseCase),
 	kessoku.Provide(usecase.NewTodoUseCase),
+	kessoku.Provide(usecase.NewProjectUseCase),
+	kessoku.Bind[usecase.TagRepository](kessoku.Provide(postgres.NewTagRepository)),
+	kessoku.Provide(usecase.NewTagUseCase),
+	kessoku.Bind[usecase.APIKeyRepository](kessoku.Provide(postgres.NewAPIKeyRepository)),
+	kessoku.Provide(usecase.NewAPIKeyUseCase),
+	kessoku.Provide(usecase.NewUserUseCase),
+	kessoku.Bind[usecase.WebhookRepository](kessoku.Provide(postgres.NewWebhookRepository)),
+	kessoku.Bind[usecase.WebhookDeliveryRepository](kessoku.Provide(postgres.NewWebhookDeliveryRepository)),
+	kessoku.Bind[usecase.WebhookSender](kessoku.Provide(NewWebhookSender)),
+	kessoku.Provide(usecase.NewWebhookUseCase),
+	kessoku.Provide(usecase.NewWebhookWorker),
+	kessoku.Bind[usecase.OutboxRepository](kessoku.Provide(postgres.NewOutboxRepository)),
+	kessoku.Provide(usecase.NewEventStream),
+	kessoku.Bind[usecase.PresenceRepository](kessoku.Provide(postgres.NewPresenceRepository)),
+	kessoku.Provide(usecase.NewPresenceTracker),
+	kessoku.Bind[usecase.IdempotencyRepository](kessoku.Provide(postgres.NewIdempotencyRepository)),
+	kessoku.Provide(usecase.NewIdempotencyUseCase),
 	kessoku.Provide(NewAPIComponents),
 )
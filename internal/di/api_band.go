@@ -18,14 +18,42 @@ func InitializeAPI(ctx context.Context) (*APIComponents, error) {
 		return zero, err
 	}
 	logger := kessoku.Provide(NewLogger).Fn()()
+	webhookSender := kessoku.Bind[usecase.WebhookSender](kessoku.Provide(NewWebhookSender)).Fn()()
 	var err0 error
-	pool, err0 := kessoku.Async(kessoku.Provide(NewPool)).Fn()(ctx, config0)
+	verifier, err0 := kessoku.Provide(NewVerifier).Fn()(ctx, config0, logger)
 	if err0 != nil {
 		var zero *APIComponents
 		return zero, err0
 	}
+	var err1 error
+	pool, err1 := kessoku.Async(kessoku.Provide(NewPool)).Fn()(ctx, config0)
+	if err1 != nil {
+		var zero *APIComponents
+		return zero, err1
+	}
 	todoRepository := kessoku.Bind[usecase.TodoRepository](kessoku.Provide(postgres.NewTodoRepository)).Fn()(pool)
-	todoUseCase := kessoku.Provide(usecase.NewTodoUseCase).Fn()(todoRepository, logger)
-	apicomponents := kessoku.Provide(NewAPIComponents).Fn()(config0, todoUseCase, logger, pool)
+	projectRepository := kessoku.Bind[usecase.ProjectRepository](kessoku.Provide(postgres.NewProjectRepository)).Fn()(pool)
+	userRepository := kessoku.Bind[usecase.UserRepository](kessoku.Provide(postgres.NewUserRepository)).Fn()(pool)
+	tagRepository := kessoku.Bind[usecase.TagRepository](kessoku.Provide(postgres.NewTagRepository)).Fn()(pool)
+	apikeyRepository := kessoku.Bind[usecase.APIKeyRepository](kessoku.Provide(postgres.NewAPIKeyRepository)).Fn()(pool)
+	auditRepository := kessoku.Bind[usecase.AuditRepository](kessoku.Provide(postgres.NewAuditRepository)).Fn()(pool)
+	webhookRepository := kessoku.Bind[usecase.WebhookRepository](kessoku.Provide(postgres.NewWebhookRepository)).Fn()(pool)
+	webhookDeliveryRepository := kessoku.Bind[usecase.WebhookDeliveryRepository](kessoku.Provide(postgres.NewWebhookDeliveryRepository)).Fn()(pool)
+	outboxRepository := kessoku.Bind[usecase.OutboxRepository](kessoku.Provide(postgres.NewOutboxRepository)).Fn()(pool)
+	presenceRepository := kessoku.Bind[usecase.PresenceRepository](kessoku.Provide(postgres.NewPresenceRepository)).Fn()(pool)
+	idempotencyRepository := kessoku.Bind[usecase.IdempotencyRepository](kessoku.Provide(postgres.NewIdempotencyRepository)).Fn()(pool)
+	projectUseCase := kessoku.Provide(usecase.NewProjectUseCase).Fn()(projectRepository, logger)
+	rolePolicy := kessoku.Bind[usecase.Policy](kessoku.Provide(usecase.NewRolePolicy)).Fn()(userRepository)
+	tagUseCase := kessoku.Provide(usecase.NewTagUseCase).Fn()(tagRepository, logger)
+	apikeyUseCase := kessoku.Provide(usecase.NewAPIKeyUseCase).Fn()(apikeyRepository, logger)
+	webhookWorker := kessoku.Provide(usecase.NewWebhookWorker).Fn()(webhookDeliveryRepository, webhookSender, logger)
+	idempotencyUseCase := kessoku.Provide(usecase.NewIdempotencyUseCase).Fn()(idempotencyRepository, logger)
+	todoUseCase := kessoku.Provide(usecase.NewTodoUseCase).Fn()(todoRepository, projectRepository, userRepository, rolePolicy, logger)
+	userUseCase := kessoku.Provide(usecase.NewUserUseCase).Fn()(userRepository, rolePolicy, logger)
+	auditUseCase := kessoku.Provide(usecase.NewAuditUseCase).Fn()(auditRepository, rolePolicy, logger)
+	webhookUseCase := kessoku.Provide(usecase.NewWebhookUseCase).Fn()(webhookRepository, webhookDeliveryRepository, rolePolicy, logger)
+	eventStream := kessoku.Provide(usecase.NewEventStream).Fn()(outboxRepository, rolePolicy, logger)
+	presenceTracker := kessoku.Provide(usecase.NewPresenceTracker).Fn()(presenceRepository, rolePolicy, logger)
+	apicomponents := kessoku.Provide(NewAPIComponents).Fn()(config0, todoUseCase, tagUseCase, projectUseCase, apikeyUseCase, userUseCase, auditUseCase, webhookUseCase, webhookWorker, eventStream, presenceTracker, idempotencyUseCase, verifier, logger, pool)
 	return apicomponents, nil
 }
@@ -14,20 +14,30 @@ import (
 )
 
 type BatchComponents struct {
-	Config  *config.Config
-	UseCase *usecase.TodoUseCase
-	Logger  *slog.Logger
-	Pool    *pgxpool.Pool
-	DB      *sql.DB
+	Config        *config.Config
+	UseCase       *usecase.TodoUseCase
+	APIKeyUseCase *usecase.APIKeyUseCase
+	AuditUseCase  *usecase.AuditUseCase
+	OutboxRelay   *usecase.OutboxRelay
+	WebhookWorker *usecase.WebhookWorker
+	Idempotency   *usecase.IdempotencyUseCase
+	Logger        *slog.Logger
+	Pool          *pgxpool.Pool
+	DB            *sql.DB
 }
 
-func NewBatchComponents(cfg *config.Config, uc *usecase.TodoUseCase, logger *slog.Logger, pool *pgxpool.Pool, db *sql.DB) *BatchComponents {
+func NewBatchComponents(cfg *config.Config, uc *usecase.TodoUseCase, apiKeyUC *usecase.APIKeyUseCase, auditUC *usecase.AuditUseCase, relay *usecase.OutboxRelay, webhookWorker *usecase.WebhookWorker, idempotency *usecase.IdempotencyUseCase, logger *slog.Logger, pool *pgxpool.Pool, db *sql.DB) *BatchComponents {
 	return &BatchComponents{
-		Config:  cfg,
-		UseCase: uc,
-		Logger:  logger,
-		Pool:    pool,
-		DB:      db,
+		Config:        cfg,
+		UseCase:       uc,
+		APIKeyUseCase: apiKeyUC,
+		AuditUseCase:  auditUC,
+		OutboxRelay:   relay,
+		WebhookWorker: webhookWorker,
+		Idempotency:   idempotency,
+		Logger:        logger,
+		Pool:          pool,
+		DB:            db,
 	}
 }
 
@@ -37,6 +47,23 @@ var _ = kessoku.Inject[*BatchComponents]("InitializeBatch",
 	kessoku.Async(kessoku.Provide(NewPool)),
 	kessoku.Async(kessoku.Provide(NewStdDB)),
 	kessoku.Bind[usecase.TodoRepository](kessoku.Provide(postgres.NewTodoRepository)),
+	kessoku.Bind[usecase.ProjectRepository](kessoku.Provide(postgres.NewProjectRepository)),
+	kessoku.Bind[usecase.UserRepository](kessoku.Provide(postgres.NewUserRepository)),
+	kessoku.Bind[usecase.Policy](kessoku.Provide(usecase.NewRolePolicy)),
+	kessoku.Bind[usecase.AuditRepository](kessoku.Provide(postgres.NewAuditRepository)),
+	kessoku.Provide(usecase.NewAuditUseCase),
 	kessoku.Provide(usecase.NewTodoUseCase),
+	kessoku.Bind[usecase.APIKeyRepository](kessoku.Provide(postgres.NewAPIKeyRepository)),
+	kessoku.Provide(usecase.NewAPIKeyUseCase),
+	kessoku.Bind[usecase.OutboxRepository](kessoku.Provide(postgres.NewOutboxRepository)),
+	kessoku.Bind[usecase.WebhookRepository](kessoku.Provide(postgres.NewWebhookRepository)),
+	kessoku.Bind[usecase.WebhookDeliveryRepository](kessoku.Provide(postgres.NewWebhookDeliveryRepository)),
+	kessoku.Bind[usecase.WebhookSender](kessoku.Provide(NewWebhookSender)),
+	kessoku.Provide(usecase.NewWebhookUseCase),
+	kessoku.Provide(usecase.NewWebhookWorker),
+	kessoku.Provide(NewEventSinks),
+	kessoku.Provide(usecase.NewOutboxRelay),
+	kessoku.Bind[usecase.IdempotencyRepository](kessoku.Provide(postgres.NewIdempotencyRepository)),
+	kessoku.Provide(usecase.NewIdempotencyUseCase),
 	kessoku.Provide(NewBatchComponents),
 )
@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/knjname/go-todo-api/internal/config"
+	"github.com/knjname/go-todo-api/internal/eventsink"
 	"github.com/knjname/go-todo-api/internal/repository/postgres"
 	"github.com/knjname/go-todo-api/internal/usecase"
 	"github.com/mazrean/kessoku"
@@ -16,17 +17,40 @@ import (
 
 func InitializeBatch(ctx context.Context) (*BatchComponents, error) {
 	var (
-		config0         *config.Config
-		configCh        = make(chan struct{})
-		logger          *slog.Logger
-		loggerCh        = make(chan struct{})
-		db              *sql.DB
-		pool            *pgxpool.Pool
-		poolCh          = make(chan struct{})
-		todoRepository  *postgres.TodoRepository
-		todoUseCase     *usecase.TodoUseCase
-		todoUseCaseCh   = make(chan struct{})
-		batchComponents *BatchComponents
+		config0                     *config.Config
+		configCh                    = make(chan struct{})
+		logger                      *slog.Logger
+		loggerCh                    = make(chan struct{})
+		webhookSender               *eventsink.WebhookSender
+		db                          *sql.DB
+		pool                        *pgxpool.Pool
+		poolCh                      = make(chan struct{})
+		todoRepository              *postgres.TodoRepository
+		projectRepository           *postgres.ProjectRepository
+		userRepository              *postgres.UserRepository
+		apikeyRepository            *postgres.APIKeyRepository
+		apikeyRepositoryCh          = make(chan struct{})
+		auditRepository             *postgres.AuditRepository
+		outboxRepository            *postgres.OutboxRepository
+		outboxRepositoryCh          = make(chan struct{})
+		webhookDeliveryRepository   *postgres.WebhookDeliveryRepository
+		webhookDeliveryRepositoryCh = make(chan struct{})
+		idempotencyRepository       *postgres.IdempotencyRepository
+		idempotencyRepositoryCh     = make(chan struct{})
+		webhookRepository           *postgres.WebhookRepository
+		rolePolicy                  *usecase.RolePolicy
+		apikeyUseCase               *usecase.APIKeyUseCase
+		webhookWorker               *usecase.WebhookWorker
+		idempotencyUseCase          *usecase.IdempotencyUseCase
+		todoUseCase                 *usecase.TodoUseCase
+		todoUseCaseCh               = make(chan struct{})
+		auditUseCase                *usecase.AuditUseCase
+		auditUseCaseCh              = make(chan struct{})
+		webhookUseCase              *usecase.WebhookUseCase
+		webhookUseCaseCh            = make(chan struct{})
+		val                         []usecase.EventSink
+		outboxRelay                 *usecase.OutboxRelay
+		batchComponents             *BatchComponents
 	)
 	eg, ctx := errgroup.WithContext(ctx)
 	eg.Go(func() error {
@@ -42,13 +66,40 @@ func InitializeBatch(ctx context.Context) (*BatchComponents, error) {
 		}
 		close(poolCh)
 		todoRepository = kessoku.Bind[usecase.TodoRepository](kessoku.Provide(postgres.NewTodoRepository)).Fn()(pool)
+		projectRepository = kessoku.Bind[usecase.ProjectRepository](kessoku.Provide(postgres.NewProjectRepository)).Fn()(pool)
+		userRepository = kessoku.Bind[usecase.UserRepository](kessoku.Provide(postgres.NewUserRepository)).Fn()(pool)
+		apikeyRepository = kessoku.Bind[usecase.APIKeyRepository](kessoku.Provide(postgres.NewAPIKeyRepository)).Fn()(pool)
+		close(apikeyRepositoryCh)
+		auditRepository = kessoku.Bind[usecase.AuditRepository](kessoku.Provide(postgres.NewAuditRepository)).Fn()(pool)
+		outboxRepository = kessoku.Bind[usecase.OutboxRepository](kessoku.Provide(postgres.NewOutboxRepository)).Fn()(pool)
+		close(outboxRepositoryCh)
+		webhookDeliveryRepository = kessoku.Bind[usecase.WebhookDeliveryRepository](kessoku.Provide(postgres.NewWebhookDeliveryRepository)).Fn()(pool)
+		close(webhookDeliveryRepositoryCh)
+		idempotencyRepository = kessoku.Bind[usecase.IdempotencyRepository](kessoku.Provide(postgres.NewIdempotencyRepository)).Fn()(pool)
+		close(idempotencyRepositoryCh)
+		webhookRepository = kessoku.Bind[usecase.WebhookRepository](kessoku.Provide(postgres.NewWebhookRepository)).Fn()(pool)
+		rolePolicy = kessoku.Bind[usecase.Policy](kessoku.Provide(usecase.NewRolePolicy)).Fn()(userRepository)
 		select {
 		case <-loggerCh:
 		case <-ctx.Done():
 			return ctx.Err()
 		}
-		todoUseCase = kessoku.Provide(usecase.NewTodoUseCase).Fn()(todoRepository, logger)
+		todoUseCase = kessoku.Provide(usecase.NewTodoUseCase).Fn()(todoRepository, projectRepository, userRepository, rolePolicy, logger)
 		close(todoUseCaseCh)
+		select {
+		case <-loggerCh:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		auditUseCase = kessoku.Provide(usecase.NewAuditUseCase).Fn()(auditRepository, rolePolicy, logger)
+		close(auditUseCaseCh)
+		select {
+		case <-loggerCh:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		webhookUseCase = kessoku.Provide(usecase.NewWebhookUseCase).Fn()(webhookRepository, webhookDeliveryRepository, rolePolicy, logger)
+		close(webhookUseCaseCh)
 		return nil
 	})
 	var err0 error
@@ -60,13 +111,54 @@ func InitializeBatch(ctx context.Context) (*BatchComponents, error) {
 	close(configCh)
 	logger = kessoku.Provide(NewLogger).Fn()()
 	close(loggerCh)
+	webhookSender = kessoku.Bind[usecase.WebhookSender](kessoku.Provide(NewWebhookSender)).Fn()()
 	var err1 error
 	db, err1 = kessoku.Async(kessoku.Provide(NewStdDB)).Fn()(config0)
 	if err1 != nil {
 		var zero *BatchComponents
 		return zero, err1
 	}
-	for _, ch := range []<-chan struct{}{todoUseCaseCh, poolCh} {
+	select {
+	case <-apikeyRepositoryCh:
+	case <-ctx.Done():
+		var zero *BatchComponents
+		return zero, ctx.Err()
+	}
+	apikeyUseCase = kessoku.Provide(usecase.NewAPIKeyUseCase).Fn()(apikeyRepository, logger)
+	select {
+	case <-webhookDeliveryRepositoryCh:
+	case <-ctx.Done():
+		var zero *BatchComponents
+		return zero, ctx.Err()
+	}
+	webhookWorker = kessoku.Provide(usecase.NewWebhookWorker).Fn()(webhookDeliveryRepository, webhookSender, logger)
+	select {
+	case <-idempotencyRepositoryCh:
+	case <-ctx.Done():
+		var zero *BatchComponents
+		return zero, ctx.Err()
+	}
+	idempotencyUseCase = kessoku.Provide(usecase.NewIdempotencyUseCase).Fn()(idempotencyRepository, logger)
+	select {
+	case <-webhookUseCaseCh:
+	case <-ctx.Done():
+		var zero *BatchComponents
+		return zero, ctx.Err()
+	}
+	var err2 error
+	val, err2 = kessoku.Provide(NewEventSinks).Fn()(config0, webhookUseCase)
+	if err2 != nil {
+		var zero *BatchComponents
+		return zero, err2
+	}
+	select {
+	case <-outboxRepositoryCh:
+	case <-ctx.Done():
+		var zero *BatchComponents
+		return zero, ctx.Err()
+	}
+	outboxRelay = kessoku.Provide(usecase.NewOutboxRelay).Fn()(outboxRepository, val, logger)
+	for _, ch := range []<-chan struct{}{todoUseCaseCh, auditUseCaseCh, poolCh} {
 		select {
 		case <-ch:
 		case <-ctx.Done():
@@ -74,7 +166,7 @@ func InitializeBatch(ctx context.Context) (*BatchComponents, error) {
 			return zero, ctx.Err()
 		}
 	}
-	batchComponents = kessoku.Provide(NewBatchComponents).Fn()(config0, todoUseCase, logger, pool, db)
+	batchComponents = kessoku.Provide(NewBatchComponents).Fn()(config0, todoUseCase, apikeyUseCase, auditUseCase, outboxRelay, webhookWorker, idempotencyUseCase, logger, pool, db)
 	if err := eg.Wait(); err != nil {
 		return nil, err
 	}
@@ -5,12 +5,17 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knjname/go-todo-api/internal/auth"
 	"github.com/knjname/go-todo-api/internal/config"
+	"github.com/knjname/go-todo-api/internal/eventsink"
+	"github.com/knjname/go-todo-api/internal/usecase"
 )
 
 func NewLogger() *slog.Logger {
@@ -41,3 +46,39 @@ func NewStdDB(cfg *config.Config) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// NewVerifier returns the verifier of bearer tokens, or nil when no JWKS is
+// configured and only API keys are accepted.
+func NewVerifier(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*auth.Verifier, error) {
+	if cfg.AllowAnonymous {
+		logger.Warn("ALLOW_ANONYMOUS is set; requests without credentials are let through")
+	}
+	if cfg.JWKS == "" {
+		return nil, nil
+	}
+	keys, err := auth.LoadKeySet(ctx, cfg.JWKS)
+	if err != nil {
+		return nil, fmt.Errorf("load JWKS: %w", err)
+	}
+	return auth.NewVerifier(keys, cfg.JWTIssuer, cfg.JWTAudience), nil
+}
+
+// NewEventSinks opens the sinks the outbox relay delivers events to: those
+// configured, and the webhooks subscribing to the events.
+func NewEventSinks(cfg *config.Config, webhooks *usecase.WebhookUseCase) ([]usecase.EventSink, error) {
+	sinks := []usecase.EventSink{webhooks}
+	for _, spec := range cfg.OutboxSinks {
+		sink, err := eventsink.Open(spec)
+		if err != nil {
+			return nil, fmt.Errorf("open event sink %q: %w", spec, err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// NewWebhookSender returns the sender of webhook deliveries. A receiver has
+// 10 seconds to respond.
+func NewWebhookSender() *eventsink.WebhookSender {
+	return eventsink.NewWebhookSender(&http.Client{Timeout: 10 * time.Second})
+}
@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrUnauthenticated reports credentials that do not identify an active
+// caller: an unknown, revoked or expired API key, or a wrong secret.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Scope is a permission granted to an API key. Each scope includes the
+// ones below it: admin includes write, and write includes read.
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"
+	ScopeWrite Scope = "write"
+	ScopeAdmin Scope = "admin"
+	// ScopeImpersonate lets a key act for the user it names in each
+	// request. It stands apart from the other scopes: none includes it, and
+	// it includes none.
+	ScopeImpersonate Scope = "impersonate"
+)
+
+var scopeRanks = map[Scope]int{ScopeRead: 1, ScopeWrite: 2, ScopeAdmin: 3}
+
+func ParseScope(s string) (Scope, error) {
+	scope := Scope(strings.ToLower(strings.TrimSpace(s)))
+	if !scope.valid() {
+		return "", NewValidationError("scopes", "must be one of read, write, admin, impersonate")
+	}
+	return scope, nil
+}
+
+func (s Scope) valid() bool {
+	_, ranked := scopeRanks[s]
+	return ranked || s == ScopeImpersonate
+}
+
+// Includes reports whether s grants everything other grants.
+func (s Scope) Includes(other Scope) bool {
+	rank, ranked := scopeRanks[other]
+	return s == other || ranked && scopeRanks[s] >= rank
+}
+
+const (
+	MaxAPIKeyNameLength = 100
+
+	// apiKeyPrefix starts every API key token, which makes leaked keys
+	// easy to recognize.
+	apiKeyPrefix = "tdk_"
+)
+
+// APIKey is a long-lived credential of a service client, confined to one
+// workspace. Only a hash of its secret token is kept; the token itself is
+// shown once, when the key is created.
+type APIKey struct {
+	ID          uuid.UUID  `json:"id"`
+	WorkspaceID uuid.UUID  `json:"workspaceId"`
+	Name        string     `json:"name"`
+	Scopes      []Scope    `json:"scopes"`
+	SecretHash  []byte     `json:"-"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// NewAPIKey generates an API key with the given scopes that expires at
+// expiresAt, or never if it is nil. It returns the key and its token.
+func NewAPIKey(name string, scopes []Scope, expiresAt *time.Time) (*APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", NewValidationError("name", "must not be empty")
+	}
+	if len([]rune(name)) > MaxAPIKeyNameLength {
+		return nil, "", NewValidationError("name", "must not exceed 100 characters")
+	}
+	if len(scopes) == 0 {
+		return nil, "", NewValidationError("scopes", "must not be empty")
+	}
+	for _, scope := range scopes {
+		if !scope.valid() {
+			return nil, "", NewValidationError("scopes", "must be one of read, write, admin, impersonate")
+		}
+	}
+	now := time.Now().UTC()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, "", NewValidationError("expiresAt", "must be in the future")
+	}
+
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+	key := &APIKey{
+		ID:        uuid.New(),
+		Name:      name,
+		Scopes:    slices.Compact(scopes),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+	token := apiKeyPrefix + hex.EncodeToString(key.ID[:]) + "_" + base64.RawURLEncoding.EncodeToString(randomBytes(32))
+	key.SecretHash = hashAPIKeyToken(token)
+	return key, token, nil
+}
+
+// ParseAPIKeyToken returns the ID of the API key a token claims to belong
+// to. The token still has to be checked with Matches.
+func ParseAPIKeyToken(token string) (uuid.UUID, error) {
+	rest, ok := strings.CutPrefix(token, apiKeyPrefix)
+	if !ok {
+		return uuid.Nil, ErrUnauthenticated
+	}
+	rawID, _, ok := strings.Cut(rest, "_")
+	b, err := hex.DecodeString(rawID)
+	if !ok || err != nil {
+		return uuid.Nil, ErrUnauthenticated
+	}
+	id, err := uuid.FromBytes(b)
+	if err != nil {
+		return uuid.Nil, ErrUnauthenticated
+	}
+	return id, nil
+}
+
+// Matches reports whether token is the token of the APIKey.
+func (k *APIKey) Matches(token string) bool {
+	return subtle.ConstantTimeCompare(k.SecretHash, hashAPIKeyToken(token)) == 1
+}
+
+// Active reports whether the APIKey is neither revoked nor expired at now.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// Grants reports whether the APIKey has a scope that includes scope.
+func (k *APIKey) Grants(scope Scope) bool {
+	return slices.ContainsFunc(k.Scopes, func(s Scope) bool { return s.Includes(scope) })
+}
+
+// hashAPIKeyToken hashes a token for storage. Tokens carry 256 random
+// bits, so a fast unsalted hash is enough.
+func hashAPIKeyToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return b
+}
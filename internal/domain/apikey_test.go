@@ -0,0 +1,86 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	expires := time.Now().Add(24 * time.Hour)
+	key, token, err := domain.NewAPIKey(" ci ", []domain.Scope{domain.ScopeWrite, domain.ScopeRead, domain.ScopeWrite}, &expires)
+	require.NoError(t, err)
+	assert.Equal(t, "ci", key.Name)
+	assert.Equal(t, []domain.Scope{domain.ScopeRead, domain.ScopeWrite}, key.Scopes)
+	assert.True(t, strings.HasPrefix(token, "tdk_"))
+	assert.NotContains(t, string(key.SecretHash), token)
+
+	id, err := domain.ParseAPIKeyToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, id)
+	assert.True(t, key.Matches(token))
+	assert.False(t, key.Matches(token+"x"))
+
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name     string
+		keyName  string
+		scopes   []domain.Scope
+		expires  *time.Time
+		errField string
+	}{
+		{name: "empty name", keyName: " ", scopes: []domain.Scope{domain.ScopeRead}, errField: "name"},
+		{name: "no scopes", keyName: "ci", errField: "scopes"},
+		{name: "unknown scope", keyName: "ci", scopes: []domain.Scope{"root"}, errField: "scopes"},
+		{name: "expired", keyName: "ci", scopes: []domain.Scope{domain.ScopeRead}, expires: &past, errField: "expiresAt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := domain.NewAPIKey(tt.keyName, tt.scopes, tt.expires)
+			var ve *domain.ValidationError
+			require.ErrorAs(t, err, &ve)
+			assert.Equal(t, tt.errField, ve.Field)
+		})
+	}
+}
+
+func TestParseAPIKeyToken(t *testing.T) {
+	for _, token := range []string{"", "secret", "tdk_", "tdk_zz_secret", "tdk_0011_secret", "Bearer tdk_x"} {
+		_, err := domain.ParseAPIKeyToken(token)
+		assert.ErrorIs(t, err, domain.ErrUnauthenticated, token)
+	}
+}
+
+func TestAPIKey_GrantsAndActive(t *testing.T) {
+	key, _, err := domain.NewAPIKey("bot", []domain.Scope{domain.ScopeWrite}, nil)
+	require.NoError(t, err)
+	assert.True(t, key.Grants(domain.ScopeRead))
+	assert.True(t, key.Grants(domain.ScopeWrite))
+	assert.False(t, key.Grants(domain.ScopeAdmin))
+	assert.False(t, key.Grants(domain.ScopeImpersonate))
+
+	key, _, err = domain.NewAPIKey("frontend", []domain.Scope{domain.ScopeImpersonate}, nil)
+	require.NoError(t, err)
+	assert.True(t, key.Grants(domain.ScopeImpersonate))
+	assert.False(t, key.Grants(domain.ScopeRead), "impersonate grants nothing else")
+
+	now := time.Now()
+	assert.True(t, key.Active(now))
+	expires := now.Add(time.Hour)
+	key.ExpiresAt = &expires
+	assert.True(t, key.Active(now))
+	assert.False(t, key.Active(expires))
+	key.ExpiresAt = nil
+	key.RevokedAt = &now
+	assert.False(t, key.Active(now))
+
+	scope, err := domain.ParseScope(" Admin")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ScopeAdmin, scope)
+	_, err = domain.ParseScope("root")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
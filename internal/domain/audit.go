@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction names the kind of change an AuditEntry records.
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditTrash   AuditAction = "trash"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+)
+
+// TodoAuditAction classifies the change of a todo from before to after.
+// before is nil for a new todo, and after is nil for a purged one.
+func TodoAuditAction(before, after *Todo) AuditAction {
+	switch {
+	case before == nil:
+		return AuditCreate
+	case after == nil:
+		return AuditPurge
+	case before.DeletedAt == nil && after.DeletedAt != nil:
+		return AuditTrash
+	case before.DeletedAt != nil && after.DeletedAt == nil:
+		return AuditRestore
+	}
+	return AuditUpdate
+}
+
+// AuditEntry is a record of the append-only audit trail: who changed which
+// todo how, and when. Entries outlive the todos they describe.
+type AuditEntry struct {
+	ID     int64       `json:"id"`
+	TodoID uuid.UUID   `json:"todoId"`
+	Action AuditAction `json:"action"`
+	// UserID is the user the change was made for; nil if it was not made
+	// for a user.
+	UserID *uuid.UUID `json:"userId,omitempty"`
+	// Subject identifies the authenticated caller, such as an API key;
+	// empty for batch jobs and anonymous requests.
+	Subject string `json:"subject,omitempty"`
+	// RequestID is the ID of the HTTP request that made the change.
+	RequestID string `json:"requestId,omitempty"`
+	// Before and After are JSON snapshots of the Todo around the change.
+	// Before is null for AuditCreate and After for AuditPurge.
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// Snapshots decodes Before and After. A missing snapshot decodes to nil.
+func (e *AuditEntry) Snapshots() (before, after *Todo, err error) {
+	if before, err = decodeSnapshot(e.Before); err != nil {
+		return nil, nil, err
+	}
+	if after, err = decodeSnapshot(e.After); err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+func decodeSnapshot(raw json.RawMessage) (*Todo, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var t Todo
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
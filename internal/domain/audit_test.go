@@ -0,0 +1,43 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoAuditAction(t *testing.T) {
+	now := time.Now()
+	live := &domain.Todo{Title: "Task"}
+	trashed := &domain.Todo{Title: "Task", DeletedAt: &now}
+
+	assert.Equal(t, domain.AuditCreate, domain.TodoAuditAction(nil, live))
+	assert.Equal(t, domain.AuditUpdate, domain.TodoAuditAction(live, live))
+	assert.Equal(t, domain.AuditTrash, domain.TodoAuditAction(live, trashed))
+	assert.Equal(t, domain.AuditRestore, domain.TodoAuditAction(trashed, live))
+	assert.Equal(t, domain.AuditPurge, domain.TodoAuditAction(trashed, nil))
+}
+
+func TestAuditEntry_Snapshots(t *testing.T) {
+	todo, err := domain.NewTodo("Report", "weekly", domain.WithPriority(domain.PriorityHigh), domain.WithTags([]string{"work"}))
+	require.NoError(t, err)
+	raw, err := json.Marshal(todo)
+	require.NoError(t, err)
+
+	entry := &domain.AuditEntry{Action: domain.AuditCreate, Before: json.RawMessage("null"), After: raw}
+	before, after, err := entry.Snapshots()
+	require.NoError(t, err)
+	assert.Nil(t, before)
+	require.NotNil(t, after)
+	assert.Equal(t, todo.ID, after.ID)
+	assert.Equal(t, domain.PriorityHigh, after.Priority)
+	assert.Equal(t, []string{"work"}, after.Tags)
+
+	entry.After = json.RawMessage("{")
+	_, _, err = entry.Snapshots()
+	assert.Error(t, err)
+}
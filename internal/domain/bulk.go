@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// BulkAction is a change applied to each of several todos by one request.
+type BulkAction string
+
+const (
+	// BulkComplete moves the todos to done.
+	BulkComplete BulkAction = "complete"
+	// BulkDelete moves the todos to the trash.
+	BulkDelete BulkAction = "delete"
+	// BulkRetag adds tags to the todos and removes others.
+	BulkRetag BulkAction = "retag"
+	// BulkMove moves the todos to a project, or out of their project.
+	BulkMove BulkAction = "move"
+)
+
+// MaxBulkTodos is the largest number of todos a bulk action applies to.
+const MaxBulkTodos = 100
+
+// ParseBulkAction parses a bulk action.
+func ParseBulkAction(s string) (BulkAction, error) {
+	switch a := BulkAction(s); a {
+	case BulkComplete, BulkDelete, BulkRetag, BulkMove:
+		return a, nil
+	}
+	return "", NewValidationError("action", fmt.Sprintf("must be %q, %q, %q or %q", BulkComplete, BulkDelete, BulkRetag, BulkMove))
+}
+
+// ValidateBulkIDs checks the IDs of the todos a bulk action applies to:
+// between 1 and MaxBulkTodos, without duplicates.
+func ValidateBulkIDs(ids []uuid.UUID) error {
+	if len(ids) == 0 || len(ids) > MaxBulkTodos {
+		return NewValidationError("ids", fmt.Sprintf("must list between 1 and %d todos", MaxBulkTodos))
+	}
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return NewValidationError("ids", "must not list a todo twice")
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// TagChange adds tags to todos and removes others, keeping the rest.
+type TagChange struct {
+	Add    []string
+	Remove []string
+}
+
+// NewTagChange normalizes the tag names to add and to remove. At least one
+// is required, and no tag may be both added and removed.
+func NewTagChange(add, remove []string) (*TagChange, error) {
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, NewValidationError("tags", "must add or remove at least one tag")
+	}
+	added, err := NormalizeTagNames(add)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := NormalizeTagNames(remove)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range added {
+		if slices.Contains(removed, name) {
+			return nil, NewValidationError("tags", fmt.Sprintf("tag %q cannot be both added and removed", name))
+		}
+	}
+	return &TagChange{Add: added, Remove: removed}, nil
+}
+
+// Apply changes the tags of t and reports whether they changed.
+func (c *TagChange) Apply(t *Todo) (bool, error) {
+	tags := slices.DeleteFunc(append(slices.Clone(t.Tags), c.Add...), func(name string) bool {
+		return slices.Contains(c.Remove, name)
+	})
+	tags, err := NormalizeTagNames(tags)
+	if err != nil {
+		return false, err
+	}
+	if slices.Equal(tags, t.Tags) {
+		return false, nil
+	}
+	return true, t.SetTags(tags)
+}
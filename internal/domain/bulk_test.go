@@ -0,0 +1,48 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBulkAction(t *testing.T) {
+	a, err := domain.ParseBulkAction("retag")
+	require.NoError(t, err)
+	assert.Equal(t, domain.BulkRetag, a)
+
+	_, err = domain.ParseBulkAction("archive")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+func TestValidateBulkIDs(t *testing.T) {
+	id := uuid.New()
+	assert.NoError(t, domain.ValidateBulkIDs([]uuid.UUID{id, uuid.New()}))
+	assert.ErrorIs(t, domain.ValidateBulkIDs(nil), domain.ErrValidation)
+	assert.ErrorIs(t, domain.ValidateBulkIDs([]uuid.UUID{id, id}), domain.ErrValidation)
+	assert.ErrorIs(t, domain.ValidateBulkIDs(make([]uuid.UUID, domain.MaxBulkTodos+1)), domain.ErrValidation)
+}
+
+func TestTagChange(t *testing.T) {
+	change, err := domain.NewTagChange([]string{" Urgent ", "home"}, []string{"work"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"home", "urgent"}, change.Add)
+
+	todo := &domain.Todo{Tags: []string{"home", "work"}}
+	changed, err := change.Apply(todo)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{"home", "urgent"}, todo.Tags)
+
+	changed, err = change.Apply(todo)
+	require.NoError(t, err)
+	assert.False(t, changed, "applying twice changes nothing")
+
+	_, err = domain.NewTagChange(nil, nil)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	_, err = domain.NewTagChange([]string{"a"}, []string{"A"})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	DueDateLayout   = "2006-01-02"
+	DueTimeLayout   = "15:04"
+	DefaultTimeZone = "UTC"
+)
+
+// Due is an optional deadline of a Todo. At is the instant the due date (and
+// time, if any) starts in TimeZone. An all-day Due is due until the end of
+// that calendar day.
+type Due struct {
+	At       time.Time `json:"at"`
+	AllDay   bool      `json:"allDay"`
+	TimeZone string    `json:"timeZone"`
+}
+
+// NewDue builds a Due from a calendar date ("2006-01-02"), an optional wall
+// clock time ("15:04") and an optional IANA time zone name (UTC by default).
+func NewDue(date, clock, timeZone string) (*Due, error) {
+	if timeZone == "" {
+		timeZone = DefaultTimeZone
+	}
+	loc, err := loadTimeZone(timeZone)
+	if err != nil {
+		return nil, NewValidationError("dueTimeZone", "must be a valid IANA time zone")
+	}
+
+	if date == "" {
+		return nil, NewValidationError("dueDate", "must not be empty")
+	}
+	day, err := time.ParseInLocation(DueDateLayout, date, loc)
+	if err != nil {
+		return nil, NewValidationError("dueDate", "must be formatted as YYYY-MM-DD")
+	}
+
+	if clock == "" {
+		return &Due{At: day.UTC(), AllDay: true, TimeZone: timeZone}, nil
+	}
+	tod, err := time.Parse(DueTimeLayout, clock)
+	if err != nil {
+		return nil, NewValidationError("dueTime", "must be formatted as HH:MM")
+	}
+	at := time.Date(day.Year(), day.Month(), day.Day(), tod.Hour(), tod.Minute(), 0, 0, loc)
+	return &Due{At: at.UTC(), AllDay: false, TimeZone: timeZone}, nil
+}
+
+// Deadline returns the instant after which the Due has passed.
+func (d *Due) Deadline() time.Time {
+	if !d.AllDay {
+		return d.At
+	}
+	return d.At.In(d.location()).AddDate(0, 0, 1).UTC()
+}
+
+// Date returns the due calendar date in the Due's time zone.
+func (d *Due) Date() string {
+	return d.At.In(d.location()).Format(DueDateLayout)
+}
+
+// Clock returns the due wall clock time in the Due's time zone, or an empty
+// string for an all-day Due.
+func (d *Due) Clock() string {
+	if d.AllDay {
+		return ""
+	}
+	return d.At.In(d.location()).Format(DueTimeLayout)
+}
+
+func (d *Due) location() *time.Location {
+	loc, err := loadTimeZone(d.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// loadTimeZone loads an IANA time zone by name. "Local", the zone of the
+// server, is refused: PostgreSQL does not know it, and it differs from one
+// server to another.
+func loadTimeZone(name string) (*time.Location, error) {
+	if name == "Local" {
+		return nil, errors.New("unknown time zone Local")
+	}
+	return time.LoadLocation(name)
+}
@@ -0,0 +1,116 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDue(t *testing.T) {
+	tests := []struct {
+		name     string
+		date     string
+		clock    string
+		timeZone string
+		wantErr  bool
+		errField string
+		wantAt   time.Time
+		allDay   bool
+	}{
+		{
+			name:   "all-day in UTC",
+			date:   "2026-03-01",
+			wantAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			allDay: true,
+		},
+		{
+			name:     "date and time in Tokyo",
+			date:     "2026-03-01",
+			clock:    "09:30",
+			timeZone: "Asia/Tokyo",
+			wantAt:   time.Date(2026, 3, 1, 0, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "missing date",
+			clock:    "09:30",
+			wantErr:  true,
+			errField: "dueDate",
+		},
+		{
+			name:     "invalid date",
+			date:     "2026-02-30",
+			wantErr:  true,
+			errField: "dueDate",
+		},
+		{
+			name:     "invalid time",
+			date:     "2026-03-01",
+			clock:    "25:00",
+			wantErr:  true,
+			errField: "dueTime",
+		},
+		{
+			name:     "unknown time zone",
+			date:     "2026-03-01",
+			timeZone: "Mars/Olympus",
+			wantErr:  true,
+			errField: "dueTimeZone",
+		},
+		{
+			name:     "server time zone",
+			date:     "2026-03-01",
+			timeZone: "Local",
+			wantErr:  true,
+			errField: "dueTimeZone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due, err := domain.NewDue(tt.date, tt.clock, tt.timeZone)
+
+			if tt.wantErr {
+				var ve *domain.ValidationError
+				require.True(t, errors.As(err, &ve))
+				assert.Equal(t, tt.errField, ve.Field)
+				assert.Nil(t, due)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, tt.wantAt.Equal(due.At))
+				assert.Equal(t, tt.allDay, due.AllDay)
+				assert.Equal(t, tt.date, due.Date())
+				assert.Equal(t, tt.clock, due.Clock())
+			}
+		})
+	}
+}
+
+func TestDue_Deadline(t *testing.T) {
+	allDay, err := domain.NewDue("2026-03-01", "", "Asia/Tokyo")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC).Equal(allDay.Deadline()))
+
+	timed, err := domain.NewDue("2026-03-01", "09:30", "Asia/Tokyo")
+	require.NoError(t, err)
+	assert.True(t, timed.At.Equal(timed.Deadline()))
+}
+
+func TestTodo_IsOverdue(t *testing.T) {
+	due, err := domain.NewDue("2026-03-01", "", "")
+	require.NoError(t, err)
+	todo, err := domain.NewTodo("Task", "", domain.WithDue(due))
+	require.NoError(t, err)
+
+	assert.False(t, todo.IsOverdue(time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)))
+	assert.True(t, todo.IsOverdue(time.Date(2026, 3, 2, 0, 0, 1, 0, time.UTC)))
+
+	todo.MarkComplete()
+	assert.False(t, todo.IsOverdue(time.Date(2026, 3, 2, 0, 0, 1, 0, time.UTC)))
+
+	todo.SetDue(nil)
+	assert.Nil(t, todo.Due)
+}
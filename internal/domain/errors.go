@@ -8,6 +8,24 @@ import (
 var (
 	ErrNotFound   = errors.New("not found")
 	ErrValidation = errors.New("validation error")
+	// ErrConflict reports that a todo changed concurrently between being
+	// read and being written.
+	ErrConflict = errors.New("conflict")
+	// ErrPreconditionFailed reports that a todo is not at the version the
+	// caller expected.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrAlreadyExists reports that a resource with the same unique name
+	// already exists.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrInvalidTransition reports a status change the todo lifecycle does
+	// not allow, such as starting a completed todo.
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrForbidden reports an operation the acting user is not allowed to
+	// perform, such as editing another user's todo.
+	ErrForbidden = errors.New("forbidden")
+	// ErrBulkAborted reports a todo left unchanged by an atomic bulk action
+	// because the action failed for another todo.
+	ErrBulkAborted = errors.New("bulk action aborted")
 )
 
 // ValidationError provides field-level validation details.
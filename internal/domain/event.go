@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType names the kind of change an Event announces.
+type EventType string
+
+const (
+	EventTodoCreated   EventType = "TodoCreated"
+	EventTodoUpdated   EventType = "TodoUpdated"
+	EventTodoCompleted EventType = "TodoCompleted"
+	EventTodoDeleted   EventType = "TodoDeleted"
+	EventAllCompleted  EventType = "AllCompleted"
+)
+
+// Event is a domain event: a change to todos that other systems are told
+// about. Events are published through the outbox, which delivers each
+// event at least once; consumers should ignore an ID they have seen.
+type Event struct {
+	ID          uuid.UUID `json:"id"`
+	Type        EventType `json:"type"`
+	WorkspaceID uuid.UUID `json:"workspaceId"`
+	// TodoID is the todo the event is about; nil for AllCompleted.
+	TodoID *uuid.UUID `json:"todoId,omitempty"`
+	// Data is the Todo after the change for the todo events, and
+	// AllCompletedData for AllCompleted.
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	// Attempts counts the failed deliveries of the event so far.
+	Attempts int `json:"-"`
+	// Seq is the position of the event in the outbox. Events written later
+	// have higher positions, but may be committed earlier.
+	Seq int64 `json:"-"`
+	// Horizon is where a subscriber that received the event resumes from:
+	// the oldest transaction still running when the event was written.
+	// Every event committed after it was written by a transaction at or
+	// after Horizon.
+	Horizon int64 `json:"-"`
+}
+
+// Todo decodes the data of a todo event: the todo after the change.
+func (e *Event) Todo() (*Todo, error) {
+	var t Todo
+	if err := json.Unmarshal(e.Data, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// AllCompleted decodes the data of an AllCompleted event.
+func (e *Event) AllCompleted() (*AllCompletedData, error) {
+	var d AllCompletedData
+	if err := json.Unmarshal(e.Data, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// AllCompletedData is the data of an AllCompleted event.
+type AllCompletedData struct {
+	// ProjectID is the project whose todos were completed; nil if those
+	// of every project were.
+	ProjectID *uuid.UUID  `json:"projectId"`
+	TodoIDs   []uuid.UUID `json:"todoIds"`
+}
+
+// NewTodoEvent returns the event announcing the change of a todo from
+// before to after; before is nil for a new todo. Moving a todo to the
+// trash deletes it and completing it completes it; any other change,
+// including a restore from the trash, updates it.
+func NewTodoEvent(before, after *Todo) (*Event, error) {
+	typ := EventTodoUpdated
+	switch {
+	case before == nil:
+		typ = EventTodoCreated
+	case before.DeletedAt == nil && after.DeletedAt != nil:
+		typ = EventTodoDeleted
+	case before.Status != StatusDone && after.Status == StatusDone:
+		typ = EventTodoCompleted
+	}
+	data, err := json.Marshal(after)
+	if err != nil {
+		return nil, err
+	}
+	id := after.ID
+	return newEvent(typ, &id, data), nil
+}
+
+// NewAllCompletedEvent returns the event announcing that the given todos
+// of a project, or of every project if projectID is nil, were completed
+// at once.
+func NewAllCompletedEvent(projectID *uuid.UUID, todoIDs []uuid.UUID) (*Event, error) {
+	data, err := json.Marshal(AllCompletedData{ProjectID: projectID, TodoIDs: todoIDs})
+	if err != nil {
+		return nil, err
+	}
+	return newEvent(EventAllCompleted, nil, data), nil
+}
+
+func newEvent(typ EventType, todoID *uuid.UUID, data json.RawMessage) *Event {
+	return &Event{ID: uuid.New(), Type: typ, TodoID: todoID, Data: data, OccurredAt: time.Now()}
+}
+
+const (
+	retryBaseDelay = time.Second
+	retryMaxDelay  = time.Hour
+)
+
+// RetryDelay returns how long to wait before retrying a delivery that
+// has failed attempts times: a second after the first failure, doubling
+// with every further failure up to an hour.
+func RetryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMaxDelay)
+}
@@ -0,0 +1,67 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTodoEvent(t *testing.T) {
+	now := time.Now()
+	id := uuid.New()
+	open := &domain.Todo{ID: id, Title: "Task", Status: domain.StatusOpen}
+	done := &domain.Todo{ID: id, Title: "Task", Status: domain.StatusDone}
+	trashed := &domain.Todo{ID: id, Title: "Task", Status: domain.StatusDone, DeletedAt: &now}
+
+	tests := []struct {
+		name          string
+		before, after *domain.Todo
+		want          domain.EventType
+	}{
+		{"create", nil, open, domain.EventTodoCreated},
+		{"update", open, open, domain.EventTodoUpdated},
+		{"complete", open, done, domain.EventTodoCompleted},
+		{"update a done todo", done, done, domain.EventTodoUpdated},
+		{"reopen", done, open, domain.EventTodoUpdated},
+		{"trash", done, trashed, domain.EventTodoDeleted},
+		{"restore", trashed, done, domain.EventTodoUpdated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := domain.NewTodoEvent(tt.before, tt.after)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, event.Type)
+			assert.Equal(t, &id, event.TodoID)
+			assert.NotEqual(t, uuid.Nil, event.ID)
+
+			data, err := event.Todo()
+			require.NoError(t, err)
+			assert.Equal(t, tt.after.Status, data.Status)
+		})
+	}
+}
+
+func TestNewAllCompletedEvent(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	event, err := domain.NewAllCompletedEvent(nil, ids)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EventAllCompleted, event.Type)
+	assert.Nil(t, event.TodoID)
+
+	data, err := event.AllCompleted()
+	require.NoError(t, err)
+	assert.Nil(t, data.ProjectID)
+	assert.Equal(t, ids, data.TodoIDs)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Second, domain.RetryDelay(1))
+	assert.Equal(t, 2*time.Second, domain.RetryDelay(2))
+	assert.Equal(t, 8*time.Second, domain.RetryDelay(4))
+	assert.Equal(t, time.Hour, domain.RetryDelay(20))
+	assert.Equal(t, time.Hour, domain.RetryDelay(1000))
+}
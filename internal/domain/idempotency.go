@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+const (
+	MaxIdempotencyKeyLength = 255
+
+	// IdempotencyKeyTTL is how long the response to a request with an
+	// idempotency key is kept for its retries.
+	IdempotencyKeyTTL = 24 * time.Hour
+	// IdempotencyLockTimeout is how long a request with an idempotency key
+	// may stay unanswered. After that, it is taken to have died with its
+	// process, and a retry runs in its stead.
+	IdempotencyLockTimeout = time.Minute
+)
+
+// IdempotencyRecord is a request made with an idempotency key, so that its
+// retries can be answered with the same response.
+type IdempotencyRecord struct {
+	Key string
+	// Fingerprint identifies the request; a retry must have the same.
+	Fingerprint string
+	// Response is the response to the request; nil while it is in
+	// progress.
+	Response    *StoredResponse
+	CreatedAt   time.Time
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
+
+// StoredResponse is the response to a request, kept to be replayed.
+type StoredResponse struct {
+	Status int
+	Header map[string][]string
+	Body   []byte
+}
+
+// NewIdempotencyRecord returns the record of a request with key and
+// fingerprint starting now.
+func NewIdempotencyRecord(key, fingerprint string, now time.Time) (*IdempotencyRecord, error) {
+	if key == "" || len(key) > MaxIdempotencyKeyLength {
+		return nil, NewValidationError("Idempotency-Key", "must be between 1 and 255 characters")
+	}
+	if strings.IndexFunc(key, func(r rune) bool { return r < '!' || r > '~' }) >= 0 {
+		return nil, NewValidationError("Idempotency-Key", "must only contain visible ASCII characters")
+	}
+	return &IdempotencyRecord{
+		Key: key, Fingerprint: fingerprint,
+		CreatedAt: now, LockedUntil: now.Add(IdempotencyLockTimeout), ExpiresAt: now.Add(IdempotencyKeyTTL),
+	}, nil
+}
+
+// Matches reports whether fingerprint identifies the same request as the
+// record.
+func (r *IdempotencyRecord) Matches(fingerprint string) bool {
+	return r.Fingerprint == fingerprint
+}
+
+// IdempotencyFingerprint identifies a request by its method, its request
+// URI (path and query) and its body.
+func IdempotencyFingerprint(method, requestURI string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + requestURI + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
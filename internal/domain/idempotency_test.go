@@ -0,0 +1,42 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIdempotencyRecord(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	rec, err := domain.NewIdempotencyRecord("0b6f8f1e-retry", "fp", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(domain.IdempotencyLockTimeout), rec.LockedUntil)
+	assert.Equal(t, now.Add(domain.IdempotencyKeyTTL), rec.ExpiresAt)
+	assert.Nil(t, rec.Response)
+	assert.True(t, rec.Matches("fp"))
+	assert.False(t, rec.Matches("other"))
+
+	for name, key := range map[string]string{
+		"empty":     "",
+		"too long":  strings.Repeat("k", domain.MaxIdempotencyKeyLength+1),
+		"space":     "my key",
+		"non-ASCII": "キー",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := domain.NewIdempotencyRecord(key, "fp", now)
+			assert.ErrorIs(t, err, domain.ErrValidation)
+		})
+	}
+}
+
+func TestIdempotencyFingerprint(t *testing.T) {
+	fp := domain.IdempotencyFingerprint("POST", "/todos", []byte(`{"title":"a"}`))
+	assert.Len(t, fp, 64)
+	assert.Equal(t, fp, domain.IdempotencyFingerprint("POST", "/todos", []byte(`{"title":"a"}`)))
+	assert.NotEqual(t, fp, domain.IdempotencyFingerprint("POST", "/todos", []byte(`{"title":"b"}`)))
+	assert.NotEqual(t, fp, domain.IdempotencyFingerprint("POST", "/todos?x=1", []byte(`{"title":"a"}`)))
+}
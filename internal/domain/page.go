@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// PageRequest selects one page of a keyset-paginated list.
+type PageRequest struct {
+	// Sort is the ordering being paginated; empty means DefaultTodoSort.
+	Sort TodoSort
+	// Limit is the maximum number of items in the page.
+	Limit int
+	// After is the position to continue from; nil starts from the beginning.
+	After *Cursor
+}
+
+// Cursor is a position in a TodoSort ordering: the sort key values and ID
+// of the last todo of the previous page.
+type Cursor struct {
+	Sort   string    `json:"s"`
+	Values []string  `json:"v"`
+	ID     uuid.UUID `json:"i"`
+}
+
+// Encode returns the opaque, URL-safe representation of the cursor.
+func (c *Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParseCursor decodes a cursor produced by Encode.
+func ParseCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, NewValidationError("cursor", "malformed cursor")
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == uuid.Nil {
+		return nil, NewValidationError("cursor", "malformed cursor")
+	}
+	return &c, nil
+}
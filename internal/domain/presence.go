@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// PresenceInterval is how often the presence of a board connection is
+	// announced again while it stays open.
+	PresenceInterval = 15 * time.Second
+	// PresenceTTL is how long a presence lasts without being announced
+	// again, after which its connection is taken to be gone along with the
+	// process that held it.
+	PresenceTTL = 3 * PresenceInterval
+)
+
+// Presence tells who views the board of a workspace over a connection:
+// a live view of its todos, kept open by any API process.
+type Presence struct {
+	ConnectionID uuid.UUID `json:"connectionId"`
+	WorkspaceID  uuid.UUID `json:"workspaceId"`
+	// UserID is the viewer; nil for service clients.
+	UserID *uuid.UUID `json:"userId,omitempty"`
+	// ProjectIDs are the projects whose todos the connection views.
+	ProjectIDs []uuid.UUID `json:"projectIds"`
+	// Left announces that the connection has closed.
+	Left bool `json:"left,omitempty"`
+	// At is when the presence was last announced.
+	At time.Time `json:"at"`
+}
+
+// Expired reports whether the presence has not been announced again in
+// time, as of now.
+func (p *Presence) Expired(now time.Time) bool {
+	return now.Sub(p.At) > PresenceTTL
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Priority ranks how urgently a Todo needs attention. Higher values are
+// more urgent, so lists sort by descending priority.
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+// PriorityNames lists the names accepted by ParsePriority, least urgent first.
+func PriorityNames() []string {
+	return append([]string(nil), priorityNames...)
+}
+
+// ParsePriority converts a priority name to a Priority. An empty name is
+// PriorityNone.
+func ParsePriority(s string) (Priority, error) {
+	if s == "" {
+		return PriorityNone, nil
+	}
+	for i, name := range priorityNames {
+		if name == s {
+			return Priority(i), nil
+		}
+	}
+	return PriorityNone, NewValidationError("priority",
+		fmt.Sprintf("must be one of %s", strings.Join(priorityNames, ", ")))
+}
+
+func (p Priority) String() string {
+	if p < PriorityNone || p > PriorityUrgent {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const MaxProjectNameLength = 100
+
+// Project groups todos. Project names are unique; todos outside any project
+// have no ProjectID.
+type Project struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func NewProject(name, description string) (*Project, error) {
+	name, err := normalizeProjectName(name)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &Project{ID: uuid.New(), Name: name, Description: description, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+// Update replaces the name and description of the Project.
+func (p *Project) Update(name, description string) error {
+	name, err := normalizeProjectName(name)
+	if err != nil {
+		return err
+	}
+	p.Name = name
+	p.Description = description
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func normalizeProjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", NewValidationError("name", "must not be empty")
+	}
+	if len([]rune(name)) > MaxProjectNameLength {
+		return "", NewValidationError("name", "must not exceed 100 characters")
+	}
+	return name, nil
+}
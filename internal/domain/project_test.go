@@ -0,0 +1,36 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProject(t *testing.T) {
+	p, err := domain.NewProject("  Website  ", "relaunch")
+	require.NoError(t, err)
+	assert.Equal(t, "Website", p.Name)
+	assert.Equal(t, "relaunch", p.Description)
+	assert.Equal(t, p.CreatedAt, p.UpdatedAt)
+
+	_, err = domain.NewProject(" ", "")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	_, err = domain.NewProject(strings.Repeat("a", 101), "")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+func TestProject_Update(t *testing.T) {
+	p, err := domain.NewProject("Website", "")
+	require.NoError(t, err)
+
+	require.NoError(t, p.Update("Web shop", "new scope"))
+	assert.Equal(t, "Web shop", p.Name)
+	assert.Equal(t, "new scope", p.Description)
+
+	assert.ErrorIs(t, p.Update("", "x"), domain.ErrValidation)
+	assert.Equal(t, "Web shop", p.Name)
+}
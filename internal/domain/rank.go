@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Ranks order todos by hand, as on a board. A rank is a string of
+// rankDigits read as the digits of a fraction, so that ranks compare like
+// strings and a rank between any two others can always be found without
+// changing them. Ranks never end with the first digit, which would leave
+// no room before them.
+const rankDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// InitialRank returns the rank of a todo created at t: ranks of new todos
+// follow those of older ones. It is the hex Unix time in microseconds,
+// zero-padded, with a final "V"; the migration adding ranks computes the
+// same for existing todos.
+func InitialRank(t time.Time) string {
+	return fmt.Sprintf("%014xV", t.UnixMicro())
+}
+
+// RankBetween returns a rank after after and before before. An empty after
+// stands for the start of the board and an empty before for its end.
+func RankBetween(after, before string) (string, error) {
+	if !validRank(after) || !validRank(before) {
+		return "", NewValidationError("rank", "is malformed")
+	}
+	if before == "" {
+		if after == "" {
+			return InitialRank(time.Now()), nil
+		}
+		// Just after after, before any rank greater than it now.
+		return after + "V", nil
+	}
+	if after >= before {
+		return "", NewValidationError("rank", "the todo to move after must come before the todo to move before")
+	}
+	return midRank(after, before), nil
+}
+
+// midRank returns a rank between a and b, given a < b. An empty b stands
+// for the end of the board.
+func midRank(a, b string) string {
+	if b != "" {
+		// Keep the digits a and b share, reading a missing digit of a as
+		// the first digit.
+		n := 0
+		for n < len(b) && rankDigit(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midRank(a[min(n, len(a)):], b[n:])
+		}
+	}
+
+	da := 0
+	if a != "" {
+		da = strings.IndexByte(rankDigits, a[0])
+	}
+	db := len(rankDigits)
+	if b != "" {
+		db = strings.IndexByte(rankDigits, b[0])
+	}
+	if db-da > 1 {
+		return rankDigits[(da+db)/2 : (da+db)/2+1]
+	}
+	// The first digits are adjacent: b without its further digits lies in
+	// between, or else the rank continues a past its first digit.
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if a != "" {
+		rest = a[1:]
+	}
+	return rankDigits[da:da+1] + midRank(rest, "")
+}
+
+func rankDigit(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return rankDigits[0]
+}
+
+func validRank(s string) bool {
+	for i := range len(s) {
+		if strings.IndexByte(rankDigits, s[i]) < 0 {
+			return false
+		}
+	}
+	return s == "" || s[len(s)-1] != rankDigits[0]
+}
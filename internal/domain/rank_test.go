@@ -0,0 +1,88 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitialRank(t *testing.T) {
+	now := time.Now()
+	older, newer := domain.InitialRank(now), domain.InitialRank(now.Add(time.Microsecond))
+	assert.Less(t, older, newer)
+	assert.Len(t, older, 15)
+}
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		name          string
+		after, before string
+	}{
+		{name: "between distant ranks", after: "1", before: "z"},
+		{name: "between adjacent digits", after: "1", before: "2"},
+		{name: "before a longer rank", after: "1", before: "2V"},
+		{name: "between a rank and its extension", after: "1", before: "101"},
+		{name: "at the start", before: "01"},
+		{name: "at the end", after: "zz"},
+		{name: "between initial ranks", after: domain.InitialRank(time.Now()), before: domain.InitialRank(time.Now().Add(time.Second))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rank, err := domain.RankBetween(tt.after, tt.before)
+			require.NoError(t, err)
+			assert.Less(t, tt.after, rank)
+			if tt.before != "" {
+				assert.Less(t, rank, tt.before)
+			}
+			assert.NotEqual(t, byte('0'), rank[len(rank)-1])
+		})
+	}
+
+	t.Run("can always bisect again", func(t *testing.T) {
+		lo, hi := "V", "W"
+		for range 200 {
+			mid, err := domain.RankBetween(lo, hi)
+			require.NoError(t, err)
+			require.Less(t, lo, mid)
+			require.Less(t, mid, hi)
+			lo = mid
+		}
+		first := hi
+		for range 200 {
+			rank, err := domain.RankBetween("", first)
+			require.NoError(t, err)
+			require.Less(t, rank, first)
+			first = rank
+		}
+	})
+
+	t.Run("rejects", func(t *testing.T) {
+		for _, bounds := range [][2]string{{"b", "a"}, {"a", "a"}, {"a-", ""}, {"", "a0"}} {
+			_, err := domain.RankBetween(bounds[0], bounds[1])
+			assert.ErrorIs(t, err, domain.ErrValidation, "%q, %q", bounds[0], bounds[1])
+		}
+	})
+}
+
+func TestTodo_MoveBetween(t *testing.T) {
+	a, err := domain.NewTodo("A", "")
+	require.NoError(t, err)
+	b, err := domain.NewTodo("B", "")
+	require.NoError(t, err)
+	b.Rank = a.Rank + "V"
+	todo, err := domain.NewTodo("Task", "")
+	require.NoError(t, err)
+
+	require.NoError(t, todo.MoveBetween(a, b))
+	assert.Less(t, a.Rank, todo.Rank)
+	assert.Less(t, todo.Rank, b.Rank)
+
+	require.NoError(t, todo.MoveBetween(nil, a))
+	assert.Less(t, todo.Rank, a.Rank)
+
+	assert.ErrorIs(t, todo.MoveBetween(todo, nil), domain.ErrValidation)
+	assert.ErrorIs(t, todo.MoveBetween(b, a), domain.ErrValidation)
+}
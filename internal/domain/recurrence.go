@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// Recurrence repeats a Todo according to an RFC 5545 recurrence rule. Each
+// occurrence is a Todo of its own, anchored at the occurrence's due date.
+type Recurrence struct {
+	// Rule is an RRULE value such as "FREQ=WEEKLY;BYDAY=MO". The start of
+	// the series is the due date of the Todo it is attached to.
+	Rule string `json:"rule"`
+	// TimeZone is the IANA zone the rule is evaluated in, so that
+	// occurrences keep their wall clock time across DST changes.
+	TimeZone string `json:"timeZone"`
+}
+
+// NewRecurrence validates an RRULE value and an IANA time zone (UTC by
+// default). COUNT and DTSTART are rejected because every occurrence starts
+// the series afresh; use UNTIL to end a series. Rules repeating more often
+// than daily are rejected as well.
+func NewRecurrence(rule, timeZone string) (*Recurrence, error) {
+	if timeZone == "" {
+		timeZone = DefaultTimeZone
+	}
+	loc, err := loadTimeZone(timeZone)
+	if err != nil {
+		return nil, NewValidationError("recurrenceTimeZone", "must be a valid IANA time zone")
+	}
+
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	opt, err := rrule.StrToROptionInLocation(rule, loc)
+	if err != nil {
+		return nil, NewValidationError("recurrenceRule", "must be a valid RRULE: "+err.Error())
+	}
+	switch {
+	case opt.Freq > rrule.DAILY:
+		return nil, NewValidationError("recurrenceRule", "must not repeat more often than daily")
+	case opt.Count != 0:
+		return nil, NewValidationError("recurrenceRule", "must not use COUNT; use UNTIL instead")
+	case !opt.Dtstart.IsZero():
+		return nil, NewValidationError("recurrenceRule", "must not contain DTSTART; the due date starts the series")
+	}
+	if _, err := rrule.NewRRule(*opt); err != nil {
+		return nil, NewValidationError("recurrenceRule", "must be a valid RRULE: "+err.Error())
+	}
+	return &Recurrence{Rule: rule, TimeZone: timeZone}, nil
+}
+
+// Next returns the due date of the first occurrence that starts after both
+// due and after, keeping due's time zone and all-day flag. It returns nil
+// once the rule has no further occurrences.
+func (r *Recurrence) Next(due *Due, after time.Time) (*Due, error) {
+	loc, err := loadTimeZone(r.TimeZone)
+	if err != nil {
+		return nil, NewValidationError("recurrenceTimeZone", "must be a valid IANA time zone")
+	}
+	opt, err := rrule.StrToROptionInLocation(r.Rule, loc)
+	if err != nil {
+		return nil, NewValidationError("recurrenceRule", "must be a valid RRULE: "+err.Error())
+	}
+	opt.Dtstart = due.At.In(loc)
+	rule, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return nil, NewValidationError("recurrenceRule", "must be a valid RRULE: "+err.Error())
+	}
+
+	from := due.At
+	if after.After(from) {
+		from = after
+	}
+	next := rule.After(from.In(loc), false)
+	if next.IsZero() {
+		return nil, nil
+	}
+	if due.AllDay {
+		return NewDue(next.In(loc).Format(DueDateLayout), "", due.TimeZone)
+	}
+	return &Due{At: next.UTC(), AllDay: false, TimeZone: due.TimeZone}, nil
+}
@@ -0,0 +1,130 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecurrence(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		timeZone string
+		errField string
+	}{
+		{name: "weekly", rule: "FREQ=WEEKLY;BYDAY=MO"},
+		{name: "RRULE prefix", rule: "RRULE:FREQ=MONTHLY;BYMONTHDAY=-1", timeZone: "Asia/Tokyo"},
+		{name: "until", rule: "FREQ=DAILY;UNTIL=20261231T000000Z"},
+		{name: "missing FREQ", rule: "BYDAY=MO", errField: "recurrenceRule"},
+		{name: "garbage", rule: "every monday", errField: "recurrenceRule"},
+		{name: "hourly", rule: "FREQ=HOURLY", errField: "recurrenceRule"},
+		{name: "count", rule: "FREQ=DAILY;COUNT=3", errField: "recurrenceRule"},
+		{name: "unknown zone", rule: "FREQ=DAILY", timeZone: "Mars/Olympus", errField: "recurrenceTimeZone"},
+		{name: "server zone", rule: "FREQ=DAILY", timeZone: "Local", errField: "recurrenceTimeZone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := domain.NewRecurrence(tt.rule, tt.timeZone)
+			if tt.errField != "" {
+				var ve *domain.ValidationError
+				require.ErrorAs(t, err, &ve)
+				assert.Equal(t, tt.errField, ve.Field)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotContains(t, r.Rule, "RRULE:")
+			assert.NotEmpty(t, r.TimeZone)
+		})
+	}
+}
+
+func TestRecurrence_Next(t *testing.T) {
+	t.Run("keeps the wall clock time across DST", func(t *testing.T) {
+		r, err := domain.NewRecurrence("FREQ=WEEKLY;BYDAY=MO", "America/New_York")
+		require.NoError(t, err)
+		// 2026-03-02 is a Monday; DST starts on 2026-03-08.
+		due, err := domain.NewDue("2026-03-02", "09:00", "America/New_York")
+		require.NoError(t, err)
+
+		next, err := r.Next(due, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, "2026-03-09", next.Date())
+		assert.Equal(t, "09:00", next.Clock())
+		assert.Equal(t, "America/New_York", next.TimeZone)
+	})
+
+	t.Run("all-day due dates stay all-day", func(t *testing.T) {
+		r, err := domain.NewRecurrence("FREQ=MONTHLY;BYMONTHDAY=-1", "Asia/Tokyo")
+		require.NoError(t, err)
+		due, err := domain.NewDue("2026-01-31", "", "Asia/Tokyo")
+		require.NoError(t, err)
+
+		next, err := r.Next(due, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, "2026-02-28", next.Date())
+		assert.True(t, next.AllDay)
+	})
+
+	t.Run("skips occurrences before after", func(t *testing.T) {
+		r, err := domain.NewRecurrence("FREQ=DAILY", "")
+		require.NoError(t, err)
+		due, err := domain.NewDue("2026-01-01", "10:00", "")
+		require.NoError(t, err)
+
+		next, err := r.Next(due, time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, "2026-01-11", next.Date())
+	})
+
+	t.Run("ends at UNTIL", func(t *testing.T) {
+		r, err := domain.NewRecurrence("FREQ=DAILY;UNTIL=20260102T235959Z", "")
+		require.NoError(t, err)
+		due, err := domain.NewDue("2026-01-02", "10:00", "")
+		require.NoError(t, err)
+
+		next, err := r.Next(due, time.Time{})
+		require.NoError(t, err)
+		assert.Nil(t, next)
+	})
+}
+
+func TestTodo_NextOccurrence(t *testing.T) {
+	r, err := domain.NewRecurrence("FREQ=WEEKLY", "")
+	require.NoError(t, err)
+	due, err := domain.NewDue("2026-03-02", "", "")
+	require.NoError(t, err)
+
+	_, err = domain.NewTodo("Report", "", domain.WithRecurrence(r))
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	owner, assignee := uuid.New(), uuid.New()
+	todo, err := domain.NewTodo("Report", "weekly", domain.WithDue(due), domain.WithRecurrence(r),
+		domain.WithPriority(domain.PriorityHigh), domain.WithTags([]string{"work"}),
+		domain.WithCreator(&owner), domain.WithAssignee(&assignee))
+	require.NoError(t, err)
+	require.NotNil(t, todo.SeriesID)
+	assert.Equal(t, todo.ID, *todo.SeriesID)
+
+	next, err := todo.NextOccurrence(time.Time{})
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.NotEqual(t, todo.ID, next.ID)
+	assert.Equal(t, "2026-03-09", next.Due.Date())
+	assert.Equal(t, todo.SeriesID, next.SeriesID)
+	assert.Equal(t, domain.StatusOpen, next.Status)
+	assert.Equal(t, domain.PriorityHigh, next.Priority)
+	assert.Equal(t, []string{"work"}, next.Tags)
+	assert.Equal(t, &owner, next.CreatedBy)
+	assert.Equal(t, &assignee, next.AssigneeID)
+
+	require.NoError(t, todo.SetRecurrence(nil))
+	next, err = todo.NextOccurrence(time.Time{})
+	require.NoError(t, err)
+	assert.Nil(t, next)
+}
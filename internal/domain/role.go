@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role is the part a user plays in a workspace. Each role may do
+// everything the ones below it may: admin includes member, and member
+// includes viewer.
+type Role string
+
+const (
+	// RoleViewer may read todos.
+	RoleViewer Role = "viewer"
+	// RoleMember may also create todos and change the ones they own or
+	// are assigned to.
+	RoleMember Role = "member"
+	// RoleAdmin may also change any todo, complete todos in bulk, manage
+	// users and webhooks and export the audit trail.
+	RoleAdmin Role = "admin"
+)
+
+var roleRanks = map[Role]int{RoleViewer: 1, RoleMember: 2, RoleAdmin: 3}
+
+// ParseRole converts a role name to a Role. An empty name is RoleMember.
+func ParseRole(s string) (Role, error) {
+	if s == "" {
+		return RoleMember, nil
+	}
+	role := Role(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := roleRanks[role]; !ok {
+		return "", NewValidationError("role", "must be one of viewer, member, admin")
+	}
+	return role, nil
+}
+
+// Includes reports whether r may do everything other may.
+func (r Role) Includes(other Role) bool {
+	return roleRanks[r] >= roleRanks[other]
+}
+
+// Action is an operation subject to authorization.
+type Action string
+
+const (
+	ActionRead           Action = "read"
+	ActionCreate         Action = "create"
+	ActionEdit           Action = "edit"
+	ActionDelete         Action = "delete"
+	ActionCompleteAll    Action = "complete_all"
+	ActionPurge          Action = "purge"
+	ActionMaterialize    Action = "materialize_recurrences"
+	ActionManageUsers    Action = "manage_users"
+	ActionExportAudit    Action = "export_audit"
+	ActionManageWebhooks Action = "manage_webhooks"
+)
+
+// actionRoles maps every action to the least role allowed to perform it.
+var actionRoles = map[Action]Role{
+	ActionRead:           RoleViewer,
+	ActionCreate:         RoleMember,
+	ActionEdit:           RoleMember,
+	ActionDelete:         RoleMember,
+	ActionCompleteAll:    RoleAdmin,
+	ActionPurge:          RoleAdmin,
+	ActionMaterialize:    RoleAdmin,
+	ActionManageUsers:    RoleAdmin,
+	ActionExportAudit:    RoleAdmin,
+	ActionManageWebhooks: RoleAdmin,
+}
+
+// Authorize returns an error wrapping ErrForbidden unless u may perform
+// action on todo. todo is nil for actions that are not about a single
+// todo. Members may only edit and delete todos that are EditableBy them;
+// admins may change any todo, including those without an owner.
+func (u *User) Authorize(action Action, todo *Todo) error {
+	least, ok := actionRoles[action]
+	if !ok || !u.Role.Includes(least) {
+		return fmt.Errorf("%w: the %s role may not %s", ErrForbidden, u.Role, strings.ReplaceAll(string(action), "_", " "))
+	}
+	if todo != nil && (action == ActionEdit || action == ActionDelete) &&
+		!u.Role.Includes(RoleAdmin) && !todo.EditableBy(u.ID) {
+		return fmt.Errorf("%w: only the owner or the assignee can change todo %s", ErrForbidden, todo.ID)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package domain
+
+import (
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchMode selects how a TodoSearch matches its query.
+type SearchMode string
+
+const (
+	// SearchAuto searches by ngram if the query contains Chinese, Japanese
+	// or Korean characters, and by word otherwise.
+	SearchAuto SearchMode = "auto"
+	// SearchSimple matches whole words, as split by the PostgreSQL "simple"
+	// text search configuration. The query follows the web search syntax:
+	// "quoted phrases", OR between alternatives and -word to exclude.
+	SearchSimple SearchMode = "simple"
+	// SearchNgram matches every whitespace separated term of the query as a
+	// substring, ignoring case, for text that is not split into words such
+	// as Japanese.
+	SearchNgram SearchMode = "ngram"
+)
+
+const (
+	// MaxSearchQueryLength is the largest query, in characters.
+	MaxSearchQueryLength = 200
+	// MaxSearchTerms is the largest number of terms in a query.
+	MaxSearchTerms = 10
+	// SearchSnippetLength is the length, in characters, of the excerpt of
+	// the description returned with each hit.
+	SearchSnippetLength = 120
+)
+
+// ParseSearchMode parses a search mode; empty means SearchAuto.
+func ParseSearchMode(s string) (SearchMode, error) {
+	switch m := SearchMode(s); m {
+	case "":
+		return SearchAuto, nil
+	case SearchAuto, SearchSimple, SearchNgram:
+		return m, nil
+	}
+	return "", NewValidationError("mode", fmt.Sprintf("must be %q, %q or %q", SearchAuto, SearchSimple, SearchNgram))
+}
+
+// TodoSearch is a full-text search of the titles and descriptions of todos.
+type TodoSearch struct {
+	// Query is the text searched for, as given.
+	Query string
+	// Mode is SearchSimple or SearchNgram; SearchAuto has been resolved.
+	Mode SearchMode
+	// Terms are the lower case words or substrings of Query that matching
+	// todos contain. Words excluded by the query are left out.
+	Terms []string
+	// Filter narrows down the todos searched.
+	Filter TodoFilter
+	// Limit is the maximum number of hits.
+	Limit int
+}
+
+// NewTodoSearch validates query and resolves mode for it.
+func NewTodoSearch(query string, mode SearchMode) (*TodoSearch, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, NewValidationError("q", "is required")
+	}
+	if utf8.RuneCountInString(query) > MaxSearchQueryLength {
+		return nil, NewValidationError("q", fmt.Sprintf("must be at most %d characters", MaxSearchQueryLength))
+	}
+	if mode == SearchAuto || mode == "" {
+		mode = SearchSimple
+		if strings.IndexFunc(query, isCJK) >= 0 {
+			mode = SearchNgram
+		}
+	}
+
+	var terms []string
+	if mode == SearchNgram {
+		terms = strings.Fields(strings.ReplaceAll(query, `"`, " "))
+	} else {
+		for _, field := range strings.Fields(query) {
+			if strings.HasPrefix(field, "-") || strings.EqualFold(field, "or") {
+				continue
+			}
+			terms = append(terms, strings.FieldsFunc(field, func(r rune) bool { return !isWordRune(r) })...)
+		}
+	}
+	for i := range terms {
+		terms[i] = strings.ToLower(terms[i])
+	}
+	slices.Sort(terms)
+	terms = slices.Compact(terms)
+	if len(terms) == 0 {
+		return nil, NewValidationError("q", "must contain a word to search for")
+	}
+	if len(terms) > MaxSearchTerms {
+		return nil, NewValidationError("q", fmt.Sprintf("must contain at most %d terms", MaxSearchTerms))
+	}
+	return &TodoSearch{Query: query, Mode: mode, Terms: terms}, nil
+}
+
+// Highlighter returns the highlighter of the terms of the search.
+func (s *TodoSearch) Highlighter() *Highlighter {
+	return NewHighlighter(s.Terms, s.Mode == SearchSimple)
+}
+
+// SearchHit is a todo found by a TodoSearch.
+type SearchHit struct {
+	Todo Todo
+	// Rank scores how well the todo matches, higher being better. Ranks
+	// only compare within a search.
+	Rank float64
+	// Title is the title with the matches highlighted.
+	Title string
+	// Snippet is an excerpt of the description around its first match,
+	// highlighted likewise; empty if the description does not match.
+	Snippet string
+}
+
+// Highlight marks are put around the matches of highlighted text.
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// Highlighter marks the occurrences of search terms in text. Its output is
+// HTML escaped, so that the marks are the only markup in it.
+//
+// Snippets are highlighted here rather than by ts_headline, which cannot
+// find terms within text that is not split into words, such as Japanese.
+type Highlighter struct {
+	terms [][]rune
+	words bool
+}
+
+// NewHighlighter returns a highlighter of terms, matched ignoring case.
+// With words, only whole words are matched.
+func NewHighlighter(terms []string, words bool) *Highlighter {
+	h := &Highlighter{words: words}
+	for _, term := range terms {
+		if term != "" {
+			h.terms = append(h.terms, foldRunes([]rune(term)))
+		}
+	}
+	// Longer terms are tried first, so that they win over their prefixes.
+	slices.SortStableFunc(h.terms, func(a, b []rune) int { return len(b) - len(a) })
+	return h
+}
+
+// Mark returns text with every match highlighted.
+func (h *Highlighter) Mark(text string) string {
+	runes := []rune(text)
+	return render(runes, h.matches(runes), 0, len(runes))
+}
+
+// Snippet returns an excerpt of at most width characters of text around
+// its first match, with the matches highlighted and "…" where text is cut.
+// Whitespace is collapsed into single spaces. It returns "" if text does
+// not match.
+func (h *Highlighter) Snippet(text string, width int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	matches := h.matches(runes)
+	if len(matches) == 0 {
+		return ""
+	}
+	// The first match is shown after a third of the width of context.
+	start := max(matches[0].start-width/3, 0)
+	end := min(start+width, len(runes))
+	start = max(end-width, 0)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	b.WriteString(render(runes, matches, start, end))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+type span struct{ start, end int }
+
+// matches returns the non-overlapping matches in runes, in order.
+func (h *Highlighter) matches(runes []rune) []span {
+	folded := foldRunes(slices.Clone(runes))
+	var found []span
+	for i := 0; i < len(folded); {
+		n := h.matchAt(folded, i)
+		if n == 0 {
+			i++
+			continue
+		}
+		found = append(found, span{i, i + n})
+		i += n
+	}
+	return found
+}
+
+// matchAt returns the length of the term matching at i, or 0.
+func (h *Highlighter) matchAt(folded []rune, i int) int {
+	if h.words && i > 0 && isWordRune(folded[i-1]) {
+		return 0
+	}
+	for _, term := range h.terms {
+		end := i + len(term)
+		if end > len(folded) || !slices.Equal(folded[i:end], term) {
+			continue
+		}
+		if h.words && end < len(folded) && isWordRune(folded[end]) {
+			continue
+		}
+		return len(term)
+	}
+	return 0
+}
+
+// render escapes runes[start:end] and marks the matches within it.
+func render(runes []rune, matches []span, start, end int) string {
+	var b strings.Builder
+	at := start
+	for _, m := range matches {
+		m.start, m.end = max(m.start, start), min(m.end, end)
+		if m.start >= m.end {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[at:m.start])))
+		b.WriteString(HighlightStart)
+		b.WriteString(html.EscapeString(string(runes[m.start:m.end])))
+		b.WriteString(HighlightEnd)
+		at = m.end
+	}
+	b.WriteString(html.EscapeString(string(runes[at:end])))
+	return b.String()
+}
+
+// foldRunes lower cases runes in place, keeping their number.
+func foldRunes(runes []rune) []rune {
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
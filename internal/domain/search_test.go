@@ -0,0 +1,84 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTodoSearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		mode      domain.SearchMode
+		wantMode  domain.SearchMode
+		wantTerms []string
+	}{
+		{name: "words", query: "Quarterly report", mode: domain.SearchAuto, wantMode: domain.SearchSimple, wantTerms: []string{"quarterly", "report"}},
+		{name: "web search syntax", query: `"sales report" or draft -old`, mode: domain.SearchSimple, wantMode: domain.SearchSimple, wantTerms: []string{"draft", "report", "sales"}},
+		{name: "japanese", query: "会議 資料", mode: domain.SearchAuto, wantMode: domain.SearchNgram, wantTerms: []string{"会議", "資料"}},
+		{name: "ngram keeps punctuation", query: `"v1.2" Release`, mode: domain.SearchNgram, wantMode: domain.SearchNgram, wantTerms: []string{"release", "v1.2"}},
+		{name: "duplicates", query: "API api", mode: domain.SearchAuto, wantMode: domain.SearchSimple, wantTerms: []string{"api"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := domain.NewTodoSearch(tt.query, tt.mode)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMode, s.Mode)
+			assert.Equal(t, tt.wantTerms, s.Terms)
+		})
+	}
+
+	for name, query := range map[string]string{
+		"empty":          "  ",
+		"only excluded":  "-draft",
+		"too long":       strings.Repeat("a", domain.MaxSearchQueryLength+1),
+		"too many terms": "a b c d e f g h i j k",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := domain.NewTodoSearch(query, domain.SearchAuto)
+			assert.ErrorIs(t, err, domain.ErrValidation)
+		})
+	}
+}
+
+func TestParseSearchMode(t *testing.T) {
+	mode, err := domain.ParseSearchMode("")
+	require.NoError(t, err)
+	assert.Equal(t, domain.SearchAuto, mode)
+
+	mode, err = domain.ParseSearchMode("ngram")
+	require.NoError(t, err)
+	assert.Equal(t, domain.SearchNgram, mode)
+
+	_, err = domain.ParseSearchMode("fuzzy")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+func TestHighlighter(t *testing.T) {
+	words := domain.NewHighlighter([]string{"report", "q1"}, true)
+	assert.Equal(t, "<mark>Q1</mark> <mark>Report</mark> &amp; reports",
+		words.Mark("Q1 Report & reports"), "whole words only, escaped")
+
+	ngram := domain.NewHighlighter([]string{"会議", "会議室"}, false)
+	assert.Equal(t, "明日の<mark>会議室</mark>で<mark>会議</mark>", ngram.Mark("明日の会議室で会議"), "longest term first")
+	assert.Equal(t, "no match", ngram.Mark("no match"))
+
+	t.Run("snippet", func(t *testing.T) {
+		text := strings.Repeat("lorem ipsum ", 20) + "the\nquarterly <report> " + strings.Repeat("dolor sit ", 20)
+		h := domain.NewHighlighter([]string{"quarterly"}, true)
+		snippet := h.Snippet(text, 60)
+
+		assert.True(t, strings.HasPrefix(snippet, "…"), snippet)
+		assert.True(t, strings.HasSuffix(snippet, "…"), snippet)
+		assert.Contains(t, snippet, "the <mark>quarterly</mark> &lt;report&gt;")
+		plain := strings.NewReplacer(domain.HighlightStart, "", domain.HighlightEnd, "", "&lt;", "<", "&gt;", ">").Replace(snippet)
+		assert.Equal(t, 60+2, len([]rune(plain)), "width plus the ellipses")
+
+		assert.Equal(t, "<mark>quarterly</mark> report", h.Snippet("quarterly report", 60), "short text is not cut")
+		assert.Empty(t, h.Snippet("annual report", 60))
+	})
+}
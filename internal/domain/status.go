@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Status is the lifecycle state of a Todo.
+type Status string
+
+const (
+	StatusOpen       Status = "open"
+	StatusInProgress Status = "in_progress"
+	StatusDone       Status = "done"
+	StatusCancelled  Status = "cancelled"
+)
+
+// Statuses lists every valid Status.
+var Statuses = []Status{StatusOpen, StatusInProgress, StatusDone, StatusCancelled}
+
+// statusTransitions lists the states each state may move to.
+var statusTransitions = map[Status][]Status{
+	StatusOpen:       {StatusInProgress, StatusDone, StatusCancelled},
+	StatusInProgress: {StatusDone, StatusCancelled},
+	StatusDone:       {StatusOpen},
+	StatusCancelled:  {StatusOpen},
+}
+
+// ParseStatus converts s to a Status, rejecting unknown values.
+func ParseStatus(s string) (Status, error) {
+	if st := Status(s); slices.Contains(Statuses, st) {
+		return st, nil
+	}
+	return "", NewValidationError("status", fmt.Sprintf("unknown status %q", s))
+}
+
+// IsActive reports whether a todo in this state still needs work.
+func (s Status) IsActive() bool {
+	return s == StatusOpen || s == StatusInProgress
+}
+
+// CanTransitionTo reports whether a todo may move from s to next.
+func (s Status) CanTransitionTo(next Status) bool {
+	return slices.Contains(statusTransitions[s], next)
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const MaxTagNameLength = 50
+
+// Tag is a label that can be attached to any number of todos. Tag names are
+// unique and stored in lower case.
+type Tag struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewTag(name string) (*Tag, error) {
+	name, err := NormalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Tag{ID: uuid.New(), Name: name, CreatedAt: time.Now().UTC()}, nil
+}
+
+func (t *Tag) Rename(name string) error {
+	name, err := NormalizeTagName(name)
+	if err != nil {
+		return err
+	}
+	t.Name = name
+	return nil
+}
+
+// NormalizeTagName trims and lower-cases a tag name and validates the result.
+func NormalizeTagName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", NewValidationError("tags", "tag name must not be empty")
+	}
+	if len([]rune(name)) > MaxTagNameLength {
+		return "", NewValidationError("tags", "tag name must not exceed 50 characters")
+	}
+	if strings.Contains(name, ",") {
+		return "", NewValidationError("tags", "tag name must not contain commas")
+	}
+	return name, nil
+}
+
+// NormalizeTagNames normalizes every name and returns them sorted without
+// duplicates.
+func NormalizeTagNames(names []string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		n, err := NormalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, n)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
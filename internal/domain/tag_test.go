@@ -0,0 +1,31 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTag(t *testing.T) {
+	tag, err := domain.NewTag("  Backend ")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", tag.Name)
+	assert.NotEmpty(t, tag.ID)
+
+	for _, name := range []string{"", "   ", "a,b", strings.Repeat("x", 51)} {
+		_, err := domain.NewTag(name)
+		assert.ErrorIs(t, err, domain.ErrValidation, "name %q", name)
+	}
+}
+
+func TestNormalizeTagNames(t *testing.T) {
+	tags, err := domain.NormalizeTagNames([]string{"urgent", "Backend", "URGENT", " api "})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api", "backend", "urgent"}, tags)
+
+	_, err = domain.NormalizeTagNames([]string{"ok", ""})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
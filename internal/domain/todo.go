@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,29 +10,143 @@ import (
 
 const MaxTitleLength = 200
 
+// MaxTodoDepth is the number of levels a todo hierarchy may have, counting
+// the top-level todo as the first.
+const MaxTodoDepth = 5
+
 type Todo struct {
 	ID          uuid.UUID `json:"id"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
-	Completed   bool      `json:"completed"`
+	Status      Status    `json:"status"`
+	Priority    Priority  `json:"priority"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
+	Due         *Due      `json:"due,omitempty"`
+	// Tags holds the names of the todo's tags, sorted and without duplicates.
+	Tags []string `json:"tags"`
+	// Version is incremented by every write and used for optimistic locking.
+	Version int64 `json:"version"`
+	// DeletedAt is set while the Todo is in the trash.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// ParentID is the todo this one is a subtask of; nil for top-level todos.
+	ParentID *uuid.UUID `json:"parentId,omitempty"`
+	// Recurrence repeats the Todo; nil for one-off todos.
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+	// SeriesID groups the occurrences of a recurring Todo. It is the ID of
+	// the Todo the recurrence was first set on.
+	SeriesID *uuid.UUID `json:"seriesId,omitempty"`
+	// ProjectID is the project the todo belongs to; nil for todos outside
+	// any project.
+	ProjectID *uuid.UUID `json:"projectId,omitempty"`
+	// CreatedBy is the user who created the todo, its owner; nil for todos
+	// created by service clients, batch jobs or before users existed.
+	CreatedBy *uuid.UUID `json:"createdBy,omitempty"`
+	// UpdatedBy is the user who changed the todo last; nil if that was not
+	// a user.
+	UpdatedBy *uuid.UUID `json:"updatedBy,omitempty"`
+	// AssigneeID is the user responsible for the todo; nil if unassigned.
+	AssigneeID *uuid.UUID `json:"assigneeId,omitempty"`
+	// Rank places the todo on boards, which list todos by rank; see
+	// RankBetween.
+	Rank string `json:"rank"`
+}
+
+// TodoOption sets an optional attribute on a Todo created by NewTodo.
+type TodoOption func(*Todo) error
+
+// WithDue sets the deadline of a new Todo.
+func WithDue(due *Due) TodoOption {
+	return func(t *Todo) error {
+		t.Due = due
+		return nil
+	}
+}
+
+// WithPriority sets the priority of a new Todo.
+func WithPriority(p Priority) TodoOption {
+	return func(t *Todo) error {
+		t.Priority = p
+		return nil
+	}
+}
+
+// WithParent makes a new Todo a subtask of parentID.
+func WithParent(parentID *uuid.UUID) TodoOption {
+	return func(t *Todo) error {
+		t.ParentID = parentID
+		return nil
+	}
+}
+
+// WithProject puts a new Todo in projectID.
+func WithProject(projectID *uuid.UUID) TodoOption {
+	return func(t *Todo) error {
+		t.ProjectID = projectID
+		return nil
+	}
+}
+
+// WithCreator records user as the creator and owner of a new Todo. A nil
+// user leaves the Todo without an owner.
+func WithCreator(user *uuid.UUID) TodoOption {
+	return func(t *Todo) error {
+		t.CreatedBy = user
+		t.UpdatedBy = user
+		return nil
+	}
+}
+
+// WithAssignee assigns a new Todo to user.
+func WithAssignee(user *uuid.UUID) TodoOption {
+	return func(t *Todo) error {
+		t.AssigneeID = user
+		return nil
+	}
+}
+
+// WithRecurrence makes a new Todo recur. It must follow WithDue, as the due
+// date starts the series.
+func WithRecurrence(r *Recurrence) TodoOption {
+	return func(t *Todo) error {
+		return t.SetRecurrence(r)
+	}
+}
+
+// WithTags labels a new Todo with the named tags.
+func WithTags(names []string) TodoOption {
+	return func(t *Todo) error {
+		tags, err := NormalizeTagNames(names)
+		if err != nil {
+			return err
+		}
+		t.Tags = tags
+		return nil
+	}
 }
 
-func NewTodo(title, description string) (*Todo, error) {
+func NewTodo(title, description string, opts ...TodoOption) (*Todo, error) {
 	if err := validateTitle(title); err != nil {
 		return nil, err
 	}
 
 	now := time.Now().UTC()
-	return &Todo{
+	todo := &Todo{
 		ID:          uuid.New(),
 		Title:       title,
 		Description: description,
-		Completed:   false,
+		Status:      StatusOpen,
 		CreatedAt:   now,
 		UpdatedAt:   now,
-	}, nil
+		Version:     1,
+		Rank:        InitialRank(now),
+	}
+	for _, opt := range opts {
+		if err := opt(todo); err != nil {
+			return nil, err
+		}
+	}
+	return todo, nil
 }
 
 func (t *Todo) UpdateTitle(title string) error {
@@ -47,9 +163,193 @@ func (t *Todo) UpdateDescription(description string) {
 	t.UpdatedAt = time.Now().UTC()
 }
 
-func (t *Todo) MarkComplete() {
-	t.Completed = true
+// SetDue replaces the deadline. A nil due removes it.
+func (t *Todo) SetDue(due *Due) {
+	t.Due = due
+	t.UpdatedAt = time.Now().UTC()
+}
+
+func (t *Todo) SetPriority(p Priority) {
+	t.Priority = p
+	t.UpdatedAt = time.Now().UTC()
+}
+
+// SetTags replaces the todo's tags with the named ones.
+func (t *Todo) SetTags(names []string) error {
+	tags, err := NormalizeTagNames(names)
+	if err != nil {
+		return err
+	}
+	t.Tags = tags
+	t.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// SetParent makes the Todo a subtask of parentID, or a top-level todo if
+// parentID is nil. Checks that span the hierarchy, such as cycles and depth,
+// are left to the caller.
+func (t *Todo) SetParent(parentID *uuid.UUID) error {
+	if parentID != nil && *parentID == t.ID {
+		return NewValidationError("parentId", "a todo cannot be its own parent")
+	}
+	t.ParentID = parentID
 	t.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// SetProject moves the Todo to projectID, or out of any project if projectID
+// is nil.
+func (t *Todo) SetProject(projectID *uuid.UUID) {
+	t.ProjectID = projectID
+	t.UpdatedAt = time.Now().UTC()
+}
+
+// Assign makes user responsible for the Todo, or unassigns it if user is nil.
+func (t *Todo) Assign(user *uuid.UUID) {
+	t.AssigneeID = user
+	t.UpdatedAt = time.Now().UTC()
+}
+
+// MoveBetween ranks the Todo right after after and before before. A nil
+// after moves it to the start of the board and a nil before to the end.
+func (t *Todo) MoveBetween(after, before *Todo) error {
+	var a, b string
+	if after != nil {
+		if after.ID == t.ID {
+			return NewValidationError("afterId", "a todo cannot be moved next to itself")
+		}
+		a = after.Rank
+	}
+	if before != nil {
+		if before.ID == t.ID {
+			return NewValidationError("beforeId", "a todo cannot be moved next to itself")
+		}
+		b = before.Rank
+	}
+	rank, err := RankBetween(a, b)
+	if err != nil {
+		return err
+	}
+	t.Rank = rank
+	t.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// EditableBy reports whether user may change the Todo: its owner and its
+// assignee may. A Todo without an owner is owned by no user, so only its
+// assignee may.
+func (t *Todo) EditableBy(user uuid.UUID) bool {
+	return (t.CreatedBy != nil && *t.CreatedBy == user) || (t.AssigneeID != nil && *t.AssigneeID == user)
+}
+
+// RecordEditor records user as the last to change the Todo; nil records a
+// change by someone other than a user.
+func (t *Todo) RecordEditor(user *uuid.UUID) {
+	t.UpdatedBy = user
+}
+
+// SetRecurrence makes the Todo recur, or stops it recurring if r is nil.
+// A recurring Todo needs a due date to anchor its occurrences.
+func (t *Todo) SetRecurrence(r *Recurrence) error {
+	if r != nil && t.Due == nil {
+		return NewValidationError("recurrenceRule", "requires a due date")
+	}
+	t.Recurrence = r
+	if r != nil && t.SeriesID == nil {
+		t.SeriesID = &t.ID
+	}
+	t.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// NextOccurrence returns a new open Todo for the first occurrence of the
+// Todo's recurrence that starts after both its own due date and after. It
+// carries over the title, description, priority, tags, project, owner and
+// assignee. It returns nil if the Todo does not recur or its rule has
+// ended.
+func (t *Todo) NextOccurrence(after time.Time) (*Todo, error) {
+	if t.Recurrence == nil || t.Due == nil {
+		return nil, nil
+	}
+	due, err := t.Recurrence.Next(t.Due, after)
+	if err != nil || due == nil {
+		return nil, err
+	}
+
+	next, err := NewTodo(t.Title, t.Description,
+		WithDue(due), WithPriority(t.Priority), WithTags(t.Tags), WithProject(t.ProjectID),
+		WithCreator(t.CreatedBy), WithAssignee(t.AssigneeID))
+	if err != nil {
+		return nil, err
+	}
+	next.Recurrence = t.Recurrence
+	next.SeriesID = t.SeriesID
+	return next, nil
+}
+
+// IsOverdue reports whether the Todo is still active after its deadline.
+func (t *Todo) IsOverdue(now time.Time) bool {
+	return t.Status.IsActive() && t.Due != nil && now.After(t.Due.Deadline())
+}
+
+// IsCompleted reports whether the Todo is done.
+func (t *Todo) IsCompleted() bool {
+	return t.Status == StatusDone
+}
+
+// Start moves an open Todo to in progress.
+func (t *Todo) Start() error {
+	return t.transition(StatusInProgress)
+}
+
+// MarkComplete moves an open or in-progress Todo to done.
+func (t *Todo) MarkComplete() error {
+	return t.transition(StatusDone)
+}
+
+// Cancel abandons an open or in-progress Todo.
+func (t *Todo) Cancel() error {
+	return t.transition(StatusCancelled)
+}
+
+// Reopen moves a done or cancelled Todo back to open.
+func (t *Todo) Reopen() error {
+	return t.transition(StatusOpen)
+}
+
+func (t *Todo) transition(next Status) error {
+	if !t.Status.CanTransitionTo(next) {
+		return fmt.Errorf("%w: cannot move from %s to %s", ErrInvalidTransition, t.Status, next)
+	}
+	t.Status = next
+	t.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// IsTrashed reports whether the Todo has been moved to the trash.
+func (t *Todo) IsTrashed() bool {
+	return t.DeletedAt != nil
+}
+
+// MoveToTrash soft-deletes the Todo; it can be restored until purged.
+func (t *Todo) MoveToTrash() {
+	now := time.Now().UTC()
+	t.DeletedAt = &now
+}
+
+// Restore takes the Todo out of the trash.
+func (t *Todo) Restore() {
+	t.DeletedAt = nil
+	t.UpdatedAt = time.Now().UTC()
+}
+
+// CheckVersion fails with ErrPreconditionFailed unless the Todo is at one
+// of the expected versions. No expected versions match any version.
+func (t *Todo) CheckVersion(expected []int64) error {
+	if len(expected) > 0 && !slices.Contains(expected, t.Version) {
+		return fmt.Errorf("%w: expected version %v, current version %d", ErrPreconditionFailed, expected, t.Version)
+	}
+	return nil
 }
 
 func validateTitle(title string) error {
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TagMatch selects how a TodoFilter combines several tags.
+type TagMatch string
+
+const (
+	// TagMatchAll keeps todos that have every listed tag.
+	TagMatchAll TagMatch = "all"
+	// TagMatchAny keeps todos that have at least one listed tag.
+	TagMatchAny TagMatch = "any"
+)
+
+// TodoFilter narrows down the todos returned by a list query.
+// Zero-valued fields do not constrain the result.
+type TodoFilter struct {
+	// Statuses keeps todos in any of the given states.
+	Statuses []Status
+	// TitleContains keeps todos whose title contains the text, ignoring case.
+	TitleContains string
+	// DescriptionContains keeps todos whose description contains the text, ignoring case.
+	DescriptionContains string
+
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+
+	// DueBefore keeps todos whose deadline is before this instant.
+	DueBefore *time.Time
+	// DueAfter keeps todos whose deadline is at or after this instant.
+	DueAfter *time.Time
+	// Overdue keeps active todos whose deadline has already passed.
+	Overdue bool
+
+	// Tags keeps todos labelled with the named tags; TagMatch decides
+	// whether all or any of them must be present.
+	Tags     []string
+	TagMatch TagMatch
+
+	// ParentID keeps the direct subtasks of the given todo.
+	ParentID *uuid.UUID
+	// ProjectID keeps the todos of the given project.
+	ProjectID *uuid.UUID
+	// AssigneeID keeps the todos assigned to the given user.
+	AssigneeID *uuid.UUID
+
+	// Trashed lists the todos in the trash instead of the live ones.
+	Trashed bool
+}
+
+// Matches reports whether t passes the filter, as of now. It agrees with
+// the list queries, so that a live view of a list can tell which changed
+// todos belong to it.
+func (f TodoFilter) Matches(t *Todo, now time.Time) bool {
+	switch {
+	case f.Trashed != t.IsTrashed():
+		return false
+	case len(f.Statuses) > 0 && !slices.Contains(f.Statuses, t.Status):
+		return false
+	case f.TitleContains != "" && !containsFold(t.Title, f.TitleContains):
+		return false
+	case f.DescriptionContains != "" && !containsFold(t.Description, f.DescriptionContains):
+		return false
+	case f.CreatedAfter != nil && t.CreatedAt.Before(*f.CreatedAfter),
+		f.CreatedBefore != nil && !t.CreatedAt.Before(*f.CreatedBefore),
+		f.UpdatedAfter != nil && t.UpdatedAt.Before(*f.UpdatedAfter),
+		f.UpdatedBefore != nil && !t.UpdatedAt.Before(*f.UpdatedBefore):
+		return false
+	case f.DueBefore != nil && (t.Due == nil || !t.Due.Deadline().Before(*f.DueBefore)),
+		f.DueAfter != nil && (t.Due == nil || t.Due.Deadline().Before(*f.DueAfter)):
+		return false
+	case f.Overdue && !t.IsOverdue(now):
+		return false
+	case f.ParentID != nil && !sameID(t.ParentID, *f.ParentID),
+		f.ProjectID != nil && !sameID(t.ProjectID, *f.ProjectID),
+		f.AssigneeID != nil && !sameID(t.AssigneeID, *f.AssigneeID):
+		return false
+	}
+	if len(f.Tags) == 0 {
+		return true
+	}
+	if f.TagMatch == TagMatchAny {
+		return slices.ContainsFunc(f.Tags, func(tag string) bool { return slices.Contains(t.Tags, tag) })
+	}
+	return !slices.ContainsFunc(f.Tags, func(tag string) bool { return !slices.Contains(t.Tags, tag) })
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func sameID(id *uuid.UUID, want uuid.UUID) bool {
+	return id != nil && *id == want
+}
@@ -0,0 +1,55 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoFilter_Matches(t *testing.T) {
+	project, user := uuid.New(), uuid.New()
+	due, err := domain.NewDue("2026-03-01", "", "")
+	require.NoError(t, err)
+	todo, err := domain.NewTodo("Write Report", "quarterly numbers",
+		domain.WithProject(&project), domain.WithAssignee(&user), domain.WithDue(due), domain.WithTags([]string{"work", "q1"}))
+	require.NoError(t, err)
+	now := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	before, after := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter domain.TodoFilter
+		want   bool
+	}{
+		{name: "no constraint", want: true},
+		{name: "status", filter: domain.TodoFilter{Statuses: []domain.Status{domain.StatusOpen}}, want: true},
+		{name: "other status", filter: domain.TodoFilter{Statuses: []domain.Status{domain.StatusDone}}},
+		{name: "title ignoring case", filter: domain.TodoFilter{TitleContains: "report"}, want: true},
+		{name: "other title", filter: domain.TodoFilter{TitleContains: "invoice"}},
+		{name: "description", filter: domain.TodoFilter{DescriptionContains: "Numbers"}, want: true},
+		{name: "due range", filter: domain.TodoFilter{DueAfter: &after, DueBefore: &before}, want: true},
+		{name: "due after", filter: domain.TodoFilter{DueAfter: &before}},
+		{name: "overdue", filter: domain.TodoFilter{Overdue: true}, want: true},
+		{name: "project", filter: domain.TodoFilter{ProjectID: &project}, want: true},
+		{name: "other project", filter: domain.TodoFilter{ProjectID: &user}},
+		{name: "assignee", filter: domain.TodoFilter{AssigneeID: &user}, want: true},
+		{name: "parent", filter: domain.TodoFilter{ParentID: &project}},
+		{name: "all tags", filter: domain.TodoFilter{Tags: []string{"work", "q1"}, TagMatch: domain.TagMatchAll}, want: true},
+		{name: "missing tag", filter: domain.TodoFilter{Tags: []string{"work", "home"}, TagMatch: domain.TagMatchAll}},
+		{name: "any tag", filter: domain.TodoFilter{Tags: []string{"work", "home"}, TagMatch: domain.TagMatchAny}, want: true},
+		{name: "trash", filter: domain.TodoFilter{Trashed: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(todo, now))
+		})
+	}
+
+	todo.MoveToTrash()
+	assert.False(t, domain.TodoFilter{}.Matches(todo, now), "trashed todos only match the trash")
+	assert.True(t, domain.TodoFilter{Trashed: true}.Matches(todo, now))
+}
@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SortField is a todo attribute lists can be ordered by.
+type SortField string
+
+const (
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+	SortByTitle     SortField = "title"
+	SortByDue       SortField = "due"
+	SortByPriority  SortField = "priority"
+	SortByRank      SortField = "rank"
+)
+
+// SortFields lists the fields accepted by ParseTodoSort.
+var SortFields = []SortField{SortByCreatedAt, SortByUpdatedAt, SortByTitle, SortByDue, SortByPriority, SortByRank}
+
+// SortKey orders by one field, ascending unless Desc is set.
+type SortKey struct {
+	Field SortField
+	Desc  bool
+}
+
+// TodoSort is an ordering of todos. The todo ID is always used as the final
+// tie-breaker so that the ordering is total and can be paginated.
+type TodoSort []SortKey
+
+// DefaultTodoSort lists the most urgent todos first, then those due soonest,
+// then the newest.
+var DefaultTodoSort = TodoSort{
+	{Field: SortByPriority, Desc: true},
+	{Field: SortByDue},
+	{Field: SortByCreatedAt, Desc: true},
+}
+
+// ParseTodoSort parses a comma separated list of fields, each optionally
+// prefixed with "-" for descending order, e.g. "-due,title".
+// An empty string yields DefaultTodoSort.
+func ParseTodoSort(s string) (TodoSort, error) {
+	if s == "" {
+		return DefaultTodoSort, nil
+	}
+
+	var sort TodoSort
+	seen := make(map[SortField]bool)
+	for part := range strings.SplitSeq(s, ",") {
+		key := SortKey{Field: SortField(strings.TrimSpace(part))}
+		if name, ok := strings.CutPrefix(string(key.Field), "-"); ok {
+			key = SortKey{Field: SortField(name), Desc: true}
+		}
+		if !isSortField(key.Field) {
+			return nil, NewValidationError("sort", "unknown sort field "+string(key.Field))
+		}
+		if seen[key.Field] {
+			return nil, NewValidationError("sort", "duplicate sort field "+string(key.Field))
+		}
+		seen[key.Field] = true
+		sort = append(sort, key)
+	}
+	return sort, nil
+}
+
+// String returns the canonical representation accepted by ParseTodoSort.
+func (s TodoSort) String() string {
+	parts := make([]string, len(s))
+	for i, k := range s {
+		parts[i] = string(k.Field)
+		if k.Desc {
+			parts[i] = "-" + parts[i]
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// TiebreakDesc reports whether the ID tie-breaker sorts descending. It
+// follows the direction of the last sort key.
+func (s TodoSort) TiebreakDesc() bool {
+	return len(s) > 0 && s[len(s)-1].Desc
+}
+
+// CursorAfter returns the cursor pointing just past t in this ordering.
+func (s TodoSort) CursorAfter(t *Todo) *Cursor {
+	values := make([]string, len(s))
+	for i, k := range s {
+		values[i] = sortValue(t, k.Field)
+	}
+	return &Cursor{Sort: s.String(), Values: values, ID: t.ID}
+}
+
+// sortValue renders the value of field for t as text. Missing due dates
+// sort as "infinity", after every real deadline.
+func sortValue(t *Todo, field SortField) string {
+	switch field {
+	case SortByCreatedAt:
+		return t.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case SortByUpdatedAt:
+		return t.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case SortByTitle:
+		return t.Title
+	case SortByDue:
+		if t.Due == nil {
+			return "infinity"
+		}
+		return t.Due.Deadline().Format(time.RFC3339Nano)
+	case SortByPriority:
+		return strconv.Itoa(int(t.Priority))
+	case SortByRank:
+		return t.Rank
+	}
+	return ""
+}
+
+// CheckSortValue validates a cursor value of field, as rendered by
+// sortValue, so that tampered cursors are refused before they reach the
+// sort column.
+func CheckSortValue(field SortField, value string) error {
+	valid := true
+	switch field {
+	case SortByCreatedAt, SortByUpdatedAt:
+		_, err := time.Parse(time.RFC3339Nano, value)
+		valid = err == nil
+	case SortByDue:
+		_, err := time.Parse(time.RFC3339Nano, value)
+		valid = err == nil || value == "infinity"
+	case SortByPriority:
+		n, err := strconv.Atoi(value)
+		valid = err == nil && Priority(n) >= PriorityNone && Priority(n) <= PriorityUrgent
+	case SortByTitle, SortByRank:
+		valid = utf8.ValidString(value) && !strings.ContainsRune(value, 0)
+	}
+	if !valid {
+		return NewValidationError("cursor", "malformed cursor")
+	}
+	return nil
+}
+
+func isSortField(f SortField) bool {
+	for _, sf := range SortFields {
+		if f == sf {
+			return true
+		}
+	}
+	return false
+}
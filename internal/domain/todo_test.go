@@ -4,7 +4,9 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/knjname/go-todo-api/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -69,7 +71,7 @@ func TestNewTodo(t *testing.T) {
 				require.NotNil(t, todo)
 				assert.Equal(t, tt.title, todo.Title)
 				assert.Equal(t, tt.description, todo.Description)
-				assert.False(t, todo.Completed)
+				assert.Equal(t, domain.StatusOpen, todo.Status)
 				assert.NotEmpty(t, todo.ID)
 				assert.False(t, todo.CreatedAt.IsZero())
 				assert.False(t, todo.UpdatedAt.IsZero())
@@ -94,10 +96,66 @@ func TestTodo_UpdateTitle(t *testing.T) {
 func TestTodo_MarkComplete(t *testing.T) {
 	todo, err := domain.NewTodo("Task", "desc")
 	require.NoError(t, err)
-	assert.False(t, todo.Completed)
+	assert.False(t, todo.IsCompleted())
 
-	todo.MarkComplete()
-	assert.True(t, todo.Completed)
+	require.NoError(t, todo.MarkComplete())
+	assert.True(t, todo.IsCompleted())
+	assert.Equal(t, domain.StatusDone, todo.Status)
+}
+
+func TestTodo_StatusTransitions(t *testing.T) {
+	transitions := map[string]func(*domain.Todo) error{
+		"start":    (*domain.Todo).Start,
+		"complete": (*domain.Todo).MarkComplete,
+		"cancel":   (*domain.Todo).Cancel,
+		"reopen":   (*domain.Todo).Reopen,
+	}
+	tests := []struct {
+		from   domain.Status
+		action string
+		want   domain.Status // empty means the transition is rejected
+	}{
+		{domain.StatusOpen, "start", domain.StatusInProgress},
+		{domain.StatusOpen, "complete", domain.StatusDone},
+		{domain.StatusOpen, "cancel", domain.StatusCancelled},
+		{domain.StatusOpen, "reopen", ""},
+		{domain.StatusInProgress, "start", ""},
+		{domain.StatusInProgress, "complete", domain.StatusDone},
+		{domain.StatusInProgress, "cancel", domain.StatusCancelled},
+		{domain.StatusInProgress, "reopen", ""},
+		{domain.StatusDone, "start", ""},
+		{domain.StatusDone, "complete", ""},
+		{domain.StatusDone, "cancel", ""},
+		{domain.StatusDone, "reopen", domain.StatusOpen},
+		{domain.StatusCancelled, "start", ""},
+		{domain.StatusCancelled, "complete", ""},
+		{domain.StatusCancelled, "reopen", domain.StatusOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" "+tt.action, func(t *testing.T) {
+			todo := &domain.Todo{Title: "Task", Status: tt.from}
+			err := transitions[tt.action](todo)
+
+			if tt.want == "" {
+				assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+				assert.Equal(t, tt.from, todo.Status)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, todo.Status)
+			assert.False(t, todo.UpdatedAt.IsZero())
+		})
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	st, err := domain.ParseStatus("in_progress")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusInProgress, st)
+
+	_, err = domain.ParseStatus("completed")
+	assert.ErrorIs(t, err, domain.ErrValidation)
 }
 
 func TestValidationError_Unwrap(t *testing.T) {
@@ -106,3 +164,173 @@ func TestValidationError_Unwrap(t *testing.T) {
 	assert.Contains(t, ve.Error(), "field")
 	assert.Contains(t, ve.Error(), "msg")
 }
+
+func TestCursor_EncodeParse(t *testing.T) {
+	todo, err := domain.NewTodo("Task", "")
+	require.NoError(t, err)
+
+	cursor := domain.DefaultTodoSort.CursorAfter(todo)
+	parsed, err := domain.ParseCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, cursor, parsed)
+
+	_, err = domain.ParseCursor("garbage!")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+func TestParseTodoSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    domain.TodoSort
+		wantErr bool
+	}{
+		{name: "default", input: "", want: domain.DefaultTodoSort},
+		{
+			name:  "mixed directions",
+			input: "-due, title",
+			want:  domain.TodoSort{{Field: domain.SortByDue, Desc: true}, {Field: domain.SortByTitle}},
+		},
+		{
+			name:  "priority",
+			input: "-priority,created_at",
+			want:  domain.TodoSort{{Field: domain.SortByPriority, Desc: true}, {Field: domain.SortByCreatedAt}},
+		},
+		{name: "unknown field", input: "secret", wantErr: true},
+		{name: "duplicate field", input: "title,-title", wantErr: true},
+		{name: "empty element", input: "title,", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.ParseTodoSort(tt.input)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrValidation)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTodoSort_CursorAfter(t *testing.T) {
+	todo, err := domain.NewTodo("Task", "")
+	require.NoError(t, err)
+
+	sort := domain.TodoSort{{Field: domain.SortByDue}, {Field: domain.SortByTitle, Desc: true}}
+	cursor := sort.CursorAfter(todo)
+	assert.Equal(t, "due,-title", cursor.Sort)
+	assert.Equal(t, []string{"infinity", "Task"}, cursor.Values)
+	assert.Equal(t, todo.ID, cursor.ID)
+	assert.True(t, sort.TiebreakDesc())
+}
+
+func TestTodoSort_CursorAfter_Default(t *testing.T) {
+	todo, err := domain.NewTodo("Task", "", domain.WithPriority(domain.PriorityHigh))
+	require.NoError(t, err)
+
+	cursor := domain.DefaultTodoSort.CursorAfter(todo)
+	assert.Equal(t, "-priority,due,-created_at", cursor.Sort)
+	assert.Equal(t, []string{"3", "infinity", todo.CreatedAt.Format(time.RFC3339Nano)}, cursor.Values)
+}
+
+func TestCheckSortValue(t *testing.T) {
+	todo, err := domain.NewTodo("Task", "", domain.WithPriority(domain.PriorityHigh))
+	require.NoError(t, err)
+	for _, field := range domain.SortFields {
+		cursor := domain.TodoSort{{Field: field}}.CursorAfter(todo)
+		assert.NoError(t, domain.CheckSortValue(field, cursor.Values[0]), field)
+	}
+
+	for field, value := range map[domain.SortField]string{
+		domain.SortByCreatedAt: "yesterday",
+		domain.SortByUpdatedAt: "",
+		domain.SortByDue:       "2026-13-01T00:00:00Z",
+		domain.SortByPriority:  "99999",
+		domain.SortByTitle:     "a\x00b",
+		domain.SortByRank:      "\xff",
+	} {
+		assert.ErrorIs(t, domain.CheckSortValue(field, value), domain.ErrValidation, field)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	p, err := domain.ParsePriority("urgent")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PriorityUrgent, p)
+	assert.Equal(t, "urgent", p.String())
+
+	p, err = domain.ParsePriority("")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PriorityNone, p)
+
+	_, err = domain.ParsePriority("P1")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	assert.Greater(t, domain.PriorityHigh, domain.PriorityMedium)
+}
+
+func TestTodo_SetParent(t *testing.T) {
+	todo, err := domain.NewTodo("Task", "")
+	require.NoError(t, err)
+	parentID := uuid.New()
+
+	require.NoError(t, todo.SetParent(&parentID))
+	assert.Equal(t, &parentID, todo.ParentID)
+	assert.ErrorIs(t, todo.SetParent(&todo.ID), domain.ErrValidation)
+	require.NoError(t, todo.SetParent(nil))
+	assert.Nil(t, todo.ParentID)
+}
+
+func TestTodo_CheckVersion(t *testing.T) {
+	todo, err := domain.NewTodo("Task", "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), todo.Version)
+
+	assert.NoError(t, todo.CheckVersion(nil))
+	assert.NoError(t, todo.CheckVersion([]int64{1}))
+	assert.NoError(t, todo.CheckVersion([]int64{3, 1}))
+	assert.ErrorIs(t, todo.CheckVersion([]int64{2}), domain.ErrPreconditionFailed)
+}
+
+func TestTodo_MoveToTrashAndRestore(t *testing.T) {
+	todo, err := domain.NewTodo("Task", "")
+	require.NoError(t, err)
+	assert.False(t, todo.IsTrashed())
+
+	todo.MoveToTrash()
+	assert.True(t, todo.IsTrashed())
+	require.NotNil(t, todo.DeletedAt)
+
+	todo.Restore()
+	assert.False(t, todo.IsTrashed())
+}
+
+func TestTodo_EditableBy(t *testing.T) {
+	owner, assignee, other := uuid.New(), uuid.New(), uuid.New()
+
+	unowned, err := domain.NewTodo("Task", "")
+	require.NoError(t, err)
+	assert.False(t, unowned.EditableBy(other), "todos without an owner are not editable by members")
+	unowned.Assign(&assignee)
+	assert.True(t, unowned.EditableBy(assignee))
+
+	todo, err := domain.NewTodo("Task", "", domain.WithCreator(&owner))
+	require.NoError(t, err)
+	assert.Equal(t, &owner, todo.CreatedBy)
+	assert.Equal(t, &owner, todo.UpdatedBy)
+	assert.True(t, todo.EditableBy(owner))
+	assert.False(t, todo.EditableBy(assignee))
+
+	todo.Assign(&assignee)
+	assert.True(t, todo.EditableBy(assignee))
+	assert.False(t, todo.EditableBy(other))
+
+	todo.RecordEditor(&assignee)
+	assert.Equal(t, &assignee, todo.UpdatedBy)
+	assert.Equal(t, &owner, todo.CreatedBy)
+
+	todo.Assign(nil)
+	assert.Nil(t, todo.AssigneeID)
+	assert.False(t, todo.EditableBy(assignee))
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const MaxUserNameLength = 100
+
+// User is a person working in a workspace. Requests identify the user they
+// act for; todos record who created and last changed them and who they
+// are assigned to. Email addresses are unique but optional. Role limits
+// what the user may do.
+type User struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email,omitempty"`
+	Role      Role      `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NewUser creates a user. A nil id generates one, so that users known to an
+// identity provider can keep their ID.
+func NewUser(id uuid.UUID, name, email string, role Role) (*User, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, NewValidationError("name", "must not be empty")
+	}
+	if len([]rune(name)) > MaxUserNameLength {
+		return nil, NewValidationError("name", "must not exceed 100 characters")
+	}
+	email = strings.TrimSpace(email)
+	if email != "" {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Address != email {
+			return nil, NewValidationError("email", "must be a valid email address")
+		}
+		email = strings.ToLower(email)
+	}
+	if _, ok := roleRanks[role]; !ok {
+		return nil, NewValidationError("role", "must be one of viewer, member, admin")
+	}
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+	return &User{ID: id, Name: name, Email: email, Role: role, CreatedAt: time.Now().UTC()}, nil
+}
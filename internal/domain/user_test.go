@@ -0,0 +1,107 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUser(t *testing.T) {
+	u, err := domain.NewUser(uuid.Nil, " Alice ", "Alice@Example.com", domain.RoleMember)
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, u.ID)
+	assert.Equal(t, "Alice", u.Name)
+	assert.Equal(t, "alice@example.com", u.Email)
+	assert.Equal(t, domain.RoleMember, u.Role)
+
+	id := uuid.New()
+	u, err = domain.NewUser(id, "Bob", "", domain.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, id, u.ID, "a given ID is kept")
+
+	tests := []struct {
+		name     string
+		userName string
+		email    string
+		role     domain.Role
+		errField string
+	}{
+		{name: "empty name", userName: " ", errField: "name"},
+		{name: "long name", userName: string(make([]rune, 101)), errField: "name"},
+		{name: "invalid email", userName: "Carol", email: "carol", errField: "email"},
+		{name: "display name", userName: "Carol", email: "Carol <carol@example.com>", errField: "email"},
+		{name: "unknown role", userName: "Carol", role: "owner", errField: "role"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := tt.role
+			if role == "" {
+				role = domain.RoleMember
+			}
+			_, err := domain.NewUser(uuid.Nil, tt.userName, tt.email, role)
+			var ve *domain.ValidationError
+			require.ErrorAs(t, err, &ve)
+			assert.Equal(t, tt.errField, ve.Field)
+		})
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	role, err := domain.ParseRole("")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleMember, role)
+
+	role, err = domain.ParseRole(" Admin ")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleAdmin, role)
+
+	_, err = domain.ParseRole("owner")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+func TestUser_Authorize(t *testing.T) {
+	viewer := &domain.User{ID: uuid.New(), Role: domain.RoleViewer}
+	member := &domain.User{ID: uuid.New(), Role: domain.RoleMember}
+	admin := &domain.User{ID: uuid.New(), Role: domain.RoleAdmin}
+	other := uuid.New()
+	owned := &domain.Todo{ID: uuid.New(), CreatedBy: &member.ID}
+	foreign := &domain.Todo{ID: uuid.New(), CreatedBy: &other}
+	assigned := &domain.Todo{ID: uuid.New(), CreatedBy: &other, AssigneeID: &member.ID}
+
+	tests := []struct {
+		name    string
+		user    *domain.User
+		action  domain.Action
+		todo    *domain.Todo
+		allowed bool
+	}{
+		{name: "viewer reads", user: viewer, action: domain.ActionRead, allowed: true},
+		{name: "viewer creates", user: viewer, action: domain.ActionCreate},
+		{name: "viewer edits an unowned todo", user: viewer, action: domain.ActionEdit, todo: &domain.Todo{}},
+		{name: "member creates", user: member, action: domain.ActionCreate, allowed: true},
+		{name: "member edits own todo", user: member, action: domain.ActionEdit, todo: owned, allowed: true},
+		{name: "member edits assigned todo", user: member, action: domain.ActionEdit, todo: assigned, allowed: true},
+		{name: "member deletes foreign todo", user: member, action: domain.ActionDelete, todo: foreign},
+		{name: "member edits an unowned todo", user: member, action: domain.ActionEdit, todo: &domain.Todo{}},
+		{name: "member completes all", user: member, action: domain.ActionCompleteAll},
+		{name: "member manages users", user: member, action: domain.ActionManageUsers},
+		{name: "member materializes recurrences", user: member, action: domain.ActionMaterialize},
+		{name: "admin edits foreign todo", user: admin, action: domain.ActionEdit, todo: foreign, allowed: true},
+		{name: "admin deletes an unowned todo", user: admin, action: domain.ActionDelete, todo: &domain.Todo{}, allowed: true},
+		{name: "admin completes all", user: admin, action: domain.ActionCompleteAll, allowed: true},
+		{name: "unknown action", user: admin, action: "launch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.user.Authorize(tt.action, tt.todo)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, domain.ErrForbidden)
+			}
+		})
+	}
+}
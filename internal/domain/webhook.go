@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxWebhookURLLength    = 2000
+	MinWebhookSecretLength = 16
+
+	// WebhookMaxAttempts is how many times a delivery is attempted before
+	// it is given up as dead. With RetryDelay between attempts, a receiver
+	// may be down for about three hours without losing events.
+	WebhookMaxAttempts = 15
+
+	// webhookSecretPrefix starts every generated webhook secret.
+	webhookSecretPrefix = "whsec_"
+)
+
+var eventTypes = []EventType{EventTodoCreated, EventTodoUpdated, EventTodoCompleted, EventTodoDeleted, EventAllCompleted}
+
+func ParseEventType(s string) (EventType, error) {
+	t := EventType(strings.TrimSpace(s))
+	if !slices.Contains(eventTypes, t) {
+		return "", NewValidationError("events", "must be one of TodoCreated, TodoUpdated, TodoCompleted, TodoDeleted, AllCompleted")
+	}
+	return t, nil
+}
+
+// Webhook subscribes a URL to the events of a workspace. Every delivery is
+// signed with the webhook's secret; see SignWebhook.
+type Webhook struct {
+	ID     uuid.UUID `json:"id"`
+	URL    string    `json:"url"`
+	Secret string    `json:"-"`
+	// Events are the types of the events delivered; empty for every type.
+	Events    []EventType `json:"events"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+}
+
+// NewWebhook returns a webhook delivering the given types of events to
+// rawURL. A secret is generated if secret is empty.
+func NewWebhook(rawURL, secret string, events []EventType) (*Webhook, error) {
+	now := time.Now().UTC()
+	w := &Webhook{ID: uuid.New(), CreatedAt: now}
+	if secret == "" {
+		secret = generateWebhookSecret()
+	}
+	if err := w.Update(rawURL, secret, events); err != nil {
+		return nil, err
+	}
+	w.UpdatedAt = now
+	return w, nil
+}
+
+// Update replaces the URL, secret and event types of the webhook. An empty
+// secret keeps the current one.
+func (w *Webhook) Update(rawURL, secret string, events []EventType) error {
+	rawURL = strings.TrimSpace(rawURL)
+	if len(rawURL) > MaxWebhookURLLength {
+		return NewValidationError("url", "must not exceed 2000 characters")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return NewValidationError("url", "must be an absolute http or https URL")
+	}
+	if secret != "" && len(secret) < MinWebhookSecretLength {
+		return NewValidationError("secret", "must be at least 16 characters")
+	}
+	for _, t := range events {
+		if _, err := ParseEventType(string(t)); err != nil {
+			return err
+		}
+	}
+
+	w.URL = rawURL
+	if secret != "" {
+		w.Secret = secret
+	}
+	w.Events = slices.Compact(slices.Sorted(slices.Values(events)))
+	if w.Events == nil {
+		w.Events = []EventType{}
+	}
+	w.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Subscribes reports whether events of type t are delivered to the webhook.
+func (w *Webhook) Subscribes(t EventType) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, t)
+}
+
+func generateWebhookSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return webhookSecretPrefix + hex.EncodeToString(b)
+}
+
+// SignWebhook returns the signature of a webhook request sent at timestamp
+// with body: "sha256=" followed by the hex HMAC-SHA256, keyed with the
+// secret, of the Unix timestamp, a dot and the body. Signing the timestamp
+// lets receivers reject replayed requests.
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeliveryStatus is the state of a webhook delivery.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending is waiting for its first attempt or a retry.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliverySucceeded was accepted by the receiver.
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryDead failed WebhookMaxAttempts times and is not retried.
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// WebhookDelivery is the delivery of one event to one webhook, with the
+// outcome of its latest attempt.
+type WebhookDelivery struct {
+	ID        uuid.UUID `json:"id"`
+	WebhookID uuid.UUID `json:"webhookId"`
+	EventID   uuid.UUID `json:"eventId"`
+	EventType EventType `json:"eventType"`
+	// Payload is the body sent: the event as JSON.
+	Payload  json.RawMessage `json:"-"`
+	Status   DeliveryStatus  `json:"status"`
+	Attempts int             `json:"attempts"`
+	// ResponseStatus is the HTTP status of the latest response; 0 if no
+	// response was received.
+	ResponseStatus int    `json:"responseStatus,omitempty"`
+	LastError      string `json:"lastError,omitempty"`
+	// NextAttemptAt is when the delivery is attempted next; nil once it
+	// succeeded or is dead.
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	LastAttemptAt *time.Time `json:"lastAttemptAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// NewWebhookDelivery returns a pending delivery of event to hook.
+func NewWebhookDelivery(hook *Webhook, event *Event) (*WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &WebhookDelivery{
+		ID: uuid.New(), WebhookID: hook.ID, EventID: event.ID, EventType: event.Type,
+		Payload: payload, Status: DeliveryPending, NextAttemptAt: &now, CreatedAt: now,
+	}, nil
+}
+
+// RecordAttempt records an attempt made at the given time, which got a
+// response with the given HTTP status, 0 if none, and failed with err
+// unless err is nil. A failed delivery is retried after RetryDelay until
+// it has failed WebhookMaxAttempts times.
+func (d *WebhookDelivery) RecordAttempt(at time.Time, status int, err error) {
+	d.Attempts++
+	d.LastAttemptAt = &at
+	d.ResponseStatus = status
+	d.NextAttemptAt = nil
+	if err == nil {
+		d.Status = DeliverySucceeded
+		d.LastError = ""
+		return
+	}
+	d.LastError = err.Error()
+	if d.Attempts >= WebhookMaxAttempts {
+		d.Status = DeliveryDead
+		return
+	}
+	next := at.Add(RetryDelay(d.Attempts))
+	d.NextAttemptAt = &next
+}
@@ -0,0 +1,109 @@
+package domain_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWebhook(t *testing.T) {
+	t.Run("generates a secret", func(t *testing.T) {
+		w, err := domain.NewWebhook(" https://example.com/hook ", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/hook", w.URL)
+		assert.True(t, strings.HasPrefix(w.Secret, "whsec_"))
+		assert.Empty(t, w.Events)
+		assert.True(t, w.Subscribes(domain.EventTodoDeleted))
+	})
+
+	t.Run("event filter", func(t *testing.T) {
+		w, err := domain.NewWebhook("http://127.0.0.1:9000", "0123456789abcdef",
+			[]domain.EventType{domain.EventTodoCompleted, domain.EventTodoCreated, domain.EventTodoCompleted})
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789abcdef", w.Secret)
+		assert.Equal(t, []domain.EventType{domain.EventTodoCompleted, domain.EventTodoCreated}, w.Events)
+		assert.True(t, w.Subscribes(domain.EventTodoCreated))
+		assert.False(t, w.Subscribes(domain.EventTodoUpdated))
+	})
+
+	for name, tc := range map[string]struct {
+		url, secret string
+		events      []domain.EventType
+	}{
+		"relative url":   {url: "/hook"},
+		"other scheme":   {url: "ftp://example.com"},
+		"no host":        {url: "https://"},
+		"long url":       {url: "https://example.com/" + strings.Repeat("a", domain.MaxWebhookURLLength)},
+		"short secret":   {url: "https://example.com", secret: "secret"},
+		"unknown events": {url: "https://example.com", events: []domain.EventType{"TodoArchived"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := domain.NewWebhook(tc.url, tc.secret, tc.events)
+			assert.ErrorIs(t, err, domain.ErrValidation)
+		})
+	}
+}
+
+func TestWebhook_Update_KeepsSecret(t *testing.T) {
+	w, err := domain.NewWebhook("https://example.com", "", nil)
+	require.NoError(t, err)
+	secret := w.Secret
+
+	require.NoError(t, w.Update("https://example.org", "", []domain.EventType{domain.EventAllCompleted}))
+	assert.Equal(t, secret, w.Secret)
+	assert.Equal(t, "https://example.org", w.URL)
+
+	require.NoError(t, w.Update("https://example.org", "a-new-secret-value", nil))
+	assert.Equal(t, "a-new-secret-value", w.Secret)
+}
+
+func TestSignWebhook(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"1"}`)
+	mac := hmac.New(sha256.New, []byte("0123456789abcdef"))
+	mac.Write([]byte(`1700000000.{"id":"1"}`))
+
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), domain.SignWebhook("0123456789abcdef", at, body))
+	assert.NotEqual(t, domain.SignWebhook("0123456789abcdef", at, body), domain.SignWebhook("0123456789abcdef", at.Add(time.Second), body))
+}
+
+func TestWebhookDelivery_RecordAttempt(t *testing.T) {
+	hook, err := domain.NewWebhook("https://example.com", "", nil)
+	require.NoError(t, err)
+	event, err := domain.NewAllCompletedEvent(nil, nil)
+	require.NoError(t, err)
+	d, err := domain.NewWebhookDelivery(hook, event)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeliveryPending, d.Status)
+	assert.Equal(t, event.ID, d.EventID)
+
+	at := time.Now()
+	d.RecordAttempt(at, 503, errors.New("503 Service Unavailable"))
+	assert.Equal(t, domain.DeliveryPending, d.Status)
+	assert.Equal(t, 1, d.Attempts)
+	assert.Equal(t, 503, d.ResponseStatus)
+	require.NotNil(t, d.NextAttemptAt)
+	assert.Equal(t, at.Add(domain.RetryDelay(1)), *d.NextAttemptAt)
+
+	d.RecordAttempt(at, 200, nil)
+	assert.Equal(t, domain.DeliverySucceeded, d.Status)
+	assert.Nil(t, d.NextAttemptAt)
+	assert.Empty(t, d.LastError)
+
+	dead, err := domain.NewWebhookDelivery(hook, event)
+	require.NoError(t, err)
+	for range domain.WebhookMaxAttempts {
+		dead.RecordAttempt(at, 0, errors.New("connection refused"))
+	}
+	assert.Equal(t, domain.DeliveryDead, dead.Status)
+	assert.Nil(t, dead.NextAttemptAt)
+	assert.Equal(t, "connection refused", dead.LastError)
+}
@@ -0,0 +1,174 @@
+// Package eventsink provides the destinations the outbox relay delivers
+// domain events to.
+package eventsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+)
+
+// Open returns the sink described by spec: "stdout", an http(s) URL to
+// POST events to, or the path of a file to append them to.
+func Open(spec string) (usecase.EventSink, error) {
+	switch {
+	case spec == "":
+		return nil, errors.New("empty event sink")
+	case spec == "stdout":
+		return NewWriter(os.Stdout), nil
+	case strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://"):
+		return NewHTTP(spec, &http.Client{Timeout: 10 * time.Second}), nil
+	}
+	return NewFile(spec), nil
+}
+
+// Writer writes each event as a line of JSON (NDJSON) to an io.Writer.
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (s *Writer) Deliver(_ context.Context, event *domain.Event) error {
+	line, err := encode(event)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(line)
+	return err
+}
+
+// File appends each event as a line of JSON (NDJSON) to a file, which is
+// created if needed. The file is opened for every event, so that it can be
+// rotated while the relay runs, and synced before the event counts as
+// delivered.
+type File struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+func (s *File) Deliver(_ context.Context, event *domain.Event) error {
+	line, err := encode(event)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// HTTP POSTs each event as JSON to a URL. Any response but a 2xx status
+// fails the delivery. The event ID and type are also sent in the
+// X-Event-ID and X-Event-Type headers, so that receivers can drop
+// duplicates without parsing the body.
+type HTTP struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTP(url string, client *http.Client) *HTTP {
+	return &HTTP{url: url, client: client}
+}
+
+func (s *HTTP) Deliver(ctx context.Context, event *domain.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID.String())
+	req.Header.Set("X-Event-Type", string(event.Type))
+
+	_, err = postRequest(s.client, req)
+	return err
+}
+
+func encode(event *domain.Event) ([]byte, error) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// WebhookSender sends webhook deliveries as signed HTTP POST requests. The
+// body is the event as JSON. Besides X-Event-ID and X-Event-Type, each
+// request carries X-Webhook-ID, X-Webhook-Delivery, X-Webhook-Timestamp
+// (Unix seconds) and X-Webhook-Signature, computed by domain.SignWebhook
+// over the timestamp and body with the webhook's secret.
+type WebhookSender struct {
+	client *http.Client
+}
+
+func NewWebhookSender(client *http.Client) *WebhookSender {
+	return &WebhookSender{client: client}
+}
+
+func (s *WebhookSender) Send(ctx context.Context, hook *domain.Webhook, d *domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", d.EventID.String())
+	req.Header.Set("X-Event-Type", string(d.EventType))
+	req.Header.Set("X-Webhook-ID", hook.ID.String())
+	req.Header.Set("X-Webhook-Delivery", d.ID.String())
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set("X-Webhook-Signature", domain.SignWebhook(hook.Secret, now, d.Payload))
+	return postRequest(s.client, req)
+}
+
+// postRequest sends req and fails unless the response status is 2xx. It
+// returns the response status, 0 if there was no response.
+func postRequest(client *http.Client, req *http.Request) (int, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("POST %s: %s", req.URL.Redacted(), resp.Status)
+	}
+	return resp.StatusCode, nil
+}
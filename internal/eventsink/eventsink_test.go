@@ -0,0 +1,166 @@
+package eventsink_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/eventsink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent(t *testing.T) *domain.Event {
+	t.Helper()
+	todo, err := domain.NewTodo("Ship it", "")
+	require.NoError(t, err)
+	event, err := domain.NewTodoEvent(nil, todo)
+	require.NoError(t, err)
+	return event
+}
+
+func decodeLines(t *testing.T, data []byte) []domain.Event {
+	t.Helper()
+	var events []domain.Event
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		var e domain.Event
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestOpen(t *testing.T) {
+	sink, err := eventsink.Open("stdout")
+	require.NoError(t, err)
+	assert.IsType(t, &eventsink.Writer{}, sink)
+
+	sink, err = eventsink.Open("https://example.com/events")
+	require.NoError(t, err)
+	assert.IsType(t, &eventsink.HTTP{}, sink)
+
+	sink, err = eventsink.Open("/var/log/events.ndjson")
+	require.NoError(t, err)
+	assert.IsType(t, &eventsink.File{}, sink)
+
+	_, err = eventsink.Open("")
+	assert.Error(t, err)
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	sink := eventsink.NewWriter(&buf)
+	first, second := testEvent(t), testEvent(t)
+	require.NoError(t, sink.Deliver(context.Background(), first))
+	require.NoError(t, sink.Deliver(context.Background(), second))
+
+	events := decodeLines(t, buf.Bytes())
+	require.Len(t, events, 2)
+	assert.Equal(t, first.ID, events[0].ID)
+	assert.Equal(t, domain.EventTodoCreated, events[0].Type)
+	assert.Equal(t, second.ID, events[1].ID)
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	sink := eventsink.NewFile(path)
+	first, second := testEvent(t), testEvent(t)
+	require.NoError(t, sink.Deliver(context.Background(), first))
+	require.NoError(t, sink.Deliver(context.Background(), second))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	events := decodeLines(t, data)
+	require.Len(t, events, 2)
+	assert.Equal(t, first.ID, events[0].ID)
+	assert.Equal(t, second.ID, events[1].ID)
+
+	err = eventsink.NewFile(filepath.Join(t.TempDir(), "missing", "events.ndjson")).Deliver(context.Background(), first)
+	assert.Error(t, err)
+}
+
+func TestHTTP(t *testing.T) {
+	var (
+		received domain.Event
+		header   http.Header
+		status   = http.StatusNoContent
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := eventsink.NewHTTP(server.URL, &http.Client{Timeout: time.Second})
+	event := testEvent(t)
+	require.NoError(t, sink.Deliver(context.Background(), event))
+	assert.Equal(t, event.ID, received.ID)
+	assert.Equal(t, event.TodoID, received.TodoID)
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, event.ID.String(), header.Get("X-Event-ID"))
+	assert.Equal(t, "TodoCreated", header.Get("X-Event-Type"))
+
+	status = http.StatusServiceUnavailable
+	assert.ErrorContains(t, sink.Deliver(context.Background(), event), "503")
+
+	server.Close()
+	assert.Error(t, sink.Deliver(context.Background(), &domain.Event{ID: uuid.New()}))
+}
+
+func TestWebhookSender(t *testing.T) {
+	hook, err := domain.NewWebhook("http://example.invalid", "0123456789abcdef", nil)
+	require.NoError(t, err)
+	delivery, err := domain.NewWebhookDelivery(hook, testEvent(t))
+	require.NoError(t, err)
+
+	var (
+		body   []byte
+		header http.Header
+		status = http.StatusOK
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	hook.URL = server.URL
+
+	sender := eventsink.NewWebhookSender(&http.Client{Timeout: time.Second})
+	got, err := sender.Send(context.Background(), hook, delivery)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, got)
+	assert.JSONEq(t, string(delivery.Payload), string(body))
+	assert.Equal(t, delivery.EventID.String(), header.Get("X-Event-ID"))
+	assert.Equal(t, hook.ID.String(), header.Get("X-Webhook-ID"))
+	assert.Equal(t, delivery.ID.String(), header.Get("X-Webhook-Delivery"))
+
+	// The receiver verifies the signature over the timestamp and body.
+	ts, err := strconv.ParseInt(header.Get("X-Webhook-Timestamp"), 10, 64)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), time.Unix(ts, 0), 5*time.Second)
+	assert.Equal(t, domain.SignWebhook(hook.Secret, time.Unix(ts, 0), body), header.Get("X-Webhook-Signature"))
+
+	status = http.StatusGone
+	got, err = sender.Send(context.Background(), hook, delivery)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusGone, got)
+
+	server.Close()
+	got, err = sender.Send(context.Background(), hook, delivery)
+	assert.Error(t, err)
+	assert.Zero(t, got)
+}
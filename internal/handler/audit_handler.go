@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+)
+
+type AuditHandler struct {
+	uc *usecase.AuditUseCase
+}
+
+func NewAuditHandler(uc *usecase.AuditUseCase) *AuditHandler {
+	return &AuditHandler{uc: uc}
+}
+
+// --- Input/Output types ---
+
+type HistoryEntryBody struct {
+	ID        int64      `json:"id" doc:"監査ログのID (記録順)"`
+	Action    string     `json:"action" enum:"create,update,trash,restore,purge" doc:"変更の種類"`
+	UserID    *uuid.UUID `json:"userId,omitempty" doc:"変更したユーザーのID。ユーザー以外による変更の場合は省略"`
+	Subject   string     `json:"subject,omitempty" doc:"認証された呼び出し元 (API キーなど)"`
+	RequestID string     `json:"requestId,omitempty" doc:"変更したリクエストの X-Request-ID"`
+	Before    *TodoBody  `json:"before,omitempty" doc:"変更前の Todo。作成時は省略"`
+	After     *TodoBody  `json:"after,omitempty" doc:"変更後の Todo。完全削除時は省略"`
+	CreatedAt time.Time  `json:"createdAt" doc:"変更日時"`
+}
+
+func newHistoryEntryBody(e *domain.AuditEntry) (HistoryEntryBody, error) {
+	body := HistoryEntryBody{
+		ID: e.ID, Action: string(e.Action), UserID: e.UserID,
+		Subject: e.Subject, RequestID: e.RequestID, CreatedAt: e.CreatedAt,
+	}
+	before, after, err := e.Snapshots()
+	if err != nil {
+		return body, fmt.Errorf("decode audit entry %d: %w", e.ID, err)
+	}
+	if before != nil {
+		b := newTodoBody(before)
+		body.Before = &b
+	}
+	if after != nil {
+		a := newTodoBody(after)
+		body.After = &a
+	}
+	return body, nil
+}
+
+type GetTodoHistoryInput struct {
+	ID uuid.UUID `path:"id" doc:"Todo ID"`
+}
+
+type TodoHistoryOutput struct {
+	Body struct {
+		Items []HistoryEntryBody `json:"items" doc:"変更履歴 (古い順)"`
+	}
+}
+
+func (h *AuditHandler) Register(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "get-todo-history",
+		Middlewares: requireScope(api, domain.ScopeRead),
+		Method:      http.MethodGet,
+		Path:        "/todos/{id}/history",
+		Summary:     "Get the change history of a todo",
+		Description: "Lists every change to the todo with snapshots before and after it, including todos in the trash or purged from it.",
+		Tags:        []string{"Todos"},
+	}, h.getTodoHistory)
+}
+
+func (h *AuditHandler) getTodoHistory(ctx context.Context, input *GetTodoHistoryInput) (*TodoHistoryOutput, error) {
+	entries, err := h.uc.TodoHistory(ctx, input.ID)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	out := &TodoHistoryOutput{}
+	out.Body.Items = make([]HistoryEntryBody, len(entries))
+	for i := range entries {
+		if out.Body.Items[i], err = newHistoryEntryBody(&entries[i]); err != nil {
+			return nil, mapDomainError(err)
+		}
+	}
+	return out, nil
+}
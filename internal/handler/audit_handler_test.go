@@ -0,0 +1,61 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/handler"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuditAPI(t *testing.T) (humatest.TestAPI, *mocks.AuditRepository) {
+	t.Helper()
+	repo := mocks.NewAuditRepository(t)
+	api := newServiceAPI(t)
+	uc := usecase.NewAuditUseCase(repo, usecase.NewRolePolicy(mocks.NewUserRepository(t)), slog.New(slog.DiscardHandler))
+	handler.NewAuditHandler(uc).Register(api)
+	return api, repo
+}
+
+func TestGetTodoHistory_Handler(t *testing.T) {
+	api, repo := setupAuditAPI(t)
+	todo, err := domain.NewTodo("Audited", "")
+	require.NoError(t, err)
+	after, err := json.Marshal(todo)
+	require.NoError(t, err)
+	user := uuid.New()
+	repo.On("ListByTodo", mock.Anything, todo.ID).Return([]domain.AuditEntry{{
+		ID: 1, TodoID: todo.ID, Action: domain.AuditCreate, UserID: &user, RequestID: "req-1",
+		Before: json.RawMessage("null"), After: after, CreatedAt: time.Now(),
+	}}, nil)
+
+	resp := api.Get("/todos/" + todo.ID.String() + "/history")
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		Items []handler.HistoryEntryBody `json:"items"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Items, 1)
+	entry := body.Items[0]
+	assert.Equal(t, "create", entry.Action)
+	assert.Equal(t, &user, entry.UserID)
+	assert.Equal(t, "req-1", entry.RequestID)
+	assert.Nil(t, entry.Before)
+	require.NotNil(t, entry.After)
+	assert.Equal(t, "Audited", entry.After.Title)
+
+	repo.On("ListByTodo", mock.Anything, mock.Anything).Return([]domain.AuditEntry{}, nil)
+	resp = api.Get("/todos/" + uuid.NewString() + "/history")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/auth"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+)
+
+// BoardPath is where clients open a board connection.
+const BoardPath = "/todos/board"
+
+const (
+	// boardBufferSize is how many messages a client may fall behind before
+	// its connection is closed.
+	boardBufferSize = 256
+	// boardReadLimit is the largest command a client may send.
+	boardReadLimit = 64 << 10
+	// boardWriteTimeout bounds the time to write a message, or to receive
+	// the pong answering a ping.
+	boardWriteTimeout = 10 * time.Second
+	// boardPingInterval is how often idle clients are pinged, so that dead
+	// connections are noticed and proxies keep live ones open.
+	boardPingInterval = 30 * time.Second
+	// boardMaxViews is how many subscriptions a connection may hold.
+	boardMaxViews = 20
+	// boardLeaveTimeout bounds the time to announce that a connection left.
+	boardLeaveTimeout = 5 * time.Second
+)
+
+// BoardHandler serves live boards over WebSocket: clients subscribe to the
+// todos of projects or of any list filter and are sent their changes,
+// send commands that change todos, and see who else views the board.
+// Being hijacked, the connections are not tracked by http.Server, so
+// Shutdown must be called to close them.
+type BoardHandler struct {
+	uc       *usecase.TodoUseCase
+	stream   *usecase.EventStream
+	presence *usecase.PresenceTracker
+
+	mu       sync.Mutex
+	closing  bool
+	sessions map[*boardSession]struct{}
+	wg       sync.WaitGroup
+}
+
+func NewBoardHandler(uc *usecase.TodoUseCase, stream *usecase.EventStream, presence *usecase.PresenceTracker) *BoardHandler {
+	return &BoardHandler{uc: uc, stream: stream, presence: presence, sessions: make(map[*boardSession]struct{})}
+}
+
+// --- Messages ---
+
+// boardCommand is a message from a client. Commands are processed one at a
+// time, in order; each is answered with a message carrying its ID.
+type boardCommand struct {
+	// Type is subscribe, unsubscribe, create, complete or reorder.
+	Type string `json:"type"`
+	// ID identifies the command. A subscription is known by the ID of the
+	// subscribe command that made it; subscribing again with the same ID
+	// replaces it.
+	ID string `json:"id"`
+
+	// Subscription is the list to subscribe to.
+	Subscription *boardSubscription `json:"subscription,omitempty"`
+	// Todo is the todo to create, as in POST /todos.
+	Todo json.RawMessage `json:"todo,omitempty"`
+	// TodoID is the todo to complete or reorder.
+	TodoID uuid.UUID `json:"todoId"`
+	// AfterID and BeforeID are the todos to reorder the todo between.
+	AfterID  *uuid.UUID `json:"afterId,omitempty"`
+	BeforeID *uuid.UUID `json:"beforeId,omitempty"`
+	// Version makes completing or reordering conditional on the todo's
+	// version, as If-Match does.
+	Version int64 `json:"version,omitempty"`
+}
+
+// boardSubscription selects todos like the GET /todos parameters.
+type boardSubscription struct {
+	ProjectID *uuid.UUID `json:"projectId,omitempty"`
+	Status    []string   `json:"status,omitempty"`
+	Title     string     `json:"title,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	TagMatch  string     `json:"tagMatch,omitempty"`
+	Assignee  string     `json:"assignee,omitempty"`
+	Overdue   bool       `json:"overdue,omitempty"`
+	// Sort orders the snapshot; it defaults to rank, the board order.
+	Sort  string `json:"sort,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+func (s *boardSubscription) params() usecase.ListTodosParams {
+	sort := s.Sort
+	if sort == "" {
+		sort = string(domain.SortByRank)
+	}
+	return usecase.ListTodosParams{
+		ProjectID: s.ProjectID, Statuses: s.Status, TitleContains: s.Title,
+		Tags: s.Tags, TagMatch: s.TagMatch, Assignee: s.Assignee, Overdue: s.Overdue,
+		Sort: sort, Limit: s.Limit,
+	}
+}
+
+// boardHello is the first message of a connection.
+type boardHello struct {
+	Type         string    `json:"type"`
+	ConnectionID uuid.UUID `json:"connectionId"`
+}
+
+// boardSnapshot is the first page of a subscription, sent when it is made
+// and whenever it must be listed again.
+type boardSnapshot struct {
+	Type  string     `json:"type"`
+	ID    string     `json:"id"`
+	Todos []TodoBody `json:"todos"`
+	Next  string     `json:"next,omitempty"`
+}
+
+// boardUpsert tells that a todo was added to or changed in a subscription.
+type boardUpsert struct {
+	Type string   `json:"type"`
+	ID   string   `json:"id"`
+	Todo TodoBody `json:"todo"`
+}
+
+// boardRemove tells that a todo left a subscription.
+type boardRemove struct {
+	Type   string    `json:"type"`
+	ID     string    `json:"id"`
+	TodoID uuid.UUID `json:"todoId"`
+}
+
+// boardPresence lists the connections viewing the board of the workspace.
+type boardPresence struct {
+	Type    string        `json:"type"`
+	Viewers []boardViewer `json:"viewers"`
+}
+
+type boardViewer struct {
+	ConnectionID uuid.UUID   `json:"connectionId"`
+	UserID       *uuid.UUID  `json:"userId,omitempty"`
+	ProjectIDs   []uuid.UUID `json:"projectIds"`
+}
+
+// boardResult answers a command that succeeded, with the todo it changed.
+type boardResult struct {
+	Type string    `json:"type"`
+	ID   string    `json:"id"`
+	Todo *TodoBody `json:"todo,omitempty"`
+}
+
+// boardError answers a command that failed, with the status and details
+// the REST API would respond with.
+type boardError struct {
+	Type   string   `json:"type"`
+	ID     string   `json:"id,omitempty"`
+	Status int      `json:"status"`
+	Detail string   `json:"detail"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+func newBoardError(id string, err error) boardError {
+	msg := boardError{Type: "error", ID: id, Status: http.StatusInternalServerError, Detail: "internal error"}
+	var em *huma.ErrorModel
+	if errors.As(mapDomainError(err), &em) {
+		msg.Status, msg.Detail = em.Status, em.Detail
+		for _, d := range em.Errors {
+			msg.Errors = append(msg.Errors, d.Message)
+		}
+	}
+	return msg
+}
+
+// --- Connections ---
+
+func (h *BoardHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p, ok := auth.PrincipalFrom(r.Context()); ok && !p.HasScope(domain.ScopeRead) {
+		writeError(w, huma.Error403Forbidden("the "+string(domain.ScopeRead)+" scope is required"))
+		return
+	}
+	if h.isClosing() {
+		writeError(w, huma.Error503ServiceUnavailable("the server is shutting down"))
+		return
+	}
+
+	// Hijacking clears the deadlines the server sets for regular requests,
+	// so its WriteTimeout does not cut board connections short; they are
+	// kept alive by pings instead.
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		// Accept has responded.
+		return
+	}
+	conn.SetReadLimit(boardReadLimit)
+
+	s := newBoardSession(h, conn, r.Context())
+	if !h.add(s) {
+		_ = conn.Close(websocket.StatusGoingAway, "server shutting down")
+		return
+	}
+	defer h.remove(s)
+	s.run()
+}
+
+// Shutdown closes the board connections, telling the clients the server
+// is going away, and waits for them to close until ctx is done, when it
+// drops those left. From then on, new connections are refused.
+func (h *BoardHandler) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closing = true
+	for s := range h.sessions {
+		s.close(websocket.StatusGoingAway, "server shutting down")
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		h.mu.Lock()
+		for s := range h.sessions {
+			_ = s.conn.CloseNow()
+		}
+		h.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (h *BoardHandler) add(s *boardSession) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closing {
+		return false
+	}
+	h.sessions[s] = struct{}{}
+	h.wg.Add(1)
+	return true
+}
+
+func (h *BoardHandler) remove(s *boardSession) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.sessions, s)
+	h.wg.Done()
+}
+
+func (h *BoardHandler) isClosing() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.closing
+}
+
+// writeError writes err, a huma.StatusError, as a problem response.
+func writeError(w http.ResponseWriter, err huma.StatusError) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(err.GetStatus())
+	_ = json.NewEncoder(w).Encode(err)
+}
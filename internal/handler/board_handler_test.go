@@ -0,0 +1,308 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/auth"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/handler"
+	"github.com/knjname/go-todo-api/internal/tenant"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// boardTest serves a board whose changes are published by hand.
+type boardTest struct {
+	url       string
+	workspace uuid.UUID
+	repo      *mocks.TodoRepository
+	projects  *mocks.ProjectRepository
+	board     *handler.BoardHandler
+	// publish hands an event of the workspace to the board.
+	publish func(*domain.Event)
+}
+
+// setupBoard serves a board to service callers authenticated as principal,
+// or anonymous if it is nil.
+func setupBoard(t *testing.T, principal *auth.Principal) *boardTest {
+	t.Helper()
+	bt := &boardTest{workspace: uuid.New(), repo: mocks.NewTodoRepository(t), projects: mocks.NewProjectRepository(t)}
+	users := mocks.NewUserRepository(t)
+	policy := usecase.NewRolePolicy(users)
+	logger := slog.New(slog.DiscardHandler)
+
+	outbox := mocks.NewOutboxRepository(t)
+	listening := make(chan struct{})
+	outbox.On("Listen", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, ready func(), publish func(*domain.Event)) error {
+			ready()
+			var seq int64
+			bt.publish = func(e *domain.Event) {
+				seq++
+				e.Seq, e.WorkspaceID = seq, bt.workspace
+				publish(e)
+			}
+			close(listening)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	presenceRepo := mocks.NewPresenceRepository(t)
+	var mu sync.Mutex
+	var announced func(*domain.Presence)
+	presenceRepo.On("Listen", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(*domain.Presence)) error {
+		mu.Lock()
+		announced = fn
+		mu.Unlock()
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	presenceRepo.On("Announce", mock.Anything, mock.Anything).Return(func(_ context.Context, p *domain.Presence) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if announced != nil {
+			announced(p)
+		}
+		return nil
+	}).Maybe()
+
+	stream := usecase.NewEventStream(outbox, policy, logger)
+	presence := usecase.NewPresenceTracker(presenceRepo, policy, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Go(func() { _ = stream.Run(ctx) })
+	wg.Go(func() { _ = presence.Run(ctx) })
+	<-listening
+
+	uc := usecase.NewTodoUseCase(bt.repo, bt.projects, users, policy, logger)
+	bt.board = handler.NewBoardHandler(uc, stream, presence)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := actor.AsService(tenant.WithWorkspace(r.Context(), bt.workspace))
+		if principal != nil {
+			ctx = auth.WithPrincipal(ctx, principal)
+		}
+		bt.board.ServeHTTP(w, r.WithContext(ctx))
+	}))
+	bt.url = "ws" + strings.TrimPrefix(srv.URL, "http") + handler.BoardPath
+	t.Cleanup(func() {
+		_ = bt.board.Shutdown(context.Background())
+		srv.Close()
+		cancel()
+		wg.Wait()
+	})
+	return bt
+}
+
+func (bt *boardTest) dial(t *testing.T) *websocket.Conn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, bt.url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.CloseNow() })
+	return conn
+}
+
+// boardMessage holds the fields of any message a board sends.
+type boardMessage struct {
+	Type         string             `json:"type"`
+	ID           string             `json:"id"`
+	ConnectionID uuid.UUID          `json:"connectionId"`
+	Todos        []handler.TodoBody `json:"todos"`
+	Todo         *handler.TodoBody  `json:"todo"`
+	TodoID       uuid.UUID          `json:"todoId"`
+	Viewers      []struct {
+		ConnectionID uuid.UUID   `json:"connectionId"`
+		ProjectIDs   []uuid.UUID `json:"projectIds"`
+	} `json:"viewers"`
+	Status int `json:"status"`
+}
+
+// expect reads messages until one of type typ, skipping presence updates
+// unless those are expected.
+func expect(t *testing.T, conn *websocket.Conn, typ string) boardMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for {
+		_, data, err := conn.Read(ctx)
+		require.NoError(t, err)
+		var msg boardMessage
+		require.NoError(t, json.Unmarshal(data, &msg))
+		if msg.Type == typ {
+			return msg
+		}
+		require.Equal(t, "presence", msg.Type, "unexpected message %s", data)
+	}
+}
+
+func command(t *testing.T, conn *websocket.Conn, cmd string) {
+	t.Helper()
+	require.NoError(t, conn.Write(context.Background(), websocket.MessageText, []byte(cmd)))
+}
+
+func TestBoard_Subscribe(t *testing.T) {
+	bt := setupBoard(t, nil)
+	project := uuid.New()
+	shown := domain.Todo{ID: uuid.New(), Title: "Shown", Status: domain.StatusOpen, ProjectID: &project, Rank: "V"}
+	bt.projects.On("GetByID", mock.Anything, project).Return(&domain.Project{ID: project}, nil)
+	bt.repo.On("List", mock.Anything, domain.TodoFilter{ProjectID: &project}, mock.MatchedBy(func(p domain.PageRequest) bool {
+		return p.Sort.String() == "rank"
+	})).Return([]domain.Todo{shown}, nil)
+
+	conn := bt.dial(t)
+	hello := expect(t, conn, "hello")
+	assert.NotEqual(t, uuid.Nil, hello.ConnectionID)
+
+	command(t, conn, `{"type":"subscribe","id":"s1","subscription":{"projectId":"`+project.String()+`"}}`)
+	snapshot := expect(t, conn, "snapshot")
+	assert.Equal(t, "s1", snapshot.ID)
+	require.Len(t, snapshot.Todos, 1)
+	assert.Equal(t, "Shown", snapshot.Todos[0].Title)
+
+	added := &domain.Todo{ID: uuid.New(), Title: "Added", Status: domain.StatusOpen, ProjectID: &project, Rank: "W"}
+	e, err := domain.NewTodoEvent(nil, added)
+	require.NoError(t, err)
+	bt.publish(e)
+	upsert := expect(t, conn, "upsert")
+	assert.Equal(t, "s1", upsert.ID)
+	assert.Equal(t, "Added", upsert.Todo.Title)
+
+	other := &domain.Todo{ID: uuid.New(), Title: "Elsewhere", Status: domain.StatusOpen}
+	e, err = domain.NewTodoEvent(nil, other)
+	require.NoError(t, err)
+	bt.publish(e)
+	moved := shown
+	moved.ProjectID = nil
+	e, err = domain.NewTodoEvent(&shown, &moved)
+	require.NoError(t, err)
+	bt.publish(e)
+	remove := expect(t, conn, "remove")
+	assert.Equal(t, shown.ID, remove.TodoID, "todos outside the subscription are not sent")
+
+	command(t, conn, `{"type":"unsubscribe","id":"s1"}`)
+	assert.Equal(t, "s1", expect(t, conn, "result").ID)
+	command(t, conn, `{"type":"unsubscribe","id":"s1"}`)
+	assert.Equal(t, http.StatusNotFound, expect(t, conn, "error").Status)
+}
+
+func TestBoard_Commands(t *testing.T) {
+	bt := setupBoard(t, nil)
+	conn := bt.dial(t)
+	expect(t, conn, "hello")
+
+	bt.repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+	command(t, conn, `{"type":"create","id":"c1","todo":{"title":"Created"}}`)
+	created := expect(t, conn, "result")
+	assert.Equal(t, "c1", created.ID)
+	require.NotNil(t, created.Todo)
+	assert.Equal(t, "Created", created.Todo.Title)
+
+	todo := &domain.Todo{ID: uuid.New(), Title: "Moved", Status: domain.StatusOpen, Rank: "a", Version: 1}
+	after := &domain.Todo{ID: uuid.New(), Title: "After", Status: domain.StatusOpen, Rank: "M"}
+	bt.repo.On("GetByID", mock.Anything, todo.ID).Return(todo, nil)
+	bt.repo.On("GetByID", mock.Anything, after.ID).Return(after, nil)
+	bt.repo.On("Update", mock.Anything, todo).Return(nil)
+	command(t, conn, `{"type":"reorder","id":"c2","todoId":"`+todo.ID.String()+`","beforeId":"`+after.ID.String()+`","version":1}`)
+	moved := expect(t, conn, "result")
+	require.NotNil(t, moved.Todo)
+	assert.Less(t, moved.Todo.Rank, after.Rank)
+
+	command(t, conn, `{"type":"reorder","id":"c3","todoId":"`+todo.ID.String()+`","version":7}`)
+	assert.Equal(t, http.StatusPreconditionFailed, expect(t, conn, "error").Status)
+	command(t, conn, `{"type":"archive","id":"c4"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, expect(t, conn, "error").Status)
+	command(t, conn, `not json`)
+	assert.Equal(t, http.StatusBadRequest, expect(t, conn, "error").Status)
+}
+
+func TestBoard_Presence(t *testing.T) {
+	bt := setupBoard(t, nil)
+	project := uuid.New()
+	bt.projects.On("GetByID", mock.Anything, project).Return(&domain.Project{ID: project}, nil)
+	bt.repo.On("List", mock.Anything, mock.Anything, mock.Anything).Return([]domain.Todo{}, nil)
+
+	first := bt.dial(t)
+	firstID := expect(t, first, "hello").ConnectionID
+	require.Len(t, expect(t, first, "presence").Viewers, 1)
+
+	second := bt.dial(t)
+	expect(t, second, "hello")
+	command(t, second, `{"type":"subscribe","id":"s1","subscription":{"projectId":"`+project.String()+`"}}`)
+	expect(t, second, "snapshot")
+
+	// The first viewer is told when the second joins, then when it views the project.
+	awaitPresence(t, first, func(msg boardMessage) bool {
+		return len(msg.Viewers) == 2 && len(msg.Viewers[0].ProjectIDs)+len(msg.Viewers[1].ProjectIDs) == 1
+	})
+
+	require.NoError(t, second.Close(websocket.StatusNormalClosure, ""))
+	awaitPresence(t, first, func(msg boardMessage) bool {
+		return len(msg.Viewers) == 1 && msg.Viewers[0].ConnectionID == firstID
+	})
+}
+
+// awaitPresence reads presence updates until one satisfies cond.
+func awaitPresence(t *testing.T, conn *websocket.Conn, cond func(boardMessage) bool) {
+	t.Helper()
+	for {
+		if cond(expect(t, conn, "presence")) {
+			return
+		}
+	}
+}
+
+func TestBoard_Scopes(t *testing.T) {
+	t.Run("reading requires the read scope", func(t *testing.T) {
+		bt := setupBoard(t, &auth.Principal{Subject: "key", Scopes: []domain.Scope{}})
+		_, resp, err := websocket.Dial(context.Background(), bt.url, nil)
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("commands require the write scope", func(t *testing.T) {
+		bt := setupBoard(t, &auth.Principal{Subject: "key", Scopes: []domain.Scope{domain.ScopeRead}})
+		conn := bt.dial(t)
+		expect(t, conn, "hello")
+		command(t, conn, `{"type":"create","id":"c1","todo":{"title":"Created"}}`)
+		assert.Equal(t, http.StatusForbidden, expect(t, conn, "error").Status)
+	})
+}
+
+func TestBoard_Shutdown(t *testing.T) {
+	bt := setupBoard(t, nil)
+	conn := bt.dial(t)
+	expect(t, conn, "hello")
+
+	// The client reads on, so that it answers the close.
+	closed := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := conn.Read(context.Background()); err != nil {
+				closed <- err
+				return
+			}
+		}
+	}()
+	require.NoError(t, bt.board.Shutdown(context.Background()))
+	assert.Equal(t, websocket.StatusGoingAway, websocket.CloseStatus(<-closed))
+
+	_, resp, err := websocket.Dial(context.Background(), bt.url, nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
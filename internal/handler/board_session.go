@@ -0,0 +1,369 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/auth"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+)
+
+// boardSession serves one board connection. Commands are read and
+// processed in the goroutine running the session, while messages are
+// written by another, from a bounded queue: a client that does not read
+// them fast enough is disconnected rather than buffered for.
+type boardSession struct {
+	h    *BoardHandler
+	conn *websocket.Conn
+	id   uuid.UUID
+
+	// connCtx lasts as long as the request, while ctx is done as soon as
+	// the session starts closing.
+	connCtx   context.Context
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	out       chan any
+
+	mu sync.Mutex
+	// views holds the subscriptions by ID.
+	views map[string]*usecase.TodoView
+}
+
+func newBoardSession(h *BoardHandler, conn *websocket.Conn, ctx context.Context) *boardSession {
+	sctx, cancel := context.WithCancel(ctx)
+	return &boardSession{
+		h: h, conn: conn, id: uuid.New(),
+		connCtx: ctx, ctx: sctx, cancel: cancel,
+		out:   make(chan any, boardBufferSize),
+		views: make(map[string]*usecase.TodoView),
+	}
+}
+
+// run serves the connection until it is closed.
+func (s *boardSession) run() {
+	var wg sync.WaitGroup
+	// Deferred calls run last to first: stop the goroutines, wait for them,
+	// then close the connection unless a close is already under way.
+	defer func() { _ = s.conn.CloseNow() }()
+	defer wg.Wait()
+	defer s.cancel()
+
+	s.send(boardHello{Type: "hello", ConnectionID: s.id})
+	wg.Go(s.write)
+
+	// Changes are followed before anything is listed, so that no change
+	// committed after a snapshot is missed.
+	subscribed := make(chan struct{})
+	wg.Go(func() {
+		s.streamEnded(s.h.stream.Follow(s.ctx, func() { close(subscribed) }, s.apply))
+	})
+	select {
+	case <-subscribed:
+	case <-s.ctx.Done():
+		return
+	}
+
+	changes, stop, err := s.h.presence.Watch(s.ctx)
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	defer stop()
+	wg.Go(func() {
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-changes:
+				s.sendPresence()
+			}
+		}
+	})
+	if err := s.h.presence.Join(s.ctx, s.id, nil); err != nil {
+		s.fail(err)
+		return
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(s.connCtx), boardLeaveTimeout)
+		defer cancel()
+		_ = s.h.presence.Leave(ctx, s.id)
+	}()
+
+	for {
+		typ, data, err := s.conn.Read(s.connCtx)
+		if err != nil {
+			return
+		}
+		var cmd boardCommand
+		if typ != websocket.MessageText || json.Unmarshal(data, &cmd) != nil {
+			s.send(boardError{Type: "error", Status: http.StatusBadRequest, Detail: "commands must be JSON text messages"})
+			continue
+		}
+		s.handle(&cmd)
+	}
+}
+
+// write sends the queued messages, and pings the client every
+// boardPingInterval, until the session closes.
+func (s *boardSession) write() {
+	ping := time.NewTicker(boardPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case msg := <-s.out:
+			data, err := json.Marshal(msg)
+			if err != nil {
+				s.fail(err)
+				return
+			}
+			ctx, cancel := context.WithTimeout(s.connCtx, boardWriteTimeout)
+			err = s.conn.Write(ctx, websocket.MessageText, data)
+			cancel()
+			if err != nil {
+				_ = s.conn.CloseNow()
+				return
+			}
+		case <-ping.C:
+			ctx, cancel := context.WithTimeout(s.connCtx, boardWriteTimeout)
+			err := s.conn.Ping(ctx)
+			cancel()
+			if err != nil {
+				_ = s.conn.CloseNow()
+				return
+			}
+		}
+	}
+}
+
+// send queues msg, or closes the connection if the client has fallen too
+// far behind.
+func (s *boardSession) send(msg any) {
+	select {
+	case s.out <- msg:
+	default:
+		s.close(websocket.StatusTryAgainLater, "too far behind; reconnect")
+	}
+}
+
+// close starts closing the connection with code and reason, telling the
+// client why. Only the first call has an effect.
+func (s *boardSession) close(code websocket.StatusCode, reason string) {
+	s.closeOnce.Do(func() {
+		s.cancel()
+		go func() { _ = s.conn.Close(code, reason) }()
+	})
+}
+
+// fail closes the connection because of err.
+func (s *boardSession) fail(err error) {
+	if errors.Is(err, domain.ErrForbidden) {
+		s.close(websocket.StatusPolicyViolation, "forbidden")
+		return
+	}
+	s.close(websocket.StatusInternalError, "internal error")
+}
+
+// streamEnded closes the connection once changes are no longer followed,
+// unless the session ended first.
+func (s *boardSession) streamEnded(err error) {
+	switch {
+	case err == nil:
+	case s.h.isClosing():
+		s.close(websocket.StatusGoingAway, "server shutting down")
+	case errors.Is(err, usecase.ErrStreamClosed):
+		// The changes committed from now until the client reconnects
+		// would be missed.
+		s.close(websocket.StatusTryAgainLater, "changes interrupted; reconnect")
+	default:
+		s.fail(err)
+	}
+}
+
+// apply sends the changes an event makes to the subscriptions.
+func (s *boardSession) apply(e *domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e.Type == domain.EventAllCompleted {
+		d, err := e.AllCompleted()
+		if err != nil {
+			return fmt.Errorf("decode event %s: %w", e.ID, err)
+		}
+		// The todos are not announced one by one, so the subscriptions they
+		// may belong to are listed again.
+		for id, v := range s.views {
+			if p := v.ProjectID(); d.ProjectID == nil || p == nil || *p == *d.ProjectID {
+				s.reload(id, v)
+			}
+		}
+		return nil
+	}
+
+	t, err := e.Todo()
+	if err != nil {
+		return fmt.Errorf("decode event %s: %w", e.ID, err)
+	}
+	for id, v := range s.views {
+		switch v.Apply(t) {
+		case usecase.ViewUpserted:
+			s.send(boardUpsert{Type: "upsert", ID: id, Todo: newTodoBody(t)})
+		case usecase.ViewRemoved:
+			s.send(boardRemove{Type: "remove", ID: id, TodoID: t.ID})
+		}
+	}
+	return nil
+}
+
+// reload sends a new snapshot of subscription id. s.mu must be held.
+func (s *boardSession) reload(id string, v *usecase.TodoView) {
+	page, err := v.Reload(s.ctx)
+	if err != nil {
+		s.send(newBoardError(id, err))
+		return
+	}
+	s.send(newBoardSnapshot(id, page))
+}
+
+func newBoardSnapshot(id string, page *usecase.TodoPage) boardSnapshot {
+	msg := boardSnapshot{Type: "snapshot", ID: id, Todos: make([]TodoBody, len(page.Todos)), Next: page.Next}
+	for i := range page.Todos {
+		msg.Todos[i] = newTodoBody(&page.Todos[i])
+	}
+	return msg
+}
+
+func (s *boardSession) sendPresence() {
+	viewers, err := s.h.presence.Viewers(s.ctx)
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	msg := boardPresence{Type: "presence", Viewers: make([]boardViewer, len(viewers))}
+	for i, p := range viewers {
+		msg.Viewers[i] = boardViewer{ConnectionID: p.ConnectionID, UserID: p.UserID, ProjectIDs: p.ProjectIDs}
+	}
+	s.send(msg)
+}
+
+// --- Commands ---
+
+func (s *boardSession) handle(cmd *boardCommand) {
+	var err error
+	switch cmd.Type {
+	case "subscribe":
+		err = s.subscribe(cmd)
+	case "unsubscribe":
+		err = s.unsubscribe(cmd)
+	case "create", "complete", "reorder":
+		var todo *domain.Todo
+		if todo, err = s.change(cmd); err == nil {
+			body := newTodoBody(todo)
+			s.send(boardResult{Type: "result", ID: cmd.ID, Todo: &body})
+		}
+	default:
+		err = domain.NewValidationError("type", "must be subscribe, unsubscribe, create, complete or reorder")
+	}
+	if err != nil {
+		s.send(newBoardError(cmd.ID, err))
+	}
+}
+
+// subscribe opens the view a command subscribes to and sends its snapshot.
+func (s *boardSession) subscribe(cmd *boardCommand) error {
+	if cmd.ID == "" {
+		return domain.NewValidationError("id", "is required")
+	}
+	if cmd.Subscription == nil {
+		return domain.NewValidationError("subscription", "is required")
+	}
+	projects, err := s.openView(cmd.ID, cmd.Subscription.params())
+	if err != nil {
+		return err
+	}
+	return s.h.presence.Join(s.ctx, s.id, projects)
+}
+
+// openView opens view id and sends its snapshot, holding s.mu so that the
+// changes to it are sent after the snapshot. It returns the projects
+// viewed then.
+func (s *boardSession) openView(id string, params usecase.ListTodosParams) ([]uuid.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.views[id]; !ok && len(s.views) >= boardMaxViews {
+		return nil, domain.NewValidationError("id", fmt.Sprintf("at most %d subscriptions are allowed", boardMaxViews))
+	}
+	view, page, err := s.h.uc.OpenView(s.ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	s.views[id] = view
+	s.send(newBoardSnapshot(id, page))
+	return s.projects(), nil
+}
+
+func (s *boardSession) unsubscribe(cmd *boardCommand) error {
+	s.mu.Lock()
+	if _, ok := s.views[cmd.ID]; !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("subscription %q: %w", cmd.ID, domain.ErrNotFound)
+	}
+	delete(s.views, cmd.ID)
+	projects := s.projects()
+	s.mu.Unlock()
+
+	s.send(boardResult{Type: "result", ID: cmd.ID})
+	return s.h.presence.Join(s.ctx, s.id, projects)
+}
+
+// projects returns the projects the subscriptions view, in a stable order.
+// s.mu must be held.
+func (s *boardSession) projects() []uuid.UUID {
+	var ids []uuid.UUID
+	for _, v := range s.views {
+		if p := v.ProjectID(); p != nil && !slices.Contains(ids, *p) {
+			ids = append(ids, *p)
+		}
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+	return ids
+}
+
+// change runs a command that changes a todo, which requires the write
+// scope like the REST operations doing the same.
+func (s *boardSession) change(cmd *boardCommand) (*domain.Todo, error) {
+	if p, ok := auth.PrincipalFrom(s.ctx); ok && !p.HasScope(domain.ScopeWrite) {
+		return nil, fmt.Errorf("the %s scope is required: %w", domain.ScopeWrite, domain.ErrForbidden)
+	}
+	var versions []int64
+	if cmd.Version != 0 {
+		versions = []int64{cmd.Version}
+	}
+	switch cmd.Type {
+	case "create":
+		var input CreateTodoInput
+		if len(cmd.Todo) == 0 {
+			return nil, domain.NewValidationError("todo", "is required")
+		}
+		if err := json.Unmarshal(cmd.Todo, &input.Body); err != nil {
+			return nil, domain.NewValidationError("todo", err.Error())
+		}
+		return s.h.uc.CreateTodo(s.ctx, input.params())
+	case "complete":
+		return s.h.uc.CompleteTodo(s.ctx, cmd.TodoID, versions, false)
+	default:
+		return s.h.uc.MoveTodo(s.ctx, cmd.TodoID, cmd.AfterID, cmd.BeforeID, versions)
+	}
+}
@@ -2,9 +2,12 @@ package handler
 
 import (
 	"errors"
+	"net/http"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/knjname/go-todo-api/internal/actor"
 	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/tenant"
 )
 
 func mapDomainError(err error) error {
@@ -13,6 +16,22 @@ func mapDomainError(err error) error {
 		return huma.Error404NotFound("resource not found", err)
 	case errors.Is(err, domain.ErrValidation):
 		return huma.Error422UnprocessableEntity("validation failed", err)
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return huma.Error412PreconditionFailed("precondition failed", err)
+	case errors.Is(err, domain.ErrAlreadyExists):
+		return huma.Error409Conflict("resource already exists", err)
+	case errors.Is(err, domain.ErrInvalidTransition):
+		return huma.Error409Conflict("status transition not allowed", err)
+	case errors.Is(err, domain.ErrConflict):
+		return huma.Error409Conflict("resource was modified concurrently", err)
+	case errors.Is(err, domain.ErrForbidden):
+		return huma.Error403Forbidden("forbidden", err)
+	case errors.Is(err, tenant.ErrNoWorkspace):
+		return huma.Error400BadRequest("workspace required", err)
+	case errors.Is(err, actor.ErrNoUser):
+		return huma.Error400BadRequest("user required", err)
+	case errors.Is(err, domain.ErrBulkAborted):
+		return huma.NewError(http.StatusFailedDependency, "not applied: the bulk action failed for another todo")
 	default:
 		return huma.Error500InternalServerError("internal error")
 	}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// todoETag returns the strong entity tag of a todo, derived from its version.
+func todoETag(t *domain.Todo) string {
+	return strconv.Quote(strconv.FormatInt(t.Version, 10))
+}
+
+// parseIfMatch converts an If-Match header into the todo versions it
+// accepts. An absent header or "*" accepts any version and yields none.
+// If-Match compares entity tags strongly, so weak tags never match; when no
+// tag of the list can match, as with tags this API never issues, it fails
+// with domain.ErrPreconditionFailed.
+func parseIfMatch(header string) ([]int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	var versions []int64
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err == nil && version > 0 {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, domain.ErrPreconditionFailed
+	}
+	return versions, nil
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/sse"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+)
+
+type EventHandler struct {
+	stream *usecase.EventStream
+}
+
+func NewEventHandler(stream *usecase.EventStream) *EventHandler {
+	return &EventHandler{stream: stream}
+}
+
+// --- Input/Output types ---
+
+type TodoEventBody struct {
+	EventID    uuid.UUID `json:"eventId" doc:"イベントID (Webhook の X-Event-ID と同じ)"`
+	OccurredAt time.Time `json:"occurredAt" doc:"発生日時"`
+	Todo       TodoBody  `json:"todo" doc:"変更後の Todo"`
+}
+
+// The todo events share a body, but each has a type of its own, which
+// names the event in the stream.
+type (
+	TodoCreatedEvent   TodoEventBody
+	TodoUpdatedEvent   TodoEventBody
+	TodoCompletedEvent TodoEventBody
+	TodoDeletedEvent   TodoEventBody
+)
+
+type AllCompletedEvent struct {
+	EventID    uuid.UUID   `json:"eventId" doc:"イベントID (Webhook の X-Event-ID と同じ)"`
+	OccurredAt time.Time   `json:"occurredAt" doc:"発生日時"`
+	ProjectID  *uuid.UUID  `json:"projectId,omitempty" doc:"対象のプロジェクトID。全プロジェクトが対象の場合は省略"`
+	TodoIDs    []uuid.UUID `json:"todoIds" doc:"完了した Todo の ID"`
+}
+
+type StreamErrorEvent struct {
+	Status int    `json:"status" doc:"HTTP ステータス相当のコード"`
+	Detail string `json:"detail" doc:"エラーの内容"`
+}
+
+func newEventMessage(e *domain.Event) (sse.Message, error) {
+	msg := sse.Message{ID: int(e.Horizon)}
+	if e.Type == domain.EventAllCompleted {
+		d, err := e.AllCompleted()
+		if err != nil {
+			return msg, fmt.Errorf("decode event %s: %w", e.ID, err)
+		}
+		msg.Data = AllCompletedEvent{EventID: e.ID, OccurredAt: e.OccurredAt, ProjectID: d.ProjectID, TodoIDs: d.TodoIDs}
+		return msg, nil
+	}
+
+	t, err := e.Todo()
+	if err != nil {
+		return msg, fmt.Errorf("decode event %s: %w", e.ID, err)
+	}
+	body := TodoEventBody{EventID: e.ID, OccurredAt: e.OccurredAt, Todo: newTodoBody(t)}
+	switch e.Type {
+	case domain.EventTodoCreated:
+		msg.Data = TodoCreatedEvent(body)
+	case domain.EventTodoCompleted:
+		msg.Data = TodoCompletedEvent(body)
+	case domain.EventTodoDeleted:
+		msg.Data = TodoDeletedEvent(body)
+	default:
+		msg.Data = TodoUpdatedEvent(body)
+	}
+	return msg, nil
+}
+
+type StreamTodoEventsInput struct {
+	LastEventID int64 `header:"Last-Event-ID" minimum:"0" doc:"最後に受け取ったイベントの id。指定するとその後にコミットされたイベントから再開する (受信済みのイベントが再送されることがある)"`
+}
+
+func (h *EventHandler) Register(api huma.API) {
+	sse.Register(api, huma.Operation{
+		OperationID: "stream-todo-events",
+		Middlewares: requireScope(api, domain.ScopeRead),
+		Method:      http.MethodGet,
+		Path:        "/todos/events",
+		Summary:     "Stream todo changes as Server-Sent Events",
+		Description: "Pushes an event for every todo change in the workspace as it is committed. " +
+			"Reconnecting with Last-Event-ID resumes after that event; events may be sent again, so clients should dedupe them by eventId. " +
+			"The stream ends when the client falls behind or the server shuts down; clients should reconnect.",
+		Tags: []string{"Todos"},
+	}, map[string]any{
+		string(domain.EventTodoCreated):   TodoCreatedEvent{},
+		string(domain.EventTodoUpdated):   TodoUpdatedEvent{},
+		string(domain.EventTodoCompleted): TodoCompletedEvent{},
+		string(domain.EventTodoDeleted):   TodoDeletedEvent{},
+		string(domain.EventAllCompleted):  AllCompletedEvent{},
+		"error":                           StreamErrorEvent{},
+	}, h.streamTodoEvents)
+}
+
+func (h *EventHandler) streamTodoEvents(ctx context.Context, input *StreamTodoEventsInput, send sse.Sender) {
+	var sendErr error
+	err := h.stream.Stream(ctx, input.LastEventID, func(e *domain.Event) error {
+		msg, err := newEventMessage(e)
+		if err != nil {
+			return err
+		}
+		sendErr = send(msg)
+		return sendErr
+	})
+	if err == nil || sendErr != nil || errors.Is(err, usecase.ErrStreamClosed) {
+		return
+	}
+
+	// The response has started, so errors are sent as an event.
+	var se huma.StatusError
+	if errors.As(mapDomainError(err), &se) {
+		_ = send.Data(StreamErrorEvent{Status: se.GetStatus(), Detail: se.Error()})
+	}
+}
@@ -0,0 +1,99 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/handler"
+	"github.com/knjname/go-todo-api/internal/tenant"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupEventAPI runs an event stream whose outbox listens until lost is
+// closed.
+func setupEventAPI(t *testing.T) (humatest.TestAPI, *mocks.OutboxRepository, chan struct{}) {
+	t.Helper()
+	outbox := mocks.NewOutboxRepository(t)
+	listening, lost := make(chan struct{}), make(chan struct{})
+	outbox.On("Listen", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, ready func(), _ func(*domain.Event)) error {
+			ready()
+			select {
+			case <-listening:
+			default:
+				close(listening)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-lost:
+				return errors.New("connection lost")
+			}
+		})
+
+	stream := usecase.NewEventStream(outbox, usecase.NewRolePolicy(mocks.NewUserRepository(t)), slog.New(slog.DiscardHandler))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = stream.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	<-listening
+
+	api := newServiceAPI(t)
+	workspace := uuid.New()
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		next(huma.WithContext(ctx, tenant.WithWorkspace(ctx.Context(), workspace)))
+	})
+	handler.NewEventHandler(stream).Register(api)
+	return api, outbox, lost
+}
+
+func TestStreamTodoEvents_Handler(t *testing.T) {
+	api, outbox, lost := setupEventAPI(t)
+	todo, err := domain.NewTodo("Streamed", "")
+	require.NoError(t, err)
+	created, err := domain.NewTodoEvent(nil, todo)
+	require.NoError(t, err)
+	created.Seq, created.Horizon = 5, 7
+	completed, err := domain.NewAllCompletedEvent(nil, []uuid.UUID{todo.ID})
+	require.NoError(t, err)
+	completed.Seq, completed.Horizon = 6, 7
+
+	// The stream ends once the missed events are sent, as it loses the
+	// database.
+	outbox.On("ListSince", mock.Anything, int64(4), int64(0), mock.Anything).
+		Run(func(mock.Arguments) { close(lost) }).
+		Return([]domain.Event{*created, *completed}, nil)
+
+	resp := api.Get("/todos/events", "Last-Event-ID: 4")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/event-stream", resp.Header().Get("Content-Type"))
+
+	body := resp.Body.String()
+	messages := strings.Split(strings.TrimSpace(body), "\n\n")
+	require.Len(t, messages, 2, body)
+	// Missed events resume from where the client asked to, until they are
+	// all sent.
+	assert.Contains(t, messages[0], "id: 4\nevent: TodoCreated\ndata: ")
+	assert.Contains(t, messages[0], `"title":"Streamed"`)
+	assert.Contains(t, messages[0], `"eventId":"`+created.ID.String()+`"`)
+	assert.Contains(t, messages[1], "id: 4\nevent: AllCompleted\ndata: ")
+	assert.Contains(t, messages[1], `"todoIds":["`+todo.ID.String()+`"]`)
+}
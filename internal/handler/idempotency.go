@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+)
+
+const (
+	// IdempotencyKeyHeader carries the key a client retries a request with.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set to "true" on the responses replayed
+	// to retries.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// IdempotencyKeyInput documents the Idempotency-Key header of the
+// operations using idempotent, which reads it itself.
+type IdempotencyKeyInput struct {
+	IdempotencyKey string `header:"Idempotency-Key" maxLength:"255" doc:"再送を識別するキー。同じキーの再送には最初の応答をそのまま返す (24 時間)"`
+}
+
+// idempotent returns the operation middlewares that answer the retries of
+// a request with an Idempotency-Key header with the response to the first
+// one, instead of running it again. Requests without the header pass.
+func idempotent(api huma.API, uc *usecase.IdempotencyUseCase) huma.Middlewares {
+	return huma.Middlewares{func(ctx huma.Context, next func(huma.Context)) {
+		key := ctx.Header(IdempotencyKeyHeader)
+		if key == "" {
+			next(ctx)
+			return
+		}
+
+		reader := ctx.BodyReader()
+		if limit := ctx.Operation().MaxBodyBytes; limit > 0 {
+			// One byte more than huma accepts, for it to see the body is too large.
+			reader = io.LimitReader(reader, limit+1)
+		}
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			_ = huma.WriteErr(api, ctx, http.StatusBadRequest, "cannot read request body", err)
+			return
+		}
+		u := ctx.URL()
+		fingerprint := domain.IdempotencyFingerprint(ctx.Method(), u.RequestURI(), body)
+
+		stored, err := uc.Begin(ctx.Context(), key, fingerprint)
+		if errors.Is(err, domain.ErrConflict) {
+			ctx.SetHeader("Retry-After", "1")
+			_ = huma.WriteErr(api, ctx, http.StatusConflict, "a request with this idempotency key is in progress", err)
+			return
+		}
+		if err != nil {
+			writeDomainError(api, ctx, err)
+			return
+		}
+		if stored != nil {
+			replay(ctx, stored)
+			return
+		}
+
+		rec := &recordingContext{humaContext: ctx, body: body, header: http.Header{}}
+		// Finish must run even if the request is cancelled, and release the
+		// key if the handler panics.
+		var response *domain.StoredResponse
+		defer func() {
+			_ = uc.Finish(context.WithoutCancel(ctx.Context()), key, response)
+		}()
+		next(rec)
+		response = &domain.StoredResponse{Status: rec.Status(), Header: rec.header, Body: rec.written.Bytes()}
+	}}
+}
+
+// replay writes a stored response.
+func replay(ctx huma.Context, stored *domain.StoredResponse) {
+	for name, values := range stored.Header {
+		for _, v := range values {
+			ctx.AppendHeader(name, v)
+		}
+	}
+	ctx.SetHeader(IdempotentReplayedHeader, "true")
+	ctx.SetStatus(stored.Status)
+	_, _ = ctx.BodyWriter().Write(stored.Body)
+}
+
+// writeDomainError writes err as mapDomainError maps it, for middlewares
+// that cannot return errors.
+func writeDomainError(api huma.API, ctx huma.Context, err error) {
+	var model *huma.ErrorModel
+	if !errors.As(mapDomainError(err), &model) {
+		_ = huma.WriteErr(api, ctx, http.StatusInternalServerError, "internal error")
+		return
+	}
+	errs := make([]error, len(model.Errors))
+	for i, detail := range model.Errors {
+		errs[i] = detail
+	}
+	_ = huma.WriteErr(api, ctx, model.Status, model.Detail, errs...)
+}
+
+// humaContext names huma.Context for recordingContext to embed it, a
+// field named Context clashing with its Context method.
+type humaContext = huma.Context
+
+// recordingContext gives the handler the request body read by idempotent
+// and records the response it writes.
+type recordingContext struct {
+	humaContext
+	body    []byte
+	header  http.Header
+	written bytes.Buffer
+}
+
+func (c *recordingContext) BodyReader() io.Reader {
+	return bytes.NewReader(c.body)
+}
+
+func (c *recordingContext) SetHeader(name, value string) {
+	c.header.Set(name, value)
+	c.humaContext.SetHeader(name, value)
+}
+
+func (c *recordingContext) AppendHeader(name, value string) {
+	c.header.Add(name, value)
+	c.humaContext.AppendHeader(name, value)
+}
+
+func (c *recordingContext) BodyWriter() io.Writer {
+	return io.MultiWriter(c.humaContext.BodyWriter(), &c.written)
+}
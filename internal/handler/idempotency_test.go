@@ -0,0 +1,109 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/handler"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupIdempotencyAPI(t *testing.T) (humatest.TestAPI, *mocks.TodoRepository, *mocks.IdempotencyRepository) {
+	t.Helper()
+	repo := mocks.NewTodoRepository(t)
+	keys := mocks.NewIdempotencyRepository(t)
+	logger := slog.New(slog.DiscardHandler)
+	users := &mocks.UserRepository{}
+	uc := usecase.NewTodoUseCase(repo, &mocks.ProjectRepository{}, users, usecase.NewRolePolicy(users), logger)
+	api := newServiceAPI(t)
+	handler.NewTodoHandler(uc, usecase.NewIdempotencyUseCase(keys, logger)).Register(api)
+	return api, repo, keys
+}
+
+func TestIdempotency_Handler(t *testing.T) {
+	create := map[string]string{"title": "Once", "description": ""}
+	key := handler.IdempotencyKeyHeader + ": retry-1"
+
+	t.Run("replays the stored response", func(t *testing.T) {
+		api, repo, keys := setupIdempotencyAPI(t)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
+		var (
+			reserved *domain.IdempotencyRecord
+			stored   *domain.StoredResponse
+		)
+		keys.On("Reserve", mock.Anything, mock.AnythingOfType("*domain.IdempotencyRecord")).
+			Run(func(args mock.Arguments) { reserved = args.Get(1).(*domain.IdempotencyRecord) }).
+			Return(nil, nil).Once()
+		keys.On("Complete", mock.Anything, "retry-1", mock.AnythingOfType("*domain.StoredResponse")).
+			Run(func(args mock.Arguments) { stored = args.Get(2).(*domain.StoredResponse) }).
+			Return(nil).Once()
+
+		first := api.Post("/todos", key, create)
+		require.Equal(t, http.StatusOK, first.Code)
+		require.NotNil(t, stored)
+		assert.Equal(t, http.StatusOK, stored.Status)
+		assert.Equal(t, first.Body.Bytes(), stored.Body)
+		assert.Empty(t, first.Header().Get(handler.IdempotentReplayedHeader))
+
+		reserved.Response = stored
+		keys.On("Reserve", mock.Anything, mock.AnythingOfType("*domain.IdempotencyRecord")).Return(reserved, nil).Once()
+		retry := api.Post("/todos", key, create)
+		assert.Equal(t, http.StatusOK, retry.Code)
+		assert.Equal(t, "true", retry.Header().Get(handler.IdempotentReplayedHeader))
+		assert.Equal(t, first.Header().Get("ETag"), retry.Header().Get("ETag"))
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+	})
+
+	t.Run("rejects a key reused for another body", func(t *testing.T) {
+		api, _, keys := setupIdempotencyAPI(t)
+		keys.On("Reserve", mock.Anything, mock.Anything).
+			Return(&domain.IdempotencyRecord{Key: "retry-1", Fingerprint: "other", Response: &domain.StoredResponse{Status: 200}}, nil)
+
+		resp := api.Post("/todos", key, create)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	})
+
+	t.Run("refuses retries while the request is in progress", func(t *testing.T) {
+		api, _, keys := setupIdempotencyAPI(t)
+		keys.On("Reserve", mock.Anything, mock.Anything).
+			Return(func(_ context.Context, r *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+				return &domain.IdempotencyRecord{Key: r.Key, Fingerprint: r.Fingerprint}, nil
+			})
+
+		resp := api.Post("/todos", key, create)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.NotEmpty(t, resp.Header().Get("Retry-After"))
+	})
+
+	t.Run("stores the validation failures of the request", func(t *testing.T) {
+		api, _, keys := setupIdempotencyAPI(t)
+		keys.On("Reserve", mock.Anything, mock.Anything).Return(nil, nil)
+		keys.On("Complete", mock.Anything, "retry-1", mock.MatchedBy(func(r *domain.StoredResponse) bool {
+			return r.Status == http.StatusUnprocessableEntity
+		})).Return(nil)
+
+		resp := api.Post("/todos", key, map[string]string{"title": ""})
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	})
+
+	t.Run("without a key", func(t *testing.T) {
+		api, repo, _ := setupIdempotencyAPI(t)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Twice()
+
+		for range 2 {
+			resp := api.Post("/todos", create)
+			require.Equal(t, http.StatusOK, resp.Code)
+			var body handler.TodoBody
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		}
+	})
+}
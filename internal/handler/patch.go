@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"reflect"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/usecase"
+)
+
+const (
+	contentTypeMergePatch = "application/merge-patch+json"
+	contentTypeJSONPatch  = "application/json-patch+json"
+)
+
+// TodoMergePatch documents the RFC 7396 merge patch accepted by PATCH
+// /todos/{id}. Omitted members are left untouched and null removes a value.
+type TodoMergePatch struct {
+	Title       string   `json:"title,omitempty" maxLength:"200" minLength:"1" doc:"Todoタイトル"`
+	Description *string  `json:"description,omitempty" nullable:"true" doc:"詳細説明。null で空にする"`
+	DueDate     *string  `json:"dueDate,omitempty" nullable:"true" format:"date" doc:"期限日。null で期限を削除"`
+	DueTime     *string  `json:"dueTime,omitempty" nullable:"true" doc:"期限時刻 (HH:MM)。null で終日にする"`
+	DueTimeZone *string  `json:"dueTimeZone,omitempty" nullable:"true" doc:"期限のタイムゾーン。null で UTC に戻す"`
+	Priority    *string  `json:"priority,omitempty" nullable:"true" enum:"none,low,medium,high,urgent" doc:"優先度。null で none に戻す"`
+	Tags        []string `json:"tags,omitempty" nullable:"true" doc:"タグ名の一覧で置き換える。null ですべて外す"`
+	ParentID    *string  `json:"parentId,omitempty" nullable:"true" format:"uuid" doc:"親TodoのID。null でトップレベルのTodoにする"`
+	ProjectID   *string  `json:"projectId,omitempty" nullable:"true" format:"uuid" doc:"所属プロジェクトのID。null でプロジェクトから外す"`
+
+	RecurrenceRule     *string `json:"recurrenceRule,omitempty" nullable:"true" doc:"繰り返しルール (RFC 5545 RRULE)。null で繰り返しをやめる"`
+	RecurrenceTimeZone *string `json:"recurrenceTimeZone,omitempty" nullable:"true" doc:"繰り返しルールのタイムゾーン。null で期限のタイムゾーンに戻す"`
+}
+
+// JSONPatchOperation documents one RFC 6902 operation accepted by PATCH
+// /todos/{id}.
+type JSONPatchOperation struct {
+	Op    string `json:"op" enum:"add,replace,remove" doc:"操作"`
+	Path  string `json:"path" enum:"/title,/description,/dueDate,/dueTime,/dueTimeZone,/priority,/tags,/parentId,/projectId,/recurrenceRule,/recurrenceTimeZone" doc:"対象フィールド"`
+	Value any    `json:"value,omitempty" doc:"設定する値 (add / replace)。/tags は文字列の配列"`
+}
+
+type PatchTodoInput struct {
+	ID          uuid.UUID `path:"id" doc:"Todo ID"`
+	IfMatch     string    `header:"If-Match" doc:"このETagと現在のバージョンが一致する場合のみ実行する"`
+	ContentType string    `header:"Content-Type" doc:"application/merge-patch+json または application/json-patch+json"`
+	RawBody     []byte    `contentType:"application/merge-patch+json"`
+}
+
+type PatchTodoOutput struct {
+	ETag string `header:"ETag" doc:"Todoのバージョンを表すエンティティタグ"`
+	Body TodoBody
+}
+
+// patchFields maps patchable JSON members to a function that decodes their
+// new value into a TodoPatch. A nil or JSON null value clears the member.
+var patchFields = map[string]func(*usecase.TodoPatch, json.RawMessage) error{
+	"title":       stringMember("title", func(p *usecase.TodoPatch, v *string) { p.Title = v }),
+	"description": stringMember("description", func(p *usecase.TodoPatch, v *string) { p.Description = v }),
+	"dueDate":     stringMember("dueDate", func(p *usecase.TodoPatch, v *string) { p.DueDate = v }),
+	"dueTime":     stringMember("dueTime", func(p *usecase.TodoPatch, v *string) { p.DueTime = v }),
+	"dueTimeZone": stringMember("dueTimeZone", func(p *usecase.TodoPatch, v *string) { p.DueTimeZone = v }),
+	"priority":    stringMember("priority", func(p *usecase.TodoPatch, v *string) { p.Priority = v }),
+	"recurrenceRule": stringMember("recurrenceRule", func(p *usecase.TodoPatch, v *string) {
+		p.RecurrenceRule = v
+	}),
+	"recurrenceTimeZone": stringMember("recurrenceTimeZone", func(p *usecase.TodoPatch, v *string) {
+		p.RecurrenceTimeZone = v
+	}),
+	"tags": func(p *usecase.TodoPatch, raw json.RawMessage) error {
+		tags := []string{}
+		if !isNull(raw) {
+			if err := json.Unmarshal(raw, &tags); err != nil {
+				return huma.Error422UnprocessableEntity("tags must be an array of strings or null")
+			}
+		}
+		p.Tags = &tags
+		return nil
+	},
+	"parentId":  idMember("parentId", func(p *usecase.TodoPatch, v *uuid.UUID) { p.ParentID = v }),
+	"projectId": idMember("projectId", func(p *usecase.TodoPatch, v *uuid.UUID) { p.ProjectID = v }),
+}
+
+// idMember decodes a UUID member; JSON null yields uuid.Nil.
+func idMember(name string, set func(*usecase.TodoPatch, *uuid.UUID)) func(*usecase.TodoPatch, json.RawMessage) error {
+	return func(p *usecase.TodoPatch, raw json.RawMessage) error {
+		id := new(uuid.UUID)
+		if !isNull(raw) {
+			if err := json.Unmarshal(raw, id); err != nil {
+				return huma.Error422UnprocessableEntity(name + " must be a UUID or null")
+			}
+		}
+		set(p, id)
+		return nil
+	}
+}
+
+// stringMember decodes a string member; JSON null yields "".
+func stringMember(name string, set func(*usecase.TodoPatch, *string)) func(*usecase.TodoPatch, json.RawMessage) error {
+	return func(p *usecase.TodoPatch, raw json.RawMessage) error {
+		value := new(string)
+		if !isNull(raw) {
+			if err := json.Unmarshal(raw, value); err != nil {
+				return huma.Error422UnprocessableEntity(name + " must be a string or null")
+			}
+		}
+		set(p, value)
+		return nil
+	}
+}
+
+func isNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || bytes.Equal(raw, []byte("null"))
+}
+
+// applyPatchMember sets member name of patch to the raw JSON value.
+func applyPatchMember(patch *usecase.TodoPatch, name string, raw json.RawMessage) error {
+	apply, ok := patchFields[name]
+	if !ok {
+		return huma.Error422UnprocessableEntity(name + " cannot be patched")
+	}
+	return apply(patch, raw)
+}
+
+// decodeTodoPatch translates a merge patch or JSON patch document into a
+// usecase.TodoPatch. A null or removed member is passed on as an empty string.
+func decodeTodoPatch(contentType string, body []byte) (usecase.TodoPatch, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if contentType == "" {
+		mediaType, err = contentTypeMergePatch, nil
+	}
+	if err != nil {
+		return usecase.TodoPatch{}, huma.Error415UnsupportedMediaType("invalid Content-Type", err)
+	}
+
+	switch mediaType {
+	case contentTypeMergePatch, "application/json":
+		return decodeMergePatch(body)
+	case contentTypeJSONPatch:
+		return decodeJSONPatch(body)
+	default:
+		return usecase.TodoPatch{}, huma.Error415UnsupportedMediaType(
+			"Content-Type must be " + contentTypeMergePatch + " or " + contentTypeJSONPatch)
+	}
+}
+
+func decodeMergePatch(body []byte) (usecase.TodoPatch, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil || members == nil {
+		return usecase.TodoPatch{}, huma.Error400BadRequest("merge patch must be a JSON object", err)
+	}
+
+	var patch usecase.TodoPatch
+	for name, raw := range members {
+		if err := applyPatchMember(&patch, name, raw); err != nil {
+			return usecase.TodoPatch{}, err
+		}
+	}
+	return patch, nil
+}
+
+func decodeJSONPatch(body []byte) (usecase.TodoPatch, error) {
+	var ops []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(body, &ops); err != nil {
+		return usecase.TodoPatch{}, huma.Error400BadRequest("JSON patch must be an array of operations", err)
+	}
+
+	var patch usecase.TodoPatch
+	for i, op := range ops {
+		if len(op.Path) < 2 || op.Path[0] != '/' {
+			return usecase.TodoPatch{}, huma.Error422UnprocessableEntity(fmt.Sprintf("operation %d: invalid path %q", i, op.Path))
+		}
+		name := op.Path[1:]
+
+		var value json.RawMessage
+		switch op.Op {
+		case "add", "replace":
+			// An explicit null clears the member, but the value is required.
+			if op.Value == nil {
+				return usecase.TodoPatch{}, huma.Error422UnprocessableEntity(fmt.Sprintf("operation %d: %s requires a value", i, op.Op))
+			}
+			value = op.Value
+		case "remove":
+			// A nil value clears the member.
+		default:
+			return usecase.TodoPatch{}, huma.Error422UnprocessableEntity(fmt.Sprintf("operation %d: unsupported op %q", i, op.Op))
+		}
+		if err := applyPatchMember(&patch, name, value); err != nil {
+			return usecase.TodoPatch{}, err
+		}
+	}
+	return patch, nil
+}
+
+// documentPatchBody replaces the binary placeholder huma generates for the
+// RawBody of the patch operation with the schemas of both patch formats.
+func documentPatchBody(api huma.API, op *huma.Operation) {
+	registry := api.OpenAPI().Components.Schemas
+	op.RequestBody.Content = map[string]*huma.MediaType{
+		contentTypeMergePatch: {Schema: huma.SchemaFromType(registry, reflect.TypeOf(TodoMergePatch{}))},
+		contentTypeJSONPatch:  {Schema: huma.SchemaFromType(registry, reflect.TypeOf([]JSONPatchOperation{}))},
+	}
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+)
+
+type ProjectHandler struct {
+	uc *usecase.ProjectUseCase
+}
+
+func NewProjectHandler(uc *usecase.ProjectUseCase) *ProjectHandler {
+	return &ProjectHandler{uc: uc}
+}
+
+// --- Input/Output types ---
+
+type ProjectBody struct {
+	ID          uuid.UUID `json:"id" doc:"プロジェクトID"`
+	Name        string    `json:"name" doc:"プロジェクト名"`
+	Description string    `json:"description" doc:"詳細説明"`
+	CreatedAt   time.Time `json:"createdAt" doc:"作成日時"`
+	UpdatedAt   time.Time `json:"updatedAt" doc:"更新日時"`
+}
+
+func newProjectBody(p *domain.Project) ProjectBody {
+	return ProjectBody{ID: p.ID, Name: p.Name, Description: p.Description, CreatedAt: p.CreatedAt, UpdatedAt: p.UpdatedAt}
+}
+
+type CreateProjectInput struct {
+	Body struct {
+		Name        string `json:"name" maxLength:"100" minLength:"1" doc:"プロジェクト名"`
+		Description string `json:"description,omitempty" doc:"詳細説明"`
+	}
+}
+
+type ProjectOutput struct {
+	Body ProjectBody
+}
+
+type GetProjectInput struct {
+	ID uuid.UUID `path:"id" doc:"プロジェクトID"`
+}
+
+type ListProjectsOutput struct {
+	Body struct {
+		Items []ProjectBody `json:"items" doc:"プロジェクト一覧 (名前順)"`
+	}
+}
+
+type UpdateProjectInput struct {
+	ID   uuid.UUID `path:"id" doc:"プロジェクトID"`
+	Body struct {
+		Name        string `json:"name" maxLength:"100" minLength:"1" doc:"プロジェクト名"`
+		Description string `json:"description,omitempty" doc:"詳細説明。省略時は空になる"`
+	}
+}
+
+type DeleteProjectInput struct {
+	ID uuid.UUID `path:"id" doc:"プロジェクトID"`
+}
+
+// Register registers the project CRUD routes on the huma API. The todo
+// routes below /projects/{id} are registered by TodoHandler.
+func (h *ProjectHandler) Register(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "create-project",
+		Middlewares: requireScope(api, domain.ScopeWrite),
+		Method:      http.MethodPost,
+		Path:        "/projects",
+		Summary:     "Create a new project",
+		Tags:        []string{"Projects"},
+	}, h.createProject)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-projects",
+		Middlewares: requireScope(api, domain.ScopeRead),
+		Method:      http.MethodGet,
+		Path:        "/projects",
+		Summary:     "List all projects",
+		Tags:        []string{"Projects"},
+	}, h.listProjects)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-project",
+		Middlewares: requireScope(api, domain.ScopeRead),
+		Method:      http.MethodGet,
+		Path:        "/projects/{id}",
+		Summary:     "Get a project by ID",
+		Tags:        []string{"Projects"},
+	}, h.getProject)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-project",
+		Middlewares: requireScope(api, domain.ScopeWrite),
+		Method:      http.MethodPut,
+		Path:        "/projects/{id}",
+		Summary:     "Update a project",
+		Tags:        []string{"Projects"},
+	}, h.updateProject)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "delete-project",
+		Middlewares: requireScope(api, domain.ScopeAdmin),
+		Method:      http.MethodDelete,
+		Path:        "/projects/{id}",
+		Summary:     "Delete a project",
+		Description: "Deletes the project. Its todos are kept outside any project.",
+		Tags:        []string{"Projects"},
+	}, h.deleteProject)
+}
+
+func (h *ProjectHandler) createProject(ctx context.Context, input *CreateProjectInput) (*ProjectOutput, error) {
+	project, err := h.uc.CreateProject(ctx, input.Body.Name, input.Body.Description)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &ProjectOutput{Body: newProjectBody(project)}, nil
+}
+
+func (h *ProjectHandler) listProjects(ctx context.Context, _ *struct{}) (*ListProjectsOutput, error) {
+	projects, err := h.uc.ListProjects(ctx)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	out := &ListProjectsOutput{}
+	out.Body.Items = make([]ProjectBody, len(projects))
+	for i := range projects {
+		out.Body.Items[i] = newProjectBody(&projects[i])
+	}
+	return out, nil
+}
+
+func (h *ProjectHandler) getProject(ctx context.Context, input *GetProjectInput) (*ProjectOutput, error) {
+	project, err := h.uc.GetProject(ctx, input.ID)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &ProjectOutput{Body: newProjectBody(project)}, nil
+}
+
+func (h *ProjectHandler) updateProject(ctx context.Context, input *UpdateProjectInput) (*ProjectOutput, error) {
+	project, err := h.uc.UpdateProject(ctx, input.ID, input.Body.Name, input.Body.Description)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &ProjectOutput{Body: newProjectBody(project)}, nil
+}
+
+func (h *ProjectHandler) deleteProject(ctx context.Context, input *DeleteProjectInput) (*struct{}, error) {
+	if err := h.uc.DeleteProject(ctx, input.ID); err != nil {
+		return nil, mapDomainError(err)
+	}
+	return nil, nil
+}
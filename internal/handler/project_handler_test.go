@@ -0,0 +1,133 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateProject_Handler(t *testing.T) {
+	api, _, projects := setupProjectAPI(t)
+	projects.On("Create", mock.Anything, mock.AnythingOfType("*domain.Project")).Return(nil).Once()
+
+	resp := api.Post("/projects", map[string]string{"name": "Website", "description": "relaunch"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.ProjectBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "Website", body.Name)
+	assert.Equal(t, "relaunch", body.Description)
+
+	projects.On("Create", mock.Anything, mock.AnythingOfType("*domain.Project")).Return(domain.ErrAlreadyExists)
+	resp = api.Post("/projects", map[string]string{"name": "Website"})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestListProjects_Handler(t *testing.T) {
+	api, _, projects := setupProjectAPI(t)
+	projects.On("List", mock.Anything).Return([]domain.Project{{ID: uuid.New(), Name: "App"}, {ID: uuid.New(), Name: "Website"}}, nil)
+
+	resp := api.Get("/projects")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		Items []handler.ProjectBody `json:"items"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Items, 2)
+	assert.Equal(t, "App", body.Items[0].Name)
+}
+
+func TestUpdateProject_Handler(t *testing.T) {
+	api, _, projects := setupProjectAPI(t)
+	id := uuid.New()
+	projects.On("GetByID", mock.Anything, id).Return(&domain.Project{ID: id, Name: "Website"}, nil)
+	projects.On("Update", mock.Anything, mock.AnythingOfType("*domain.Project")).Return(nil)
+
+	resp := api.Put("/projects/"+id.String(), map[string]string{"name": "Web shop"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.ProjectBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "Web shop", body.Name)
+}
+
+func TestDeleteProject_Handler(t *testing.T) {
+	api, _, projects := setupProjectAPI(t)
+	id := uuid.New()
+	projects.On("Delete", mock.Anything, id).Return(nil)
+
+	resp := api.Delete("/projects/" + id.String())
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	missing := uuid.New()
+	projects.On("Delete", mock.Anything, missing).Return(domain.ErrNotFound)
+	resp = api.Delete("/projects/" + missing.String())
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestListProjectTodos_Handler(t *testing.T) {
+	api, repo, projects := setupProjectAPI(t)
+	project := &domain.Project{ID: uuid.New(), Name: "Website"}
+	projects.On("GetByID", mock.Anything, project.ID).Return(project, nil)
+	repo.On("List", mock.Anything, mock.MatchedBy(func(f domain.TodoFilter) bool {
+		return f.ProjectID != nil && *f.ProjectID == project.ID
+	}), mock.Anything).Return([]domain.Todo{{ID: uuid.New(), Title: "Launch", ProjectID: &project.ID}}, nil)
+
+	resp := api.Get("/projects/" + project.ID.String() + "/todos")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		Items []handler.TodoBody `json:"items"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Items, 1)
+	assert.Equal(t, &project.ID, body.Items[0].ProjectID)
+
+	missing := uuid.New()
+	projects.On("GetByID", mock.Anything, missing).Return(nil, domain.ErrNotFound)
+	resp = api.Get("/projects/" + missing.String() + "/todos")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestCompleteProjectTodos_Handler(t *testing.T) {
+	api, repo, projects := setupProjectAPI(t)
+	project := &domain.Project{ID: uuid.New(), Name: "Website"}
+	projects.On("GetByID", mock.Anything, project.ID).Return(project, nil)
+	repo.On("CompleteAll", mock.Anything, &project.ID, (*uuid.UUID)(nil)).Return(int64(3), nil)
+
+	resp := api.Post("/projects/" + project.ID.String() + "/complete-all")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		Count int64 `json:"count"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, int64(3), body.Count)
+}
+
+func TestMoveTodoToProject_Handler(t *testing.T) {
+	api, repo, projects := setupProjectAPI(t)
+	project := &domain.Project{ID: uuid.New(), Name: "Website"}
+	projects.On("GetByID", mock.Anything, project.ID).Return(project, nil)
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Launch", Version: 1}, nil)
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(td *domain.Todo) bool {
+		return td.ProjectID != nil && *td.ProjectID == project.ID
+	})).Return(nil)
+
+	resp := api.Patch("/todos/"+id.String(), "Content-Type: application/merge-patch+json",
+		map[string]any{"projectId": project.ID})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TodoBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, &project.ID, body.ProjectID)
+}
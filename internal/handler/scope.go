@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/knjname/go-todo-api/internal/auth"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// requireScope returns the operation middlewares that refuse callers
+// without scope with a 403. Anonymous callers and callers whose
+// credentials are not limited by scopes pass.
+func requireScope(api huma.API, scope domain.Scope) huma.Middlewares {
+	return huma.Middlewares{func(ctx huma.Context, next func(huma.Context)) {
+		if p, ok := auth.PrincipalFrom(ctx.Context()); ok && !p.HasScope(scope) {
+			_ = huma.WriteErr(api, ctx, http.StatusForbidden, "the "+string(scope)+" scope is required")
+			return
+		}
+		next(ctx)
+	}}
+}
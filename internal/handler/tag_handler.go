@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+)
+
+type TagHandler struct {
+	uc *usecase.TagUseCase
+}
+
+func NewTagHandler(uc *usecase.TagUseCase) *TagHandler {
+	return &TagHandler{uc: uc}
+}
+
+// --- Input/Output types ---
+
+type TagBody struct {
+	ID        uuid.UUID `json:"id" doc:"タグID"`
+	Name      string    `json:"name" doc:"タグ名 (小文字)"`
+	CreatedAt time.Time `json:"createdAt" doc:"作成日時"`
+}
+
+func newTagBody(t *domain.Tag) TagBody {
+	return TagBody{ID: t.ID, Name: t.Name, CreatedAt: t.CreatedAt}
+}
+
+type CreateTagInput struct {
+	Body struct {
+		Name string `json:"name" maxLength:"50" minLength:"1" doc:"タグ名"`
+	}
+}
+
+type TagOutput struct {
+	Body TagBody
+}
+
+type GetTagInput struct {
+	ID uuid.UUID `path:"id" doc:"タグID"`
+}
+
+type ListTagsOutput struct {
+	Body struct {
+		Items []TagBody `json:"items" doc:"タグ一覧 (名前順)"`
+	}
+}
+
+type RenameTagInput struct {
+	ID   uuid.UUID `path:"id" doc:"タグID"`
+	Body struct {
+		Name string `json:"name" maxLength:"50" minLength:"1" doc:"新しいタグ名"`
+	}
+}
+
+type DeleteTagInput struct {
+	ID uuid.UUID `path:"id" doc:"タグID"`
+}
+
+// Register registers all tag routes on the huma API.
+func (h *TagHandler) Register(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "create-tag",
+		Middlewares: requireScope(api, domain.ScopeWrite),
+		Method:      http.MethodPost,
+		Path:        "/tags",
+		Summary:     "Create a new tag",
+		Tags:        []string{"Tags"},
+	}, h.createTag)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-tags",
+		Middlewares: requireScope(api, domain.ScopeRead),
+		Method:      http.MethodGet,
+		Path:        "/tags",
+		Summary:     "List all tags",
+		Tags:        []string{"Tags"},
+	}, h.listTags)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-tag",
+		Middlewares: requireScope(api, domain.ScopeRead),
+		Method:      http.MethodGet,
+		Path:        "/tags/{id}",
+		Summary:     "Get a tag by ID",
+		Tags:        []string{"Tags"},
+	}, h.getTag)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "rename-tag",
+		Middlewares: requireScope(api, domain.ScopeWrite),
+		Method:      http.MethodPut,
+		Path:        "/tags/{id}",
+		Summary:     "Rename a tag",
+		Tags:        []string{"Tags"},
+	}, h.renameTag)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "delete-tag",
+		Middlewares: requireScope(api, domain.ScopeAdmin),
+		Method:      http.MethodDelete,
+		Path:        "/tags/{id}",
+		Summary:     "Delete a tag",
+		Description: "Deletes the tag and removes it from every todo.",
+		Tags:        []string{"Tags"},
+	}, h.deleteTag)
+}
+
+func (h *TagHandler) createTag(ctx context.Context, input *CreateTagInput) (*TagOutput, error) {
+	tag, err := h.uc.CreateTag(ctx, input.Body.Name)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &TagOutput{Body: newTagBody(tag)}, nil
+}
+
+func (h *TagHandler) listTags(ctx context.Context, _ *struct{}) (*ListTagsOutput, error) {
+	tags, err := h.uc.ListTags(ctx)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	out := &ListTagsOutput{}
+	out.Body.Items = make([]TagBody, len(tags))
+	for i := range tags {
+		out.Body.Items[i] = newTagBody(&tags[i])
+	}
+	return out, nil
+}
+
+func (h *TagHandler) getTag(ctx context.Context, input *GetTagInput) (*TagOutput, error) {
+	tag, err := h.uc.GetTag(ctx, input.ID)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &TagOutput{Body: newTagBody(tag)}, nil
+}
+
+func (h *TagHandler) renameTag(ctx context.Context, input *RenameTagInput) (*TagOutput, error) {
+	tag, err := h.uc.RenameTag(ctx, input.ID, input.Body.Name)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &TagOutput{Body: newTagBody(tag)}, nil
+}
+
+func (h *TagHandler) deleteTag(ctx context.Context, input *DeleteTagInput) (*struct{}, error) {
+	if err := h.uc.DeleteTag(ctx, input.ID); err != nil {
+		return nil, mapDomainError(err)
+	}
+	return nil, nil
+}
@@ -0,0 +1,86 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/handler"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTagAPI(t *testing.T) (humatest.TestAPI, *mocks.TagRepository) {
+	t.Helper()
+	repo := mocks.NewTagRepository(t)
+	uc := usecase.NewTagUseCase(repo, slog.New(slog.DiscardHandler))
+	_, api := humatest.New(t)
+	handler.NewTagHandler(uc).Register(api)
+	return api, repo
+}
+
+func TestCreateTag_Handler(t *testing.T) {
+	api, repo := setupTagAPI(t)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Tag")).Return(nil).Once()
+
+	resp := api.Post("/tags", map[string]string{"name": "Backend"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TagBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "backend", body.Name)
+
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Tag")).Return(domain.ErrAlreadyExists)
+	resp = api.Post("/tags", map[string]string{"name": "backend"})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestListTags_Handler(t *testing.T) {
+	api, repo := setupTagAPI(t)
+	repo.On("List", mock.Anything).Return([]domain.Tag{{ID: uuid.New(), Name: "api"}, {ID: uuid.New(), Name: "backend"}}, nil)
+
+	resp := api.Get("/tags")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		Items []handler.TagBody `json:"items"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Items, 2)
+	assert.Equal(t, "api", body.Items[0].Name)
+}
+
+func TestRenameTag_Handler(t *testing.T) {
+	api, repo := setupTagAPI(t)
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Tag{ID: id, Name: "backend"}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Tag")).Return(nil)
+
+	resp := api.Put("/tags/"+id.String(), map[string]string{"name": "server"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TagBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "server", body.Name)
+}
+
+func TestDeleteTag_Handler(t *testing.T) {
+	api, repo := setupTagAPI(t)
+	id := uuid.New()
+	repo.On("Delete", mock.Anything, id).Return(nil)
+
+	resp := api.Delete("/tags/" + id.String())
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	missing := uuid.New()
+	repo.On("Delete", mock.Anything, missing).Return(domain.ErrNotFound)
+	resp = api.Delete("/tags/" + missing.String())
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
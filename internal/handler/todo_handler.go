@@ -2,41 +2,117 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
 	"github.com/knjname/go-todo-api/internal/usecase"
 )
 
 type TodoHandler struct {
-	uc *usecase.TodoUseCase
+	uc          *usecase.TodoUseCase
+	idempotency *usecase.IdempotencyUseCase
 }
 
-func NewTodoHandler(uc *usecase.TodoUseCase) *TodoHandler {
-	return &TodoHandler{uc: uc}
+func NewTodoHandler(uc *usecase.TodoUseCase, idempotency *usecase.IdempotencyUseCase) *TodoHandler {
+	return &TodoHandler{uc: uc, idempotency: idempotency}
 }
 
 // --- Input/Output types ---
 
 type TodoBody struct {
-	ID          uuid.UUID `json:"id" doc:"Todo ID"`
-	Title       string    `json:"title" doc:"Todoタイトル"`
-	Description string    `json:"description" doc:"詳細説明"`
-	Completed   bool      `json:"completed" doc:"完了フラグ"`
-	CreatedAt   time.Time `json:"createdAt" doc:"作成日時"`
-	UpdatedAt   time.Time `json:"updatedAt" doc:"更新日時"`
+	ID          uuid.UUID  `json:"id" doc:"Todo ID"`
+	Title       string     `json:"title" doc:"Todoタイトル"`
+	Description string     `json:"description" doc:"詳細説明"`
+	Status      string     `json:"status" enum:"open,in_progress,done,cancelled" doc:"ステータス"`
+	Completed   bool       `json:"completed" doc:"完了フラグ (status が done のとき true)"`
+	Priority    string     `json:"priority" enum:"none,low,medium,high,urgent" doc:"優先度"`
+	CreatedAt   time.Time  `json:"createdAt" doc:"作成日時"`
+	UpdatedAt   time.Time  `json:"updatedAt" doc:"更新日時"`
+	DueDate     string     `json:"dueDate,omitempty" format:"date" doc:"期限日 (YYYY-MM-DD)"`
+	DueTime     string     `json:"dueTime,omitempty" doc:"期限時刻 (HH:MM)。省略時は終日"`
+	DueTimeZone string     `json:"dueTimeZone,omitempty" doc:"期限のタイムゾーン (IANA)"`
+	Overdue     bool       `json:"overdue" doc:"期限切れフラグ"`
+	Version     int64      `json:"version" doc:"バージョン (ETag と同じ値)"`
+	Tags        []string   `json:"tags" doc:"タグ名 (名前順)"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty" doc:"ゴミ箱へ移動した日時。ゴミ箱内のTodoのみ"`
+	ParentID    *uuid.UUID `json:"parentId,omitempty" doc:"親TodoのID。サブタスクのみ"`
+	ProjectID   *uuid.UUID `json:"projectId,omitempty" doc:"所属プロジェクトのID。プロジェクトに属するTodoのみ"`
+	CreatedBy   *uuid.UUID `json:"createdBy,omitempty" doc:"作成したユーザー (所有者) のID。ユーザー以外が作成した場合は省略"`
+	UpdatedBy   *uuid.UUID `json:"updatedBy,omitempty" doc:"最後に更新したユーザーのID。ユーザー以外が更新した場合は省略"`
+	AssigneeID  *uuid.UUID `json:"assigneeId,omitempty" doc:"担当者のユーザーID。未割り当ての場合は省略"`
+	Rank        string     `json:"rank" doc:"ボード上の並び順を決めるキー (sort=rank で並ぶ)。ボードでの並べ替えで変わる"`
+
+	RecurrenceRule     string     `json:"recurrenceRule,omitempty" doc:"繰り返しルール (RFC 5545 RRULE)。繰り返しTodoのみ"`
+	RecurrenceTimeZone string     `json:"recurrenceTimeZone,omitempty" doc:"繰り返しルールを評価するタイムゾーン (IANA)"`
+	SeriesID           *uuid.UUID `json:"seriesId,omitempty" doc:"繰り返しTodoの系列ID。同じ繰り返しから生成されたTodoで共通"`
+}
+
+func newTodoBody(t *domain.Todo) TodoBody {
+	body := TodoBody{
+		ID: t.ID, Title: t.Title, Description: t.Description,
+		Status: string(t.Status), Completed: t.IsCompleted(), Priority: t.Priority.String(), CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt,
+		Overdue: t.IsOverdue(time.Now()), Version: t.Version, Tags: t.Tags, DeletedAt: t.DeletedAt,
+		ParentID: t.ParentID, ProjectID: t.ProjectID, SeriesID: t.SeriesID,
+		CreatedBy: t.CreatedBy, UpdatedBy: t.UpdatedBy, AssigneeID: t.AssigneeID, Rank: t.Rank,
+	}
+	if body.Tags == nil {
+		body.Tags = []string{}
+	}
+	if t.Recurrence != nil {
+		body.RecurrenceRule = t.Recurrence.Rule
+		body.RecurrenceTimeZone = t.Recurrence.TimeZone
+	}
+	if t.Due != nil {
+		body.DueDate = t.Due.Date()
+		body.DueTime = t.Due.Clock()
+		body.DueTimeZone = t.Due.TimeZone
+	}
+	return body
 }
 
 type CreateTodoInput struct {
+	IdempotencyKeyInput
 	Body struct {
-		Title       string `json:"title" maxLength:"200" minLength:"1" doc:"Todoタイトル"`
-		Description string `json:"description" doc:"詳細説明"`
+		Title       string    `json:"title" maxLength:"200" minLength:"1" doc:"Todoタイトル"`
+		Description string    `json:"description" doc:"詳細説明"`
+		DueDate     string    `json:"dueDate,omitempty" format:"date" doc:"期限日 (YYYY-MM-DD)"`
+		DueTime     string    `json:"dueTime,omitempty" pattern:"^[0-2][0-9]:[0-5][0-9]$" doc:"期限時刻 (HH:MM)"`
+		DueTimeZone string    `json:"dueTimeZone,omitempty" doc:"期限のタイムゾーン (IANA, 既定は UTC)"`
+		Priority    string    `json:"priority,omitempty" enum:"none,low,medium,high,urgent" doc:"優先度 (既定は none)"`
+		Tags        []string  `json:"tags,omitempty" doc:"タグ名。未登録のタグは自動で作成される"`
+		ParentID    uuid.UUID `json:"parentId,omitempty" doc:"親TodoのID。指定するとサブタスクとして作成する"`
+		ProjectID   uuid.UUID `json:"projectId,omitempty" doc:"所属プロジェクトのID。省略時、サブタスクは親のプロジェクトに属する"`
+
+		RecurrenceRule     string `json:"recurrenceRule,omitempty" example:"FREQ=WEEKLY;BYDAY=MO" doc:"繰り返しルール (RFC 5545 RRULE)。期限日が必要。完了すると次回分が作成される"`
+		RecurrenceTimeZone string `json:"recurrenceTimeZone,omitempty" doc:"繰り返しルールを評価するタイムゾーン (IANA, 既定は期限のタイムゾーン)"`
+	}
+}
+
+func (input *CreateTodoInput) params() usecase.CreateTodoParams {
+	return usecase.CreateTodoParams{
+		Title:       input.Body.Title,
+		Description: input.Body.Description,
+		DueDate:     input.Body.DueDate,
+		DueTime:     input.Body.DueTime,
+		DueTimeZone: input.Body.DueTimeZone,
+		Priority:    input.Body.Priority,
+		Tags:        input.Body.Tags,
+		ParentID:    input.Body.ParentID,
+		ProjectID:   input.Body.ProjectID,
+
+		RecurrenceRule:     input.Body.RecurrenceRule,
+		RecurrenceTimeZone: input.Body.RecurrenceTimeZone,
 	}
 }
 
 type CreateTodoOutput struct {
+	ETag string `header:"ETag" doc:"Todoのバージョンを表すエンティティタグ"`
 	Body TodoBody
 }
 
@@ -45,40 +121,206 @@ type GetTodoInput struct {
 }
 
 type GetTodoOutput struct {
+	ETag string `header:"ETag" doc:"Todoのバージョンを表すエンティティタグ"`
 	Body TodoBody
 }
 
+type ListTodosInput struct {
+	Status        []string  `query:"status" enum:"open,in_progress,done,cancelled" doc:"ステータスで絞り込む (カンマ区切りで複数指定可)"`
+	Completed     string    `query:"completed" enum:"true,false" doc:"完了状態で絞り込む。status と同時には指定できない"`
+	Title         string    `query:"title" doc:"タイトルに含まれる文字列 (大文字小文字を区別しない)"`
+	Description   string    `query:"description" doc:"詳細説明に含まれる文字列 (大文字小文字を区別しない)"`
+	CreatedAfter  time.Time `query:"created_after" doc:"作成日時がこの日時以降 (RFC 3339)"`
+	CreatedBefore time.Time `query:"created_before" doc:"作成日時がこの日時より前 (RFC 3339)"`
+	UpdatedAfter  time.Time `query:"updated_after" doc:"更新日時がこの日時以降 (RFC 3339)"`
+	UpdatedBefore time.Time `query:"updated_before" doc:"更新日時がこの日時より前 (RFC 3339)"`
+	DueBefore     time.Time `query:"due_before" doc:"期限がこの日時より前のTodoに絞り込む (RFC 3339)"`
+	DueAfter      time.Time `query:"due_after" doc:"期限がこの日時以降のTodoに絞り込む (RFC 3339)"`
+	Overdue       bool      `query:"overdue" doc:"期限切れの未完了Todoのみ返す"`
+	Tag           []string  `query:"tag,explode" doc:"タグで絞り込む (tag=a&tag=b のように複数指定可)"`
+	TagMatch      string    `query:"tag_match" enum:"all,any" default:"all" doc:"複数タグの結合方法。all はすべてのタグ、any はいずれかのタグを持つTodo"`
+	Assignee      string    `query:"assignee" example:"me" doc:"担当者で絞り込む。ユーザーID、または me で X-User-ID のユーザー"`
+	PageParams
+}
+
+// PageParams are the sort and keyset pagination parameters shared by the
+// todo listings.
+type PageParams struct {
+	Sort   string `query:"sort" example:"-due,title" doc:"並び順。カンマ区切りのフィールド名、先頭に - で降順 (created_at, updated_at, title, due, priority, rank)。既定は -priority,due,-created_at"`
+	Cursor string `query:"cursor" doc:"前ページの next カーソル"`
+	Limit  int    `query:"limit" minimum:"1" maximum:"200" default:"50" doc:"1ページの最大件数"`
+
+	url url.URL
+}
+
+// Resolve keeps the request URL so the next page link can reuse its query.
+func (p *PageParams) Resolve(ctx huma.Context) []error {
+	p.url = ctx.URL()
+	return nil
+}
+
+// nextLink returns the RFC 8288 Link header value pointing at the next page.
+func (p *PageParams) nextLink(next string) string {
+	u := url.URL{Path: p.url.Path}
+	q := p.url.Query()
+	q.Set("cursor", next)
+	u.RawQuery = q.Encode()
+	return fmt.Sprintf(`<%s>; rel="next"`, u.String())
+}
+
+type ListTrashInput struct {
+	PageParams
+}
+
+type ListChildrenInput struct {
+	ID uuid.UUID `path:"id" doc:"親Todo ID"`
+	PageParams
+}
+
+type ListProjectTodosInput struct {
+	ID uuid.UUID `path:"id" doc:"プロジェクトID"`
+	PageParams
+}
+
 type ListTodosOutput struct {
-	Body []TodoBody
+	Link string `header:"Link" doc:"次ページへのリンク (RFC 8288)"`
+	Body struct {
+		Items []TodoBody `json:"items" doc:"Todo一覧"`
+		Next  string     `json:"next,omitempty" doc:"次ページのカーソル。最終ページでは省略"`
+	}
 }
 
-type UpdateTodoInput struct {
-	ID   uuid.UUID `path:"id" doc:"Todo ID"`
+type SearchTodosInput struct {
+	Query     string    `query:"q" required:"true" minLength:"1" maxLength:"200" example:"週次 会議" doc:"検索語。タイトルと詳細説明から探す"`
+	Mode      string    `query:"mode" enum:"auto,simple,ngram" default:"auto" doc:"一致方法。simple は単語単位 (\"フレーズ\" / or / -除外 が使える)、ngram は空白区切りの各語を部分一致 (日本語向け)、auto は検索語に日本語などを含むと ngram"`
+	Status    []string  `query:"status" enum:"open,in_progress,done,cancelled" doc:"ステータスで絞り込む (カンマ区切りで複数指定可)"`
+	Tag       []string  `query:"tag,explode" doc:"タグで絞り込む (tag=a&tag=b のように複数指定可)"`
+	TagMatch  string    `query:"tag_match" enum:"all,any" default:"all" doc:"複数タグの結合方法。all はすべてのタグ、any はいずれかのタグを持つTodo"`
+	ProjectID uuid.UUID `query:"project_id" doc:"プロジェクトで絞り込む"`
+	Limit     int       `query:"limit" minimum:"1" maximum:"200" default:"20" doc:"最大件数"`
+}
+
+type SearchHitBody struct {
+	Todo    TodoBody `json:"todo" doc:"一致したTodo"`
+	Rank    float64  `json:"rank" doc:"一致度。大きいほどよく一致する (同じ検索の結果の間でのみ比較できる)"`
+	Title   string   `json:"title" doc:"一致箇所を <mark> で囲んだタイトル (HTML エスケープ済み)"`
+	Snippet string   `json:"snippet,omitempty" doc:"詳細説明の最初の一致箇所の前後を抜粋し、一致箇所を <mark> で囲んだもの (HTML エスケープ済み)。詳細説明が一致しない場合は省略"`
+}
+
+type SearchTodosOutput struct {
 	Body struct {
-		Title       string `json:"title" maxLength:"200" minLength:"1" doc:"Todoタイトル"`
-		Description string `json:"description" doc:"詳細説明"`
+		Items []SearchHitBody `json:"items" doc:"一致度の高い順の検索結果"`
+	}
+}
+
+type UpdateTodoInput struct {
+	ID      uuid.UUID `path:"id" doc:"Todo ID"`
+	IfMatch string    `header:"If-Match" doc:"このETagと現在のバージョンが一致する場合のみ実行する"`
+	Body    struct {
+		Title       string    `json:"title" maxLength:"200" minLength:"1" doc:"Todoタイトル"`
+		Description string    `json:"description" doc:"詳細説明"`
+		DueDate     string    `json:"dueDate,omitempty" format:"date" doc:"期限日 (YYYY-MM-DD)。省略時は期限なし"`
+		DueTime     string    `json:"dueTime,omitempty" pattern:"^[0-2][0-9]:[0-5][0-9]$" doc:"期限時刻 (HH:MM)"`
+		DueTimeZone string    `json:"dueTimeZone,omitempty" doc:"期限のタイムゾーン (IANA, 既定は UTC)"`
+		Priority    string    `json:"priority,omitempty" enum:"none,low,medium,high,urgent" doc:"優先度。省略時は none"`
+		Tags        []string  `json:"tags,omitempty" doc:"タグ名。省略時はタグなし。未登録のタグは自動で作成される"`
+		ParentID    uuid.UUID `json:"parentId,omitempty" doc:"親TodoのID。省略時はトップレベルのTodoになる"`
+		ProjectID   uuid.UUID `json:"projectId,omitempty" doc:"所属プロジェクトのID。省略時はどのプロジェクトにも属さない"`
+
+		RecurrenceRule     string `json:"recurrenceRule,omitempty" example:"FREQ=WEEKLY;BYDAY=MO" doc:"繰り返しルール (RFC 5545 RRULE)。省略時は繰り返しなし"`
+		RecurrenceTimeZone string `json:"recurrenceTimeZone,omitempty" doc:"繰り返しルールを評価するタイムゾーン (IANA, 既定は期限のタイムゾーン)"`
 	}
 }
 
 type UpdateTodoOutput struct {
+	ETag string `header:"ETag" doc:"Todoのバージョンを表すエンティティタグ"`
+	Body TodoBody
+}
+
+type RestoreTodoInput struct {
+	ID      uuid.UUID `path:"id" doc:"Todo ID"`
+	IfMatch string    `header:"If-Match" doc:"このETagと現在のバージョンが一致する場合のみ実行する"`
+}
+
+type RestoreTodoOutput struct {
+	ETag string `header:"ETag" doc:"Todoのバージョンを表すエンティティタグ"`
 	Body TodoBody
 }
 
 type DeleteTodoInput struct {
-	ID uuid.UUID `path:"id" doc:"Todo ID"`
+	ID      uuid.UUID `path:"id" doc:"Todo ID"`
+	IfMatch string    `header:"If-Match" doc:"このETagと現在のバージョンが一致する場合のみ実行する"`
+	Cascade bool      `query:"cascade" doc:"true の場合サブタスクもゴミ箱へ移動する。false の場合直下のサブタスクはトップレベルのTodoになる"`
+}
+
+// TransitionTodoInput is the input of the status change operations
+// (complete, start, cancel, reopen).
+type TransitionTodoInput struct {
+	ID      uuid.UUID `path:"id" doc:"Todo ID"`
+	IfMatch string    `header:"If-Match" doc:"このETagと現在のバージョンが一致する場合のみ実行する"`
 }
 
 type CompleteTodoInput struct {
-	ID uuid.UUID `path:"id" doc:"Todo ID"`
+	TransitionTodoInput
+	IdempotencyKeyInput
+	Cascade bool `query:"cascade" doc:"true の場合未完了のサブタスクもまとめて完了にする。false の場合未完了のサブタスクがあると 409"`
 }
 
-type CompleteTodoOutput struct {
+type TransitionTodoOutput struct {
+	ETag string `header:"ETag" doc:"Todoのバージョンを表すエンティティタグ"`
 	Body TodoBody
 }
 
+type AssignTodoInput struct {
+	ID      uuid.UUID `path:"id" doc:"Todo ID"`
+	IfMatch string    `header:"If-Match" doc:"このETagと現在のバージョンが一致する場合のみ実行する"`
+	Body    struct {
+		AssigneeID uuid.UUID `json:"assigneeId,omitempty" doc:"担当者のユーザーID。省略時は担当を外す"`
+	}
+}
+
+type CompleteProjectTodosInput struct {
+	ID uuid.UUID `path:"id" doc:"プロジェクトID"`
+}
+
+type CompleteAllTodosInput struct {
+	IdempotencyKeyInput
+}
+
+type BulkTodosInput struct {
+	IdempotencyKeyInput
+	Body struct {
+		Action     string      `json:"action" enum:"complete,delete,retag,move" doc:"対象のTodoに適用する操作。complete は完了、delete はゴミ箱へ移動、retag はタグの追加・削除、move はプロジェクトの移動"`
+		IDs        []uuid.UUID `json:"ids" minItems:"1" maxItems:"100" uniqueItems:"true" doc:"対象のTodoのID (100 件まで)"`
+		Mode       string      `json:"mode,omitempty" enum:"atomic,best_effort" default:"atomic" doc:"atomic はすべてのTodoに適用するか、1 件でも失敗すればどれにも適用しない。best_effort はTodoごとに適用する"`
+		Cascade    bool        `json:"cascade,omitempty" doc:"complete / delete で、未完了のサブタスクもまとめて完了にする / ゴミ箱へ移動する"`
+		AddTags    []string    `json:"addTags,omitempty" doc:"retag で追加するタグ名。未登録のタグは自動で作成される"`
+		RemoveTags []string    `json:"removeTags,omitempty" doc:"retag で外すタグ名"`
+		ProjectID  uuid.UUID   `json:"projectId,omitempty" doc:"move の移動先のプロジェクトID。省略時はプロジェクトから外す"`
+	}
+}
+
+// BulkResultBody is the outcome of a bulk action for one todo, with the
+// status and details the single todo operation would respond with.
+type BulkResultBody struct {
+	ID     uuid.UUID `json:"id" doc:"Todo ID"`
+	Status int       `json:"status" doc:"このTodoの結果の HTTP ステータス。成功は 200、atomic で他のTodoが失敗したため適用しなかった場合は 424"`
+	Todo   *TodoBody `json:"todo,omitempty" doc:"適用後のTodo。成功時のみ (delete ではゴミ箱内のTodo)"`
+	Detail string    `json:"detail,omitempty" doc:"失敗の理由"`
+	Errors []string  `json:"errors,omitempty" doc:"失敗の詳細"`
+}
+
+type BulkTodosOutput struct {
+	Body struct {
+		Succeeded int              `json:"succeeded" doc:"適用したTodo数"`
+		Failed    int              `json:"failed" doc:"適用しなかったTodo数"`
+		Results   []BulkResultBody `json:"results" doc:"Todoごとの結果 (ids と同じ順)"`
+	}
+}
+
 type CompleteAllOutput struct {
 	Body struct {
-		Count int64 `json:"count" doc:"完了にしたTodo数"`
+		Count int64 `json:"count" doc:"完了にしたTodo数 (open / in_progress のTodoのみ対象)"`
 	}
 }
 
@@ -86,22 +328,53 @@ type CompleteAllOutput struct {
 func (h *TodoHandler) Register(api huma.API) {
 	huma.Register(api, huma.Operation{
 		OperationID: "create-todo",
+		Middlewares: append(requireScope(api, domain.ScopeWrite), idempotent(api, h.idempotency)...),
 		Method:      http.MethodPost,
 		Path:        "/todos",
 		Summary:     "Create a new todo",
 		Tags:        []string{"Todos"},
 	}, h.createTodo)
 
+	// Registered ahead of /todos/{id} for routers that match in order.
+	huma.Register(api, huma.Operation{
+		OperationID: "list-trashed-todos",
+		Middlewares: requireScope(api, domain.ScopeRead),
+		Method:      http.MethodGet,
+		Path:        "/todos/trash",
+		Summary:     "List todos in the trash",
+		Tags:        []string{"Todos"},
+	}, h.listTrash)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "search-todos",
+		Middlewares: requireScope(api, domain.ScopeRead),
+		Method:      http.MethodGet,
+		Path:        "/todos/search",
+		Summary:     "Search the titles and descriptions of todos",
+		Tags:        []string{"Todos"},
+	}, h.searchTodos)
+
 	huma.Register(api, huma.Operation{
 		OperationID: "get-todo",
+		Middlewares: requireScope(api, domain.ScopeRead),
 		Method:      http.MethodGet,
 		Path:        "/todos/{id}",
 		Summary:     "Get a todo by ID",
 		Tags:        []string{"Todos"},
 	}, h.getTodo)
 
+	huma.Register(api, huma.Operation{
+		OperationID: "list-todo-children",
+		Middlewares: requireScope(api, domain.ScopeRead),
+		Method:      http.MethodGet,
+		Path:        "/todos/{id}/children",
+		Summary:     "List the subtasks of a todo",
+		Tags:        []string{"Todos"},
+	}, h.listChildren)
+
 	huma.Register(api, huma.Operation{
 		OperationID: "list-todos",
+		Middlewares: requireScope(api, domain.ScopeRead),
 		Method:      http.MethodGet,
 		Path:        "/todos",
 		Summary:     "List all todos",
@@ -110,46 +383,143 @@ func (h *TodoHandler) Register(api huma.API) {
 
 	huma.Register(api, huma.Operation{
 		OperationID: "update-todo",
+		Middlewares: requireScope(api, domain.ScopeWrite),
 		Method:      http.MethodPut,
 		Path:        "/todos/{id}",
 		Summary:     "Update a todo",
 		Tags:        []string{"Todos"},
 	}, h.updateTodo)
 
+	huma.Register(api, huma.Operation{
+		OperationID: "patch-todo",
+		Middlewares: requireScope(api, domain.ScopeWrite),
+		Method:      http.MethodPatch,
+		Path:        "/todos/{id}",
+		Summary:     "Partially update a todo",
+		Description: "Accepts an RFC 7396 merge patch (application/merge-patch+json) " +
+			"or an RFC 6902 JSON patch (application/json-patch+json) with add, replace and remove operations.",
+		Tags: []string{"Todos"},
+	}, h.patchTodo)
+	documentPatchBody(api, api.OpenAPI().Paths["/todos/{id}"].Patch)
+
 	huma.Register(api, huma.Operation{
 		OperationID: "delete-todo",
+		Middlewares: requireScope(api, domain.ScopeWrite),
 		Method:      http.MethodDelete,
 		Path:        "/todos/{id}",
 		Summary:     "Delete a todo",
-		Tags:        []string{"Todos"},
+		Description: "Moves the todo to the trash. It can be restored until the batch purge removes it. " +
+			"With cascade=true its subtasks are moved to the trash too; otherwise its direct subtasks become top-level todos.",
+		Tags: []string{"Todos"},
 	}, h.deleteTodo)
 
+	huma.Register(api, huma.Operation{
+		OperationID: "restore-todo",
+		Middlewares: requireScope(api, domain.ScopeWrite),
+		Method:      http.MethodPost,
+		Path:        "/todos/{id}/restore",
+		Summary:     "Restore a todo from the trash",
+		Tags:        []string{"Todos"},
+	}, h.restoreTodo)
+
 	huma.Register(api, huma.Operation{
 		OperationID: "complete-todo",
+		Middlewares: append(requireScope(api, domain.ScopeWrite), idempotent(api, h.idempotency)...),
 		Method:      http.MethodPost,
 		Path:        "/todos/{id}/complete",
 		Summary:     "Mark a todo as complete",
-		Tags:        []string{"Todos"},
+		Description: "Moves an open or in-progress todo to done. " +
+			"A todo with open subtasks is only completed with cascade=true, which completes the subtasks as well. " +
+			"Completing a recurring todo creates its next occurrence.",
+		Tags: []string{"Todos"},
 	}, h.completeTodo)
 
+	huma.Register(api, huma.Operation{
+		OperationID: "start-todo",
+		Middlewares: requireScope(api, domain.ScopeWrite),
+		Method:      http.MethodPost,
+		Path:        "/todos/{id}/start",
+		Summary:     "Start working on a todo",
+		Description: "Moves an open todo to in_progress.",
+		Tags:        []string{"Todos"},
+	}, h.startTodo)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "cancel-todo",
+		Middlewares: requireScope(api, domain.ScopeWrite),
+		Method:      http.MethodPost,
+		Path:        "/todos/{id}/cancel",
+		Summary:     "Cancel a todo",
+		Description: "Moves an open or in-progress todo to cancelled.",
+		Tags:        []string{"Todos"},
+	}, h.cancelTodo)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "reopen-todo",
+		Middlewares: requireScope(api, domain.ScopeWrite),
+		Method:      http.MethodPost,
+		Path:        "/todos/{id}/reopen",
+		Summary:     "Reopen a todo",
+		Description: "Moves a done or cancelled todo back to open.",
+		Tags:        []string{"Todos"},
+	}, h.reopenTodo)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "assign-todo",
+		Middlewares: requireScope(api, domain.ScopeWrite),
+		Method:      http.MethodPost,
+		Path:        "/todos/{id}/assign",
+		Summary:     "Assign a todo to a user",
+		Description: "Makes a user of the workspace responsible for the todo, or unassigns it without assigneeId.",
+		Tags:        []string{"Todos"},
+	}, h.assignTodo)
+
 	huma.Register(api, huma.Operation{
 		OperationID: "complete-all-todos",
+		Middlewares: append(requireScope(api, domain.ScopeAdmin), idempotent(api, h.idempotency)...),
 		Method:      http.MethodPost,
 		Path:        "/todos/complete-all",
-		Summary:     "Mark all todos as complete",
+		Summary:     "Mark all active todos as complete",
 		Tags:        []string{"Todos"},
 	}, h.completeAllTodos)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "bulk-todos",
+		Middlewares:   append(requireScope(api, domain.ScopeWrite), idempotent(api, h.idempotency)...),
+		Method:        http.MethodPost,
+		Path:          "/todos/bulk",
+		DefaultStatus: http.StatusMultiStatus,
+		Summary:       "Apply an action to several todos",
+		Description: "Completes, deletes, retags or moves up to 100 todos in one transaction. " +
+			"The response lists the outcome for each todo. In atomic mode, the action applies to every todo or, if it fails for one, to none.",
+		Tags: []string{"Todos"},
+	}, h.bulkTodos)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-project-todos",
+		Middlewares: requireScope(api, domain.ScopeRead),
+		Method:      http.MethodGet,
+		Path:        "/projects/{id}/todos",
+		Summary:     "List the todos of a project",
+		Tags:        []string{"Projects"},
+	}, h.listProjectTodos)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "complete-all-project-todos",
+		Middlewares: requireScope(api, domain.ScopeAdmin),
+		Method:      http.MethodPost,
+		Path:        "/projects/{id}/complete-all",
+		Summary:     "Mark all active todos of a project as complete",
+		Tags:        []string{"Projects"},
+	}, h.completeProjectTodos)
 }
 
 func (h *TodoHandler) createTodo(ctx context.Context, input *CreateTodoInput) (*CreateTodoOutput, error) {
-	todo, err := h.uc.CreateTodo(ctx, input.Body.Title, input.Body.Description)
+	todo, err := h.uc.CreateTodo(ctx, input.params())
 	if err != nil {
 		return nil, mapDomainError(err)
 	}
-	return &CreateTodoOutput{Body: TodoBody{
-		ID: todo.ID, Title: todo.Title, Description: todo.Description,
-		Completed: todo.Completed, CreatedAt: todo.CreatedAt, UpdatedAt: todo.UpdatedAt,
-	}}, nil
+	return &CreateTodoOutput{ETag: todoETag(todo), Body: newTodoBody(todo)}, nil
 }
 
 func (h *TodoHandler) getTodo(ctx context.Context, input *GetTodoInput) (*GetTodoOutput, error) {
@@ -157,58 +527,227 @@ func (h *TodoHandler) getTodo(ctx context.Context, input *GetTodoInput) (*GetTod
 	if err != nil {
 		return nil, mapDomainError(err)
 	}
-	return &GetTodoOutput{Body: TodoBody{
-		ID: todo.ID, Title: todo.Title, Description: todo.Description,
-		Completed: todo.Completed, CreatedAt: todo.CreatedAt, UpdatedAt: todo.UpdatedAt,
-	}}, nil
+	return &GetTodoOutput{ETag: todoETag(todo), Body: newTodoBody(todo)}, nil
 }
 
-func (h *TodoHandler) listTodos(ctx context.Context, _ *struct{}) (*ListTodosOutput, error) {
-	todos, err := h.uc.ListTodos(ctx)
+func (h *TodoHandler) listTodos(ctx context.Context, input *ListTodosInput) (*ListTodosOutput, error) {
+	params := usecase.ListTodosParams{
+		TitleContains:       input.Title,
+		DescriptionContains: input.Description,
+		CreatedAfter:        optionalTime(input.CreatedAfter),
+		CreatedBefore:       optionalTime(input.CreatedBefore),
+		UpdatedAfter:        optionalTime(input.UpdatedAfter),
+		UpdatedBefore:       optionalTime(input.UpdatedBefore),
+		DueAfter:            optionalTime(input.DueAfter),
+		DueBefore:           optionalTime(input.DueBefore),
+		Overdue:             input.Overdue,
+		Tags:                input.Tag,
+		TagMatch:            input.TagMatch,
+		Assignee:            input.Assignee,
+	}
+	switch {
+	case input.Completed != "" && len(input.Status) > 0:
+		return nil, huma.Error422UnprocessableEntity("status and completed cannot be combined")
+	case input.Completed == "true":
+		params.Statuses = []string{string(domain.StatusDone)}
+	case input.Completed == "false":
+		params.Statuses = []string{string(domain.StatusOpen), string(domain.StatusInProgress), string(domain.StatusCancelled)}
+	default:
+		params.Statuses = input.Status
+	}
+	return listPage(ctx, params, &input.PageParams, h.uc.ListTodos)
+}
+
+func (h *TodoHandler) listTrash(ctx context.Context, input *ListTrashInput) (*ListTodosOutput, error) {
+	return listPage(ctx, usecase.ListTodosParams{Trashed: true}, &input.PageParams, h.uc.ListTodos)
+}
+
+func (h *TodoHandler) listChildren(ctx context.Context, input *ListChildrenInput) (*ListTodosOutput, error) {
+	return listPage(ctx, usecase.ListTodosParams{}, &input.PageParams,
+		func(ctx context.Context, params usecase.ListTodosParams) (*usecase.TodoPage, error) {
+			return h.uc.ListChildren(ctx, input.ID, params)
+		})
+}
+
+func (h *TodoHandler) listProjectTodos(ctx context.Context, input *ListProjectTodosInput) (*ListTodosOutput, error) {
+	return listPage(ctx, usecase.ListTodosParams{}, &input.PageParams,
+		func(ctx context.Context, params usecase.ListTodosParams) (*usecase.TodoPage, error) {
+			return h.uc.ListProjectTodos(ctx, input.ID, params)
+		})
+}
+
+func (h *TodoHandler) searchTodos(ctx context.Context, input *SearchTodosInput) (*SearchTodosOutput, error) {
+	hits, err := h.uc.SearchTodos(ctx, usecase.SearchTodosParams{
+		Query:     input.Query,
+		Mode:      input.Mode,
+		Statuses:  input.Status,
+		Tags:      input.Tag,
+		TagMatch:  input.TagMatch,
+		ProjectID: optionalID(input.ProjectID),
+		Limit:     input.Limit,
+	})
 	if err != nil {
 		return nil, mapDomainError(err)
 	}
-	body := make([]TodoBody, len(todos))
-	for i, t := range todos {
-		body[i] = TodoBody{
-			ID: t.ID, Title: t.Title, Description: t.Description,
-			Completed: t.Completed, CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt,
+	out := &SearchTodosOutput{}
+	out.Body.Items = make([]SearchHitBody, len(hits))
+	for i := range hits {
+		out.Body.Items[i] = SearchHitBody{
+			Todo: newTodoBody(&hits[i].Todo), Rank: hits[i].Rank, Title: hits[i].Title, Snippet: hits[i].Snippet,
 		}
 	}
-	return &ListTodosOutput{Body: body}, nil
+	return out, nil
+}
+
+// listPage lists one page of todos matching params with list.
+func listPage(
+	ctx context.Context,
+	params usecase.ListTodosParams,
+	page *PageParams,
+	list func(context.Context, usecase.ListTodosParams) (*usecase.TodoPage, error),
+) (*ListTodosOutput, error) {
+	params.Sort = page.Sort
+	params.Cursor = page.Cursor
+	params.Limit = page.Limit
+
+	result, err := list(ctx, params)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	out := &ListTodosOutput{}
+	out.Body.Items = make([]TodoBody, len(result.Todos))
+	for i := range result.Todos {
+		out.Body.Items[i] = newTodoBody(&result.Todos[i])
+	}
+	if result.Next != "" {
+		out.Body.Next = result.Next
+		out.Link = page.nextLink(result.Next)
+	}
+	return out, nil
 }
 
 func (h *TodoHandler) updateTodo(ctx context.Context, input *UpdateTodoInput) (*UpdateTodoOutput, error) {
-	todo, err := h.uc.UpdateTodo(ctx, input.ID, input.Body.Title, input.Body.Description)
+	versions, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	todo, err := h.uc.UpdateTodo(ctx, input.ID, usecase.UpdateTodoParams{
+		Title:       input.Body.Title,
+		Description: input.Body.Description,
+		DueDate:     input.Body.DueDate,
+		DueTime:     input.Body.DueTime,
+		DueTimeZone: input.Body.DueTimeZone,
+		Priority:    input.Body.Priority,
+		Tags:        input.Body.Tags,
+		ParentID:    input.Body.ParentID,
+		ProjectID:   input.Body.ProjectID,
+
+		RecurrenceRule:     input.Body.RecurrenceRule,
+		RecurrenceTimeZone: input.Body.RecurrenceTimeZone,
+		ExpectedVersions:   versions,
+	})
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &UpdateTodoOutput{ETag: todoETag(todo), Body: newTodoBody(todo)}, nil
+}
+
+func (h *TodoHandler) patchTodo(ctx context.Context, input *PatchTodoInput) (*PatchTodoOutput, error) {
+	patch, err := decodeTodoPatch(input.ContentType, input.RawBody)
+	if err != nil {
+		return nil, err
+	}
+	if patch.ExpectedVersions, err = parseIfMatch(input.IfMatch); err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	todo, err := h.uc.PatchTodo(ctx, input.ID, patch)
 	if err != nil {
 		return nil, mapDomainError(err)
 	}
-	return &UpdateTodoOutput{Body: TodoBody{
-		ID: todo.ID, Title: todo.Title, Description: todo.Description,
-		Completed: todo.Completed, CreatedAt: todo.CreatedAt, UpdatedAt: todo.UpdatedAt,
-	}}, nil
+	return &PatchTodoOutput{ETag: todoETag(todo), Body: newTodoBody(todo)}, nil
 }
 
 func (h *TodoHandler) deleteTodo(ctx context.Context, input *DeleteTodoInput) (*struct{}, error) {
-	if err := h.uc.DeleteTodo(ctx, input.ID); err != nil {
+	versions, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	if err := h.uc.DeleteTodo(ctx, input.ID, versions, input.Cascade); err != nil {
 		return nil, mapDomainError(err)
 	}
 	return nil, nil
 }
 
-func (h *TodoHandler) completeTodo(ctx context.Context, input *CompleteTodoInput) (*CompleteTodoOutput, error) {
-	todo, err := h.uc.CompleteTodo(ctx, input.ID)
+func (h *TodoHandler) restoreTodo(ctx context.Context, input *RestoreTodoInput) (*RestoreTodoOutput, error) {
+	versions, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	todo, err := h.uc.RestoreTodo(ctx, input.ID, versions)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &RestoreTodoOutput{ETag: todoETag(todo), Body: newTodoBody(todo)}, nil
+}
+
+func (h *TodoHandler) completeTodo(ctx context.Context, input *CompleteTodoInput) (*TransitionTodoOutput, error) {
+	return transitionTodo(ctx, &input.TransitionTodoInput, func(ctx context.Context, id uuid.UUID, versions []int64) (*domain.Todo, error) {
+		return h.uc.CompleteTodo(ctx, id, versions, input.Cascade)
+	})
+}
+
+func (h *TodoHandler) startTodo(ctx context.Context, input *TransitionTodoInput) (*TransitionTodoOutput, error) {
+	return transitionTodo(ctx, input, h.uc.StartTodo)
+}
+
+func (h *TodoHandler) cancelTodo(ctx context.Context, input *TransitionTodoInput) (*TransitionTodoOutput, error) {
+	return transitionTodo(ctx, input, h.uc.CancelTodo)
+}
+
+func (h *TodoHandler) reopenTodo(ctx context.Context, input *TransitionTodoInput) (*TransitionTodoOutput, error) {
+	return transitionTodo(ctx, input, h.uc.ReopenTodo)
+}
+
+// transitionTodo runs one of the status change usecases.
+func transitionTodo(
+	ctx context.Context,
+	input *TransitionTodoInput,
+	transition func(context.Context, uuid.UUID, []int64) (*domain.Todo, error),
+) (*TransitionTodoOutput, error) {
+	versions, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	todo, err := transition(ctx, input.ID, versions)
 	if err != nil {
 		return nil, mapDomainError(err)
 	}
-	return &CompleteTodoOutput{Body: TodoBody{
-		ID: todo.ID, Title: todo.Title, Description: todo.Description,
-		Completed: todo.Completed, CreatedAt: todo.CreatedAt, UpdatedAt: todo.UpdatedAt,
-	}}, nil
+	return &TransitionTodoOutput{ETag: todoETag(todo), Body: newTodoBody(todo)}, nil
 }
 
-func (h *TodoHandler) completeAllTodos(ctx context.Context, _ *struct{}) (*CompleteAllOutput, error) {
-	count, err := h.uc.CompleteAllTodos(ctx)
+func (h *TodoHandler) assignTodo(ctx context.Context, input *AssignTodoInput) (*TransitionTodoOutput, error) {
+	versions, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	todo, err := h.uc.AssignTodo(ctx, input.ID, input.Body.AssigneeID, versions)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &TransitionTodoOutput{ETag: todoETag(todo), Body: newTodoBody(todo)}, nil
+}
+
+func (h *TodoHandler) completeAllTodos(ctx context.Context, _ *CompleteAllTodosInput) (*CompleteAllOutput, error) {
+	return h.completeAll(ctx, nil)
+}
+
+func (h *TodoHandler) completeProjectTodos(ctx context.Context, input *CompleteProjectTodosInput) (*CompleteAllOutput, error) {
+	return h.completeAll(ctx, &input.ID)
+}
+
+func (h *TodoHandler) completeAll(ctx context.Context, projectID *uuid.UUID) (*CompleteAllOutput, error) {
+	count, err := h.uc.CompleteAllTodos(ctx, projectID)
 	if err != nil {
 		return nil, mapDomainError(err)
 	}
@@ -216,3 +755,56 @@ func (h *TodoHandler) completeAllTodos(ctx context.Context, _ *struct{}) (*Compl
 	out.Body.Count = count
 	return out, nil
 }
+
+func (h *TodoHandler) bulkTodos(ctx context.Context, input *BulkTodosInput) (*BulkTodosOutput, error) {
+	results, err := h.uc.BulkTodos(ctx, usecase.BulkTodosParams{
+		Action: input.Body.Action, IDs: input.Body.IDs, Atomic: input.Body.Mode != "best_effort", Cascade: input.Body.Cascade,
+		AddTags: input.Body.AddTags, RemoveTags: input.Body.RemoveTags, ProjectID: optionalID(input.Body.ProjectID),
+	})
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	out := &BulkTodosOutput{}
+	out.Body.Results = make([]BulkResultBody, len(results))
+	for i, r := range results {
+		out.Body.Results[i] = newBulkResultBody(r)
+		if r.Err != nil {
+			out.Body.Failed++
+		} else {
+			out.Body.Succeeded++
+		}
+	}
+	return out, nil
+}
+
+func newBulkResultBody(r usecase.BulkResult) BulkResultBody {
+	if r.Err == nil {
+		todo := newTodoBody(r.Todo)
+		return BulkResultBody{ID: r.ID, Status: http.StatusOK, Todo: &todo}
+	}
+	body := BulkResultBody{ID: r.ID, Status: http.StatusInternalServerError, Detail: "internal error"}
+	var em *huma.ErrorModel
+	if errors.As(mapDomainError(r.Err), &em) {
+		body.Status, body.Detail = em.Status, em.Detail
+		for _, d := range em.Errors {
+			body.Errors = append(body.Errors, d.Message)
+		}
+	}
+	return body
+}
+
+// optionalTime maps an omitted (zero) time parameter to nil.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// optionalID maps an omitted (nil) ID parameter to nil.
+func optionalID(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
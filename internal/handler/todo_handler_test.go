@@ -4,10 +4,16 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/humatest"
 	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/auth"
 	"github.com/knjname/go-todo-api/internal/domain"
 	"github.com/knjname/go-todo-api/internal/handler"
 	"github.com/knjname/go-todo-api/internal/usecase"
@@ -17,15 +23,37 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// newServiceAPI returns a test API whose requests act for a service, which
+// roles do not restrict.
+func newServiceAPI(t *testing.T) humatest.TestAPI {
+	t.Helper()
+	_, api := humatest.New(t)
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		next(huma.WithContext(ctx, actor.AsService(ctx.Context())))
+	})
+	return api
+}
+
 func setupAPI(t *testing.T) (humatest.TestAPI, *mocks.TodoRepository) {
+	t.Helper()
+	api, repo, _ := setupProjectAPI(t)
+	return api, repo
+}
+
+// setupProjectAPI registers the todo and project routes, which share the
+// project repository.
+func setupProjectAPI(t *testing.T) (humatest.TestAPI, *mocks.TodoRepository, *mocks.ProjectRepository) {
 	t.Helper()
 	repo := mocks.NewTodoRepository(t)
+	projects := mocks.NewProjectRepository(t)
 	logger := slog.New(slog.DiscardHandler)
-	uc := usecase.NewTodoUseCase(repo, logger)
-	_, api := humatest.New(t)
-	h := handler.NewTodoHandler(uc)
+	users := &mocks.UserRepository{}
+	uc := usecase.NewTodoUseCase(repo, projects, users, usecase.NewRolePolicy(users), logger)
+	api := newServiceAPI(t)
+	h := handler.NewTodoHandler(uc, usecase.NewIdempotencyUseCase(&mocks.IdempotencyRepository{}, logger))
 	h.Register(api)
-	return api, repo
+	handler.NewProjectHandler(usecase.NewProjectUseCase(projects, logger)).Register(api)
+	return api, repo, projects
 }
 
 func TestCreateTodo_Handler(t *testing.T) {
@@ -43,6 +71,7 @@ func TestCreateTodo_Handler(t *testing.T) {
 	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
 	assert.Equal(t, "Test Todo", body.Title)
 	assert.Equal(t, "A test todo", body.Description)
+	assert.Equal(t, "open", body.Status)
 	assert.False(t, body.Completed)
 }
 
@@ -84,25 +113,686 @@ func TestGetTodo_Handler_NotFound(t *testing.T) {
 
 func TestListTodos_Handler(t *testing.T) {
 	api, repo := setupAPI(t)
-	repo.On("List", mock.Anything).Return([]domain.Todo{
+	repo.On("List", mock.Anything, domain.TodoFilter{}, domain.PageRequest{
+		Sort: domain.DefaultTodoSort, Limit: 51,
+	}).Return([]domain.Todo{
 		{Title: "A"},
 		{Title: "B"},
 	}, nil)
 
 	resp := api.Get("/todos")
 	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, resp.Header().Get("Link"))
+
+	var body struct {
+		Items []handler.TodoBody `json:"items"`
+		Next  string             `json:"next"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Len(t, body.Items, 2)
+	assert.Empty(t, body.Next)
+}
+
+func TestListTodos_Handler_Pagination(t *testing.T) {
+	api, repo := setupAPI(t)
+	last := domain.Todo{ID: uuid.New(), Title: "B", CreatedAt: time.Now().UTC()}
+	repo.On("List", mock.Anything, domain.TodoFilter{Overdue: true}, domain.PageRequest{
+		Sort: domain.DefaultTodoSort, Limit: 3,
+	}).Return([]domain.Todo{
+		{ID: uuid.New(), Title: "A"}, last, {ID: uuid.New(), Title: "C"},
+	}, nil)
+
+	resp := api.Get("/todos?limit=2&overdue=true")
+	assert.Equal(t, http.StatusOK, resp.Code)
 
-	var body []handler.TodoBody
+	var body struct {
+		Items []handler.TodoBody `json:"items"`
+		Next  string             `json:"next"`
+	}
 	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
-	assert.Len(t, body, 2)
+	assert.Len(t, body.Items, 2)
+	require.NotEmpty(t, body.Next)
+
+	cursor, err := domain.ParseCursor(body.Next)
+	require.NoError(t, err)
+	assert.Equal(t, last.ID, cursor.ID)
+	assert.Equal(t, `</todos?cursor=`+body.Next+`&limit=2&overdue=true>; rel="next"`, resp.Header().Get("Link"))
+
+	repo.On("List", mock.Anything, domain.TodoFilter{Overdue: true}, mock.MatchedBy(func(p domain.PageRequest) bool {
+		return p.Limit == 3 && p.After != nil && p.After.ID == last.ID
+	})).Return([]domain.Todo{}, nil)
+
+	resp = api.Get("/todos?limit=2&overdue=true&cursor=" + body.Next)
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestListTodos_Handler_InvalidCursor(t *testing.T) {
+	api, _ := setupAPI(t)
+
+	resp := api.Get("/todos?cursor=not-a-cursor")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	tampered := &domain.Cursor{Sort: "title", Values: []string{"a\x00"}, ID: uuid.New()}
+	resp = api.Get("/todos?sort=title&cursor=" + tampered.Encode())
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestListTodos_Handler_FiltersAndSort(t *testing.T) {
+	api, repo := setupAPI(t)
+	createdAfter := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.On("List", mock.Anything, mock.MatchedBy(func(f domain.TodoFilter) bool {
+		return slices.Equal(f.Statuses, []domain.Status{domain.StatusOpen, domain.StatusInProgress, domain.StatusCancelled}) &&
+			f.TitleContains == "report" && f.DescriptionContains == "" &&
+			f.CreatedAfter != nil && f.CreatedAfter.Equal(createdAfter) &&
+			f.CreatedBefore == nil && f.UpdatedAfter == nil
+	}), domain.PageRequest{
+		Sort:  domain.TodoSort{{Field: domain.SortByDue}, {Field: domain.SortByTitle, Desc: true}},
+		Limit: 51,
+	}).Return([]domain.Todo{{Title: "Weekly report"}}, nil)
+
+	resp := api.Get("/todos?completed=false&title=report&created_after=2026-01-01T00:00:00Z&sort=due,-title")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestListTodos_Handler_InvalidSort(t *testing.T) {
+	api, _ := setupAPI(t)
+
+	resp := api.Get("/todos?sort=-secret")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = api.Get("/todos?completed=maybe")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = api.Get("/todos?status=paused")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = api.Get("/todos?status=done&completed=true")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestListTodos_Handler_StatusFilter(t *testing.T) {
+	api, repo := setupAPI(t)
+	repo.On("List", mock.Anything, mock.MatchedBy(func(f domain.TodoFilter) bool {
+		return slices.Equal(f.Statuses, []domain.Status{domain.StatusOpen, domain.StatusInProgress})
+	}), mock.Anything).Return([]domain.Todo{{Title: "Task", Status: domain.StatusInProgress}}, nil)
+
+	resp := api.Get("/todos?status=open,in_progress")
+	assert.Equal(t, http.StatusOK, resp.Code)
 }
 
 func TestDeleteTodo_Handler(t *testing.T) {
 	api, repo := setupAPI(t)
 	id := uuid.New()
 	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id}, nil)
-	repo.On("Delete", mock.Anything, id).Return(nil)
+	repo.On("ListDescendants", mock.Anything, id).Return(nil, nil)
+	repo.On("UpdateMany", mock.Anything, mock.AnythingOfType("[]*domain.Todo"), mock.Anything).Return(nil)
 
 	resp := api.Delete("/todos/" + id.String())
 	assert.Equal(t, http.StatusNoContent, resp.Code)
 }
+
+func TestGetTodo_Handler_ETag(t *testing.T) {
+	api, repo := setupAPI(t)
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Found", Version: 7}, nil)
+
+	resp := api.Get("/todos/" + id.String())
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"7"`, resp.Header().Get("ETag"))
+}
+
+func TestUpdateTodo_Handler_IfMatch(t *testing.T) {
+	t.Run("matching version", func(t *testing.T) {
+		api, repo := setupAPI(t)
+		id := uuid.New()
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Old", Version: 3}, nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.Todo).Version++
+		}).Return(nil)
+
+		resp := api.Put("/todos/"+id.String(), "If-Match: \"3\"", map[string]string{
+			"title": "New", "description": "",
+		})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, `"4"`, resp.Header().Get("ETag"))
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		api, repo := setupAPI(t)
+		id := uuid.New()
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Old", Version: 4}, nil)
+
+		resp := api.Put("/todos/"+id.String(), "If-Match: \"3\"", map[string]string{
+			"title": "New", "description": "",
+		})
+		assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+	})
+
+	t.Run("concurrent write", func(t *testing.T) {
+		api, repo := setupAPI(t)
+		id := uuid.New()
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Old", Version: 3}, nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(domain.ErrConflict)
+
+		resp := api.Put("/todos/"+id.String(), map[string]string{
+			"title": "New", "description": "",
+		})
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+}
+
+func TestDeleteTodo_Handler_IfMatch(t *testing.T) {
+	api, repo := setupAPI(t)
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Version: 2}, nil)
+
+	resp := api.Delete("/todos/"+id.String(), "If-Match: W/\"1\"")
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+
+	resp = api.Delete("/todos/"+id.String(), "If-Match: W/\"2\"")
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code, "weak tags never match strongly")
+
+	resp = api.Delete("/todos/"+id.String(), "If-Match: \"not-ours\"")
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+
+	resp = api.Delete("/todos/"+id.String(), "If-Match: \"1\", W/\"2\"")
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+
+	repo.On("ListDescendants", mock.Anything, id).Return(nil, nil)
+	repo.On("UpdateMany", mock.Anything, mock.AnythingOfType("[]*domain.Todo"), mock.Anything).Return(nil)
+	resp = api.Delete("/todos/"+id.String(), "If-Match: \"1\", \"2\"")
+	assert.Equal(t, http.StatusNoContent, resp.Code, "any tag of the list matches")
+}
+
+func TestListTodos_Handler_DueFilters(t *testing.T) {
+	api, repo := setupAPI(t)
+	before := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo.On("List", mock.Anything, mock.MatchedBy(func(f domain.TodoFilter) bool {
+		return f.Overdue && f.DueBefore != nil && f.DueBefore.Equal(before) && f.DueAfter == nil
+	}), mock.Anything).Return([]domain.Todo{}, nil)
+
+	resp := api.Get("/todos?overdue=true&due_before=2026-03-01T00:00:00Z")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestCreateTodo_Handler_WithDue(t *testing.T) {
+	api, repo := setupAPI(t)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+	resp := api.Post("/todos", map[string]string{
+		"title":       "Pay rent",
+		"description": "",
+		"dueDate":     "2000-01-31",
+		"dueTime":     "18:00",
+		"dueTimeZone": "Asia/Tokyo",
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TodoBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "2000-01-31", body.DueDate)
+	assert.Equal(t, "18:00", body.DueTime)
+	assert.Equal(t, "Asia/Tokyo", body.DueTimeZone)
+	assert.True(t, body.Overdue)
+}
+
+func TestPatchTodo_Handler_MergePatch(t *testing.T) {
+	api, repo := setupAPI(t)
+	id := uuid.New()
+	due, err := domain.NewDue("2026-03-01", "09:00", "Asia/Tokyo")
+	require.NoError(t, err)
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{
+		ID: id, Title: "Old", Description: "keep me", Due: due, Version: 1,
+	}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+	resp := api.Patch("/todos/"+id.String(), "Content-Type: application/merge-patch+json", map[string]any{
+		"title":   "New",
+		"dueTime": nil,
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TodoBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "New", body.Title)
+	assert.Equal(t, "keep me", body.Description)
+	assert.Equal(t, "2026-03-01", body.DueDate)
+	assert.Empty(t, body.DueTime)
+	assert.Equal(t, "Asia/Tokyo", body.DueTimeZone)
+}
+
+func TestPatchTodo_Handler_JSONPatch(t *testing.T) {
+	api, repo := setupAPI(t)
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Old", Description: "desc"}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+	resp := api.Patch("/todos/"+id.String(), "Content-Type: application/json-patch+json", []map[string]any{
+		{"op": "replace", "path": "/title", "value": "Patched"},
+		{"op": "remove", "path": "/description"},
+		{"op": "add", "path": "/dueDate", "value": "2026-04-01"},
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TodoBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "Patched", body.Title)
+	assert.Empty(t, body.Description)
+	assert.Equal(t, "2026-04-01", body.DueDate)
+}
+
+func TestPatchTodo_Handler_Errors(t *testing.T) {
+	api, repo := setupAPI(t)
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Old"}, nil)
+
+	// Domain invariants still apply.
+	resp := api.Patch("/todos/"+id.String(), "Content-Type: application/merge-patch+json", map[string]any{"title": nil})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	// Read-only members cannot be patched.
+	resp = api.Patch("/todos/"+id.String(), "Content-Type: application/merge-patch+json", map[string]any{"version": 9})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = api.Patch("/todos/"+id.String(), "Content-Type: application/json-patch+json", []map[string]any{
+		{"op": "move", "from": "/title", "path": "/description"},
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	// RFC 6902 requires the value of add and replace.
+	resp = api.Patch("/todos/"+id.String(), "Content-Type: application/json-patch+json", []map[string]any{
+		{"op": "replace", "path": "/title", "value": "New"},
+		{"op": "replace", "path": "/description"},
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "operation 1: replace requires a value")
+
+	resp = api.Patch("/todos/"+id.String(), "Content-Type: text/plain", strings.NewReader("title=x"))
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+}
+
+func TestTransitionTodo_Handler(t *testing.T) {
+	api, repo := setupAPI(t)
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Task", Status: domain.StatusOpen, Version: 1}, nil).Once()
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+	resp := api.Post("/todos/" + id.String() + "/start")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TodoBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "in_progress", body.Status)
+	assert.False(t, body.Completed)
+
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Task", Status: domain.StatusDone, Version: 2}, nil)
+
+	resp = api.Post("/todos/" + id.String() + "/reopen")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "open", body.Status)
+}
+
+func TestTransitionTodo_Handler_InvalidTransition(t *testing.T) {
+	api, repo := setupAPI(t)
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Task", Status: domain.StatusCancelled}, nil)
+
+	resp := api.Post("/todos/" + id.String() + "/start")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	resp = api.Post("/todos/" + id.String() + "/complete")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestListTrash_Handler(t *testing.T) {
+	api, repo := setupAPI(t)
+	deletedAt := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+	repo.On("List", mock.Anything, domain.TodoFilter{Trashed: true}, mock.Anything).
+		Return([]domain.Todo{{ID: uuid.New(), Title: "Gone", DeletedAt: &deletedAt}}, nil)
+
+	resp := api.Get("/todos/trash")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		Items []handler.TodoBody `json:"items"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Items, 1)
+	require.NotNil(t, body.Items[0].DeletedAt)
+	assert.True(t, body.Items[0].DeletedAt.Equal(deletedAt))
+}
+
+func TestRestoreTodo_Handler(t *testing.T) {
+	api, repo := setupAPI(t)
+	id := uuid.New()
+	deletedAt := time.Now()
+	repo.On("GetTrashedByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Back", Version: 2, DeletedAt: &deletedAt}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+	resp := api.Post("/todos/"+id.String()+"/restore", "If-Match: \"2\"")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TodoBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Nil(t, body.DeletedAt)
+
+	missing := uuid.New()
+	repo.On("GetTrashedByID", mock.Anything, missing).Return(nil, domain.ErrNotFound)
+	resp = api.Post("/todos/" + missing.String() + "/restore")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestCreateTodo_Handler_Tags(t *testing.T) {
+	api, repo := setupAPI(t)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(td *domain.Todo) bool {
+		return slices.Equal(td.Tags, []string{"backend", "urgent"})
+	})).Return(nil)
+
+	resp := api.Post("/todos", map[string]any{
+		"title":       "Fix login",
+		"description": "",
+		"tags":        []string{"Urgent", "backend", "urgent"},
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TodoBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, []string{"backend", "urgent"}, body.Tags)
+}
+
+func TestListTodos_Handler_TagFilter(t *testing.T) {
+	api, repo := setupAPI(t)
+	repo.On("List", mock.Anything, domain.TodoFilter{
+		Tags: []string{"backend", "urgent"}, TagMatch: domain.TagMatchAny,
+	}, mock.Anything).Return([]domain.Todo{{Title: "Tagged", Tags: []string{"backend"}}}, nil)
+
+	resp := api.Get("/todos?tag=urgent&tag=Backend&tag_match=any")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		Items []handler.TodoBody `json:"items"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Items, 1)
+	assert.Equal(t, []string{"backend"}, body.Items[0].Tags)
+
+	resp = api.Get("/todos?tag=a&tag_match=some")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestSearchTodos_Handler(t *testing.T) {
+	api, repo := setupAPI(t)
+	project := uuid.New()
+	repo.On("Search", mock.Anything, mock.MatchedBy(func(s *domain.TodoSearch) bool {
+		return s.Query == "quarterly report" && s.Mode == domain.SearchSimple && s.Limit == 5 &&
+			s.Filter.ProjectID != nil && *s.Filter.ProjectID == project
+	})).Return([]domain.SearchHit{
+		{Todo: domain.Todo{Title: "Quarterly report", Description: "Draft the report & send it"}, Rank: 0.5},
+	}, nil)
+
+	resp := api.Get("/todos/search?q=quarterly+report&limit=5&project_id=" + project.String())
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		Items []handler.SearchHitBody `json:"items"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Items, 1)
+	assert.Equal(t, "Quarterly report", body.Items[0].Todo.Title)
+	assert.InDelta(t, 0.5, body.Items[0].Rank, 1e-9)
+	assert.Equal(t, "<mark>Quarterly</mark> <mark>report</mark>", body.Items[0].Title)
+	assert.Equal(t, "Draft the <mark>report</mark> &amp; send it", body.Items[0].Snippet)
+
+	resp = api.Get("/todos/search")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	resp = api.Get("/todos/search?q=-draft")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestPatchTodo_Handler_Tags(t *testing.T) {
+	api, repo := setupAPI(t)
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Task", Tags: []string{"old"}}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+	resp := api.Patch("/todos/"+id.String(), "Content-Type: application/merge-patch+json", map[string]any{
+		"tags": []string{"New"},
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var body handler.TodoBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, []string{"new"}, body.Tags)
+
+	resp = api.Patch("/todos/"+id.String(), "Content-Type: application/json-patch+json", []map[string]any{
+		{"op": "remove", "path": "/tags"},
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Empty(t, body.Tags)
+}
+
+func TestCreateTodo_Handler_Priority(t *testing.T) {
+	api, repo := setupAPI(t)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(td *domain.Todo) bool {
+		return td.Priority == domain.PriorityHigh
+	})).Return(nil)
+
+	resp := api.Post("/todos", map[string]any{"title": "Triage", "description": "", "priority": "high"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TodoBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "high", body.Priority)
+
+	resp = api.Post("/todos", map[string]any{"title": "Triage", "description": "", "priority": "P1"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestPatchTodo_Handler_Priority(t *testing.T) {
+	api, repo := setupAPI(t)
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Task", Priority: domain.PriorityUrgent}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+	resp := api.Patch("/todos/"+id.String(), "Content-Type: application/merge-patch+json", map[string]any{"priority": nil})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TodoBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "none", body.Priority)
+
+	resp = api.Patch("/todos/"+id.String(), "Content-Type: application/merge-patch+json", map[string]any{"priority": "highest"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestCreateTodo_Handler_Subtask(t *testing.T) {
+	api, repo := setupAPI(t)
+	parentID := uuid.New()
+	repo.On("GetByID", mock.Anything, parentID).Return(&domain.Todo{ID: parentID, Title: "Parent"}, nil)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(td *domain.Todo) bool {
+		return td.ParentID != nil && *td.ParentID == parentID
+	})).Return(nil)
+
+	resp := api.Post("/todos", map[string]any{
+		"title": "Child", "description": "", "parentId": parentID.String(),
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TodoBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.NotNil(t, body.ParentID)
+	assert.Equal(t, parentID, *body.ParentID)
+}
+
+func TestListChildren_Handler(t *testing.T) {
+	api, repo := setupAPI(t)
+	parentID := uuid.New()
+	repo.On("GetByID", mock.Anything, parentID).Return(&domain.Todo{ID: parentID, Title: "Parent"}, nil)
+	repo.On("List", mock.Anything, domain.TodoFilter{ParentID: &parentID}, mock.Anything).
+		Return([]domain.Todo{{ID: uuid.New(), Title: "Child", ParentID: &parentID}}, nil)
+
+	resp := api.Get("/todos/" + parentID.String() + "/children")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		Items []handler.TodoBody `json:"items"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Items, 1)
+	assert.Equal(t, "Child", body.Items[0].Title)
+
+	missing := uuid.New()
+	repo.On("GetByID", mock.Anything, missing).Return(nil, domain.ErrNotFound)
+	resp = api.Get("/todos/" + missing.String() + "/children")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestCompleteTodo_Handler_OpenSubtasks(t *testing.T) {
+	api, repo := setupAPI(t)
+	id := uuid.New()
+	parent := func() *domain.Todo { return &domain.Todo{ID: id, Title: "Parent", Status: domain.StatusOpen} }
+	repo.On("GetByID", mock.Anything, id).Return(parent(), nil).Once()
+	repo.On("ListDescendants", mock.Anything, id).
+		Return([]domain.Todo{{ID: uuid.New(), Title: "Child", Status: domain.StatusOpen, ParentID: &id}}, nil)
+
+	resp := api.Post("/todos/" + id.String() + "/complete")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	repo.On("GetByID", mock.Anything, id).Return(parent(), nil).Once()
+	repo.On("UpdateMany", mock.Anything, mock.MatchedBy(func(todos []*domain.Todo) bool {
+		return len(todos) == 2 && todos[1].Status == domain.StatusDone
+	}), mock.Anything).Return(nil)
+	resp = api.Post("/todos/" + id.String() + "/complete?cascade=true")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestPatchTodo_Handler_Parent(t *testing.T) {
+	api, repo := setupAPI(t)
+	parentID := uuid.New()
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Child", ParentID: &parentID}, nil)
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(td *domain.Todo) bool {
+		return td.ParentID == nil
+	})).Return(nil)
+
+	resp := api.Patch("/todos/"+id.String(), "Content-Type: application/merge-patch+json",
+		strings.NewReader(`{"parentId": null}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = api.Patch("/todos/"+id.String(), "Content-Type: application/merge-patch+json",
+		strings.NewReader(`{"parentId": "not-a-uuid"}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestCreateTodo_Handler_Recurrence(t *testing.T) {
+	api, repo := setupAPI(t)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+	resp := api.Post("/todos", map[string]any{
+		"title": "Weekly report", "description": "", "dueDate": "2026-03-06",
+		"dueTimeZone": "Asia/Tokyo", "recurrenceRule": "FREQ=WEEKLY;BYDAY=FR",
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TodoBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=FR", body.RecurrenceRule)
+	assert.Equal(t, "Asia/Tokyo", body.RecurrenceTimeZone)
+	require.NotNil(t, body.SeriesID)
+	assert.Equal(t, body.ID, *body.SeriesID)
+
+	resp = api.Post("/todos", map[string]any{
+		"title": "No anchor", "description": "", "recurrenceRule": "FREQ=WEEKLY",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = api.Post("/todos", map[string]any{
+		"title": "Too often", "description": "", "dueDate": "2026-03-06", "recurrenceRule": "FREQ=MINUTELY",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestScopes_Handler(t *testing.T) {
+	repo := mocks.NewTodoRepository(t)
+	users := &mocks.UserRepository{}
+	logger := slog.New(slog.DiscardHandler)
+	uc := usecase.NewTodoUseCase(repo, &mocks.ProjectRepository{}, users, usecase.NewRolePolicy(users), logger)
+	api := newServiceAPI(t)
+	// Authenticate callers as holders of the scopes named in X-Scopes.
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		if header := ctx.Header("X-Scopes"); header != "" {
+			p := &auth.Principal{Subject: "apikey:test", Scopes: []domain.Scope{}}
+			for _, s := range strings.Split(header, ",") {
+				p.Scopes = append(p.Scopes, domain.Scope(s))
+			}
+			ctx = huma.WithContext(ctx, auth.WithPrincipal(ctx.Context(), p))
+		}
+		next(ctx)
+	})
+	handler.NewTodoHandler(uc, usecase.NewIdempotencyUseCase(&mocks.IdempotencyRepository{}, logger)).Register(api)
+
+	repo.On("List", mock.Anything, mock.Anything, mock.Anything).Return([]domain.Todo{}, nil)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+	repo.On("CompleteAll", mock.Anything, (*uuid.UUID)(nil), (*uuid.UUID)(nil)).Return(int64(0), nil)
+	create := map[string]string{"title": "Scoped", "description": ""}
+
+	assert.Equal(t, http.StatusOK, api.Get("/todos", "X-Scopes: read").Code)
+	assert.Equal(t, http.StatusForbidden, api.Post("/todos", "X-Scopes: read", create).Code)
+	assert.Equal(t, http.StatusOK, api.Post("/todos", "X-Scopes: write", create).Code)
+	assert.Equal(t, http.StatusOK, api.Get("/todos", "X-Scopes: write").Code, "write includes read")
+	assert.Equal(t, http.StatusForbidden, api.Post("/todos/complete-all", "X-Scopes: write").Code)
+	assert.Equal(t, http.StatusOK, api.Post("/todos/complete-all", "X-Scopes: read,admin").Code)
+	assert.Equal(t, http.StatusOK, api.Post("/todos/complete-all").Code, "callers without scope limits pass")
+
+	resp := api.Post("/todos", "X-Scopes: read", create)
+	assert.Contains(t, resp.Body.String(), "the write scope is required")
+}
+
+func TestBulkTodos_Handler(t *testing.T) {
+	api, repo := setupAPI(t)
+	done, missing := uuid.New(), uuid.New()
+	open := func() []domain.Todo { return []domain.Todo{{ID: done, Title: "Open", Status: domain.StatusOpen}} }
+	repo.On("ListByIDs", mock.Anything, []uuid.UUID{done, missing}).Return(open(), nil)
+	repo.On("ListDescendantsOfAll", mock.Anything, []uuid.UUID{done}).Return([]domain.Todo{}, nil)
+	repo.On("UpdateBulk", mock.Anything, mock.MatchedBy(func(groups [][]*domain.Todo) bool {
+		return len(groups) == 1 && groups[0][0].Status == domain.StatusDone
+	}), mock.Anything, false).Return([]error{nil}, nil)
+
+	resp := api.Post("/todos/bulk", map[string]any{
+		"action": "complete", "ids": []uuid.UUID{done, missing}, "mode": "best_effort",
+	})
+	assert.Equal(t, http.StatusMultiStatus, resp.Code)
+	var body struct {
+		Succeeded int                      `json:"succeeded"`
+		Failed    int                      `json:"failed"`
+		Results   []handler.BulkResultBody `json:"results"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, 1, body.Succeeded)
+	assert.Equal(t, 1, body.Failed)
+	require.Len(t, body.Results, 2)
+	assert.Equal(t, http.StatusOK, body.Results[0].Status)
+	require.NotNil(t, body.Results[0].Todo)
+	assert.Equal(t, "done", body.Results[0].Todo.Status)
+	assert.Equal(t, http.StatusNotFound, body.Results[1].Status)
+
+	// In atomic mode, the todo that could be completed is not.
+	resp = api.Post("/todos/bulk", map[string]any{"action": "complete", "ids": []uuid.UUID{done, missing}})
+	assert.Equal(t, http.StatusMultiStatus, resp.Code)
+	body.Results = nil
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, 0, body.Succeeded)
+	require.Len(t, body.Results, 2)
+	assert.Equal(t, http.StatusFailedDependency, body.Results[0].Status)
+	assert.Nil(t, body.Results[0].Todo)
+	assert.Equal(t, http.StatusNotFound, body.Results[1].Status)
+	repo.AssertNumberOfCalls(t, "UpdateBulk", 1)
+
+	resp = api.Post("/todos/bulk", map[string]any{"action": "archive", "ids": []uuid.UUID{done}})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	resp = api.Post("/todos/bulk", map[string]any{"action": "complete", "ids": []uuid.UUID{}})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	resp = api.Post("/todos/bulk", map[string]any{"action": "retag", "ids": []uuid.UUID{done}})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
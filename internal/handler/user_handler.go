@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+)
+
+type UserHandler struct {
+	uc *usecase.UserUseCase
+}
+
+func NewUserHandler(uc *usecase.UserUseCase) *UserHandler {
+	return &UserHandler{uc: uc}
+}
+
+// --- Input/Output types ---
+
+type UserBody struct {
+	ID        uuid.UUID `json:"id" doc:"ユーザーID"`
+	Name      string    `json:"name" doc:"表示名"`
+	Email     string    `json:"email,omitempty" doc:"メールアドレス"`
+	Role      string    `json:"role" enum:"viewer,member,admin" doc:"ワークスペースでのロール"`
+	CreatedAt time.Time `json:"createdAt" doc:"作成日時"`
+}
+
+func newUserBody(u *domain.User) UserBody {
+	return UserBody{ID: u.ID, Name: u.Name, Email: u.Email, Role: string(u.Role), CreatedAt: u.CreatedAt}
+}
+
+type CreateUserInput struct {
+	Body struct {
+		ID    uuid.UUID `json:"id,omitempty" doc:"ユーザーID。認証基盤のユーザーIDと揃える場合に指定する。省略時は自動採番"`
+		Name  string    `json:"name" maxLength:"100" minLength:"1" doc:"表示名"`
+		Email string    `json:"email,omitempty" format:"email" doc:"メールアドレス。ワークスペース内で一意"`
+		Role  string    `json:"role,omitempty" enum:"viewer,member,admin" doc:"ワークスペースでのロール。viewer は参照のみ、member は作成と自分の Todo の変更、admin はすべての操作。省略時は member"`
+	}
+}
+
+type UserOutput struct {
+	Body UserBody
+}
+
+type GetUserInput struct {
+	ID uuid.UUID `path:"id" doc:"ユーザーID"`
+}
+
+type SetUserRoleInput struct {
+	ID   uuid.UUID `path:"id" doc:"ユーザーID"`
+	Body struct {
+		Role string `json:"role" enum:"viewer,member,admin" doc:"新しいロール"`
+	}
+}
+
+type ListUsersOutput struct {
+	Body struct {
+		Items []UserBody `json:"items" doc:"ユーザー一覧 (名前順)"`
+	}
+}
+
+func (h *UserHandler) Register(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "create-user",
+		Middlewares: requireScope(api, domain.ScopeAdmin),
+		Method:      http.MethodPost,
+		Path:        "/users",
+		Summary:     "Create a new user",
+		Tags:        []string{"Users"},
+	}, h.createUser)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-users",
+		Middlewares: requireScope(api, domain.ScopeRead),
+		Method:      http.MethodGet,
+		Path:        "/users",
+		Summary:     "List all users",
+		Tags:        []string{"Users"},
+	}, h.listUsers)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-current-user",
+		Middlewares: requireScope(api, domain.ScopeRead),
+		Method:      http.MethodGet,
+		Path:        "/users/me",
+		Summary:     "Get the calling user",
+		Description: "Returns the user identified by the credentials or the X-User-ID header.",
+		Tags:        []string{"Users"},
+	}, h.getCurrentUser)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-user",
+		Middlewares: requireScope(api, domain.ScopeRead),
+		Method:      http.MethodGet,
+		Path:        "/users/{id}",
+		Summary:     "Get a user by ID",
+		Tags:        []string{"Users"},
+	}, h.getUser)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "set-user-role",
+		Middlewares: requireScope(api, domain.ScopeAdmin),
+		Method:      http.MethodPut,
+		Path:        "/users/{id}/role",
+		Summary:     "Change the role of a user",
+		Tags:        []string{"Users"},
+	}, h.setUserRole)
+}
+
+func (h *UserHandler) createUser(ctx context.Context, input *CreateUserInput) (*UserOutput, error) {
+	user, err := h.uc.CreateUser(ctx, input.Body.ID, input.Body.Name, input.Body.Email, input.Body.Role)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &UserOutput{Body: newUserBody(user)}, nil
+}
+
+func (h *UserHandler) listUsers(ctx context.Context, _ *struct{}) (*ListUsersOutput, error) {
+	users, err := h.uc.ListUsers(ctx)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	out := &ListUsersOutput{}
+	out.Body.Items = make([]UserBody, len(users))
+	for i := range users {
+		out.Body.Items[i] = newUserBody(&users[i])
+	}
+	return out, nil
+}
+
+func (h *UserHandler) getCurrentUser(ctx context.Context, _ *struct{}) (*UserOutput, error) {
+	user, err := h.uc.CurrentUser(ctx)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &UserOutput{Body: newUserBody(user)}, nil
+}
+
+func (h *UserHandler) getUser(ctx context.Context, input *GetUserInput) (*UserOutput, error) {
+	user, err := h.uc.GetUser(ctx, input.ID)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &UserOutput{Body: newUserBody(user)}, nil
+}
+
+func (h *UserHandler) setUserRole(ctx context.Context, input *SetUserRoleInput) (*UserOutput, error) {
+	user, err := h.uc.SetUserRole(ctx, input.ID, input.Body.Role)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &UserOutput{Body: newUserBody(user)}, nil
+}
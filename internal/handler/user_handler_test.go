@@ -0,0 +1,167 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/handler"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupUserAPI registers the todo and user routes, authorized by role.
+// Requests act as the user named in X-User-ID, or for a service without it.
+func setupUserAPI(t *testing.T) (humatest.TestAPI, *mocks.TodoRepository, *mocks.UserRepository) {
+	t.Helper()
+	repo := mocks.NewTodoRepository(t)
+	users := mocks.NewUserRepository(t)
+	logger := slog.New(slog.DiscardHandler)
+	_, api := humatest.New(t)
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		if id, err := uuid.Parse(ctx.Header("X-User-ID")); err == nil {
+			ctx = huma.WithContext(ctx, actor.WithUser(ctx.Context(), id))
+		} else {
+			ctx = huma.WithContext(ctx, actor.AsService(ctx.Context()))
+		}
+		next(ctx)
+	})
+	policy := usecase.NewRolePolicy(users)
+	handler.NewTodoHandler(usecase.NewTodoUseCase(repo, &mocks.ProjectRepository{}, users, policy, logger), usecase.NewIdempotencyUseCase(&mocks.IdempotencyRepository{}, logger)).Register(api)
+	handler.NewUserHandler(usecase.NewUserUseCase(users, policy, logger)).Register(api)
+	return api, repo, users
+}
+
+func TestCreateUser_Handler(t *testing.T) {
+	api, _, users := setupUserAPI(t)
+	id := uuid.New()
+	users.On("Create", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil).Once()
+
+	resp := api.Post("/users", map[string]string{"id": id.String(), "name": "Alice", "email": "Alice@Example.com"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.UserBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, id, body.ID)
+	assert.Equal(t, "alice@example.com", body.Email)
+
+	users.On("Create", mock.Anything, mock.AnythingOfType("*domain.User")).Return(domain.ErrAlreadyExists)
+	resp = api.Post("/users", map[string]string{"name": "Alice", "email": "alice@example.com"})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestGetCurrentUser_Handler(t *testing.T) {
+	api, _, users := setupUserAPI(t)
+	id := uuid.New()
+	users.On("GetByID", mock.Anything, id).Return(&domain.User{ID: id, Name: "Alice", Role: domain.RoleViewer}, nil)
+
+	resp := api.Get("/users/me", "X-User-ID: "+id.String())
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.UserBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "Alice", body.Name)
+
+	resp = api.Get("/users/me")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	missing := uuid.New()
+	users.On("GetByID", mock.Anything, missing).Return(nil, domain.ErrNotFound)
+	resp = api.Get("/users/" + missing.String())
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestAssignTodo_Handler(t *testing.T) {
+	api, repo, users := setupUserAPI(t)
+	id, owner, assignee := uuid.New(), uuid.New(), uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Task", Status: domain.StatusOpen, CreatedBy: &owner, Version: 2}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+	other := uuid.New()
+	for _, u := range []uuid.UUID{owner, assignee, other} {
+		users.On("GetByID", mock.Anything, u).Return(&domain.User{ID: u, Name: "User", Role: domain.RoleMember}, nil)
+	}
+
+	resp := api.Post("/todos/"+id.String()+"/assign", "X-User-ID: "+owner.String(), "If-Match: \"2\"",
+		map[string]string{"assigneeId": assignee.String()})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.TodoBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, &assignee, body.AssigneeID)
+	assert.Equal(t, &owner, body.UpdatedBy)
+
+	resp = api.Post("/todos/"+id.String()+"/assign", "X-User-ID: "+other.String(), map[string]string{})
+	assert.Equal(t, http.StatusForbidden, resp.Code, "only the owner or the assignee may assign")
+
+	stranger := uuid.New()
+	users.On("GetByID", mock.Anything, stranger).Return(nil, domain.ErrNotFound)
+	resp = api.Post("/todos/"+id.String()+"/assign", "X-User-ID: "+stranger.String(), map[string]string{})
+	assert.Equal(t, http.StatusForbidden, resp.Code, "unknown users may not act")
+
+	unknown := uuid.New()
+	users.On("GetByID", mock.Anything, unknown).Return(nil, domain.ErrNotFound)
+	resp = api.Post("/todos/"+id.String()+"/assign", map[string]string{"assigneeId": unknown.String()})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestListTodos_Handler_AssigneeMe(t *testing.T) {
+	api, repo, users := setupUserAPI(t)
+	me := uuid.New()
+	users.On("GetByID", mock.Anything, me).Return(&domain.User{ID: me, Role: domain.RoleViewer}, nil)
+	repo.On("List", mock.Anything, domain.TodoFilter{AssigneeID: &me}, mock.Anything).
+		Return([]domain.Todo{{ID: uuid.New(), Title: "Mine", AssigneeID: &me}}, nil)
+
+	resp := api.Get("/todos?assignee=me", "X-User-ID: "+me.String())
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		Items []handler.TodoBody `json:"items"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Items, 1)
+	assert.Equal(t, &me, body.Items[0].AssigneeID)
+
+	resp = api.Get("/todos?assignee=me")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = api.Get("/todos?assignee=someone")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestRoles_Handler(t *testing.T) {
+	api, repo, users := setupUserAPI(t)
+	roles := map[domain.Role]uuid.UUID{}
+	for _, role := range []domain.Role{domain.RoleViewer, domain.RoleMember, domain.RoleAdmin} {
+		roles[role] = uuid.New()
+		users.On("GetByID", mock.Anything, roles[role]).Return(&domain.User{ID: roles[role], Role: role}, nil)
+	}
+	as := func(role domain.Role) string { return "X-User-ID: " + roles[role].String() }
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+	repo.On("CompleteAll", mock.Anything, (*uuid.UUID)(nil), mock.Anything).Return(int64(0), nil)
+	create := map[string]string{"title": "Task", "description": ""}
+
+	assert.Equal(t, http.StatusForbidden, api.Post("/todos", as(domain.RoleViewer), create).Code)
+	assert.Equal(t, http.StatusOK, api.Post("/todos", as(domain.RoleMember), create).Code)
+	assert.Equal(t, http.StatusForbidden, api.Post("/todos/complete-all", as(domain.RoleMember)).Code)
+	assert.Equal(t, http.StatusOK, api.Post("/todos/complete-all", as(domain.RoleAdmin)).Code)
+
+	member := roles[domain.RoleMember]
+	users.On("Update", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+	resp := api.Put("/users/"+member.String()+"/role", as(domain.RoleMember), map[string]string{"role": "admin"})
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	resp = api.Put("/users/"+member.String()+"/role", as(domain.RoleAdmin), map[string]string{"role": "viewer"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.UserBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "viewer", body.Role)
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+)
+
+type WebhookHandler struct {
+	uc *usecase.WebhookUseCase
+}
+
+func NewWebhookHandler(uc *usecase.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{uc: uc}
+}
+
+// --- Input/Output types ---
+
+type WebhookBody struct {
+	ID        uuid.UUID `json:"id" doc:"Webhook ID"`
+	URL       string    `json:"url" doc:"配信先 URL"`
+	Events    []string  `json:"events" doc:"配信するイベントの種類。空の場合はすべてのイベント"`
+	Secret    string    `json:"secret,omitempty" doc:"署名の鍵。作成時のみ返される"`
+	CreatedAt time.Time `json:"createdAt" doc:"作成日時"`
+	UpdatedAt time.Time `json:"updatedAt" doc:"更新日時"`
+}
+
+func newWebhookBody(w *domain.Webhook) WebhookBody {
+	events := make([]string, len(w.Events))
+	for i, e := range w.Events {
+		events[i] = string(e)
+	}
+	return WebhookBody{ID: w.ID, URL: w.URL, Events: events, CreatedAt: w.CreatedAt, UpdatedAt: w.UpdatedAt}
+}
+
+type WebhookDeliveryBody struct {
+	ID             uuid.UUID  `json:"id" doc:"配信ID"`
+	EventID        uuid.UUID  `json:"eventId" doc:"イベントID"`
+	EventType      string     `json:"eventType" doc:"イベントの種類"`
+	Status         string     `json:"status" enum:"pending,succeeded,dead" doc:"配信状態。dead は再送の上限に達したもの"`
+	Attempts       int        `json:"attempts" doc:"送信を試みた回数"`
+	ResponseStatus int        `json:"responseStatus,omitempty" doc:"最後の送信の HTTP ステータス。応答がなかった場合は省略"`
+	LastError      string     `json:"lastError,omitempty" doc:"最後の送信が失敗した理由"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt,omitempty" doc:"次の送信予定日時"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt,omitempty" doc:"最後に送信した日時"`
+	CreatedAt      time.Time  `json:"createdAt" doc:"作成日時"`
+}
+
+func newWebhookDeliveryBody(d *domain.WebhookDelivery) WebhookDeliveryBody {
+	return WebhookDeliveryBody{
+		ID: d.ID, EventID: d.EventID, EventType: string(d.EventType), Status: string(d.Status),
+		Attempts: d.Attempts, ResponseStatus: d.ResponseStatus, LastError: d.LastError,
+		NextAttemptAt: d.NextAttemptAt, LastAttemptAt: d.LastAttemptAt, CreatedAt: d.CreatedAt,
+	}
+}
+
+type CreateWebhookInput struct {
+	Body struct {
+		URL    string   `json:"url" maxLength:"2000" minLength:"1" doc:"配信先 URL (http または https)"`
+		Secret string   `json:"secret,omitempty" minLength:"16" doc:"署名の鍵。省略時は生成される"`
+		Events []string `json:"events,omitempty" enum:"TodoCreated,TodoUpdated,TodoCompleted,TodoDeleted,AllCompleted" doc:"配信するイベントの種類。省略時はすべてのイベント"`
+	}
+}
+
+type WebhookOutput struct {
+	Body WebhookBody
+}
+
+type GetWebhookInput struct {
+	ID uuid.UUID `path:"id" doc:"Webhook ID"`
+}
+
+type ListWebhooksOutput struct {
+	Body struct {
+		Items []WebhookBody `json:"items" doc:"Webhook 一覧 (作成順)"`
+	}
+}
+
+type UpdateWebhookInput struct {
+	ID   uuid.UUID `path:"id" doc:"Webhook ID"`
+	Body struct {
+		URL    string   `json:"url" maxLength:"2000" minLength:"1" doc:"配信先 URL (http または https)"`
+		Secret string   `json:"secret,omitempty" minLength:"16" doc:"署名の鍵。省略時は変更しない"`
+		Events []string `json:"events,omitempty" enum:"TodoCreated,TodoUpdated,TodoCompleted,TodoDeleted,AllCompleted" doc:"配信するイベントの種類。省略時はすべてのイベント"`
+	}
+}
+
+type DeleteWebhookInput struct {
+	ID uuid.UUID `path:"id" doc:"Webhook ID"`
+}
+
+type ListWebhookDeliveriesInput struct {
+	ID    uuid.UUID `path:"id" doc:"Webhook ID"`
+	Limit int       `query:"limit" minimum:"1" maximum:"200" default:"50" doc:"取得する件数"`
+}
+
+type ListWebhookDeliveriesOutput struct {
+	Body struct {
+		Items []WebhookDeliveryBody `json:"items" doc:"配信一覧 (新しい順)"`
+	}
+}
+
+func (h *WebhookHandler) Register(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "create-webhook",
+		Middlewares: requireScope(api, domain.ScopeAdmin),
+		Method:      http.MethodPost,
+		Path:        "/webhooks",
+		Summary:     "Subscribe a URL to todo events",
+		Description: "Events are POSTed to the URL as JSON, signed with HMAC-SHA256 in X-Webhook-Signature. The secret is returned only in this response.",
+		Tags:        []string{"Webhooks"},
+	}, h.createWebhook)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-webhooks",
+		Middlewares: requireScope(api, domain.ScopeAdmin),
+		Method:      http.MethodGet,
+		Path:        "/webhooks",
+		Summary:     "List all webhooks",
+		Tags:        []string{"Webhooks"},
+	}, h.listWebhooks)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-webhook",
+		Middlewares: requireScope(api, domain.ScopeAdmin),
+		Method:      http.MethodGet,
+		Path:        "/webhooks/{id}",
+		Summary:     "Get a webhook by ID",
+		Tags:        []string{"Webhooks"},
+	}, h.getWebhook)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-webhook",
+		Middlewares: requireScope(api, domain.ScopeAdmin),
+		Method:      http.MethodPut,
+		Path:        "/webhooks/{id}",
+		Summary:     "Update a webhook",
+		Tags:        []string{"Webhooks"},
+	}, h.updateWebhook)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "delete-webhook",
+		Middlewares: requireScope(api, domain.ScopeAdmin),
+		Method:      http.MethodDelete,
+		Path:        "/webhooks/{id}",
+		Summary:     "Delete a webhook",
+		Description: "Deletes the webhook with its deliveries, including those not yet sent.",
+		Tags:        []string{"Webhooks"},
+	}, h.deleteWebhook)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-webhook-deliveries",
+		Middlewares: requireScope(api, domain.ScopeAdmin),
+		Method:      http.MethodGet,
+		Path:        "/webhooks/{id}/deliveries",
+		Summary:     "List the latest deliveries of a webhook",
+		Description: "Lists the deliveries of the webhook with the outcome of their latest attempt, newest first.",
+		Tags:        []string{"Webhooks"},
+	}, h.listDeliveries)
+}
+
+func (h *WebhookHandler) createWebhook(ctx context.Context, input *CreateWebhookInput) (*WebhookOutput, error) {
+	hook, err := h.uc.CreateWebhook(ctx, input.Body.URL, input.Body.Secret, eventTypes(input.Body.Events))
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	body := newWebhookBody(hook)
+	body.Secret = hook.Secret
+	return &WebhookOutput{Body: body}, nil
+}
+
+func (h *WebhookHandler) listWebhooks(ctx context.Context, _ *struct{}) (*ListWebhooksOutput, error) {
+	hooks, err := h.uc.ListWebhooks(ctx)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	out := &ListWebhooksOutput{}
+	out.Body.Items = make([]WebhookBody, len(hooks))
+	for i := range hooks {
+		out.Body.Items[i] = newWebhookBody(&hooks[i])
+	}
+	return out, nil
+}
+
+func (h *WebhookHandler) getWebhook(ctx context.Context, input *GetWebhookInput) (*WebhookOutput, error) {
+	hook, err := h.uc.GetWebhook(ctx, input.ID)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &WebhookOutput{Body: newWebhookBody(hook)}, nil
+}
+
+func (h *WebhookHandler) updateWebhook(ctx context.Context, input *UpdateWebhookInput) (*WebhookOutput, error) {
+	hook, err := h.uc.UpdateWebhook(ctx, input.ID, input.Body.URL, input.Body.Secret, eventTypes(input.Body.Events))
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &WebhookOutput{Body: newWebhookBody(hook)}, nil
+}
+
+func (h *WebhookHandler) deleteWebhook(ctx context.Context, input *DeleteWebhookInput) (*struct{}, error) {
+	if err := h.uc.DeleteWebhook(ctx, input.ID); err != nil {
+		return nil, mapDomainError(err)
+	}
+	return nil, nil
+}
+
+func (h *WebhookHandler) listDeliveries(ctx context.Context, input *ListWebhookDeliveriesInput) (*ListWebhookDeliveriesOutput, error) {
+	deliveries, err := h.uc.ListDeliveries(ctx, input.ID, input.Limit)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	out := &ListWebhookDeliveriesOutput{}
+	out.Body.Items = make([]WebhookDeliveryBody, len(deliveries))
+	for i := range deliveries {
+		out.Body.Items[i] = newWebhookDeliveryBody(&deliveries[i])
+	}
+	return out, nil
+}
+
+func eventTypes(names []string) []domain.EventType {
+	types := make([]domain.EventType, len(names))
+	for i, name := range names {
+		types[i] = domain.EventType(name)
+	}
+	return types
+}
@@ -0,0 +1,109 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/handler"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupWebhookAPI(t *testing.T) (humatest.TestAPI, *mocks.WebhookRepository, *mocks.WebhookDeliveryRepository) {
+	t.Helper()
+	repo := mocks.NewWebhookRepository(t)
+	deliveries := mocks.NewWebhookDeliveryRepository(t)
+	api := newServiceAPI(t)
+	uc := usecase.NewWebhookUseCase(repo, deliveries, usecase.NewRolePolicy(mocks.NewUserRepository(t)), slog.New(slog.DiscardHandler))
+	handler.NewWebhookHandler(uc).Register(api)
+	return api, repo, deliveries
+}
+
+func TestCreateWebhook_Handler(t *testing.T) {
+	api, repo, _ := setupWebhookAPI(t)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Webhook")).Return(nil)
+
+	resp := api.Post("/webhooks", map[string]any{
+		"url":    "https://example.com/hook",
+		"events": []string{"TodoDeleted", "TodoCompleted"},
+	})
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var body handler.WebhookBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "https://example.com/hook", body.URL)
+	assert.Equal(t, []string{"TodoCompleted", "TodoDeleted"}, body.Events)
+	assert.NotEmpty(t, body.Secret)
+
+	resp = api.Post("/webhooks", map[string]any{"url": "ftp://example.com/hook"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = api.Post("/webhooks", map[string]any{"url": "https://example.com/hook", "events": []string{"Unknown"}})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestGetWebhook_Handler(t *testing.T) {
+	api, repo, _ := setupWebhookAPI(t)
+	hook, err := domain.NewWebhook("https://example.com/hook", "", nil)
+	require.NoError(t, err)
+	repo.On("GetByID", mock.Anything, hook.ID).Return(hook, nil)
+	repo.On("GetByID", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound)
+
+	resp := api.Get("/webhooks/" + hook.ID.String())
+	require.Equal(t, http.StatusOK, resp.Code)
+	var body handler.WebhookBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, hook.ID, body.ID)
+	assert.Empty(t, body.Secret, "the secret is only returned on creation")
+	assert.Equal(t, []string{}, body.Events)
+
+	resp = api.Get("/webhooks/" + uuid.NewString())
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestDeleteWebhook_Handler(t *testing.T) {
+	api, repo, _ := setupWebhookAPI(t)
+	id := uuid.New()
+	repo.On("Delete", mock.Anything, id).Return(nil)
+
+	resp := api.Delete("/webhooks/" + id.String())
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestListWebhookDeliveries_Handler(t *testing.T) {
+	api, repo, deliveries := setupWebhookAPI(t)
+	hook, err := domain.NewWebhook("https://example.com/hook", "", nil)
+	require.NoError(t, err)
+	event, err := domain.NewAllCompletedEvent(nil, nil)
+	require.NoError(t, err)
+	d, err := domain.NewWebhookDelivery(hook, event)
+	require.NoError(t, err)
+	d.RecordAttempt(d.CreatedAt, http.StatusServiceUnavailable, assert.AnError)
+
+	repo.On("GetByID", mock.Anything, hook.ID).Return(hook, nil)
+	deliveries.On("ListByWebhook", mock.Anything, hook.ID, 10).Return([]domain.WebhookDelivery{*d}, nil)
+
+	resp := api.Get("/webhooks/" + hook.ID.String() + "/deliveries?limit=10")
+	require.Equal(t, http.StatusOK, resp.Code)
+	var body struct {
+		Items []handler.WebhookDeliveryBody `json:"items"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Items, 1)
+	got := body.Items[0]
+	assert.Equal(t, event.ID, got.EventID)
+	assert.Equal(t, "AllCompleted", got.EventType)
+	assert.Equal(t, "pending", got.Status)
+	assert.Equal(t, 1, got.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, got.ResponseStatus)
+	assert.NotEmpty(t, got.LastError)
+	assert.NotNil(t, got.NextAttemptAt)
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/knjname/go-todo-api/internal/auth"
+)
+
+// PublicPaths are the path prefixes served without authentication: the
+// API documentation and the schemas it refers to.
+var PublicPaths = []string{"/docs", "/openapi", "/schemas"}
+
+// AuthConfig configures Authenticate.
+type AuthConfig struct {
+	// Schemes authenticate the credentials of the Authorization header by
+	// its scheme, such as "Bearer" or "ApiKey".
+	Schemes map[string]auth.Authenticator
+	// Public lists path prefixes served without authentication.
+	Public []string
+	// AllowAnonymous lets requests without an Authorization header through
+	// unauthenticated. Credentials that are sent are checked all the same.
+	AllowAnonymous bool
+}
+
+// Authenticate authenticates the Authorization header of every request
+// outside the public path prefixes and puts the caller into the request
+// context. Requests with missing or invalid credentials get a 401 problem
+// response.
+func Authenticate(cfg AuthConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range cfg.Public {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			header := r.Header.Get("Authorization")
+			if header == "" && cfg.AllowAnonymous {
+				next.ServeHTTP(w, r)
+				return
+			}
+			scheme, credentials, _ := strings.Cut(header, " ")
+			authenticator, name := lookupScheme(cfg.Schemes, scheme)
+			credentials = strings.TrimSpace(credentials)
+			if authenticator == nil || credentials == "" {
+				w.Header().Set("WWW-Authenticate", challenge(cfg.Schemes, ""))
+				writeProblem(w, http.StatusUnauthorized, "credentials required")
+				return
+			}
+
+			principal, err := authenticator.Authenticate(r.Context(), credentials)
+			if errors.Is(err, auth.ErrInvalidToken) {
+				w.Header().Set("WWW-Authenticate", challenge(map[string]auth.Authenticator{name: authenticator}, `error="invalid_token"`))
+				writeProblem(w, http.StatusUnauthorized, "invalid credentials")
+				return
+			}
+			if err != nil {
+				writeProblem(w, http.StatusInternalServerError, "authentication failed")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// lookupScheme finds the authenticator of an Authorization scheme, which
+// is case-insensitive.
+func lookupScheme(schemes map[string]auth.Authenticator, scheme string) (auth.Authenticator, string) {
+	for name, a := range schemes {
+		if strings.EqualFold(name, scheme) {
+			return a, name
+		}
+	}
+	return nil, ""
+}
+
+// challenge renders a WWW-Authenticate header value for schemes.
+func challenge(schemes map[string]auth.Authenticator, params string) string {
+	challenges := make([]string, 0, len(schemes))
+	for name := range schemes {
+		if params != "" {
+			name += " " + params
+		}
+		challenges = append(challenges, name)
+	}
+	slices.Sort(challenges)
+	return strings.Join(challenges, ", ")
+}
@@ -0,0 +1,265 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/auth"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/middleware"
+	"github.com/knjname/go-todo-api/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticate(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	v := auth.NewVerifier(auth.NewKeySet(map[string]crypto.PublicKey{"k1": pub}), "", "")
+	token := func(claims jwt.MapClaims) string {
+		tok := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		tok.Header["kid"] = "k1"
+		s, err := tok.SignedString(priv)
+		require.NoError(t, err)
+		return s
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+	workspace := uuid.New()
+
+	var gotSubject string
+	var gotWorkspace uuid.UUID
+	h := middleware.Authenticate(middleware.AuthConfig{
+		Schemes: map[string]auth.Authenticator{"Bearer": v},
+		Public:  middleware.PublicPaths,
+	})(middleware.Workspace(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotSubject, gotWorkspace = "", uuid.Nil
+			if p, ok := auth.PrincipalFrom(r.Context()); ok {
+				gotSubject = p.Subject
+			}
+			gotWorkspace, _ = tenant.WorkspaceID(r.Context())
+		})))
+
+	serve := func(path, authorization, workspaceHeader string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		if workspaceHeader != "" {
+			req.Header.Set(middleware.WorkspaceHeader, workspaceHeader)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		rec := serve("/todos", "Bearer "+token(jwt.MapClaims{"sub": "alice", "exp": exp, "workspace_id": workspace.String()}), "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "alice", gotSubject)
+		assert.Equal(t, workspace, gotWorkspace)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		rec := serve("/todos", "", "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+		assert.JSONEq(t, `{"title":"Unauthorized","status":401,"detail":"credentials required"}`, rec.Body.String())
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		expired := token(jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(-time.Hour).Unix()})
+		for _, authorization := range []string{"Bearer " + expired, "Bearer garbage", "Basic YWxpY2U6c2VjcmV0"} {
+			rec := serve("/todos", authorization, "")
+			assert.Equal(t, http.StatusUnauthorized, rec.Code, authorization)
+			assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("public paths", func(t *testing.T) {
+		rec := serve("/openapi.json", "", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, gotSubject)
+	})
+
+	t.Run("workspace claim", func(t *testing.T) {
+		bearer := "Bearer " + token(jwt.MapClaims{"sub": "bob", "exp": exp, "workspace_id": workspace.String()})
+
+		rec := serve("/todos", bearer, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, workspace, gotWorkspace)
+
+		rec = serve("/todos", bearer, workspace.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = serve("/todos", bearer, uuid.NewString())
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+		// Tokens without the claim cannot pick a workspace with the header.
+		unbound := "Bearer " + token(jwt.MapClaims{"sub": "bob", "exp": exp})
+		for _, header := range []string{"", workspace.String()} {
+			rec = serve("/todos", unbound, header)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		}
+	})
+}
+
+type authenticatorFunc func(ctx context.Context, credentials string) (*auth.Principal, error)
+
+func (f authenticatorFunc) Authenticate(ctx context.Context, credentials string) (*auth.Principal, error) {
+	return f(ctx, credentials)
+}
+
+func TestAuthenticate_AllowAnonymous(t *testing.T) {
+	keyWorkspace := uuid.New()
+	apiKeys := authenticatorFunc(func(_ context.Context, credentials string) (*auth.Principal, error) {
+		switch credentials {
+		case "tdk_good":
+			return &auth.Principal{Subject: "apikey:1", Claims: map[string]any{auth.WorkspaceClaim: keyWorkspace.String()}}, nil
+		case "tdk_broken":
+			return nil, errors.New("db down")
+		}
+		return nil, auth.ErrInvalidToken
+	})
+
+	var gotSubject string
+	var gotWorkspace uuid.UUID
+	h := middleware.Authenticate(middleware.AuthConfig{
+		Schemes:        map[string]auth.Authenticator{"ApiKey": apiKeys},
+		AllowAnonymous: true,
+	})(middleware.Workspace(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		gotSubject = ""
+		if p, ok := auth.PrincipalFrom(r.Context()); ok {
+			gotSubject = p.Subject
+		}
+		gotWorkspace, _ = tenant.WorkspaceID(r.Context())
+	})))
+	serve := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, gotSubject)
+
+	rec = serve("apikey tdk_good")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "apikey:1", gotSubject)
+	assert.Equal(t, keyWorkspace, gotWorkspace, "API keys act in their own workspace")
+
+	rec = serve("ApiKey tdk_bad")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, `ApiKey error="invalid_token"`, rec.Header().Get("WWW-Authenticate"))
+
+	rec = serve("Bearer some.jwt.token")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "unknown schemes are refused")
+
+	rec = serve("ApiKey tdk_broken")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+}
+
+func TestWorkspace_InvalidHeader(t *testing.T) {
+	h := middleware.Workspace(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Fatal("handler must not be called")
+	}))
+	for _, header := range []string{"nope", uuid.Nil.String()} {
+		req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+		req.Header.Set(middleware.WorkspaceHeader, header)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestUser(t *testing.T) {
+	var gotUser uuid.UUID
+	var gotOK, gotService bool
+	h := middleware.User(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		gotUser, gotOK = actor.UserID(r.Context())
+		gotService = actor.IsService(r.Context())
+	}))
+	serve := func(p *auth.Principal, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+		if p != nil {
+			req = req.WithContext(auth.WithPrincipal(req.Context(), p))
+		}
+		if header != "" {
+			req.Header.Set(middleware.UserHeader, header)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	user := uuid.New()
+
+	rec := serve(nil, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, gotOK)
+	assert.False(t, gotService, "unauthenticated requests act for no one")
+
+	rec = serve(nil, user.String())
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, user, gotUser)
+
+	rec = serve(&auth.Principal{Subject: "apikey:1", Client: true}, user.String())
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, user, gotUser, "service clients name the user in the header")
+
+	// API keys need the impersonate scope to act for a user.
+	rec = serve(&auth.Principal{Subject: "apikey:2", Client: true, Scopes: []domain.Scope{domain.ScopeImpersonate}}, user.String())
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, user, gotUser)
+	for _, scope := range []domain.Scope{domain.ScopeRead, domain.ScopeAdmin} {
+		gotUser = uuid.Nil
+		rec = serve(&auth.Principal{Subject: "apikey:3", Client: true, Scopes: []domain.Scope{scope}}, user.String())
+		assert.Equal(t, http.StatusForbidden, rec.Code, scope)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, uuid.Nil, gotUser, "the handler is not called")
+	}
+
+	rec = serve(&auth.Principal{Subject: "apikey:1", Client: true}, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, gotOK)
+	assert.True(t, gotService, "clients without a user act as a service")
+
+	rec = serve(&auth.Principal{Subject: user.String()}, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, user, gotUser, "a user ID subject is the user")
+
+	rec = serve(&auth.Principal{Subject: user.String()}, uuid.NewString())
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// Users that are not identified by a user ID cannot name one instead.
+	for _, header := range []string{"", user.String()} {
+		rec = serve(&auth.Principal{Subject: "alice"}, header)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	}
+
+	for _, header := range []string{"nope", uuid.Nil.String()} {
+		rec = serve(nil, header)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// writeProblem writes an RFC 9457 problem response in the same shape huma
+// uses for errors returned by handlers.
+func writeProblem(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+		Detail string `json:"detail"`
+	}{http.StatusText(status), status, detail})
+}
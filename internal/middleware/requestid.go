@@ -5,27 +5,21 @@ import (
 	"net/http"
 
 	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/requestctx"
 )
 
-type contextKey string
-
-const RequestIDKey contextKey = "request_id"
-
 func RequestID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		id := r.Header.Get("X-Request-ID")
 		if id == "" {
 			id = uuid.New().String()
 		}
-		ctx := context.WithValue(r.Context(), RequestIDKey, id)
+		ctx := requestctx.WithID(r.Context(), id)
 		w.Header().Set("X-Request-ID", id)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
 func GetRequestID(ctx context.Context) string {
-	if id, ok := ctx.Value(RequestIDKey).(string); ok {
-		return id
-	}
-	return ""
+	return requestctx.ID(ctx)
 }
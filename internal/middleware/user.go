@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/auth"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// UserHeader names the request header carrying the ID of the user a
+// trusted client, such as a frontend holding an API key, acts for.
+const UserHeader = "X-User-ID"
+
+// User resolves the user a request acts as. An authenticated user, such as
+// the subject of a JWT, acts as itself: its subject must be a user ID, and a
+// UserHeader naming another user is refused. Clients trusted to act for
+// users, such as API keys with the impersonate scope, and unauthenticated
+// requests name the user in UserHeader; other clients are refused. Without
+// it, clients act as a service, and unauthenticated requests as no one.
+func User(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get(UserHeader)
+		p, authenticated := auth.PrincipalFrom(r.Context())
+
+		var id uuid.UUID
+		switch {
+		case authenticated && !p.Client:
+			subject, err := uuid.Parse(p.Subject)
+			if err != nil || subject == uuid.Nil {
+				writeProblem(w, http.StatusUnauthorized, "token subject is not a user ID")
+				return
+			}
+			if other, err := uuid.Parse(header); header != "" && (err != nil || other != subject) {
+				writeProblem(w, http.StatusForbidden, "credentials are not valid for user "+header)
+				return
+			}
+			id = subject
+		case header != "":
+			if authenticated && !p.HasScope(domain.ScopeImpersonate) {
+				writeProblem(w, http.StatusForbidden, "credentials may not act for users")
+				return
+			}
+			parsed, err := uuid.Parse(header)
+			if err != nil || parsed == uuid.Nil {
+				writeProblem(w, http.StatusBadRequest, "invalid "+UserHeader+" header")
+				return
+			}
+			id = parsed
+		case authenticated:
+			next.ServeHTTP(w, r.WithContext(actor.AsService(r.Context())))
+			return
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(actor.WithUser(r.Context(), id)))
+	})
+}
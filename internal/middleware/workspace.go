@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/auth"
+	"github.com/knjname/go-todo-api/internal/tenant"
+)
+
+// WorkspaceHeader names the request header carrying the workspace ID.
+const WorkspaceHeader = "X-Workspace-ID"
+
+// Workspace resolves the workspace a request acts in. Authenticated
+// callers must be bound to a workspace by an auth.WorkspaceClaim and act
+// in it; a WorkspaceHeader naming another one is refused. Only
+// unauthenticated requests, which are allowed in development, name the
+// workspace in WorkspaceHeader. Requests without either pass through
+// without a workspace, and every access to tenant data fails.
+func Workspace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get(WorkspaceHeader)
+		var claim string
+		if p, ok := auth.PrincipalFrom(r.Context()); ok {
+			var bound bool
+			if claim, bound = p.StringClaim(auth.WorkspaceClaim); !bound {
+				writeProblem(w, http.StatusUnauthorized, "credentials have no "+auth.WorkspaceClaim+" claim")
+				return
+			}
+		}
+
+		var id uuid.UUID
+		switch {
+		case claim != "":
+			parsed, err := uuid.Parse(claim)
+			if err != nil || parsed == uuid.Nil {
+				writeProblem(w, http.StatusUnauthorized, "invalid "+auth.WorkspaceClaim+" claim")
+				return
+			}
+			if other, err := uuid.Parse(header); header != "" && (err != nil || other != parsed) {
+				writeProblem(w, http.StatusForbidden, "credentials are not valid for workspace "+header)
+				return
+			}
+			id = parsed
+		case header != "":
+			parsed, err := uuid.Parse(header)
+			if err != nil || parsed == uuid.Nil {
+				writeProblem(w, http.StatusBadRequest, "invalid "+WorkspaceHeader+" header")
+				return
+			}
+			id = parsed
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(tenant.WithWorkspace(r.Context(), id)))
+	})
+}
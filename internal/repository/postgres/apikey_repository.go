@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+type APIKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(pool *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{pool: pool}
+}
+
+// Create stores a new API key in the workspace of ctx and sets its
+// WorkspaceID.
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	return inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, queryInsertAPIKey,
+			key.ID, key.Name, scopeStrings(key.Scopes), key.SecretHash, key.ExpiresAt, key.CreatedAt,
+		).Scan(&key.WorkspaceID)
+	})
+}
+
+// List returns the API keys of the workspace, newest first.
+func (r *APIKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, queryListAPIKeys)
+		if err != nil {
+			return err
+		}
+		keys, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.APIKey, error) {
+			return scanAPIKey(row)
+		})
+		return err
+	})
+	return keys, err
+}
+
+// Revoke revokes an API key of the workspace at the given time. A key that
+// is revoked already keeps its revocation time.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	result, err := execInWorkspace(ctx, r.pool, queryRevokeAPIKey, id, at)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Lookup returns an API key of any workspace. It runs outside of row-level
+// security, as the workspace of a request is only known once its key is.
+func (r *APIKeyRepository) Lookup(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, queryGetAPIKeyByID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// MarkUsed records when an API key of any workspace was last used.
+func (r *APIKeyRepository) MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.pool.Exec(ctx, queryMarkAPIKeyUsed, id, at)
+	return err
+}
+
+func scanAPIKey(row pgx.Row) (domain.APIKey, error) {
+	var (
+		k      domain.APIKey
+		scopes []string
+	)
+	err := row.Scan(&k.ID, &k.WorkspaceID, &k.Name, &scopes, &k.SecretHash,
+		&k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt)
+	for _, s := range scopes {
+		k.Scopes = append(k.Scopes, domain.Scope(s))
+	}
+	return k, err
+}
+
+func scopeStrings(scopes []domain.Scope) []string {
+	s := make([]string, len(scopes))
+	for i, scope := range scopes {
+		s[i] = string(scope)
+	}
+	return s
+}
@@ -0,0 +1,58 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/repository/postgres"
+	"github.com/knjname/go-todo-api/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewAPIKeyRepository(pool)
+	ctx := workspaceContext()
+	workspace, _ := tenant.WorkspaceID(ctx)
+
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+	key, token, err := domain.NewAPIKey("ci", []domain.Scope{domain.ScopeRead, domain.ScopeWrite}, &expires)
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, key))
+	assert.Equal(t, workspace, key.WorkspaceID)
+
+	other, _, _ := domain.NewAPIKey("other", []domain.Scope{domain.ScopeAdmin}, nil)
+	require.NoError(t, repo.Create(workspaceContext(), other))
+
+	keys, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1, "keys of other workspaces are hidden")
+	assert.Equal(t, key.ID, keys[0].ID)
+	assert.Equal(t, key.Scopes, keys[0].Scopes)
+	assert.Equal(t, expires, keys[0].ExpiresAt.UTC())
+
+	// Lookup finds keys without a workspace in the context.
+	got, err := repo.Lookup(context.Background(), key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, workspace, got.WorkspaceID)
+	assert.True(t, got.Matches(token))
+	_, err = repo.Lookup(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	usedAt := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, repo.MarkUsed(context.Background(), key.ID, usedAt))
+
+	revokedAt := usedAt.Add(time.Minute)
+	require.NoError(t, repo.Revoke(ctx, key.ID, revokedAt))
+	require.NoError(t, repo.Revoke(ctx, key.ID, revokedAt.Add(time.Minute)))
+	assert.ErrorIs(t, repo.Revoke(ctx, other.ID, revokedAt), domain.ErrNotFound, "keys of other workspaces cannot be revoked")
+
+	got, err = repo.Lookup(context.Background(), key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, usedAt, got.LastUsedAt.UTC())
+	assert.Equal(t, revokedAt, got.RevokedAt.UTC(), "revoking again keeps the first time")
+}
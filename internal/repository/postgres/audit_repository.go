@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/auth"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/requestctx"
+)
+
+// AuditRepository reads the audit trail that TodoRepository writes.
+type AuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{pool: pool}
+}
+
+// ListByTodo returns the entries of a todo, oldest first.
+func (r *AuditRepository) ListByTodo(ctx context.Context, todoID uuid.UUID) ([]domain.AuditEntry, error) {
+	var entries []domain.AuditEntry
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, queryListTodoAudit, todoID)
+		if err != nil {
+			return err
+		}
+		entries, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.AuditEntry, error) {
+			return scanAuditEntry(row)
+		})
+		return err
+	})
+	return entries, err
+}
+
+// Export calls fn with every entry recorded since the given time, oldest
+// first, stopping at the first error fn returns. The entries are read in a
+// single transaction and are not held in memory.
+func (r *AuditRepository) Export(ctx context.Context, since time.Time, fn func(*domain.AuditEntry) error) error {
+	return inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, queryExportAudit, since)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			e, err := scanAuditEntry(rows)
+			if err != nil {
+				return err
+			}
+			if err := fn(&e); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+func scanAuditEntry(row pgx.Row) (domain.AuditEntry, error) {
+	var (
+		e                  domain.AuditEntry
+		subject, requestID *string
+		before, after      []byte
+	)
+	err := row.Scan(&e.ID, &e.TodoID, &e.Action, &e.UserID, &subject, &requestID, &before, &after, &e.CreatedAt)
+	if subject != nil {
+		e.Subject = *subject
+	}
+	if requestID != nil {
+		e.RequestID = *requestID
+	}
+	e.Before, e.After = snapshotOrNull(before), snapshotOrNull(after)
+	return e, err
+}
+
+func snapshotOrNull(b []byte) json.RawMessage {
+	if b == nil {
+		return json.RawMessage("null")
+	}
+	return b
+}
+
+// todoChange is a change to a todo, as recorded in the audit trail. before
+// is nil for a new todo, and after is nil for a purged one.
+type todoChange struct {
+	before, after *domain.Todo
+}
+
+// recordAudit appends changes to the audit trail in tx, the transaction
+// making them, so that the trail has an entry for every committed change
+// and for nothing else. The entries name the user, the authenticated
+// caller and the request of ctx.
+func recordAudit(ctx context.Context, tx pgx.Tx, changes ...todoChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	var (
+		user      *uuid.UUID
+		subject   *string
+		requestID = optionalString(requestctx.ID(ctx))
+	)
+	if id, ok := actor.UserID(ctx); ok {
+		user = &id
+	}
+	if p, ok := auth.PrincipalFrom(ctx); ok {
+		subject = optionalString(p.Subject)
+	}
+
+	batch := &pgx.Batch{}
+	for _, c := range changes {
+		before, err := snapshot(c.before)
+		if err != nil {
+			return err
+		}
+		after, err := snapshot(c.after)
+		if err != nil {
+			return err
+		}
+		t := c.after
+		if t == nil {
+			t = c.before
+		}
+		batch.Queue(queryInsertAudit, t.ID, domain.TodoAuditAction(c.before, c.after),
+			user, subject, requestID, before, after)
+	}
+	return tx.SendBatch(ctx, batch).Close()
+}
+
+// snapshot encodes a todo for the audit trail; nil encodes to NULL.
+func snapshot(t *domain.Todo) ([]byte, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return json.Marshal(t)
+}
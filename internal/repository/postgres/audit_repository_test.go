@@ -0,0 +1,125 @@
+package postgres_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/repository/postgres"
+	"github.com/knjname/go-todo-api/internal/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoRepository_Audit(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	audit := postgres.NewAuditRepository(pool)
+	ctx := workspaceContext()
+	user := uuid.New()
+	userCtx := requestctx.WithID(actor.WithUser(ctx, user), "req-1")
+	start := time.Now().Add(-time.Minute)
+
+	todo, err := domain.NewTodo("Audited", "", domain.WithTags([]string{"work"}), domain.WithCreator(&user))
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(userCtx, todo))
+
+	require.NoError(t, todo.UpdateTitle("Audited twice"))
+	require.NoError(t, repo.Update(userCtx, todo))
+	todo.MoveToTrash()
+	require.NoError(t, repo.Update(ctx, todo))
+
+	stale := *todo
+	stale.Version--
+	assert.ErrorIs(t, repo.Update(ctx, &stale), domain.ErrConflict)
+
+	purged, err := repo.PurgeTrash(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	entries, err := audit.ListByTodo(ctx, todo.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 4, "failed writes are not recorded")
+	actions := make([]domain.AuditAction, len(entries))
+	for i, e := range entries {
+		actions[i] = e.Action
+	}
+	assert.Equal(t, []domain.AuditAction{domain.AuditCreate, domain.AuditUpdate, domain.AuditTrash, domain.AuditPurge}, actions)
+
+	created := entries[0]
+	assert.Equal(t, &user, created.UserID)
+	assert.Equal(t, "req-1", created.RequestID)
+	before, after, err := created.Snapshots()
+	require.NoError(t, err)
+	assert.Nil(t, before)
+	require.NotNil(t, after)
+	assert.Equal(t, []string{"work"}, after.Tags)
+
+	before, after, err = entries[1].Snapshots()
+	require.NoError(t, err)
+	assert.Equal(t, "Audited", before.Title)
+	assert.Equal(t, "Audited twice", after.Title)
+	assert.Equal(t, before.Version+1, after.Version)
+
+	assert.Nil(t, entries[2].UserID)
+	assert.Empty(t, entries[2].RequestID)
+	before, after, err = entries[3].Snapshots()
+	require.NoError(t, err)
+	assert.NotNil(t, before.DeletedAt)
+	assert.Nil(t, after)
+
+	others, err := audit.ListByTodo(workspaceContext(), todo.ID)
+	require.NoError(t, err)
+	assert.Empty(t, others, "the trail is isolated by workspace")
+
+	t.Run("complete all", func(t *testing.T) {
+		a, _ := domain.NewTodo("A", "")
+		b, _ := domain.NewTodo("B", "")
+		require.NoError(t, repo.Create(ctx, a))
+		require.NoError(t, repo.Create(ctx, b))
+		require.NoError(t, b.Cancel())
+		require.NoError(t, repo.Update(ctx, b))
+
+		count, err := repo.CompleteAll(userCtx, nil, &user)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		entries, err := audit.ListByTodo(ctx, a.ID)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		before, after, err := entries[1].Snapshots()
+		require.NoError(t, err)
+		assert.Equal(t, domain.StatusOpen, before.Status)
+		assert.Equal(t, domain.StatusDone, after.Status)
+		assert.Equal(t, &user, after.UpdatedBy)
+
+		entries, err = audit.ListByTodo(ctx, b.ID)
+		require.NoError(t, err)
+		assert.Len(t, entries, 2, "cancelled todos are left alone")
+	})
+
+	t.Run("export", func(t *testing.T) {
+		var exported []domain.AuditEntry
+		err := audit.Export(ctx, start, func(e *domain.AuditEntry) error {
+			exported = append(exported, *e)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Len(t, exported, 9)
+		for i := 1; i < len(exported); i++ {
+			assert.False(t, exported[i].CreatedAt.Before(exported[i-1].CreatedAt))
+		}
+
+		err = audit.Export(ctx, time.Now().Add(time.Hour), func(*domain.AuditEntry) error {
+			return errors.New("nothing to export")
+		})
+		require.NoError(t, err)
+
+		stop := errors.New("stop")
+		err = audit.Export(ctx, start, func(*domain.AuditEntry) error { return stop })
+		assert.ErrorIs(t, err, stop)
+	})
+}
@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// whereBuilder accumulates AND-ed SQL conditions with positional arguments.
+type whereBuilder struct {
+	conds []string
+	args  []any
+}
+
+// add appends a condition. Each "?" in cond is replaced by the next
+// positional parameter, bound to the corresponding value in args.
+func (b *whereBuilder) add(cond string, args ...any) {
+	for _, arg := range args {
+		b.args = append(b.args, arg)
+		cond = strings.Replace(cond, "?", fmt.Sprintf("$%d", len(b.args)), 1)
+	}
+	b.conds = append(b.conds, cond)
+}
+
+// param binds arg to the next positional parameter and returns its
+// placeholder, for expressions outside the conditions.
+func (b *whereBuilder) param(arg any) string {
+	b.args = append(b.args, arg)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *whereBuilder) String() string {
+	if len(b.conds) == 0 {
+		return ""
+	}
+	return "\n\t\tWHERE " + strings.Join(b.conds, "\n\t\t\tAND ")
+}
+
+func buildTodoFilter(filter domain.TodoFilter) *whereBuilder {
+	b := &whereBuilder{}
+	if filter.Trashed {
+		b.add("deleted_at IS NOT NULL")
+	} else {
+		b.add("deleted_at IS NULL")
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, st := range filter.Statuses {
+			statuses[i] = string(st)
+		}
+		b.add("status = ANY(?)", statuses)
+	}
+	if filter.TitleContains != "" {
+		b.add("strpos(lower(title), lower(?)) > 0", filter.TitleContains)
+	}
+	if filter.DescriptionContains != "" {
+		b.add("strpos(lower(description), lower(?)) > 0", filter.DescriptionContains)
+	}
+	if filter.CreatedAfter != nil {
+		b.add("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		b.add("created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.UpdatedAfter != nil {
+		b.add("updated_at >= ?", *filter.UpdatedAfter)
+	}
+	if filter.UpdatedBefore != nil {
+		b.add("updated_at < ?", *filter.UpdatedBefore)
+	}
+	if filter.DueBefore != nil {
+		b.add("due_at IS NOT NULL AND "+dueDeadline+" < ?", *filter.DueBefore)
+	}
+	if filter.DueAfter != nil {
+		b.add("due_at IS NOT NULL AND "+dueDeadline+" >= ?", *filter.DueAfter)
+	}
+	if len(filter.Tags) > 0 {
+		tagged := `SELECT tt.todo_id FROM todo_tags tt JOIN tags tg ON tg.id = tt.tag_id
+			WHERE tg.name = ANY(?)`
+		if filter.TagMatch == domain.TagMatchAny {
+			b.add("id IN ("+tagged+")", filter.Tags)
+		} else {
+			b.add("id IN ("+tagged+" GROUP BY tt.todo_id HAVING count(*) = ?)", filter.Tags, len(filter.Tags))
+		}
+	}
+	if filter.ParentID != nil {
+		b.add("parent_id = ?", *filter.ParentID)
+	}
+	if filter.ProjectID != nil {
+		b.add("project_id = ?", *filter.ProjectID)
+	}
+	if filter.AssigneeID != nil {
+		b.add("assignee_id = ?", *filter.AssigneeID)
+	}
+	if filter.Overdue {
+		b.add(activeStatus + " AND due_at IS NOT NULL AND " + dueDeadline + " < NOW()")
+	}
+	return b
+}
+
+// addSearch restricts b to the todos matching search and returns the
+// expression ranking them, higher being better.
+func (b *whereBuilder) addSearch(search *domain.TodoSearch) string {
+	if search.Mode == domain.SearchNgram {
+		// Each term scores 1 if it is in the title and 0.4 if only in the
+		// description, as the weights of search_vector do. Similarity is of
+		// no use, short terms having no trigram in common with the text.
+		scores := make([]string, len(search.Terms))
+		for i, term := range search.Terms {
+			pattern := b.param("%" + likeEscaper.Replace(term) + "%")
+			b.add(fmt.Sprintf("(title ILIKE %s OR description ILIKE %s)", pattern, pattern))
+			scores[i] = fmt.Sprintf("CASE WHEN title ILIKE %s THEN 1 ELSE 0.4 END", pattern)
+		}
+		return fmt.Sprintf("((%s) / %d)::float8", strings.Join(scores, " + "), len(scores))
+	}
+	query := "websearch_to_tsquery('simple', " + b.param(search.Query) + ")"
+	b.add("search_vector @@ " + query)
+	return "ts_rank_cd(search_vector, " + query + ")"
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// sortColumn is the SQL expression a domain.SortField orders by and the
+// type its cursor values are cast to. Expressions never yield NULL so that
+// keyset comparisons stay well-defined.
+type sortColumn struct {
+	expr string
+	cast string
+}
+
+var sortColumns = map[domain.SortField]sortColumn{
+	domain.SortByCreatedAt: {expr: "created_at", cast: "timestamptz"},
+	domain.SortByUpdatedAt: {expr: "updated_at", cast: "timestamptz"},
+	domain.SortByTitle:     {expr: "title", cast: "text"},
+	domain.SortByDue:       {expr: "COALESCE(" + dueDeadline + ", 'infinity')", cast: "timestamptz"},
+	domain.SortByPriority:  {expr: "priority", cast: "smallint"},
+	domain.SortByRank:      {expr: "rank", cast: "text"},
+}
+
+// orderBy renders the ORDER BY clause of sort, ending with the ID tie-breaker.
+func orderBy(sort domain.TodoSort) string {
+	parts := make([]string, 0, len(sort)+1)
+	for _, k := range sort {
+		parts = append(parts, sortColumns[k.Field].expr+direction(k.Desc))
+	}
+	parts = append(parts, "id"+direction(sort.TiebreakDesc()))
+	return "\n\t\tORDER BY " + strings.Join(parts, ", ")
+}
+
+// addKeyset restricts b to the rows after cursor in sort order:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... OR (k1 = v1 AND ... AND id > vid),
+// with ">" turned into "<" for descending keys.
+func (b *whereBuilder) addKeyset(sort domain.TodoSort, cursor *domain.Cursor) {
+	var (
+		alts       []string
+		args       []any
+		prefix     []string // equality conditions on the preceding keys
+		prefixArgs []any
+	)
+	alt := func(cond string, arg any) {
+		conds := append(slices.Clone(prefix), cond)
+		alts = append(alts, "("+strings.Join(conds, " AND ")+")")
+		args = append(append(args, prefixArgs...), arg)
+	}
+	for i, k := range sort {
+		col := sortColumns[k.Field]
+		alt(col.expr+comparator(k.Desc)+"?::"+col.cast, cursor.Values[i])
+		prefix = append(prefix, col.expr+" = ?::"+col.cast)
+		prefixArgs = append(prefixArgs, cursor.Values[i])
+	}
+	alt("id"+comparator(sort.TiebreakDesc())+"?", cursor.ID)
+
+	b.add("("+strings.Join(alts, " OR ")+")", args...)
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+func comparator(desc bool) string {
+	if desc {
+		return " < "
+	}
+	return " > "
+}
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/auth"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// IdempotencyRepository stores the requests made with an idempotency key
+// and their responses. Keys belong to the caller of the request, in its
+// workspace: the same key sent by another caller is another key.
+type IdempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(pool *pgxpool.Pool) *IdempotencyRepository {
+	return &IdempotencyRepository{pool: pool}
+}
+
+// Reserve stores record as a request in progress, unless the caller holds
+// its key: answered and not expired, or in progress and not past its lock.
+// It then returns the record holding the key instead, and nil otherwise.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	caller := idempotencyCaller(ctx)
+	var held *domain.IdempotencyRecord
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, queryReserveIdempotencyKey, caller, record.Key, record.Fingerprint,
+			record.CreatedAt, record.LockedUntil, record.ExpiresAt)
+		if err != nil || tag.RowsAffected() > 0 {
+			return err
+		}
+		held, err = scanIdempotencyRecord(tx.QueryRow(ctx, queryGetIdempotencyKey, caller, record.Key))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return held, nil
+}
+
+// Complete stores the response to the request in progress with key. It
+// does nothing if the request is no longer in progress.
+func (r *IdempotencyRepository) Complete(ctx context.Context, key string, response *domain.StoredResponse) error {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+	_, err = execInWorkspace(ctx, r.pool, queryCompleteIdempotencyKey,
+		idempotencyCaller(ctx), key, response.Status, header, response.Body)
+	return err
+}
+
+// Release removes the request in progress with key, so that a retry runs
+// again. It does nothing if the request is no longer in progress.
+func (r *IdempotencyRepository) Release(ctx context.Context, key string) error {
+	_, err := execInWorkspace(ctx, r.pool, queryReleaseIdempotencyKey, idempotencyCaller(ctx), key)
+	return err
+}
+
+// DeleteExpired removes the keys of every workspace that expired at or
+// before the given time. It runs outside of row-level security, like the
+// outbox relay.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, queryDeleteExpiredIdempotencyKeys, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// idempotencyCaller identifies the caller of ctx by the user it acts as
+// and its authenticated subject. A user ID has no colon, so the first one
+// ends it.
+func idempotencyCaller(ctx context.Context) string {
+	var user, subject string
+	if id, ok := actor.UserID(ctx); ok {
+		user = id.String()
+	}
+	if p, ok := auth.PrincipalFrom(ctx); ok {
+		subject = p.Subject
+	}
+	return user + ":" + subject
+}
+
+func scanIdempotencyRecord(row pgx.Row) (*domain.IdempotencyRecord, error) {
+	var (
+		rec    domain.IdempotencyRecord
+		status *int32
+		header []byte
+		body   []byte
+	)
+	if err := row.Scan(&rec.Key, &rec.Fingerprint, &status, &header, &body,
+		&rec.CreatedAt, &rec.LockedUntil, &rec.ExpiresAt); err != nil {
+		return nil, err
+	}
+	if status != nil {
+		rec.Response = &domain.StoredResponse{Status: int(*status), Body: body}
+		if err := json.Unmarshal(header, &rec.Response.Header); err != nil {
+			return nil, err
+		}
+	}
+	return &rec, nil
+}
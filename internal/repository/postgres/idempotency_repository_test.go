@@ -0,0 +1,71 @@
+package postgres_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyRepository(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewIdempotencyRepository(pool)
+	ctx := actor.WithUser(workspaceContext(), uuid.New())
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	rec, err := domain.NewIdempotencyRecord("key-1", "fp", now)
+	require.NoError(t, err)
+	held, err := repo.Reserve(ctx, rec)
+	require.NoError(t, err)
+	assert.Nil(t, held)
+
+	held, err = repo.Reserve(ctx, rec)
+	require.NoError(t, err)
+	require.NotNil(t, held)
+	assert.Equal(t, "fp", held.Fingerprint)
+	assert.Nil(t, held.Response, "the request is in progress")
+
+	other, err := repo.Reserve(actor.WithUser(ctx, uuid.New()), rec)
+	require.NoError(t, err)
+	assert.Nil(t, other, "keys are scoped to their caller")
+
+	response := &domain.StoredResponse{
+		Status: http.StatusCreated,
+		Header: map[string][]string{"Etag": {`"1"`}},
+		Body:   []byte(`{"title":"a"}`),
+	}
+	require.NoError(t, repo.Complete(ctx, "key-1", response))
+	held, err = repo.Reserve(ctx, rec)
+	require.NoError(t, err)
+	require.NotNil(t, held)
+	assert.Equal(t, response, held.Response)
+	require.NoError(t, repo.Release(ctx, "key-1"))
+	held, err = repo.Reserve(ctx, rec)
+	require.NoError(t, err)
+	assert.NotNil(t, held, "answered requests are not released")
+
+	// A request in progress past its lock is taken over.
+	stale, err := domain.NewIdempotencyRecord("key-2", "fp", now.Add(-time.Hour))
+	require.NoError(t, err)
+	_, err = repo.Reserve(ctx, stale)
+	require.NoError(t, err)
+	rec2, err := domain.NewIdempotencyRecord("key-2", "fp", now)
+	require.NoError(t, err)
+	held, err = repo.Reserve(ctx, rec2)
+	require.NoError(t, err)
+	assert.Nil(t, held)
+	require.NoError(t, repo.Release(ctx, "key-2"))
+	held, err = repo.Reserve(ctx, rec2)
+	require.NoError(t, err)
+	assert.Nil(t, held, "released keys are free")
+
+	count, err := repo.DeleteExpired(ctx, now.Add(domain.IdempotencyKeyTTL))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// listen runs the LISTEN statement query and calls handle with the
+// payload of every notification received, until ctx is done, the
+// connection fails or handle fails. It calls ready, if not nil, once it is
+// listening. The connection is taken out of the pool for good, since it
+// would keep listening if it were returned; handle may query on it.
+func listen(ctx context.Context, pool *pgxpool.Pool, query string, ready func(), handle func(conn *pgx.Conn, payload string) error) error {
+	pooled, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, query); err != nil {
+		return err
+	}
+	if ready != nil {
+		ready()
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		if err := handle(conn, n.Payload); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// OutboxRepository hands out the events that TodoRepository writes to the
+// outbox for delivery.
+type OutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOutboxRepository(pool *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{pool: pool}
+}
+
+// Dispatch calls deliver with up to limit events of any workspace that are
+// due for delivery, oldest first, and records the outcome. It runs outside
+// of row-level security, as the relay serves every workspace. The events
+// stay locked until the outcomes are committed, so an event is handed to
+// one relay at a time; if the commit fails, the events are delivered again.
+func (r *OutboxRepository) Dispatch(ctx context.Context, limit int, deliver func(context.Context, *domain.Event) error) (delivered, failed int, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		delivered, failed = 0, 0
+		rows, err := tx.Query(ctx, queryClaimEvents, limit)
+		if err != nil {
+			return err
+		}
+		events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Event, error) {
+			var e domain.Event
+			err := row.Scan(&e.ID, &e.Type, &e.WorkspaceID, &e.TodoID, &e.Data, &e.OccurredAt, &e.Attempts)
+			return e, err
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := range events {
+			e := &events[i]
+			if derr := deliver(ctx, e); derr != nil {
+				e.Attempts++
+				next := time.Now().Add(domain.RetryDelay(e.Attempts))
+				if _, err := tx.Exec(ctx, queryMarkEventFailed, e.ID, derr.Error(), next); err != nil {
+					return err
+				}
+				failed++
+				continue
+			}
+			if _, err := tx.Exec(ctx, queryMarkEventDelivered, e.ID); err != nil {
+				return err
+			}
+			delivered++
+		}
+		return nil
+	})
+	return delivered, failed, err
+}
+
+// ListSince returns up to limit events of the workspace that were written
+// by transactions at or after horizon, from after the event at position
+// after on, in the order they were written.
+func (r *OutboxRepository) ListSince(ctx context.Context, horizon, after int64, limit int) ([]domain.Event, error) {
+	var events []domain.Event
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, queryListEventsSince, horizon, after, limit)
+		if err != nil {
+			return err
+		}
+		events, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Event, error) {
+			return scanEvent(row)
+		})
+		return err
+	})
+	return events, err
+}
+
+// Listen calls publish with the events of every workspace as they are
+// committed, until ctx is done or the connection fails, and calls ready
+// once it is listening. The events are read outside of row-level
+// security on a connection taken out of the pool for good, since it would
+// keep listening if it were returned.
+func (r *OutboxRepository) Listen(ctx context.Context, ready func(), publish func(*domain.Event)) error {
+	return listen(ctx, r.pool, queryListenEvents, ready, func(conn *pgx.Conn, payload string) error {
+		seq, err := strconv.ParseInt(payload, 10, 64)
+		if err != nil {
+			return fmt.Errorf("parse event notification %q: %w", payload, err)
+		}
+		e, err := scanEvent(conn.QueryRow(ctx, queryGetEventBySeq, seq))
+		if err != nil {
+			return err
+		}
+		publish(&e)
+		return nil
+	})
+}
+
+func scanEvent(row pgx.Row) (domain.Event, error) {
+	var e domain.Event
+	err := row.Scan(&e.Seq, &e.ID, &e.Type, &e.WorkspaceID, &e.TodoID, &e.Data, &e.OccurredAt, &e.Horizon)
+	return e, err
+}
+
+// enqueueEvents writes events to the outbox in tx, the transaction making
+// the changes they announce, so that an event is published if and only if
+// its change is committed.
+func enqueueEvents(ctx context.Context, tx pgx.Tx, events ...*domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	batch := &pgx.Batch{}
+	for _, e := range events {
+		batch.Queue(queryInsertEvent, e.ID, e.Type, e.TodoID, e.Data, e.OccurredAt)
+	}
+	return tx.SendBatch(ctx, batch).Close()
+}
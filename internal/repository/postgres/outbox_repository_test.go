@@ -0,0 +1,187 @@
+package postgres_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/repository/postgres"
+	"github.com/knjname/go-todo-api/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRepository_Dispatch(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	outbox := postgres.NewOutboxRepository(pool)
+	ctx := workspaceContext()
+	workspace, _ := tenant.WorkspaceID(ctx)
+
+	todo, err := domain.NewTodo("Ship it", "")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, todo))
+	require.NoError(t, todo.MarkComplete())
+	require.NoError(t, repo.Update(ctx, todo))
+	todo.MoveToTrash()
+	require.NoError(t, repo.Update(ctx, todo))
+	_, err = repo.PurgeTrash(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	otherCtx := workspaceContext()
+	other, err := domain.NewTodo("Other", "")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(otherCtx, other))
+	stale := *other
+	stale.Version++
+	require.ErrorIs(t, repo.Update(otherCtx, &stale), domain.ErrConflict, "failed writes publish nothing")
+
+	active, err := domain.NewTodo("Active", "")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, active))
+	_, err = repo.CompleteAll(ctx, nil, nil)
+	require.NoError(t, err)
+
+	var seen []domain.Event
+	delivered, failed, err := outbox.Dispatch(context.Background(), 100, func(ctx context.Context, e *domain.Event) error {
+		seen = append(seen, *e)
+		// Concurrent relays skip the events being delivered.
+		n, m, err := outbox.Dispatch(ctx, 100, func(context.Context, *domain.Event) error { return nil })
+		require.NoError(t, err)
+		assert.Zero(t, n+m)
+
+		if e.Type == domain.EventTodoCreated && *e.TodoID == todo.ID {
+			return errors.New("receiver unavailable")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5, delivered)
+	assert.Equal(t, 1, failed)
+
+	types := make([]domain.EventType, len(seen))
+	for i, e := range seen {
+		types[i] = e.Type
+	}
+	assert.Equal(t, []domain.EventType{
+		domain.EventTodoCreated, domain.EventTodoCompleted, domain.EventTodoDeleted,
+		domain.EventTodoCreated, domain.EventTodoCreated, domain.EventAllCompleted,
+	}, types, "the outbox is relayed across workspaces, oldest first")
+	assert.Equal(t, workspace, seen[0].WorkspaceID)
+	assert.NotEqual(t, workspace, seen[3].WorkspaceID)
+
+	var completed domain.Todo
+	require.NoError(t, json.Unmarshal(seen[1].Data, &completed))
+	assert.Equal(t, domain.StatusDone, completed.Status)
+	var all domain.AllCompletedData
+	require.NoError(t, json.Unmarshal(seen[5].Data, &all))
+	assert.Equal(t, []uuid.UUID{active.ID}, all.TodoIDs)
+
+	delivered, failed, err = outbox.Dispatch(context.Background(), 100, func(context.Context, *domain.Event) error { return nil })
+	require.NoError(t, err)
+	assert.Zero(t, delivered+failed, "a failed event waits for its retry")
+
+	time.Sleep(domain.RetryDelay(1) + 100*time.Millisecond)
+	seen = nil
+	delivered, _, err = outbox.Dispatch(context.Background(), 100, func(_ context.Context, e *domain.Event) error {
+		seen = append(seen, *e)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	require.Len(t, seen, 1)
+	assert.Equal(t, todo.ID, *seen[0].TodoID)
+	assert.Equal(t, 1, seen[0].Attempts)
+}
+
+func TestOutboxRepository_Listen(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	outbox := postgres.NewOutboxRepository(pool)
+	ctx := workspaceContext()
+
+	first, err := domain.NewTodo("Before listening", "")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, first))
+
+	listenCtx, stop := context.WithCancel(context.Background())
+	listening := make(chan struct{})
+	published := make(chan domain.Event, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- outbox.Listen(listenCtx, func() { close(listening) }, func(e *domain.Event) { published <- *e })
+	}()
+	<-listening
+
+	second, err := domain.NewTodo("While listening", "")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, second))
+	other, err := domain.NewTodo("Other workspace", "")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(workspaceContext(), other))
+
+	var seen []domain.Event
+	for range 2 {
+		select {
+		case e := <-published:
+			seen = append(seen, e)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event was published")
+		}
+	}
+	assert.Equal(t, second.ID, *seen[0].TodoID)
+	assert.Equal(t, other.ID, *seen[1].TodoID, "events of every workspace are published")
+	assert.Greater(t, seen[1].Seq, seen[0].Seq)
+
+	stop()
+	require.Error(t, <-done)
+
+	missed, err := outbox.ListSince(ctx, 1, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, missed, 2, "events of other workspaces are not listed")
+	assert.Equal(t, first.ID, *missed[0].TodoID)
+	missed, err = outbox.ListSince(ctx, 1, missed[0].Seq, 10)
+	require.NoError(t, err)
+	require.Len(t, missed, 1)
+	assert.Equal(t, seen[0].ID, missed[0].ID)
+}
+
+func TestOutboxRepository_ListSince(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	outbox := postgres.NewOutboxRepository(pool)
+	ctx := workspaceContext()
+	workspace, _ := tenant.WorkspaceID(ctx)
+
+	// An event written first but committed last.
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback(ctx) }()
+	_, err = tx.Exec(ctx, `SELECT set_config('app.workspace_id', $1, true)`, workspace.String())
+	require.NoError(t, err)
+	late := uuid.New()
+	_, err = tx.Exec(ctx, `INSERT INTO outbox (event_id, type, data, occurred_at) VALUES ($1, 'TodoUpdated', '{}', NOW())`, late)
+	require.NoError(t, err)
+
+	todo, err := domain.NewTodo("Committed first", "")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, todo))
+	received, err := outbox.ListSince(ctx, 1, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	assert.Positive(t, received[0].Horizon)
+	require.NoError(t, tx.Commit(ctx))
+
+	missed, err := outbox.ListSince(ctx, received[0].Horizon, 0, 10)
+	require.NoError(t, err)
+	ids := make([]uuid.UUID, len(missed))
+	for i, e := range missed {
+		ids[i] = e.ID
+	}
+	assert.Equal(t, []uuid.UUID{late, received[0].ID}, ids, "events committed later are listed from the horizon, whatever their position")
+	assert.Less(t, missed[0].Seq, received[0].Seq)
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// PresenceRepository passes presences between processes as notifications,
+// without storing them: a presence only matters to the processes running
+// while it lasts.
+type PresenceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPresenceRepository(pool *pgxpool.Pool) *PresenceRepository {
+	return &PresenceRepository{pool: pool}
+}
+
+func (r *PresenceRepository) Announce(ctx context.Context, p *domain.Presence) error {
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, queryAnnouncePresence, string(payload))
+	return err
+}
+
+// Listen calls announced with the presences of every workspace as they
+// are announced, until ctx is done or the connection fails. Like
+// OutboxRepository.Listen, it keeps a connection out of the pool.
+func (r *PresenceRepository) Listen(ctx context.Context, announced func(*domain.Presence)) error {
+	return listen(ctx, r.pool, queryListenPresence, nil, func(_ *pgx.Conn, payload string) error {
+		var p domain.Presence
+		if err := json.Unmarshal([]byte(payload), &p); err != nil {
+			return fmt.Errorf("parse presence notification: %w", err)
+		}
+		announced(&p)
+		return nil
+	})
+}
@@ -0,0 +1,54 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresenceRepository(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewPresenceRepository(pool)
+	ctx := context.Background()
+
+	listenCtx, stop := context.WithCancel(ctx)
+	announced := make(chan domain.Presence, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- repo.Listen(listenCtx, func(p *domain.Presence) { announced <- *p })
+	}()
+
+	user := uuid.New()
+	p := &domain.Presence{
+		ConnectionID: uuid.New(), WorkspaceID: uuid.New(), UserID: &user,
+		ProjectIDs: []uuid.UUID{uuid.New()}, At: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	// Listening starts in the background, so announce until it is heard.
+	var got domain.Presence
+	deadline := time.After(5 * time.Second)
+heard:
+	for {
+		require.NoError(t, repo.Announce(ctx, p))
+		select {
+		case got = <-announced:
+			break heard
+		case <-time.After(100 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("the presence was not heard")
+		}
+	}
+	assert.Equal(t, p.ConnectionID, got.ConnectionID)
+	assert.Equal(t, p.WorkspaceID, got.WorkspaceID)
+	assert.Equal(t, p.UserID, got.UserID)
+	assert.Equal(t, p.ProjectIDs, got.ProjectIDs)
+	assert.True(t, p.At.Equal(got.At))
+
+	stop()
+	require.Error(t, <-done)
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+type ProjectRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewProjectRepository(pool *pgxpool.Pool) *ProjectRepository {
+	return &ProjectRepository{pool: pool}
+}
+
+func (r *ProjectRepository) Create(ctx context.Context, project *domain.Project) error {
+	_, err := execInWorkspace(ctx, r.pool, queryInsertProject,
+		project.ID, project.Name, project.Description, project.CreatedAt, project.UpdatedAt)
+	if isUniqueViolation(err) {
+		return domain.ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *ProjectRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Project, error) {
+	var p domain.Project
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		p, err = scanProject(tx.QueryRow(ctx, queryGetProjectByID, id))
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// List returns every project ordered by name.
+func (r *ProjectRepository) List(ctx context.Context) ([]domain.Project, error) {
+	var projects []domain.Project
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, queryListProjects)
+		if err != nil {
+			return err
+		}
+		projects, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Project, error) {
+			return scanProject(row)
+		})
+		return err
+	})
+	return projects, err
+}
+
+func (r *ProjectRepository) Update(ctx context.Context, project *domain.Project) error {
+	result, err := execInWorkspace(ctx, r.pool, queryUpdateProject,
+		project.ID, project.Name, project.Description, project.UpdatedAt)
+	if isUniqueViolation(err) {
+		return domain.ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes the project. Its todos are kept outside any project.
+func (r *ProjectRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := execInWorkspace(ctx, r.pool, queryDeleteProject, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanProject(row pgx.Row) (domain.Project, error) {
+	var p domain.Project
+	err := row.Scan(&p.ID, &p.Name, &p.Description, &p.CreatedAt, &p.UpdatedAt)
+	return p, err
+}
@@ -0,0 +1,87 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectRepository_CRUD(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewProjectRepository(pool)
+	ctx := workspaceContext()
+
+	website, _ := domain.NewProject("Website", "relaunch")
+	app, _ := domain.NewProject("App", "")
+	require.NoError(t, repo.Create(ctx, website))
+	require.NoError(t, repo.Create(ctx, app))
+
+	dup, _ := domain.NewProject("Website", "")
+	assert.ErrorIs(t, repo.Create(ctx, dup), domain.ErrAlreadyExists)
+
+	projects, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	assert.Equal(t, "App", projects[0].Name)
+
+	require.NoError(t, website.Update("Web shop", "new scope"))
+	require.NoError(t, repo.Update(ctx, website))
+	got, err := repo.GetByID(ctx, website.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Web shop", got.Name)
+	assert.Equal(t, "new scope", got.Description)
+
+	require.NoError(t, app.Update("Web shop", ""))
+	assert.ErrorIs(t, repo.Update(ctx, app), domain.ErrAlreadyExists)
+
+	require.NoError(t, repo.Delete(ctx, website.ID))
+	_, err = repo.GetByID(ctx, website.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, uuid.New()), domain.ErrNotFound)
+}
+
+func TestTodoRepository_Projects(t *testing.T) {
+	pool := setupTestDB(t)
+	todos := postgres.NewTodoRepository(pool)
+	projects := postgres.NewProjectRepository(pool)
+	ctx := workspaceContext()
+
+	website, _ := domain.NewProject("Website", "")
+	app, _ := domain.NewProject("App", "")
+	require.NoError(t, projects.Create(ctx, website))
+	require.NoError(t, projects.Create(ctx, app))
+
+	inWebsite, _ := domain.NewTodo("Website todo", "", domain.WithProject(&website.ID))
+	inApp, _ := domain.NewTodo("App todo", "", domain.WithProject(&app.ID))
+	loose, _ := domain.NewTodo("Loose todo", "")
+	for _, td := range []*domain.Todo{inWebsite, inApp, loose} {
+		require.NoError(t, todos.Create(ctx, td))
+	}
+
+	list, err := todos.List(ctx, domain.TodoFilter{ProjectID: &website.ID}, domain.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, inWebsite.ID, list[0].ID)
+	assert.Equal(t, &website.ID, list[0].ProjectID)
+
+	// Todos move between projects with a plain update.
+	inApp.SetProject(&website.ID)
+	require.NoError(t, todos.Update(ctx, inApp))
+
+	count, err := todos.CompleteAll(ctx, &website.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	got, err := todos.GetByID(ctx, loose.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusOpen, got.Status)
+
+	// Deleting a project keeps its todos.
+	require.NoError(t, projects.Delete(ctx, website.ID))
+	got, err = todos.GetByID(ctx, inWebsite.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.ProjectID)
+}
@@ -1,30 +1,445 @@
 package postgres
 
+// queryEnterWorkspace switches a transaction to the todo_app role, which
+// is subject to row-level security, and sets the workspace it may access.
+const queryEnterWorkspace = `
+	SELECT set_config('role', 'todo_app', true), set_config('app.workspace_id', $1, true)`
+
 const (
+	todoColumns = `id, title, description, status, priority, created_at, updated_at, due_at, due_all_day, due_timezone, version, deleted_at, parent_id,
+		recurrence_rule, recurrence_timezone, series_id, project_id, created_by, updated_by, assignee_id, rank`
+
+	// todoSelectColumns adds the sorted tag names to todoColumns. They are
+	// gathered by a correlated subquery so that lists load in a single query.
+	todoSelectColumns = todoColumns + `,
+		ARRAY(SELECT tg.name FROM todo_tags tt JOIN tags tg ON tg.id = tt.tag_id
+			WHERE tt.todo_id = todos.id ORDER BY tg.name) AS tags`
+
+	// dueDeadline is the instant a todo's due date passes. All-day due dates
+	// last until the end of the calendar day in their own time zone.
+	dueDeadline = `CASE WHEN due_all_day
+		THEN (due_at AT TIME ZONE due_timezone + INTERVAL '1 day') AT TIME ZONE due_timezone
+		ELSE due_at END`
+
+	// activeStatus matches todos that still need work.
+	activeStatus = `status IN ('open', 'in_progress')`
+
 	queryInsertTodo = `
-		INSERT INTO todos (id, title, description, completed, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`
+		INSERT INTO todos (` + todoColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)`
 
 	queryGetTodoByID = `
-		SELECT id, title, description, completed, created_at, updated_at
+		SELECT ` + todoSelectColumns + `
 		FROM todos
-		WHERE id = $1`
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	queryGetTrashedTodoByID = `
+		SELECT ` + todoSelectColumns + `
+		FROM todos
+		WHERE id = $1 AND deleted_at IS NOT NULL`
 
 	queryListTodos = `
-		SELECT id, title, description, completed, created_at, updated_at
+		SELECT ` + todoSelectColumns + `
+		FROM todos`
+
+	// querySearchTodos selects todoSelectColumns followed by the rank of
+	// a search, an expression to format in.
+	querySearchTodos = `
+		SELECT ` + todoSelectColumns + `, %s AS search_rank
+		FROM todos`
+
+	// queryListDescendants walks the live subtasks of the todos $1 at any
+	// depth. UNION rather than UNION ALL stops the walk should the data ever
+	// contain a cycle.
+	queryListDescendants = `
+		WITH RECURSIVE subtree (id) AS (
+			SELECT id FROM todos WHERE parent_id = ANY($1) AND deleted_at IS NULL
+			UNION
+			SELECT t.id FROM todos t JOIN subtree s ON t.parent_id = s.id
+			WHERE t.deleted_at IS NULL
+		)
+		SELECT ` + todoSelectColumns + `
 		FROM todos
-		ORDER BY created_at DESC`
+		WHERE id IN (SELECT id FROM subtree)
+		ORDER BY created_at, id`
+
+	// queryListRecurring selects the latest occurrence of every series,
+	// trashed or not, unless its recurrence has been removed.
+	queryListRecurring = `
+		SELECT ` + todoSelectColumns + `
+		FROM todos
+		WHERE recurrence_rule IS NOT NULL AND id IN (
+			SELECT DISTINCT ON (series_id) id
+			FROM todos
+			WHERE series_id IS NOT NULL
+			ORDER BY series_id, due_at DESC NULLS LAST, created_at DESC
+		)`
+
+	queryTodoExists = `
+		SELECT EXISTS (SELECT 1 FROM todos WHERE id = $1)`
 
 	queryUpdateTodo = `
 		UPDATE todos
-		SET title = $2, description = $3, completed = $4, updated_at = $5
-		WHERE id = $1`
+		SET title = $2, description = $3, status = $4, priority = $5, updated_at = $6,
+			due_at = $7, due_all_day = $8, due_timezone = $9, deleted_at = $10, parent_id = $12,
+			recurrence_rule = $13, recurrence_timezone = $14, series_id = $15, project_id = $16,
+			updated_by = $17, assignee_id = $18, rank = $19, version = version + 1
+		WHERE id = $1 AND version = $11`
 
-	queryDeleteTodo = `
-		DELETE FROM todos WHERE id = $1`
+	// queryLockTodo selects a todo, live or trashed, and locks it until
+	// the end of the transaction, so that its audited snapshot stays
+	// accurate.
+	queryLockTodo = `
+		SELECT ` + todoSelectColumns + `
+		FROM todos
+		WHERE id = $1
+		FOR UPDATE`
+
+	queryListTodosByID = `
+		SELECT ` + todoSelectColumns + `
+		FROM todos
+		WHERE id = ANY($1)`
+
+	queryListLiveTodosByID = `
+		SELECT ` + todoSelectColumns + `
+		FROM todos
+		WHERE id = ANY($1) AND deleted_at IS NULL`
+
+	// queryLockTodosByID selects the todos $1, live or trashed, and locks
+	// them in a fixed order, so that concurrent bulk writes do not deadlock.
+	queryLockTodosByID = `
+		SELECT ` + todoSelectColumns + `
+		FROM todos
+		WHERE id = ANY($1)
+		ORDER BY id
+		FOR UPDATE`
 
+	// queryBulkUpdateTodos writes the fields bulk actions change to the
+	// todos $1 that are still at the versions $2, one row per array index.
+	queryBulkUpdateTodos = `
+		UPDATE todos AS t
+		SET status = v.status, deleted_at = v.deleted_at, parent_id = v.parent_id, project_id = v.project_id,
+			updated_at = v.updated_at, updated_by = v.updated_by, version = t.version + 1
+		FROM unnest($1::uuid[], $2::bigint[], $3::text[], $4::timestamptz[], $5::uuid[], $6::uuid[], $7::timestamptz[], $8::uuid[])
+			AS v (id, version, status, deleted_at, parent_id, project_id, updated_at, updated_by)
+		WHERE t.id = v.id AND t.version = v.version`
+
+	// queryLockPurgeable selects and locks the todos trashed before $1.
+	queryLockPurgeable = `
+		SELECT ` + todoSelectColumns + `
+		FROM todos
+		WHERE deleted_at < $1
+		FOR UPDATE`
+
+	queryDeleteTodos = `
+		DELETE FROM todos WHERE id = ANY($1)`
+
+	// queryLockActive selects and locks the active todos of the project $1,
+	// or of every project if $1 is NULL.
+	queryLockActive = `
+		SELECT ` + todoSelectColumns + `
+		FROM todos
+		WHERE deleted_at IS NULL AND ` + activeStatus + `
+			AND ($1::uuid IS NULL OR project_id = $1)
+		FOR UPDATE`
+
+	// queryCompleteAll completes the todos $1 on behalf of the user $2.
 	queryCompleteAll = `
 		UPDATE todos
-		SET completed = TRUE, updated_at = NOW()
-		WHERE completed = FALSE`
+		SET status = 'done', updated_at = NOW(), updated_by = $2, version = version + 1
+		WHERE id = ANY($1)`
+
+	// queryEnsureTags creates the named tags that do not exist yet.
+	queryEnsureTags = `
+		INSERT INTO tags (id, name)
+		SELECT gen_random_uuid(), name FROM unnest($1::text[]) AS name
+		ON CONFLICT (workspace_id, name) DO NOTHING`
+
+	queryClearTodoTags = `
+		DELETE FROM todo_tags WHERE todo_id = $1`
+
+	queryLinkTodoTags = `
+		INSERT INTO todo_tags (todo_id, tag_id)
+		SELECT $1, id FROM tags WHERE name = ANY($2)`
+
+	queryClearTodosTags = `
+		DELETE FROM todo_tags WHERE todo_id = ANY($1)`
+
+	// queryLinkTodosTags attaches to each todo $1 the tag named at the same
+	// index of $2.
+	queryLinkTodosTags = `
+		INSERT INTO todo_tags (todo_id, tag_id)
+		SELECT v.todo_id, tg.id
+		FROM unnest($1::uuid[], $2::text[]) AS v (todo_id, name)
+		JOIN tags tg ON tg.name = v.name`
+)
+
+const (
+	tagColumns = `id, name, created_at`
+
+	queryInsertTag = `
+		INSERT INTO tags (` + tagColumns + `)
+		VALUES ($1, $2, $3)`
+
+	queryGetTagByID = `
+		SELECT ` + tagColumns + `
+		FROM tags
+		WHERE id = $1`
+
+	queryListTags = `
+		SELECT ` + tagColumns + `
+		FROM tags
+		ORDER BY name`
+
+	queryUpdateTag = `
+		UPDATE tags SET name = $2 WHERE id = $1`
+
+	queryDeleteTag = `
+		DELETE FROM tags WHERE id = $1`
+)
+
+const (
+	projectColumns = `id, name, description, created_at, updated_at`
+
+	queryInsertProject = `
+		INSERT INTO projects (` + projectColumns + `)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	queryGetProjectByID = `
+		SELECT ` + projectColumns + `
+		FROM projects
+		WHERE id = $1`
+
+	queryListProjects = `
+		SELECT ` + projectColumns + `
+		FROM projects
+		ORDER BY name, id`
+
+	queryUpdateProject = `
+		UPDATE projects SET name = $2, description = $3, updated_at = $4 WHERE id = $1`
+
+	queryDeleteProject = `
+		DELETE FROM projects WHERE id = $1`
+)
+
+const (
+	apiKeyColumns = `id, workspace_id, name, scopes, secret_hash, expires_at, last_used_at, revoked_at, created_at`
+
+	// queryInsertAPIKey leaves workspace_id to its default, the workspace
+	// of the transaction.
+	queryInsertAPIKey = `
+		INSERT INTO api_keys (id, name, scopes, secret_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING workspace_id`
+
+	queryListAPIKeys = `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		ORDER BY created_at DESC, id`
+
+	queryRevokeAPIKey = `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1`
+
+	queryGetAPIKeyByID = `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE id = $1`
+
+	queryMarkAPIKeyUsed = `
+		UPDATE api_keys SET last_used_at = $2 WHERE id = $1`
+)
+
+const (
+	userColumns = `id, name, email, role, created_at`
+
+	queryInsertUser = `
+		INSERT INTO users (` + userColumns + `)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	queryGetUserByID = `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = $1`
+
+	queryListUsers = `
+		SELECT ` + userColumns + `
+		FROM users
+		ORDER BY name, id`
+
+	queryUpdateUser = `
+		UPDATE users SET name = $2, email = $3, role = $4 WHERE id = $1`
+)
+
+const (
+	auditColumns = `id, todo_id, action, user_id, subject, request_id, before, after, created_at`
+
+	queryInsertAudit = `
+		INSERT INTO todo_audit (todo_id, action, user_id, subject, request_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	queryListTodoAudit = `
+		SELECT ` + auditColumns + `
+		FROM todo_audit
+		WHERE todo_id = $1
+		ORDER BY id`
+
+	queryExportAudit = `
+		SELECT ` + auditColumns + `
+		FROM todo_audit
+		WHERE created_at >= $1
+		ORDER BY created_at, id`
+)
+
+const (
+	eventColumns = `id, event_id, type, workspace_id, todo_id, data, occurred_at, horizon::text::bigint`
+
+	// queryInsertEvent writes an event and, once the transaction commits,
+	// notifies the listeners of outbox_events of its position.
+	queryInsertEvent = `
+		WITH e AS (
+			INSERT INTO outbox (event_id, type, todo_id, data, occurred_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		)
+		SELECT pg_notify('outbox_events', id::text) FROM e`
+
+	queryListenEvents = `LISTEN outbox_events`
+
+	queryGetEventBySeq = `
+		SELECT ` + eventColumns + `
+		FROM outbox
+		WHERE id = $1`
+
+	// queryListEventsSince lists the events written by transactions at or
+	// after a horizon, from a position on.
+	queryListEventsSince = `
+		SELECT ` + eventColumns + `
+		FROM outbox
+		WHERE xid >= $1::bigint::text::xid8 AND id > $2
+		ORDER BY id
+		LIMIT $3`
+
+	queryAnnouncePresence = `SELECT pg_notify('board_presence', $1)`
+
+	queryListenPresence = `LISTEN board_presence`
+
+	// queryClaimEvents locks the events due for delivery, oldest first.
+	// Events locked by a concurrent relay are skipped rather than waited
+	// for, so that relays share the outbox.
+	queryClaimEvents = `
+		SELECT event_id, type, workspace_id, todo_id, data, occurred_at, attempts
+		FROM outbox
+		WHERE delivered_at IS NULL AND next_attempt_at <= NOW()
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+
+	queryMarkEventDelivered = `
+		UPDATE outbox SET delivered_at = NOW(), last_error = NULL
+		WHERE event_id = $1`
+
+	queryMarkEventFailed = `
+		UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE event_id = $1`
+)
+
+const (
+	webhookColumns = `id, url, secret, events, created_at, updated_at`
+
+	queryInsertWebhook = `
+		INSERT INTO webhooks (id, url, secret, events, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	queryGetWebhookByID = `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE id = $1`
+
+	queryListWebhooks = `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		ORDER BY created_at, id`
+
+	querySubscribedWebhooks = `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE cardinality(events) = 0 OR $1 = ANY(events)
+		ORDER BY created_at, id`
+
+	queryUpdateWebhook = `
+		UPDATE webhooks SET url = $2, secret = $3, events = $4, updated_at = $5
+		WHERE id = $1`
+
+	queryDeleteWebhook = `DELETE FROM webhooks WHERE id = $1`
+)
+
+const (
+	webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts,
+		response_status, last_error, next_attempt_at, last_attempt_at, created_at`
+
+	// queryInsertWebhookDelivery ignores an event already queued for the
+	// webhook, as the outbox relay may deliver an event more than once.
+	queryInsertWebhookDelivery = `
+		INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (webhook_id, event_id) DO NOTHING`
+
+	queryListWebhookDeliveries = `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2`
+
+	// queryClaimWebhookDeliveries locks the deliveries due for an attempt,
+	// oldest first, with their webhooks. Deliveries locked by a concurrent
+	// worker are skipped rather than waited for.
+	queryClaimWebhookDeliveries = `
+		SELECT d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+			d.response_status, d.last_error, d.next_attempt_at, d.last_attempt_at, d.created_at,
+			w.id, w.url, w.secret, w.events, w.created_at, w.updated_at
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT $1
+		FOR UPDATE OF d SKIP LOCKED`
+
+	queryRecordWebhookAttempt = `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, response_status = $4, last_error = $5,
+			next_attempt_at = $6, last_attempt_at = $7
+		WHERE id = $1`
+)
+
+const (
+	// queryReserveIdempotencyKey stores a request in progress, taking over
+	// the key from an expired request or from one in progress that is past
+	// its lock. It affects no row if the key is held.
+	queryReserveIdempotencyKey = `
+		INSERT INTO idempotency_keys (caller, key, fingerprint, created_at, locked_until, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (workspace_id, caller, key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status = NULL, header = NULL, body = NULL,
+			created_at = EXCLUDED.created_at, locked_until = EXCLUDED.locked_until, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+			OR (idempotency_keys.status IS NULL AND idempotency_keys.locked_until <= EXCLUDED.created_at)`
+
+	queryGetIdempotencyKey = `
+		SELECT key, fingerprint, status, header, body, created_at, locked_until, expires_at
+		FROM idempotency_keys
+		WHERE caller = $1 AND key = $2`
+
+	queryCompleteIdempotencyKey = `
+		UPDATE idempotency_keys
+		SET status = $3, header = $4, body = $5
+		WHERE caller = $1 AND key = $2 AND status IS NULL`
+
+	queryReleaseIdempotencyKey = `
+		DELETE FROM idempotency_keys
+		WHERE caller = $1 AND key = $2 AND status IS NULL`
+
+	queryDeleteExpiredIdempotencyKeys = `
+		DELETE FROM idempotency_keys
+		WHERE expires_at <= $1`
 )
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+type TagRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTagRepository(pool *pgxpool.Pool) *TagRepository {
+	return &TagRepository{pool: pool}
+}
+
+func (r *TagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	_, err := execInWorkspace(ctx, r.pool, queryInsertTag, tag.ID, tag.Name, tag.CreatedAt)
+	if isUniqueViolation(err) {
+		return domain.ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *TagRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tag, error) {
+	var t domain.Tag
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, queryGetTagByID, id).Scan(&t.ID, &t.Name, &t.CreatedAt)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// List returns every tag ordered by name.
+func (r *TagRepository) List(ctx context.Context) ([]domain.Tag, error) {
+	var tags []domain.Tag
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, queryListTags)
+		if err != nil {
+			return err
+		}
+		tags, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Tag, error) {
+			var t domain.Tag
+			err := row.Scan(&t.ID, &t.Name, &t.CreatedAt)
+			return t, err
+		})
+		return err
+	})
+	return tags, err
+}
+
+func (r *TagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	result, err := execInWorkspace(ctx, r.pool, queryUpdateTag, tag.ID, tag.Name)
+	if isUniqueViolation(err) {
+		return domain.ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes the tag and detaches it from every todo.
+func (r *TagRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := execInWorkspace(ctx, r.pool, queryDeleteTag, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
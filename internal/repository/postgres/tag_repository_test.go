@@ -0,0 +1,97 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagRepository_CRUD(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTagRepository(pool)
+	ctx := workspaceContext()
+
+	backend, _ := domain.NewTag("backend")
+	api, _ := domain.NewTag("api")
+	require.NoError(t, repo.Create(ctx, backend))
+	require.NoError(t, repo.Create(ctx, api))
+
+	dup, _ := domain.NewTag("backend")
+	assert.ErrorIs(t, repo.Create(ctx, dup), domain.ErrAlreadyExists)
+
+	tags, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	assert.Equal(t, "api", tags[0].Name)
+
+	require.NoError(t, backend.Rename("server"))
+	require.NoError(t, repo.Update(ctx, backend))
+	got, err := repo.GetByID(ctx, backend.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "server", got.Name)
+
+	require.NoError(t, api.Rename("server"))
+	assert.ErrorIs(t, repo.Update(ctx, api), domain.ErrAlreadyExists)
+
+	require.NoError(t, repo.Delete(ctx, backend.ID))
+	_, err = repo.GetByID(ctx, backend.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, uuid.New()), domain.ErrNotFound)
+}
+
+func TestTodoRepository_Tags(t *testing.T) {
+	pool := setupTestDB(t)
+	todos := postgres.NewTodoRepository(pool)
+	tags := postgres.NewTagRepository(pool)
+	ctx := workspaceContext()
+
+	both, _ := domain.NewTodo("Both", "", domain.WithTags([]string{"backend", "urgent"}))
+	backendOnly, _ := domain.NewTodo("Backend only", "", domain.WithTags([]string{"backend"}))
+	untagged, _ := domain.NewTodo("Untagged", "")
+	for _, td := range []*domain.Todo{both, backendOnly, untagged} {
+		require.NoError(t, todos.Create(ctx, td))
+	}
+
+	// Tags are created on first use.
+	all, err := tags.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+
+	got, err := todos.GetByID(ctx, both.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend", "urgent"}, got.Tags)
+
+	ids := func(filter domain.TodoFilter) []uuid.UUID {
+		list, err := todos.List(ctx, filter, domain.PageRequest{Sort: domain.TodoSort{{Field: domain.SortByTitle}}})
+		require.NoError(t, err)
+		var out []uuid.UUID
+		for _, td := range list {
+			out = append(out, td.ID)
+		}
+		return out
+	}
+	assert.Equal(t, []uuid.UUID{both.ID},
+		ids(domain.TodoFilter{Tags: []string{"backend", "urgent"}, TagMatch: domain.TagMatchAll}))
+	assert.Equal(t, []uuid.UUID{backendOnly.ID, both.ID},
+		ids(domain.TodoFilter{Tags: []string{"backend", "urgent"}, TagMatch: domain.TagMatchAny}))
+
+	require.NoError(t, got.SetTags([]string{"frontend"}))
+	require.NoError(t, todos.Update(ctx, got))
+	got, err = todos.GetByID(ctx, both.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend"}, got.Tags)
+
+	// Deleting a tag detaches it from its todos.
+	for _, tag := range all {
+		if tag.Name == "backend" {
+			require.NoError(t, tags.Delete(ctx, tag.ID))
+		}
+	}
+	got, err = todos.GetByID(ctx, backendOnly.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Tags)
+}
@@ -3,6 +3,9 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -10,6 +13,9 @@ import (
 	"github.com/knjname/go-todo-api/internal/domain"
 )
 
+// TodoRepository stores todos. Every change it makes is recorded in the
+// audit trail and announced by a domain event in the outbox, in the same
+// transaction; see AuditRepository and OutboxRepository.
 type TodoRepository struct {
 	pool *pgxpool.Pool
 }
@@ -18,29 +24,370 @@ func NewTodoRepository(pool *pgxpool.Pool) *TodoRepository {
 	return &TodoRepository{pool: pool}
 }
 
+// Create stores a new todo together with its tags, creating tags that do not
+// exist yet. It returns domain.ErrAlreadyExists if the todo's series already
+// has an occurrence on the same due date.
 func (r *TodoRepository) Create(ctx context.Context, todo *domain.Todo) error {
-	_, err := r.pool.Exec(ctx, queryInsertTodo,
-		todo.ID, todo.Title, todo.Description, todo.Completed, todo.CreatedAt, todo.UpdatedAt,
-	)
-	return err
+	return inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		return r.insert(ctx, tx, todo)
+	})
+}
+
+func (r *TodoRepository) insert(ctx context.Context, tx pgx.Tx, todo *domain.Todo) error {
+	dueAt, dueAllDay, dueTimeZone := dueColumns(todo.Due)
+	rule, ruleTimeZone := recurrenceColumns(todo.Recurrence)
+	if _, err := tx.Exec(ctx, queryInsertTodo,
+		todo.ID, todo.Title, todo.Description, todo.Status, int16(todo.Priority), todo.CreatedAt, todo.UpdatedAt,
+		dueAt, dueAllDay, dueTimeZone, todo.Version, todo.DeletedAt, todo.ParentID,
+		rule, ruleTimeZone, todo.SeriesID, todo.ProjectID, todo.CreatedBy, todo.UpdatedBy, todo.AssigneeID, todo.Rank,
+	); err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrAlreadyExists
+		}
+		return err
+	}
+	if err := linkTags(ctx, tx, todo.ID, todo.Tags); err != nil {
+		return err
+	}
+	return recordChanges(ctx, tx, todoChange{after: todo})
+}
+
+// insertOccurrences stores the occurrences created along with an update,
+// skipping those whose series already has an occurrence on the same due
+// date. Each is inserted under a savepoint, so that a duplicate does not
+// abort the transaction.
+func (r *TodoRepository) insertOccurrences(ctx context.Context, tx pgx.Tx, todos []*domain.Todo) error {
+	for _, todo := range todos {
+		err := pgx.BeginFunc(ctx, tx, func(sp pgx.Tx) error {
+			return r.insert(ctx, sp, todo)
+		})
+		if err != nil && !errors.Is(err, domain.ErrAlreadyExists) {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *TodoRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
-	var t domain.Todo
-	err := r.pool.QueryRow(ctx, queryGetTodoByID, id).Scan(
-		&t.ID, &t.Title, &t.Description, &t.Completed, &t.CreatedAt, &t.UpdatedAt,
-	)
+	return r.getOne(ctx, queryGetTodoByID, id)
+}
+
+func (r *TodoRepository) GetTrashedByID(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
+	return r.getOne(ctx, queryGetTrashedTodoByID, id)
+}
+
+func (r *TodoRepository) getOne(ctx context.Context, query string, id uuid.UUID) (*domain.Todo, error) {
+	var t *domain.Todo
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		t, err = scanTodo(tx.QueryRow(ctx, query, id))
+		return err
+	})
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domain.ErrNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
-	return &t, nil
+	return t, nil
+}
+
+// List returns at most page.Limit todos matching filter in page.Sort order,
+// starting after page.After.
+func (r *TodoRepository) List(ctx context.Context, filter domain.TodoFilter, page domain.PageRequest) ([]domain.Todo, error) {
+	sort := page.Sort
+	if len(sort) == 0 {
+		sort = domain.DefaultTodoSort
+	}
+
+	where := buildTodoFilter(filter)
+	if page.After != nil {
+		where.addKeyset(sort, page.After)
+	}
+	query := queryListTodos + where.String() + orderBy(sort)
+	if page.Limit > 0 {
+		where.args = append(where.args, page.Limit)
+		query += fmt.Sprintf("\n\t\tLIMIT $%d", len(where.args))
+	}
+
+	return r.query(ctx, query, where.args...)
+}
+
+// Search returns at most search.Limit todos matching search, best ranked
+// first. Their highlights are left empty.
+func (r *TodoRepository) Search(ctx context.Context, search *domain.TodoSearch) ([]domain.SearchHit, error) {
+	where := buildTodoFilter(search.Filter)
+	rank := where.addSearch(search)
+	query := fmt.Sprintf(querySearchTodos, rank) + where.String() + "\n\t\tORDER BY search_rank DESC, id"
+	if search.Limit > 0 {
+		query += "\n\t\tLIMIT " + where.param(search.Limit)
+	}
+
+	var hits []domain.SearchHit
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, where.args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var hit domain.SearchHit
+			t, err := scanTodo(rankedRow{Row: rows, rank: &hit.Rank})
+			if err != nil {
+				return err
+			}
+			hit.Todo = *t
+			hits = append(hits, hit)
+		}
+		return rows.Err()
+	})
+	return hits, err
+}
+
+// rankedRow scans a row selecting todoSelectColumns followed by a rank.
+type rankedRow struct {
+	pgx.Row
+	rank *float64
+}
+
+func (r rankedRow) Scan(dest ...any) error {
+	return r.Row.Scan(append(dest, r.rank)...)
+}
+
+// Update writes todo and replaces its tags if it is still at todo.Version,
+// then increments todo.Version. It fails with domain.ErrConflict if the
+// stored todo has been modified in the meantime.
+func (r *TodoRepository) Update(ctx context.Context, todo *domain.Todo) error {
+	return r.UpdateMany(ctx, []*domain.Todo{todo}, nil)
+}
+
+// UpdateMany writes todos in a single transaction, following the rules of
+// Update, and creates the occurrences created in the same transaction.
+// Either every todo is written or, on the first error, none is.
+func (r *TodoRepository) UpdateMany(ctx context.Context, todos, created []*domain.Todo) error {
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		for _, todo := range todos {
+			if err := r.update(ctx, tx, todo); err != nil {
+				return err
+			}
+		}
+		return r.insertOccurrences(ctx, tx, created)
+	})
+	if err != nil {
+		return err
+	}
+	for _, todo := range todos {
+		todo.Version++
+	}
+	return nil
+}
+
+func (r *TodoRepository) update(ctx context.Context, tx pgx.Tx, todo *domain.Todo) error {
+	before, err := scanTodo(tx.QueryRow(ctx, queryLockTodo, todo.ID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if before.Version != todo.Version {
+		return domain.ErrConflict
+	}
+
+	dueAt, dueAllDay, dueTimeZone := dueColumns(todo.Due)
+	rule, ruleTimeZone := recurrenceColumns(todo.Recurrence)
+	tag, err := tx.Exec(ctx, queryUpdateTodo,
+		todo.ID, todo.Title, todo.Description, todo.Status, int16(todo.Priority), todo.UpdatedAt,
+		dueAt, dueAllDay, dueTimeZone, todo.DeletedAt, todo.Version, todo.ParentID,
+		rule, ruleTimeZone, todo.SeriesID, todo.ProjectID, todo.UpdatedBy, todo.AssigneeID, todo.Rank,
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return missingOrConflict(ctx, tx, todo.ID)
+	}
+	if _, err := tx.Exec(ctx, queryClearTodoTags, todo.ID); err != nil {
+		return err
+	}
+	if err := linkTags(ctx, tx, todo.ID, todo.Tags); err != nil {
+		return err
+	}
+
+	after := *todo
+	after.Version++
+	return recordChanges(ctx, tx, todoChange{before: before, after: &after})
+}
+
+// ListDescendants returns the live subtasks of a todo at any depth, oldest
+// first.
+func (r *TodoRepository) ListDescendants(ctx context.Context, id uuid.UUID) ([]domain.Todo, error) {
+	return r.query(ctx, queryListDescendants, []uuid.UUID{id})
+}
+
+// ListDescendantsOfAll returns the live subtasks at any depth of any of the
+// todos ids, oldest first. Todos among ids may be subtasks of others.
+func (r *TodoRepository) ListDescendantsOfAll(ctx context.Context, ids []uuid.UUID) ([]domain.Todo, error) {
+	return r.query(ctx, queryListDescendants, ids)
+}
+
+// ListByIDs returns the live todos among ids, in no particular order.
+// Missing and trashed todos are left out.
+func (r *TodoRepository) ListByIDs(ctx context.Context, ids []uuid.UUID) ([]domain.Todo, error) {
+	return r.query(ctx, queryListLiveTodosByID, ids)
+}
+
+// UpdateBulk writes groups of todos in a single transaction, with one
+// statement for all of their rows. Only the fields bulk actions change are
+// written: status, trash state, parent, project, tags and last editor.
+//
+// Every todo must still be at todo.Version. The error of a group that is
+// not is domain.ErrNotFound or domain.ErrConflict, and the group is not
+// written. If atomic, no group is written unless every group can be. The
+// occurrences created[i] are created if group i is written, as by
+// UpdateMany. UpdateBulk returns the error of each group, and increments
+// the version of the todos written.
+func (r *TodoRepository) UpdateBulk(ctx context.Context, groups, created [][]*domain.Todo, atomic bool) ([]error, error) {
+	errs := make([]error, len(groups))
+	var written []*domain.Todo
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		var ids []uuid.UUID
+		for _, group := range groups {
+			for _, todo := range group {
+				ids = append(ids, todo.ID)
+			}
+		}
+		locked, err := queryTodos(ctx, tx, queryLockTodosByID, ids)
+		if err != nil {
+			return err
+		}
+		before := make(map[uuid.UUID]*domain.Todo, len(locked))
+		for i := range locked {
+			before[locked[i].ID] = &locked[i]
+		}
+
+		failed := false
+		for i, group := range groups {
+			for _, todo := range group {
+				if b, ok := before[todo.ID]; !ok {
+					errs[i] = domain.ErrNotFound
+				} else if b.Version != todo.Version {
+					errs[i] = domain.ErrConflict
+				}
+				if errs[i] != nil {
+					failed = true
+					break
+				}
+			}
+		}
+		if failed && atomic {
+			return nil
+		}
+		var occurrences []*domain.Todo
+		for i, group := range groups {
+			if errs[i] == nil {
+				written = append(written, group...)
+				if i < len(created) {
+					occurrences = append(occurrences, created[i]...)
+				}
+			}
+		}
+		if len(written) == 0 {
+			return nil
+		}
+		if err := r.writeBulk(ctx, tx, written, before); err != nil {
+			return err
+		}
+		return r.insertOccurrences(ctx, tx, occurrences)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, todo := range written {
+		todo.Version++
+	}
+	return errs, nil
 }
 
-func (r *TodoRepository) List(ctx context.Context) ([]domain.Todo, error) {
-	rows, err := r.pool.Query(ctx, queryListTodos)
+// writeBulk writes the locked todos of UpdateBulk, before being their
+// stored state, and records the changes.
+func (r *TodoRepository) writeBulk(ctx context.Context, tx pgx.Tx, todos []*domain.Todo, before map[uuid.UUID]*domain.Todo) error {
+	n := len(todos)
+	var (
+		ids        = make([]uuid.UUID, n)
+		versions   = make([]int64, n)
+		statuses   = make([]string, n)
+		deletedAt  = make([]*time.Time, n)
+		parentIDs  = make([]*uuid.UUID, n)
+		projectIDs = make([]*uuid.UUID, n)
+		updatedAt  = make([]time.Time, n)
+		updatedBy  = make([]*uuid.UUID, n)
+		tagTodos   []uuid.UUID
+		tagNames   []string
+		changes    = make([]todoChange, n)
+	)
+	for i, todo := range todos {
+		ids[i], versions[i], statuses[i] = todo.ID, todo.Version, string(todo.Status)
+		deletedAt[i], parentIDs[i], projectIDs[i] = todo.DeletedAt, todo.ParentID, todo.ProjectID
+		updatedAt[i], updatedBy[i] = todo.UpdatedAt, todo.UpdatedBy
+		for _, name := range todo.Tags {
+			tagTodos = append(tagTodos, todo.ID)
+			tagNames = append(tagNames, name)
+		}
+		after := *todo
+		after.Version++
+		changes[i] = todoChange{before: before[todo.ID], after: &after}
+	}
+
+	tag, err := tx.Exec(ctx, queryBulkUpdateTodos, ids, versions, statuses, deletedAt, parentIDs, projectIDs, updatedAt, updatedBy)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != int64(n) {
+		// The rows are locked and their versions checked.
+		return fmt.Errorf("bulk update wrote %d of %d todos", tag.RowsAffected(), n)
+	}
+	if _, err := tx.Exec(ctx, queryClearTodosTags, ids); err != nil {
+		return err
+	}
+	if len(tagNames) > 0 {
+		names := slices.Clone(tagNames)
+		slices.Sort(names)
+		if _, err := tx.Exec(ctx, queryEnsureTags, slices.Compact(names)); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, queryLinkTodosTags, tagTodos, tagNames); err != nil {
+			return err
+		}
+	}
+	return recordChanges(ctx, tx, changes...)
+}
+
+// ListRecurring returns the latest occurrence of every recurring series,
+// including occurrences in the trash. Series whose latest occurrence no
+// longer recurs are left out.
+func (r *TodoRepository) ListRecurring(ctx context.Context) ([]domain.Todo, error) {
+	return r.query(ctx, queryListRecurring)
+}
+
+// query runs a query selecting todoSelectColumns and collects the todos.
+func (r *TodoRepository) query(ctx context.Context, query string, args ...any) ([]domain.Todo, error) {
+	var todos []domain.Todo
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		todos, err = queryTodos(ctx, tx, query, args...)
+		return err
+	})
+	return todos, err
+}
+
+// queryTodos runs a query selecting todoSelectColumns in tx and collects
+// the todos.
+func queryTodos(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]domain.Todo, error) {
+	rows, err := tx.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -48,43 +395,170 @@ func (r *TodoRepository) List(ctx context.Context) ([]domain.Todo, error) {
 
 	var todos []domain.Todo
 	for rows.Next() {
-		var t domain.Todo
-		if err := rows.Scan(&t.ID, &t.Title, &t.Description, &t.Completed, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		t, err := scanTodo(rows)
+		if err != nil {
 			return nil, err
 		}
-		todos = append(todos, t)
+		todos = append(todos, *t)
 	}
 	return todos, rows.Err()
 }
 
-func (r *TodoRepository) Update(ctx context.Context, todo *domain.Todo) error {
-	tag, err := r.pool.Exec(ctx, queryUpdateTodo,
-		todo.ID, todo.Title, todo.Description, todo.Completed, todo.UpdatedAt,
-	)
-	if err != nil {
+// PurgeTrash permanently deletes the todos trashed before the given time.
+// No event is published, as the todos were deleted when they were trashed.
+func (r *TodoRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		purged, err := queryTodos(ctx, tx, queryLockPurgeable, before)
+		if err != nil || len(purged) == 0 {
+			return err
+		}
+		tag, err := tx.Exec(ctx, queryDeleteTodos, todoIDs(purged))
+		if err != nil {
+			return err
+		}
+		count = tag.RowsAffected()
+
+		changes := make([]todoChange, len(purged))
+		for i := range purged {
+			changes[i] = todoChange{before: &purged[i]}
+		}
+		return recordAudit(ctx, tx, changes...)
+	})
+	return count, err
+}
+
+// missingOrConflict explains why a versioned write to id matched no row.
+func missingOrConflict(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	var exists bool
+	if err := tx.QueryRow(ctx, queryTodoExists, id).Scan(&exists); err != nil {
 		return err
 	}
-	if tag.RowsAffected() == 0 {
-		return domain.ErrNotFound
+	if exists {
+		return domain.ErrConflict
 	}
-	return nil
+	return domain.ErrNotFound
 }
 
-func (r *TodoRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	tag, err := r.pool.Exec(ctx, queryDeleteTodo, id)
-	if err != nil {
+// CompleteAll marks the open and in-progress todos of a project as done, or
+// those of every project if projectID is nil, on behalf of user. A single
+// AllCompleted event announces them.
+func (r *TodoRepository) CompleteAll(ctx context.Context, projectID, user *uuid.UUID) (int64, error) {
+	var count int64
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		active, err := queryTodos(ctx, tx, queryLockActive, projectID)
+		if err != nil || len(active) == 0 {
+			return err
+		}
+		ids := todoIDs(active)
+		tag, err := tx.Exec(ctx, queryCompleteAll, ids, user)
+		if err != nil {
+			return err
+		}
+		count = tag.RowsAffected()
+
+		completed, err := queryTodos(ctx, tx, queryListTodosByID, ids)
+		if err != nil {
+			return err
+		}
+		after := make(map[uuid.UUID]*domain.Todo, len(completed))
+		for i := range completed {
+			after[completed[i].ID] = &completed[i]
+		}
+		changes := make([]todoChange, len(active))
+		for i := range active {
+			changes[i] = todoChange{before: &active[i], after: after[active[i].ID]}
+		}
+		if err := recordAudit(ctx, tx, changes...); err != nil {
+			return err
+		}
+		event, err := domain.NewAllCompletedEvent(projectID, ids)
+		if err != nil {
+			return err
+		}
+		return enqueueEvents(ctx, tx, event)
+	})
+	return count, err
+}
+
+// recordChanges records changes to todos in the audit trail and publishes
+// an event for each in tx.
+func recordChanges(ctx context.Context, tx pgx.Tx, changes ...todoChange) error {
+	if err := recordAudit(ctx, tx, changes...); err != nil {
 		return err
 	}
-	if tag.RowsAffected() == 0 {
-		return domain.ErrNotFound
+	events := make([]*domain.Event, len(changes))
+	for i, c := range changes {
+		event, err := domain.NewTodoEvent(c.before, c.after)
+		if err != nil {
+			return err
+		}
+		events[i] = event
 	}
-	return nil
+	return enqueueEvents(ctx, tx, events...)
 }
 
-func (r *TodoRepository) CompleteAll(ctx context.Context) (int64, error) {
-	tag, err := r.pool.Exec(ctx, queryCompleteAll)
-	if err != nil {
-		return 0, err
+func todoIDs(todos []domain.Todo) []uuid.UUID {
+	ids := make([]uuid.UUID, len(todos))
+	for i := range todos {
+		ids[i] = todos[i].ID
+	}
+	return ids
+}
+
+// linkTags attaches the named tags to a todo, creating missing tags first.
+func linkTags(ctx context.Context, tx pgx.Tx, todoID uuid.UUID, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if _, err := tx.Exec(ctx, queryEnsureTags, names); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, queryLinkTodoTags, todoID, names)
+	return err
+}
+
+// scanTodo reads a row selected with todoSelectColumns.
+func scanTodo(row pgx.Row) (*domain.Todo, error) {
+	var (
+		t           domain.Todo
+		dueAt       *time.Time
+		dueAllDay   bool
+		dueTimeZone string
+		priority    int16
+		rule        *string
+		ruleZone    *string
+	)
+	if err := row.Scan(
+		&t.ID, &t.Title, &t.Description, &t.Status, &priority, &t.CreatedAt, &t.UpdatedAt,
+		&dueAt, &dueAllDay, &dueTimeZone, &t.Version, &t.DeletedAt, &t.ParentID,
+		&rule, &ruleZone, &t.SeriesID, &t.ProjectID, &t.CreatedBy, &t.UpdatedBy, &t.AssigneeID, &t.Rank, &t.Tags,
+	); err != nil {
+		return nil, err
+	}
+	if rule != nil {
+		t.Recurrence = &domain.Recurrence{Rule: *rule, TimeZone: domain.DefaultTimeZone}
+		if ruleZone != nil {
+			t.Recurrence.TimeZone = *ruleZone
+		}
+	}
+	t.Priority = domain.Priority(priority)
+	if dueAt != nil {
+		t.Due = &domain.Due{At: dueAt.UTC(), AllDay: dueAllDay, TimeZone: dueTimeZone}
+	}
+	return &t, nil
+}
+
+func dueColumns(due *domain.Due) (*time.Time, bool, string) {
+	if due == nil {
+		return nil, false, domain.DefaultTimeZone
+	}
+	return &due.At, due.AllDay, due.TimeZone
+}
+
+func recurrenceColumns(r *domain.Recurrence) (*string, *string) {
+	if r == nil {
+		return nil, nil
 	}
-	return tag.RowsAffected(), nil
+	return &r.Rule, &r.TimeZone
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/knjname/go-todo-api/internal/domain"
 	"github.com/knjname/go-todo-api/internal/repository/postgres"
+	"github.com/knjname/go-todo-api/internal/tenant"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	pgcontainer "github.com/testcontainers/testcontainers-go/modules/postgres"
@@ -29,13 +30,15 @@ func setupTestDB(t *testing.T) *pgxpool.Pool {
 
 	_, filename, _, _ := runtime.Caller(0)
 	migrationsDir := filepath.Join(filepath.Dir(filename), "..", "..", "..", "migrations")
+	upScripts, err := filepath.Glob(filepath.Join(migrationsDir, "*.up.sql"))
+	require.NoError(t, err)
 
 	container, err := pgcontainer.Run(ctx,
 		"postgres:16-alpine",
 		pgcontainer.WithDatabase("test"),
 		pgcontainer.WithUsername("test"),
 		pgcontainer.WithPassword("test"),
-		pgcontainer.WithInitScripts(filepath.Join(migrationsDir, "001_create_todos_table.up.sql")),
+		pgcontainer.WithInitScripts(upScripts...),
 		testcontainers.WithWaitStrategy(
 			wait.ForLog("database system is ready to accept connections").
 				WithOccurrence(2).
@@ -61,10 +64,15 @@ func setupTestDB(t *testing.T) *pgxpool.Pool {
 	return pool
 }
 
+// workspaceContext returns a context acting in a new workspace.
+func workspaceContext() context.Context {
+	return tenant.WithWorkspace(context.Background(), uuid.New())
+}
+
 func TestTodoRepository_Create_and_GetByID(t *testing.T) {
 	pool := setupTestDB(t)
 	repo := postgres.NewTodoRepository(pool)
-	ctx := context.Background()
+	ctx := workspaceContext()
 
 	todo, err := domain.NewTodo("Integration Test", "Testing with real DB")
 	require.NoError(t, err)
@@ -77,92 +85,699 @@ func TestTodoRepository_Create_and_GetByID(t *testing.T) {
 	assert.Equal(t, todo.ID, got.ID)
 	assert.Equal(t, todo.Title, got.Title)
 	assert.Equal(t, todo.Description, got.Description)
-	assert.Equal(t, todo.Completed, got.Completed)
+	assert.Equal(t, todo.Status, got.Status)
 }
 
 func TestTodoRepository_GetByID_NotFound(t *testing.T) {
 	pool := setupTestDB(t)
 	repo := postgres.NewTodoRepository(pool)
 
-	_, err := repo.GetByID(context.Background(), uuid.New())
+	_, err := repo.GetByID(workspaceContext(), uuid.New())
 	assert.ErrorIs(t, err, domain.ErrNotFound)
 }
 
 func TestTodoRepository_List(t *testing.T) {
 	pool := setupTestDB(t)
 	repo := postgres.NewTodoRepository(pool)
-	ctx := context.Background()
+	ctx := workspaceContext()
 
 	for i := range 3 {
 		todo, _ := domain.NewTodo("Todo "+string(rune('A'+i)), "")
 		require.NoError(t, repo.Create(ctx, todo))
 	}
 
-	todos, err := repo.List(ctx)
+	todos, err := repo.List(ctx, domain.TodoFilter{}, domain.PageRequest{})
 	require.NoError(t, err)
 	assert.Len(t, todos, 3)
 }
 
+func TestTodoRepository_List_Pagination(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	ctx := workspaceContext()
+
+	for i := range 5 {
+		todo, _ := domain.NewTodo("Todo "+string(rune('A'+i)), "")
+		require.NoError(t, repo.Create(ctx, todo))
+	}
+
+	var seen []uuid.UUID
+	page := domain.PageRequest{Limit: 2}
+	for {
+		todos, err := repo.List(ctx, domain.TodoFilter{}, page)
+		require.NoError(t, err)
+		if len(todos) == 0 {
+			break
+		}
+		assert.LessOrEqual(t, len(todos), 2)
+		for i := range todos {
+			seen = append(seen, todos[i].ID)
+		}
+		page.After = domain.DefaultTodoSort.CursorAfter(&todos[len(todos)-1])
+	}
+
+	assert.Len(t, seen, 5)
+	all, err := repo.List(ctx, domain.TodoFilter{}, domain.PageRequest{})
+	require.NoError(t, err)
+	for i := range all {
+		assert.Equal(t, all[i].ID, seen[i])
+	}
+}
+
+func TestTodoRepository_List_SortedPagination(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	ctx := workspaceContext()
+
+	early, err := domain.NewDue("2030-01-01", "", "")
+	require.NoError(t, err)
+	late, err := domain.NewDue("2030-06-01", "", "")
+	require.NoError(t, err)
+	for _, spec := range []struct {
+		title string
+		due   *domain.Due
+	}{{"b", late}, {"a", early}, {"c", nil}, {"d", early}, {"e", nil}} {
+		todo, _ := domain.NewTodo(spec.title, "", domain.WithDue(spec.due))
+		require.NoError(t, repo.Create(ctx, todo))
+	}
+
+	sort, err := domain.ParseTodoSort("due,-title")
+	require.NoError(t, err)
+
+	var titles []string
+	page := domain.PageRequest{Sort: sort, Limit: 2}
+	for {
+		todos, err := repo.List(ctx, domain.TodoFilter{}, page)
+		require.NoError(t, err)
+		if len(todos) == 0 {
+			break
+		}
+		for i := range todos {
+			titles = append(titles, todos[i].Title)
+		}
+		page.After = sort.CursorAfter(&todos[len(todos)-1])
+	}
+
+	// Todos without a due date sort after every deadline.
+	assert.Equal(t, []string{"d", "a", "b", "e", "c"}, titles)
+}
+
+func TestTodoRepository_List_ByRank(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	ctx := workspaceContext()
+
+	var todos []*domain.Todo
+	for _, title := range []string{"a", "b", "c"} {
+		todo, err := domain.NewTodo(title, "")
+		require.NoError(t, err)
+		require.NoError(t, repo.Create(ctx, todo))
+		todos = append(todos, todo)
+	}
+	// Move c between a and b, and a to the end.
+	require.NoError(t, todos[2].MoveBetween(todos[0], todos[1]))
+	require.NoError(t, repo.Update(ctx, todos[2]))
+	require.NoError(t, todos[0].MoveBetween(todos[1], nil))
+	require.NoError(t, repo.Update(ctx, todos[0]))
+
+	sort, err := domain.ParseTodoSort("rank")
+	require.NoError(t, err)
+	var titles []string
+	page := domain.PageRequest{Sort: sort, Limit: 2}
+	for {
+		got, err := repo.List(ctx, domain.TodoFilter{}, page)
+		require.NoError(t, err)
+		if len(got) == 0 {
+			break
+		}
+		for i := range got {
+			titles = append(titles, got[i].Title)
+		}
+		page.After = sort.CursorAfter(&got[len(got)-1])
+	}
+	assert.Equal(t, []string{"c", "b", "a"}, titles)
+
+	stored, err := repo.GetByID(ctx, todos[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, todos[0].Rank, stored.Rank)
+}
+
+func TestTodoRepository_Search(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	ctx := workspaceContext()
+
+	for _, td := range []struct{ title, description string }{
+		{"Quarterly report", "numbers for the board"},
+		{"Board meeting", "present the quarterly report"},
+		{"Reporting tool", "100% done"},
+		{"週次会議の資料", "売上の集計"},
+		{"定例", "来週の会議室を予約"},
+	} {
+		todo, err := domain.NewTodo(td.title, td.description)
+		require.NoError(t, err)
+		require.NoError(t, repo.Create(ctx, todo))
+	}
+	// Other workspaces are not searched.
+	other, err := domain.NewTodo("Quarterly report", "")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(workspaceContext(), other))
+
+	search := func(query string, mode domain.SearchMode) []string {
+		t.Helper()
+		s, err := domain.NewTodoSearch(query, mode)
+		require.NoError(t, err)
+		s.Limit = 10
+		hits, err := repo.Search(ctx, s)
+		require.NoError(t, err)
+		titles := make([]string, len(hits))
+		for i := range hits {
+			titles[i] = hits[i].Todo.Title
+			assert.Positive(t, hits[i].Rank)
+		}
+		return titles
+	}
+
+	assert.Equal(t, []string{"Quarterly report", "Board meeting"}, search("quarterly report", domain.SearchAuto),
+		"title matches rank first; whole words only")
+	assert.Equal(t, []string{"Board meeting"}, search("quarterly -numbers", domain.SearchSimple))
+	assert.Equal(t, []string{"週次会議の資料", "定例"}, search("会議", domain.SearchAuto), "substrings; title matches rank first")
+	assert.Equal(t, []string{"週次会議の資料"}, search("会議 売上", domain.SearchAuto), "every term must match")
+	assert.Equal(t, []string{"Reporting tool"}, search("100%", domain.SearchNgram), "wildcards are literal")
+
+	s, err := domain.NewTodoSearch("report", domain.SearchNgram)
+	require.NoError(t, err)
+	s.Filter.Trashed = true
+	hits, err := repo.Search(ctx, s)
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+}
+
+func TestTodoRepository_List_DefaultOrder(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	ctx := workspaceContext()
+
+	soon, err := domain.NewDue("2030-01-01", "", "")
+	require.NoError(t, err)
+	for _, spec := range []struct {
+		title    string
+		priority domain.Priority
+		due      *domain.Due
+	}{
+		{"low", domain.PriorityLow, nil},
+		{"urgent", domain.PriorityUrgent, nil},
+		{"high later", domain.PriorityHigh, nil},
+		{"high soon", domain.PriorityHigh, soon},
+		{"none", domain.PriorityNone, soon},
+	} {
+		todo, _ := domain.NewTodo(spec.title, "", domain.WithPriority(spec.priority), domain.WithDue(spec.due))
+		require.NoError(t, repo.Create(ctx, todo))
+	}
+
+	var titles []string
+	page := domain.PageRequest{Limit: 2}
+	for {
+		todos, err := repo.List(ctx, domain.TodoFilter{}, page)
+		require.NoError(t, err)
+		if len(todos) == 0 {
+			break
+		}
+		for i := range todos {
+			titles = append(titles, todos[i].Title)
+		}
+		page.After = domain.DefaultTodoSort.CursorAfter(&todos[len(todos)-1])
+	}
+
+	assert.Equal(t, []string{"urgent", "high soon", "high later", "low", "none"}, titles)
+}
+
+func TestTodoRepository_List_Filters(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	ctx := workspaceContext()
+
+	report, _ := domain.NewTodo("Weekly REPORT", "send to team")
+	invoice, _ := domain.NewTodo("Invoice", "monthly report")
+	require.NoError(t, invoice.MarkComplete())
+	for _, td := range []*domain.Todo{report, invoice} {
+		require.NoError(t, repo.Create(ctx, td))
+	}
+
+	active := []domain.Status{domain.StatusOpen, domain.StatusInProgress}
+	todos, err := repo.List(ctx, domain.TodoFilter{Statuses: active}, domain.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, todos, 1)
+	assert.Equal(t, report.ID, todos[0].ID)
+
+	todos, err = repo.List(ctx, domain.TodoFilter{TitleContains: "report"}, domain.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, todos, 1)
+	assert.Equal(t, report.ID, todos[0].ID)
+
+	todos, err = repo.List(ctx, domain.TodoFilter{DescriptionContains: "Report"}, domain.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, todos, 1)
+	assert.Equal(t, invoice.ID, todos[0].ID)
+
+	future := time.Now().Add(time.Hour)
+	todos, err = repo.List(ctx, domain.TodoFilter{CreatedAfter: &future}, domain.PageRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, todos)
+}
+
+func TestTodoRepository_List_DueFilters(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	ctx := workspaceContext()
+
+	pastDue, err := domain.NewDue("2000-01-01", "", "Asia/Tokyo")
+	require.NoError(t, err)
+	futureDue, err := domain.NewDue("2999-01-01", "12:00", "")
+	require.NoError(t, err)
+
+	overdue, _ := domain.NewTodo("Overdue", "", domain.WithDue(pastDue))
+	upcoming, _ := domain.NewTodo("Upcoming", "", domain.WithDue(futureDue))
+	noDue, _ := domain.NewTodo("No due", "")
+	for _, td := range []*domain.Todo{overdue, upcoming, noDue} {
+		require.NoError(t, repo.Create(ctx, td))
+	}
+
+	got, err := repo.GetByID(ctx, overdue.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.Due)
+	assert.Equal(t, "2000-01-01", got.Due.Date())
+	assert.True(t, got.Due.AllDay)
+	assert.Equal(t, "Asia/Tokyo", got.Due.TimeZone)
+
+	todos, err := repo.List(ctx, domain.TodoFilter{Overdue: true}, domain.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, todos, 1)
+	assert.Equal(t, overdue.ID, todos[0].ID)
+
+	now := time.Now()
+	todos, err = repo.List(ctx, domain.TodoFilter{DueAfter: &now}, domain.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, todos, 1)
+	assert.Equal(t, upcoming.ID, todos[0].ID)
+
+	// The all-day due date lasts until midnight in Tokyo (15:00 UTC the day before).
+	boundary := time.Date(2000, 1, 1, 15, 0, 0, 0, time.UTC)
+	todos, err = repo.List(ctx, domain.TodoFilter{DueBefore: &boundary}, domain.PageRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, todos)
+}
+
 func TestTodoRepository_Update(t *testing.T) {
 	pool := setupTestDB(t)
 	repo := postgres.NewTodoRepository(pool)
-	ctx := context.Background()
+	ctx := workspaceContext()
 
 	todo, _ := domain.NewTodo("Original", "desc")
 	require.NoError(t, repo.Create(ctx, todo))
 
 	require.NoError(t, todo.UpdateTitle("Updated"))
-	todo.MarkComplete()
+	require.NoError(t, todo.Start())
 	require.NoError(t, repo.Update(ctx, todo))
 
 	got, err := repo.GetByID(ctx, todo.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "Updated", got.Title)
-	assert.True(t, got.Completed)
+	assert.Equal(t, domain.StatusInProgress, got.Status)
 }
 
-func TestTodoRepository_Delete(t *testing.T) {
+func TestTodoRepository_Update_VersionConflict(t *testing.T) {
 	pool := setupTestDB(t)
 	repo := postgres.NewTodoRepository(pool)
-	ctx := context.Background()
+	ctx := workspaceContext()
 
-	todo, _ := domain.NewTodo("To delete", "")
+	todo, _ := domain.NewTodo("Original", "desc")
 	require.NoError(t, repo.Create(ctx, todo))
-	require.NoError(t, repo.Delete(ctx, todo.ID))
+
+	first, err := repo.GetByID(ctx, todo.ID)
+	require.NoError(t, err)
+	second, err := repo.GetByID(ctx, todo.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, first.UpdateTitle("First writer"))
+	require.NoError(t, repo.Update(ctx, first))
+	assert.Equal(t, int64(2), first.Version)
+
+	require.NoError(t, second.UpdateTitle("Second writer"))
+	assert.ErrorIs(t, repo.Update(ctx, second), domain.ErrConflict)
+
+	got, err := repo.GetByID(ctx, todo.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "First writer", got.Title)
+	assert.Equal(t, int64(2), got.Version)
+}
+
+func TestTodoRepository_Trash(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	ctx := workspaceContext()
+
+	todo, _ := domain.NewTodo("To delete", "")
+	kept, _ := domain.NewTodo("Kept", "")
+	for _, td := range []*domain.Todo{todo, kept} {
+		require.NoError(t, repo.Create(ctx, td))
+	}
+
+	todo.MoveToTrash()
+	require.NoError(t, repo.Update(ctx, todo))
 
 	_, err := repo.GetByID(ctx, todo.ID)
 	assert.ErrorIs(t, err, domain.ErrNotFound)
+	todos, err := repo.List(ctx, domain.TodoFilter{}, domain.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, todos, 1)
+	assert.Equal(t, kept.ID, todos[0].ID)
+
+	trashed, err := repo.List(ctx, domain.TodoFilter{Trashed: true}, domain.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+	assert.Equal(t, todo.ID, trashed[0].ID)
+	assert.NotNil(t, trashed[0].DeletedAt)
+
+	got, err := repo.GetTrashedByID(ctx, todo.ID)
+	require.NoError(t, err)
+	got.Restore()
+	require.NoError(t, repo.Update(ctx, got))
+
+	got, err = repo.GetByID(ctx, todo.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.DeletedAt)
+	_, err = repo.GetTrashedByID(ctx, todo.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestTodoRepository_Hierarchy(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	ctx := workspaceContext()
+
+	parent, _ := domain.NewTodo("Parent", "")
+	child, _ := domain.NewTodo("Child", "", domain.WithParent(&parent.ID))
+	grandchild, _ := domain.NewTodo("Grandchild", "", domain.WithParent(&child.ID))
+	other, _ := domain.NewTodo("Other", "")
+	for _, td := range []*domain.Todo{parent, child, grandchild, other} {
+		require.NoError(t, repo.Create(ctx, td))
+	}
+
+	got, err := repo.GetByID(ctx, child.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.ParentID)
+	assert.Equal(t, parent.ID, *got.ParentID)
+
+	children, err := repo.List(ctx, domain.TodoFilter{ParentID: &parent.ID}, domain.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+	assert.Equal(t, child.ID, children[0].ID)
+
+	descendants, err := repo.ListDescendants(ctx, parent.ID)
+	require.NoError(t, err)
+	assert.Len(t, descendants, 2)
+
+	// A stale version rolls back every write of the batch.
+	require.NoError(t, child.SetParent(nil))
+	stale := *other
+	stale.Version = 99
+	err = repo.UpdateMany(ctx, []*domain.Todo{child, &stale}, nil)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	got, err = repo.GetByID(ctx, child.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, got.ParentID)
+
+	child.Version = got.Version
+	grandchild.MoveToTrash()
+	require.NoError(t, repo.UpdateMany(ctx, []*domain.Todo{child, grandchild}, nil))
+	descendants, err = repo.ListDescendants(ctx, parent.ID)
+	require.NoError(t, err)
+	assert.Empty(t, descendants)
+}
+
+func TestTodoRepository_UpdateBulk(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	ctx := workspaceContext()
+
+	first, _ := domain.NewTodo("First", "", domain.WithTags([]string{"home"}))
+	second, _ := domain.NewTodo("Second", "")
+	child, _ := domain.NewTodo("Child", "", domain.WithParent(&second.ID))
+	for _, td := range []*domain.Todo{first, second, child} {
+		require.NoError(t, repo.Create(ctx, td))
+	}
+
+	got, err := repo.ListByIDs(ctx, []uuid.UUID{second.ID, uuid.New(), first.ID})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+	descendants, err := repo.ListDescendantsOfAll(ctx, []uuid.UUID{first.ID, second.ID})
+	require.NoError(t, err)
+	require.Len(t, descendants, 1)
+	assert.Equal(t, child.ID, descendants[0].ID)
+
+	retagged := *first
+	require.NoError(t, retagged.SetTags([]string{"work", "urgent"}))
+	stale := *second
+	stale.Version = 99
+	require.NoError(t, stale.UpdateTitle("Stale"))
+
+	// In atomic mode, the stale group keeps the other from being written.
+	errs, err := repo.UpdateBulk(ctx, [][]*domain.Todo{{&retagged}, {&stale}}, nil, true)
+	require.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], domain.ErrConflict)
+	stored, err := repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"home"}, stored.Tags)
+
+	errs, err = repo.UpdateBulk(ctx, [][]*domain.Todo{{&retagged}, {&stale}}, nil, false)
+	require.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], domain.ErrConflict)
+	assert.Equal(t, int64(2), retagged.Version)
+	stored, err = repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"urgent", "work"}, stored.Tags)
+	assert.Equal(t, int64(2), stored.Version)
+	stored, err = repo.GetByID(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Second", stored.Title)
+
+	// A group is written as a whole: the todo with its subtasks.
+	require.NoError(t, second.MarkComplete())
+	require.NoError(t, child.MarkComplete())
+	errs, err = repo.UpdateBulk(ctx, [][]*domain.Todo{{second, child}}, nil, true)
+	require.NoError(t, err)
+	assert.NoError(t, errs[0])
+	got, err = repo.ListByIDs(ctx, []uuid.UUID{second.ID, child.ID})
+	require.NoError(t, err)
+	for _, td := range got {
+		assert.Equal(t, domain.StatusDone, td.Status)
+	}
+}
+
+func TestTodoRepository_Recurring(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	ctx := workspaceContext()
+
+	rule, err := domain.NewRecurrence("FREQ=WEEKLY", "Asia/Tokyo")
+	require.NoError(t, err)
+	due, err := domain.NewDue("2026-03-02", "09:00", "Asia/Tokyo")
+	require.NoError(t, err)
+	first, _ := domain.NewTodo("Weekly", "", domain.WithDue(due), domain.WithRecurrence(rule))
+	require.NoError(t, repo.Create(ctx, first))
+
+	got, err := repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rule, got.Recurrence)
+	assert.Equal(t, first.SeriesID, got.SeriesID)
+
+	second, err := first.NextOccurrence(time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, second))
+	duplicate, err := first.NextOccurrence(time.Time{})
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.Create(ctx, duplicate), domain.ErrAlreadyExists)
+
+	// An occurrence created with an update is skipped if it exists already,
+	// without failing the update.
+	require.NoError(t, first.MarkComplete())
+	require.NoError(t, repo.UpdateMany(ctx, []*domain.Todo{first}, []*domain.Todo{duplicate}))
+	got, err = repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusDone, got.Status)
+	_, err = repo.GetByID(ctx, duplicate.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	latest, err := repo.ListRecurring(ctx)
+	require.NoError(t, err)
+	require.Len(t, latest, 1)
+	assert.Equal(t, second.ID, latest[0].ID)
+
+	require.NoError(t, second.SetRecurrence(nil))
+	require.NoError(t, repo.Update(ctx, second))
+	latest, err = repo.ListRecurring(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, latest)
 }
 
-func TestTodoRepository_Delete_NotFound(t *testing.T) {
+func TestTodoRepository_PurgeTrash(t *testing.T) {
 	pool := setupTestDB(t)
 	repo := postgres.NewTodoRepository(pool)
+	ctx := workspaceContext()
+
+	live, _ := domain.NewTodo("Live", "")
+	trashed, _ := domain.NewTodo("Trashed", "")
+	for _, td := range []*domain.Todo{live, trashed} {
+		require.NoError(t, repo.Create(ctx, td))
+	}
+	trashed.MoveToTrash()
+	require.NoError(t, repo.Update(ctx, trashed))
+
+	count, err := repo.PurgeTrash(ctx, trashed.DeletedAt.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
 
-	err := repo.Delete(context.Background(), uuid.New())
+	count, err = repo.PurgeTrash(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	_, err = repo.GetTrashedByID(ctx, trashed.ID)
 	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = repo.GetByID(ctx, live.ID)
+	assert.NoError(t, err)
 }
 
 func TestTodoRepository_CompleteAll(t *testing.T) {
 	pool := setupTestDB(t)
 	repo := postgres.NewTodoRepository(pool)
-	ctx := context.Background()
+	ctx := workspaceContext()
 
 	for i := range 3 {
 		todo, _ := domain.NewTodo("Todo "+string(rune('A'+i)), "")
 		require.NoError(t, repo.Create(ctx, todo))
 	}
+	started, _ := domain.NewTodo("Started", "")
+	require.NoError(t, started.Start())
+	cancelled, _ := domain.NewTodo("Cancelled", "")
+	require.NoError(t, cancelled.Cancel())
+	for _, td := range []*domain.Todo{started, cancelled} {
+		require.NoError(t, repo.Create(ctx, td))
+	}
 
-	count, err := repo.CompleteAll(ctx)
+	count, err := repo.CompleteAll(ctx, nil, nil)
 	require.NoError(t, err)
-	assert.Equal(t, int64(3), count)
+	assert.Equal(t, int64(4), count)
 
-	todos, _ := repo.List(ctx)
+	todos, _ := repo.List(ctx, domain.TodoFilter{}, domain.PageRequest{})
 	for _, td := range todos {
-		assert.True(t, td.Completed)
+		if td.ID == cancelled.ID {
+			assert.Equal(t, domain.StatusCancelled, td.Status)
+		} else {
+			assert.Equal(t, domain.StatusDone, td.Status)
+		}
 	}
 
 	// Running again should affect 0 rows
-	count, err = repo.CompleteAll(ctx)
+	count, err = repo.CompleteAll(ctx, nil, nil)
 	require.NoError(t, err)
 	assert.Equal(t, int64(0), count)
 }
+
+func TestTodoRepository_WorkspaceIsolation(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewTodoRepository(pool)
+	tags := postgres.NewTagRepository(pool)
+	alice, bob := workspaceContext(), workspaceContext()
+
+	todo, _ := domain.NewTodo("Alice's todo", "", domain.WithTags([]string{"secret"}))
+	require.NoError(t, repo.Create(alice, todo))
+	trashed, _ := domain.NewTodo("Alice's trash", "")
+	trashed.MoveToTrash()
+	require.NoError(t, repo.Create(alice, trashed))
+
+	t.Run("reads", func(t *testing.T) {
+		_, err := repo.GetByID(bob, todo.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = repo.GetTrashedByID(bob, trashed.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		// Even a filter that names the todo does not reach across workspaces.
+		list, err := repo.List(bob, domain.TodoFilter{TitleContains: "Alice"}, domain.PageRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, list)
+
+		bobTags, err := tags.List(bob)
+		require.NoError(t, err)
+		assert.Empty(t, bobTags)
+	})
+
+	t.Run("writes", func(t *testing.T) {
+		stolen := *todo
+		stolen.Title = "Stolen"
+		assert.ErrorIs(t, repo.Update(bob, &stolen), domain.ErrNotFound)
+
+		count, err := repo.CompleteAll(bob, nil, nil)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		count, err = repo.PurgeTrash(bob, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		got, err := repo.GetByID(alice, todo.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Alice's todo", got.Title)
+		assert.Equal(t, domain.StatusOpen, got.Status)
+		_, err = repo.GetTrashedByID(alice, trashed.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("names are unique per workspace", func(t *testing.T) {
+		secret, _ := domain.NewTag("secret")
+		require.NoError(t, tags.Create(bob, secret))
+		tagged, _ := domain.NewTodo("Bob's todo", "", domain.WithTags([]string{"secret"}))
+		require.NoError(t, repo.Create(bob, tagged))
+
+		list, err := repo.List(alice, domain.TodoFilter{Tags: []string{"secret"}}, domain.PageRequest{})
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, todo.ID, list[0].ID)
+	})
+
+	t.Run("unfiltered SQL", func(t *testing.T) {
+		// Row-level security holds for any statement run as todo_app, not
+		// only for the repository's queries.
+		tx, err := pool.Begin(context.Background())
+		require.NoError(t, err)
+		defer func() { _ = tx.Rollback(context.Background()) }()
+		aliceID, _ := tenant.WorkspaceID(alice)
+		bobID, _ := tenant.WorkspaceID(bob)
+		_, err = tx.Exec(context.Background(),
+			`SELECT set_config('role', 'todo_app', true), set_config('app.workspace_id', $1, true)`, bobID.String())
+		require.NoError(t, err)
+
+		var count int
+		require.NoError(t, tx.QueryRow(context.Background(),
+			`SELECT count(*) FROM todos WHERE title LIKE 'Alice%'`).Scan(&count))
+		assert.Zero(t, count)
+
+		tag, err := tx.Exec(context.Background(), `UPDATE todos SET title = 'Stolen'`)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), tag.RowsAffected(), "only Bob's own todo is updated")
+
+		_, err = tx.Exec(context.Background(),
+			`INSERT INTO todos (id, title, workspace_id) VALUES ($1, 'Planted', $2)`,
+			uuid.New(), aliceID)
+		assert.Error(t, err, "rows cannot be written into another workspace")
+	})
+
+	t.Run("without a workspace", func(t *testing.T) {
+		_, err := repo.GetByID(context.Background(), todo.ID)
+		assert.ErrorIs(t, err, tenant.ErrNoWorkspace)
+		_, err = repo.List(context.Background(), domain.TodoFilter{}, domain.PageRequest{})
+		assert.ErrorIs(t, err, tenant.ErrNoWorkspace)
+	})
+}
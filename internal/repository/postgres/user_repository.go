@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+type UserRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
+	return &UserRepository{pool: pool}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	_, err := execInWorkspace(ctx, r.pool, queryInsertUser, user.ID, user.Name, optionalString(user.Email), user.Role, user.CreatedAt)
+	if isUniqueViolation(err) {
+		return domain.ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	var u domain.User
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		u, err = scanUser(tx.QueryRow(ctx, queryGetUserByID, id))
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	result, err := execInWorkspace(ctx, r.pool, queryUpdateUser, user.ID, user.Name, optionalString(user.Email), user.Role)
+	if isUniqueViolation(err) {
+		return domain.ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// List returns every user ordered by name.
+func (r *UserRepository) List(ctx context.Context) ([]domain.User, error) {
+	var users []domain.User
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, queryListUsers)
+		if err != nil {
+			return err
+		}
+		users, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.User, error) {
+			return scanUser(row)
+		})
+		return err
+	})
+	return users, err
+}
+
+func scanUser(row pgx.Row) (domain.User, error) {
+	var (
+		u     domain.User
+		email *string
+	)
+	err := row.Scan(&u.ID, &u.Name, &email, &u.Role, &u.CreatedAt)
+	if email != nil {
+		u.Email = *email
+	}
+	return u, err
+}
+
+// optionalString maps an empty string to NULL.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
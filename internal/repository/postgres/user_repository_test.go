@@ -0,0 +1,93 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepository(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewUserRepository(pool)
+	ctx := workspaceContext()
+
+	alice, _ := domain.NewUser(uuid.Nil, "Alice", "alice@example.com", domain.RoleAdmin)
+	bob, _ := domain.NewUser(uuid.Nil, "Bob", "", domain.RoleMember)
+	carol, _ := domain.NewUser(uuid.Nil, "Carol", "", domain.RoleMember)
+	require.NoError(t, repo.Create(ctx, alice))
+	require.NoError(t, repo.Create(ctx, bob))
+	require.NoError(t, repo.Create(ctx, carol), "users without an email do not clash")
+
+	dup, _ := domain.NewUser(uuid.Nil, "Alice 2", "alice@example.com", domain.RoleMember)
+	assert.ErrorIs(t, repo.Create(ctx, dup), domain.ErrAlreadyExists)
+	require.NoError(t, repo.Create(workspaceContext(), dup), "emails are unique per workspace")
+
+	users, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+	assert.Equal(t, "Alice", users[0].Name)
+	assert.Equal(t, "alice@example.com", users[0].Email)
+	assert.Equal(t, domain.RoleAdmin, users[0].Role)
+
+	got, err := repo.GetByID(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Email)
+	_, err = repo.GetByID(ctx, dup.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	got.Role = domain.RoleViewer
+	require.NoError(t, repo.Update(ctx, got))
+	got, err = repo.GetByID(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleViewer, got.Role)
+	got.Email = "alice@example.com"
+	assert.ErrorIs(t, repo.Update(ctx, got), domain.ErrAlreadyExists)
+	assert.ErrorIs(t, repo.Update(ctx, dup), domain.ErrNotFound, "users of other workspaces are invisible")
+}
+
+func TestTodoRepository_Assignees(t *testing.T) {
+	pool := setupTestDB(t)
+	todos := postgres.NewTodoRepository(pool)
+	users := postgres.NewUserRepository(pool)
+	ctx := workspaceContext()
+
+	alice, _ := domain.NewUser(uuid.Nil, "Alice", "", domain.RoleMember)
+	bob, _ := domain.NewUser(uuid.Nil, "Bob", "", domain.RoleMember)
+	require.NoError(t, users.Create(ctx, alice))
+	require.NoError(t, users.Create(ctx, bob))
+
+	mine, _ := domain.NewTodo("Mine", "", domain.WithCreator(&alice.ID), domain.WithAssignee(&bob.ID))
+	other, _ := domain.NewTodo("Other", "")
+	require.NoError(t, todos.Create(ctx, mine))
+	require.NoError(t, todos.Create(ctx, other))
+
+	got, err := todos.GetByID(ctx, mine.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &alice.ID, got.CreatedBy)
+	assert.Equal(t, &alice.ID, got.UpdatedBy)
+	assert.Equal(t, &bob.ID, got.AssigneeID)
+
+	assigned, err := todos.List(ctx, domain.TodoFilter{AssigneeID: &bob.ID}, domain.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, assigned, 1)
+	assert.Equal(t, mine.ID, assigned[0].ID)
+
+	got.Assign(&alice.ID)
+	got.RecordEditor(&bob.ID)
+	require.NoError(t, todos.Update(ctx, got))
+	got, err = todos.GetByID(ctx, mine.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &alice.ID, got.AssigneeID)
+	assert.Equal(t, &bob.ID, got.UpdatedBy)
+	assert.Equal(t, &alice.ID, got.CreatedBy, "the owner never changes")
+
+	_, err = todos.CompleteAll(ctx, nil, &bob.ID)
+	require.NoError(t, err)
+	got, err = todos.GetByID(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &bob.ID, got.UpdatedBy)
+}
@@ -0,0 +1,230 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+type WebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookRepository(pool *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{pool: pool}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, hook *domain.Webhook) error {
+	_, err := execInWorkspace(ctx, r.pool, queryInsertWebhook,
+		hook.ID, hook.URL, hook.Secret, eventTypeStrings(hook.Events), hook.CreatedAt, hook.UpdatedAt)
+	return err
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	var w domain.Webhook
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		w, err = scanWebhook(tx.QueryRow(ctx, queryGetWebhookByID, id))
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// List returns every webhook, oldest first.
+func (r *WebhookRepository) List(ctx context.Context) ([]domain.Webhook, error) {
+	return r.query(ctx, queryListWebhooks)
+}
+
+// ListSubscribed returns the webhooks that subscribe to events of type t.
+func (r *WebhookRepository) ListSubscribed(ctx context.Context, t domain.EventType) ([]domain.Webhook, error) {
+	return r.query(ctx, querySubscribedWebhooks, string(t))
+}
+
+func (r *WebhookRepository) query(ctx context.Context, query string, args ...any) ([]domain.Webhook, error) {
+	var hooks []domain.Webhook
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		hooks, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Webhook, error) {
+			return scanWebhook(row)
+		})
+		return err
+	})
+	return hooks, err
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, hook *domain.Webhook) error {
+	result, err := execInWorkspace(ctx, r.pool, queryUpdateWebhook,
+		hook.ID, hook.URL, hook.Secret, eventTypeStrings(hook.Events), hook.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes the webhook with its deliveries.
+func (r *WebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := execInWorkspace(ctx, r.pool, queryDeleteWebhook, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanWebhook(row pgx.Row) (domain.Webhook, error) {
+	var (
+		w      domain.Webhook
+		events []string
+	)
+	err := row.Scan(&w.ID, &w.URL, &w.Secret, &events, &w.CreatedAt, &w.UpdatedAt)
+	w.Events = make([]domain.EventType, len(events))
+	for i, e := range events {
+		w.Events[i] = domain.EventType(e)
+	}
+	return w, err
+}
+
+func eventTypeStrings(types []domain.EventType) []string {
+	s := make([]string, len(types))
+	for i, t := range types {
+		s[i] = string(t)
+	}
+	return s
+}
+
+type WebhookDeliveryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookDeliveryRepository(pool *pgxpool.Pool) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{pool: pool}
+}
+
+// Enqueue stores new deliveries. A delivery of an event already queued for
+// the same webhook is ignored.
+func (r *WebhookDeliveryRepository) Enqueue(ctx context.Context, deliveries ...*domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for _, d := range deliveries {
+			batch.Queue(queryInsertWebhookDelivery, d.ID, d.WebhookID, d.EventID, d.EventType,
+				d.Payload, d.Status, d.NextAttemptAt, d.CreatedAt)
+		}
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
+
+// ListByWebhook returns up to limit deliveries of a webhook, newest first.
+func (r *WebhookDeliveryRepository) ListByWebhook(ctx context.Context, webhookID uuid.UUID, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := inWorkspace(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, queryListWebhookDeliveries, webhookID, limit)
+		if err != nil {
+			return err
+		}
+		deliveries, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.WebhookDelivery, error) {
+			return scanWebhookDelivery(row)
+		})
+		return err
+	})
+	return deliveries, err
+}
+
+// Dispatch calls send with up to limit deliveries of any workspace that are
+// due for an attempt, oldest first, and records the outcome of each. Like
+// OutboxRepository.Dispatch, it runs outside of row-level security and
+// keeps the deliveries locked until the outcomes are committed.
+func (r *WebhookDeliveryRepository) Dispatch(ctx context.Context, limit int, send func(context.Context, *domain.Webhook, *domain.WebhookDelivery) (int, error)) (succeeded, failed int, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		succeeded, failed = 0, 0
+		rows, err := tx.Query(ctx, queryClaimWebhookDeliveries, limit)
+		if err != nil {
+			return err
+		}
+		type claim struct {
+			hook     domain.Webhook
+			delivery domain.WebhookDelivery
+		}
+		claims, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (claim, error) {
+			var (
+				c      claim
+				events []string
+				err    error
+			)
+			c.delivery, err = scanWebhookDelivery(row,
+				&c.hook.ID, &c.hook.URL, &c.hook.Secret, &events, &c.hook.CreatedAt, &c.hook.UpdatedAt)
+			for _, e := range events {
+				c.hook.Events = append(c.hook.Events, domain.EventType(e))
+			}
+			return c, err
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := range claims {
+			d := &claims[i].delivery
+			status, serr := send(ctx, &claims[i].hook, d)
+			d.RecordAttempt(time.Now().UTC(), status, serr)
+			if _, err := tx.Exec(ctx, queryRecordWebhookAttempt, d.ID, d.Status, d.Attempts,
+				optionalStatus(d.ResponseStatus), optionalString(d.LastError), d.NextAttemptAt, d.LastAttemptAt); err != nil {
+				return err
+			}
+			if serr != nil {
+				failed++
+			} else {
+				succeeded++
+			}
+		}
+		return nil
+	})
+	return succeeded, failed, err
+}
+
+// scanWebhookDelivery scans webhookDeliveryColumns into a delivery and
+// any further columns into extra.
+func scanWebhookDelivery(row pgx.Row, extra ...any) (domain.WebhookDelivery, error) {
+	var (
+		d              domain.WebhookDelivery
+		responseStatus *int
+		lastError      *string
+	)
+	dest := append([]any{&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&responseStatus, &lastError, &d.NextAttemptAt, &d.LastAttemptAt, &d.CreatedAt}, extra...)
+	err := row.Scan(dest...)
+	if responseStatus != nil {
+		d.ResponseStatus = *responseStatus
+	}
+	if lastError != nil {
+		d.LastError = *lastError
+	}
+	return d, err
+}
+
+func optionalStatus(status int) *int {
+	if status == 0 {
+		return nil
+	}
+	return &status
+}
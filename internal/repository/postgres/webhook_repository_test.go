@@ -0,0 +1,132 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	pool := setupTestDB(t)
+	repo := postgres.NewWebhookRepository(pool)
+	ctx := workspaceContext()
+
+	all, err := domain.NewWebhook("https://example.com/all", "", nil)
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, all))
+	deleted, err := domain.NewWebhook("https://example.com/deleted", "", []domain.EventType{domain.EventTodoDeleted})
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, deleted))
+
+	got, err := repo.GetByID(ctx, deleted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, deleted.Secret, got.Secret)
+	assert.Equal(t, []domain.EventType{domain.EventTodoDeleted}, got.Events)
+
+	subscribed, err := repo.ListSubscribed(ctx, domain.EventTodoCreated)
+	require.NoError(t, err)
+	require.Len(t, subscribed, 1)
+	assert.Equal(t, all.ID, subscribed[0].ID)
+	subscribed, err = repo.ListSubscribed(ctx, domain.EventTodoDeleted)
+	require.NoError(t, err)
+	assert.Len(t, subscribed, 2)
+
+	hooks, err := repo.List(workspaceContext())
+	require.NoError(t, err)
+	assert.Empty(t, hooks, "webhooks are scoped to their workspace")
+
+	require.NoError(t, all.Update("https://example.org/all", "", []domain.EventType{domain.EventAllCompleted}))
+	require.NoError(t, repo.Update(ctx, all))
+	got, err = repo.GetByID(ctx, all.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.org/all", got.URL)
+	assert.Equal(t, []domain.EventType{domain.EventAllCompleted}, got.Events)
+
+	require.NoError(t, repo.Delete(ctx, deleted.ID))
+	_, err = repo.GetByID(ctx, deleted.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, deleted.ID), domain.ErrNotFound)
+}
+
+func TestWebhookDeliveryRepository_Dispatch(t *testing.T) {
+	pool := setupTestDB(t)
+	hooks := postgres.NewWebhookRepository(pool)
+	deliveries := postgres.NewWebhookDeliveryRepository(pool)
+	ctx := workspaceContext()
+
+	ok, err := domain.NewWebhook("https://example.com/ok", "", nil)
+	require.NoError(t, err)
+	require.NoError(t, hooks.Create(ctx, ok))
+	down, err := domain.NewWebhook("https://example.com/down", "", nil)
+	require.NoError(t, err)
+	require.NoError(t, hooks.Create(ctx, down))
+
+	event, err := domain.NewAllCompletedEvent(nil, []uuid.UUID{uuid.New()})
+	require.NoError(t, err)
+	var queued []*domain.WebhookDelivery
+	for _, hook := range []*domain.Webhook{ok, down} {
+		d, err := domain.NewWebhookDelivery(hook, event)
+		require.NoError(t, err)
+		queued = append(queued, d)
+	}
+	require.NoError(t, deliveries.Enqueue(ctx, queued...))
+	duplicate, err := domain.NewWebhookDelivery(ok, event)
+	require.NoError(t, err)
+	require.NoError(t, deliveries.Enqueue(ctx, duplicate), "queuing an event twice is ignored")
+
+	var sent []uuid.UUID
+	succeeded, failed, err := deliveries.Dispatch(context.Background(), 10,
+		func(ctx context.Context, hook *domain.Webhook, d *domain.WebhookDelivery) (int, error) {
+			sent = append(sent, hook.ID)
+			assert.JSONEq(t, string(queued[0].Payload), string(d.Payload))
+			// Concurrent workers skip the deliveries being sent.
+			n, m, err := deliveries.Dispatch(ctx, 10, func(context.Context, *domain.Webhook, *domain.WebhookDelivery) (int, error) {
+				return http.StatusOK, nil
+			})
+			require.NoError(t, err)
+			assert.Zero(t, n+m)
+
+			if hook.ID == down.ID {
+				return http.StatusServiceUnavailable, errors.New("unexpected status 503")
+			}
+			return http.StatusOK, nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, 1, failed)
+	assert.ElementsMatch(t, []uuid.UUID{ok.ID, down.ID}, sent)
+
+	list, err := deliveries.ListByWebhook(ctx, ok.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, domain.DeliverySucceeded, list[0].Status)
+	assert.Equal(t, http.StatusOK, list[0].ResponseStatus)
+	assert.Nil(t, list[0].NextAttemptAt)
+
+	list, err = deliveries.ListByWebhook(ctx, down.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, domain.DeliveryPending, list[0].Status)
+	assert.Equal(t, 1, list[0].Attempts)
+	assert.Equal(t, "unexpected status 503", list[0].LastError)
+	require.NotNil(t, list[0].NextAttemptAt)
+
+	succeeded, failed, err = deliveries.Dispatch(context.Background(), 10,
+		func(context.Context, *domain.Webhook, *domain.WebhookDelivery) (int, error) {
+			return http.StatusOK, nil
+		})
+	require.NoError(t, err)
+	assert.Zero(t, succeeded+failed, "a failed delivery waits for its retry")
+
+	require.NoError(t, hooks.Delete(ctx, down.ID))
+	list, err = deliveries.ListByWebhook(ctx, down.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, list, "deliveries are deleted with their webhook")
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knjname/go-todo-api/internal/tenant"
+)
+
+// inWorkspace runs fn in a transaction confined to the workspace of ctx.
+// The transaction assumes the todo_app role, so the row-level security
+// policies hide and protect the rows of every other workspace even from a
+// query that does not filter by workspace. Without a workspace in ctx it
+// fails with tenant.ErrNoWorkspace.
+func inWorkspace(ctx context.Context, pool *pgxpool.Pool, fn func(pgx.Tx) error) error {
+	id, ok := tenant.WorkspaceID(ctx)
+	if !ok {
+		return tenant.ErrNoWorkspace
+	}
+	return pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, queryEnterWorkspace, id.String()); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// execInWorkspace runs a single statement with inWorkspace.
+func execInWorkspace(ctx context.Context, pool *pgxpool.Pool, query string, args ...any) (pgconn.CommandTag, error) {
+	var tag pgconn.CommandTag
+	err := inWorkspace(ctx, pool, func(tx pgx.Tx) error {
+		var err error
+		tag, err = tx.Exec(ctx, query, args...)
+		return err
+	})
+	return tag, err
+}
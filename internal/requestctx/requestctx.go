@@ -0,0 +1,19 @@
+// Package requestctx carries the ID of the request an operation serves
+// through a context.Context, so that logs and the audit trail can name it
+// without depending on the HTTP layer.
+package requestctx
+
+import "context"
+
+type contextKey struct{}
+
+// WithID returns a copy of ctx serving the request id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// ID returns the request ctx serves, or "" outside of a request.
+func ID(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
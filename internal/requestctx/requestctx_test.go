@@ -0,0 +1,14 @@
+package requestctx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/knjname/go-todo-api/internal/requestctx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestID(t *testing.T) {
+	assert.Empty(t, requestctx.ID(context.Background()))
+	assert.Equal(t, "req-1", requestctx.ID(requestctx.WithID(context.Background(), "req-1")))
+}
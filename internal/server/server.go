@@ -7,30 +7,89 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humago"
+	"github.com/knjname/go-todo-api/internal/auth"
 	"github.com/knjname/go-todo-api/internal/config"
 	"github.com/knjname/go-todo-api/internal/handler"
 	"github.com/knjname/go-todo-api/internal/middleware"
 	"github.com/knjname/go-todo-api/internal/usecase"
 )
 
-func Run(ctx context.Context, cfg *config.Config, uc *usecase.TodoUseCase, logger *slog.Logger) error {
+const (
+	webhookBatchSize    = 50
+	webhookPollInterval = time.Second
+)
+
+func Run(ctx context.Context, cfg *config.Config, uc *usecase.TodoUseCase, tagUC *usecase.TagUseCase, projectUC *usecase.ProjectUseCase, userUC *usecase.UserUseCase, auditUC *usecase.AuditUseCase, webhookUC *usecase.WebhookUseCase, webhookWorker *usecase.WebhookWorker, eventStream *usecase.EventStream, presence *usecase.PresenceTracker, idempotencyUC *usecase.IdempotencyUseCase, apiKeyUC *usecase.APIKeyUseCase, verifier *auth.Verifier, logger *slog.Logger) error {
 	mux := http.NewServeMux()
 
-	api := humago.New(mux, huma.DefaultConfig("Todo API", "1.0.0"))
+	// API keys are always accepted, and JWTs when there is a verifier for
+	// them. Requests without credentials are let through only if allowed.
+	authConfig := middleware.AuthConfig{
+		Schemes:        map[string]auth.Authenticator{"ApiKey": auth.NewAPIKeyAuthenticator(apiKeyUC)},
+		Public:         middleware.PublicPaths,
+		AllowAnonymous: cfg.AllowAnonymous,
+	}
+	apiConfig := huma.DefaultConfig("Todo API", "1.0.0")
+	apiConfig.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+		"apiKey": {
+			Type:        "apiKey",
+			In:          "header",
+			Name:        "Authorization",
+			Description: "`ApiKey <token>` with a token issued by `batch apikey create`",
+		},
+	}
+	apiConfig.Security = []map[string][]string{{"apiKey": {}}}
+	if verifier != nil {
+		authConfig.Schemes["Bearer"] = verifier
+		apiConfig.Components.SecuritySchemes["bearer"] = &huma.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+		apiConfig.Security = append(apiConfig.Security, map[string][]string{"bearer": {}})
+	}
+	if cfg.AllowAnonymous {
+		apiConfig.Security = append(apiConfig.Security, map[string][]string{})
+	}
+	api := humago.New(mux, apiConfig)
 
-	todoHandler := handler.NewTodoHandler(uc)
+	todoHandler := handler.NewTodoHandler(uc, idempotencyUC)
 	todoHandler.Register(api)
 
+	tagHandler := handler.NewTagHandler(tagUC)
+	tagHandler.Register(api)
+
+	projectHandler := handler.NewProjectHandler(projectUC)
+	projectHandler.Register(api)
+
+	userHandler := handler.NewUserHandler(userUC)
+	userHandler.Register(api)
+
+	auditHandler := handler.NewAuditHandler(auditUC)
+	auditHandler.Register(api)
+
+	webhookHandler := handler.NewWebhookHandler(webhookUC)
+	webhookHandler.Register(api)
+
+	eventHandler := handler.NewEventHandler(eventStream)
+	eventHandler.Register(api)
+
+	// The board is served over WebSocket, outside of the OpenAPI operations.
+	boardHandler := handler.NewBoardHandler(uc, eventStream, presence)
+	mux.Handle("GET "+handler.BoardPath, boardHandler)
+
 	var h http.Handler = mux
+	h = middleware.User(h)
+	h = middleware.Workspace(h)
+	h = middleware.Authenticate(authConfig)(h)
 	h = middleware.Logging(logger)(h)
 	h = middleware.Recovery(logger)(h)
 	h = middleware.RequestID(h)
 
+	// The timeouts apply to regular requests. Board connections are
+	// hijacked, which lifts them.
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
 		Handler:      h,
@@ -39,6 +98,29 @@ func Run(ctx context.Context, cfg *config.Config, uc *usecase.TodoUseCase, logge
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// The webhook worker stops once the server has shut down, or failed.
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	defer workers.Wait()
+	defer stopWorker()
+	// The event streams end as soon as the server starts shutting down,
+	// which waits for the requests streaming them to finish.
+	streamCtx, stopStream := context.WithCancel(context.Background())
+	defer stopStream()
+	srv.RegisterOnShutdown(stopStream)
+	workers.Go(func() {
+		_ = eventStream.Run(streamCtx)
+	})
+	workers.Go(func() {
+		_ = presence.Run(streamCtx)
+	})
+	if cfg.WebhookWorker {
+		workers.Go(func() {
+			logger.Info("webhook worker starting")
+			_ = webhookWorker.Run(workerCtx, webhookBatchSize, webhookPollInterval)
+		})
+	}
+
 	errCh := make(chan error, 1)
 	go func() {
 		logger.Info("server starting", slog.Int("port", cfg.Port))
@@ -63,6 +145,11 @@ func Run(ctx context.Context, cfg *config.Config, uc *usecase.TodoUseCase, logge
 	defer cancel()
 
 	logger.Info("shutting down server")
+	// http.Server does not track hijacked connections, so the boards are
+	// closed first, which also keeps new ones from opening meanwhile.
+	if err := boardHandler.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("board connections did not close in time", slog.String("error", err.Error()))
+	}
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		return fmt.Errorf("server shutdown: %w", err)
 	}
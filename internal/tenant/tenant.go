@@ -0,0 +1,27 @@
+// Package tenant carries the workspace a request acts in through a
+// context.Context. The repositories refuse to touch data without one.
+package tenant
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoWorkspace reports an operation on tenant data without a workspace in
+// its context.
+var ErrNoWorkspace = errors.New("no workspace")
+
+type contextKey struct{}
+
+// WithWorkspace returns a copy of ctx acting in workspace id.
+func WithWorkspace(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// WorkspaceID returns the workspace ctx acts in, if any.
+func WorkspaceID(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(contextKey{}).(uuid.UUID)
+	return id, ok && id != uuid.Nil
+}
@@ -0,0 +1,23 @@
+package tenant_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceID(t *testing.T) {
+	_, ok := tenant.WorkspaceID(context.Background())
+	assert.False(t, ok)
+
+	_, ok = tenant.WorkspaceID(tenant.WithWorkspace(context.Background(), uuid.Nil))
+	assert.False(t, ok)
+
+	id := uuid.New()
+	got, ok := tenant.WorkspaceID(tenant.WithWorkspace(context.Background(), id))
+	assert.True(t, ok)
+	assert.Equal(t, id, got)
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// apiKeyUsageInterval limits how often the last use of an API key is
+// written, so that busy clients do not cause a write on every request.
+const apiKeyUsageInterval = time.Minute
+
+type APIKeyUseCase struct {
+	repo   APIKeyRepository
+	logger *slog.Logger
+}
+
+func NewAPIKeyUseCase(repo APIKeyRepository, logger *slog.Logger) *APIKeyUseCase {
+	return &APIKeyUseCase{repo: repo, logger: logger}
+}
+
+// CreateAPIKey creates an API key in the workspace of ctx. It returns the
+// key and its token, which cannot be retrieved again.
+func (uc *APIKeyUseCase) CreateAPIKey(ctx context.Context, name string, scopes []domain.Scope, expiresAt *time.Time) (*domain.APIKey, string, error) {
+	key, token, err := domain.NewAPIKey(name, scopes, expiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := uc.repo.Create(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("create api key: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "api key created", slog.String("id", key.ID.String()), slog.String("name", key.Name))
+	return key, token, nil
+}
+
+func (uc *APIKeyUseCase) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	keys, err := uc.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key of the workspace of ctx for good.
+func (uc *APIKeyUseCase) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	if err := uc.repo.Revoke(ctx, id, time.Now().UTC()); err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "api key revoked", slog.String("id", id.String()))
+	return nil
+}
+
+// Authenticate returns the active API key a token belongs to and records
+// its use. Unknown, revoked and expired keys and wrong secrets are all
+// reported as domain.ErrUnauthenticated.
+func (uc *APIKeyUseCase) Authenticate(ctx context.Context, token string) (*domain.APIKey, error) {
+	id, err := domain.ParseAPIKeyToken(token)
+	if err != nil {
+		return nil, err
+	}
+	key, err := uc.repo.Lookup(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, fmt.Errorf("look up api key: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if !key.Matches(token) || !key.Active(now) {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyUsageInterval {
+		if err := uc.repo.MarkUsed(ctx, key.ID, now); err != nil {
+			// Losing a usage timestamp must not lock the client out.
+			uc.logger.WarnContext(ctx, "failed to record api key use",
+				slog.String("id", key.ID.String()), slog.String("error", err.Error()))
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+	return key, nil
+}
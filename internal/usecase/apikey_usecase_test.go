@@ -0,0 +1,113 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPIKeyUseCase(repo *mocks.APIKeyRepository) *usecase.APIKeyUseCase {
+	return usecase.NewAPIKeyUseCase(repo, slog.New(slog.DiscardHandler))
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	repo := mocks.NewAPIKeyRepository(t)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.APIKey")).Return(nil)
+	uc := newTestAPIKeyUseCase(repo)
+
+	key, token, err := uc.CreateAPIKey(context.Background(), "ci", []domain.Scope{domain.ScopeRead}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ci", key.Name)
+	assert.True(t, key.Matches(token))
+
+	_, _, err = uc.CreateAPIKey(context.Background(), "ci", nil, nil)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	repo := mocks.NewAPIKeyRepository(t)
+	missing := uuid.New()
+	repo.On("Revoke", mock.Anything, missing, mock.AnythingOfType("time.Time")).Return(domain.ErrNotFound)
+	uc := newTestAPIKeyUseCase(repo)
+
+	assert.ErrorIs(t, uc.RevokeAPIKey(context.Background(), missing), domain.ErrNotFound)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	newKey := func(t *testing.T) (*domain.APIKey, string) {
+		key, token, err := domain.NewAPIKey("ci", []domain.Scope{domain.ScopeWrite}, nil)
+		require.NoError(t, err)
+		key.WorkspaceID = uuid.New()
+		return key, token
+	}
+
+	t.Run("records use", func(t *testing.T) {
+		key, token := newKey(t)
+		repo := mocks.NewAPIKeyRepository(t)
+		repo.On("Lookup", mock.Anything, key.ID).Return(key, nil)
+		repo.On("MarkUsed", mock.Anything, key.ID, mock.AnythingOfType("time.Time")).Return(nil)
+		uc := newTestAPIKeyUseCase(repo)
+
+		got, err := uc.Authenticate(context.Background(), token)
+		require.NoError(t, err)
+		assert.Equal(t, key.WorkspaceID, got.WorkspaceID)
+		assert.NotNil(t, got.LastUsedAt)
+	})
+
+	t.Run("recent use is not recorded again", func(t *testing.T) {
+		key, token := newKey(t)
+		usedAt := time.Now().Add(-10 * time.Second)
+		key.LastUsedAt = &usedAt
+		repo := mocks.NewAPIKeyRepository(t)
+		repo.On("Lookup", mock.Anything, key.ID).Return(key, nil)
+		uc := newTestAPIKeyUseCase(repo)
+
+		_, err := uc.Authenticate(context.Background(), token)
+		require.NoError(t, err)
+	})
+
+	t.Run("failing to record use", func(t *testing.T) {
+		key, token := newKey(t)
+		repo := mocks.NewAPIKeyRepository(t)
+		repo.On("Lookup", mock.Anything, key.ID).Return(key, nil)
+		repo.On("MarkUsed", mock.Anything, key.ID, mock.Anything).Return(errors.New("db down"))
+		uc := newTestAPIKeyUseCase(repo)
+
+		_, err := uc.Authenticate(context.Background(), token)
+		require.NoError(t, err)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		key, token := newKey(t)
+		revoked, revokedToken := newKey(t)
+		now := time.Now()
+		revoked.RevokedAt = &now
+		unknown, unknownToken := newKey(t)
+
+		repo := mocks.NewAPIKeyRepository(t)
+		repo.On("Lookup", mock.Anything, key.ID).Return(key, nil)
+		repo.On("Lookup", mock.Anything, revoked.ID).Return(revoked, nil)
+		repo.On("Lookup", mock.Anything, unknown.ID).Return(nil, domain.ErrNotFound)
+		uc := newTestAPIKeyUseCase(repo)
+
+		for name, tok := range map[string]string{
+			"wrong secret": token + "x",
+			"revoked":      revokedToken,
+			"unknown":      unknownToken,
+			"malformed":    "secret",
+		} {
+			_, err := uc.Authenticate(context.Background(), tok)
+			assert.ErrorIs(t, err, domain.ErrUnauthenticated, name)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// AuditUseCase reads the audit trail of todo changes, which the
+// TodoRepository records along with every change.
+type AuditUseCase struct {
+	repo   AuditRepository
+	policy Policy
+	logger *slog.Logger
+}
+
+func NewAuditUseCase(repo AuditRepository, policy Policy, logger *slog.Logger) *AuditUseCase {
+	return &AuditUseCase{repo: repo, policy: policy, logger: logger}
+}
+
+// TodoHistory returns the changes of a todo, oldest first. The history of a
+// purged todo remains available. It fails with domain.ErrNotFound if the
+// todo has no history.
+func (uc *AuditUseCase) TodoHistory(ctx context.Context, id uuid.UUID) ([]domain.AuditEntry, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionRead, nil); err != nil {
+		return nil, err
+	}
+	entries, err := uc.repo.ListByTodo(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list todo history: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("todo history: %w", domain.ErrNotFound)
+	}
+	return entries, nil
+}
+
+// ExportAudit calls fn with every audit entry recorded since the given
+// time, oldest first. It returns the number of entries exported.
+func (uc *AuditUseCase) ExportAudit(ctx context.Context, since time.Time, fn func(*domain.AuditEntry) error) (int, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionExportAudit, nil); err != nil {
+		return 0, err
+	}
+	count := 0
+	err := uc.repo.Export(ctx, since, func(e *domain.AuditEntry) error {
+		count++
+		return fn(e)
+	})
+	if err != nil {
+		return count, fmt.Errorf("export audit trail: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "audit trail exported", slog.Int("count", count), slog.Time("since", since))
+	return count, nil
+}
@@ -0,0 +1,77 @@
+package usecase_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuditUseCase(repo *mocks.AuditRepository, users *mocks.UserRepository) *usecase.AuditUseCase {
+	return usecase.NewAuditUseCase(repo, usecase.NewRolePolicy(users), slog.New(slog.DiscardHandler))
+}
+
+func TestTodoHistory(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := mocks.NewAuditRepository(t)
+		id := uuid.New()
+		entries := []domain.AuditEntry{{ID: 1, TodoID: id, Action: domain.AuditCreate}}
+		repo.On("ListByTodo", mock.Anything, id).Return(entries, nil)
+
+		got, err := newTestAuditUseCase(repo, mocks.NewUserRepository(t)).TodoHistory(serviceContext(), id)
+		require.NoError(t, err)
+		assert.Equal(t, entries, got)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repo := mocks.NewAuditRepository(t)
+		repo.On("ListByTodo", mock.Anything, mock.Anything).Return([]domain.AuditEntry{}, nil)
+
+		_, err := newTestAuditUseCase(repo, mocks.NewUserRepository(t)).TodoHistory(serviceContext(), uuid.New())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestExportAudit(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		repo := mocks.NewAuditRepository(t)
+		repo.On("Export", mock.Anything, since, mock.Anything).
+			Run(func(args mock.Arguments) {
+				fn := args.Get(2).(func(*domain.AuditEntry) error)
+				for i := range 3 {
+					_ = fn(&domain.AuditEntry{ID: int64(i + 1)})
+				}
+			}).
+			Return(nil)
+
+		var ids []int64
+		count, err := newTestAuditUseCase(repo, mocks.NewUserRepository(t)).ExportAudit(serviceContext(), since, func(e *domain.AuditEntry) error {
+			ids = append(ids, e.ID)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, []int64{1, 2, 3}, ids)
+	})
+
+	t.Run("members may not export", func(t *testing.T) {
+		repo := mocks.NewAuditRepository(t)
+		users := mocks.NewUserRepository(t)
+		member := uuid.New()
+		users.On("GetByID", mock.Anything, member).Return(&domain.User{ID: member, Role: domain.RoleMember}, nil)
+
+		_, err := newTestAuditUseCase(repo, users).ExportAudit(actor.WithUser(context.Background(), member), since, func(*domain.AuditEntry) error { return nil })
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+}
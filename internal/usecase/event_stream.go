@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/tenant"
+)
+
+// ErrStreamClosed is returned by EventStream.Stream when the stream ends
+// before the subscriber leaves: the subscriber fell behind, the stream
+// lost the database or is shutting down. The subscriber may resume from
+// the last event it received.
+var ErrStreamClosed = errors.New("event stream closed")
+
+const (
+	// streamBufferSize is how many events a subscriber may fall behind
+	// before it is dropped.
+	streamBufferSize = 256
+	// streamReplayBatchSize is how many missed events are read at a time.
+	streamReplayBatchSize = 100
+	// streamRetryDelay is how long Run waits before listening again after
+	// listening failed.
+	streamRetryDelay = time.Second
+)
+
+// EventStream streams the events of todo changes to subscribers as they
+// are committed. It listens to the outbox, which is notified of the events
+// written by any process, so subscribers of every API replica see them.
+type EventStream struct {
+	outbox OutboxRepository
+	policy Policy
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	listening bool
+	subs      map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	workspace uuid.UUID
+	// events is closed when the subscriber is dropped.
+	events chan *domain.Event
+}
+
+func NewEventStream(outbox OutboxRepository, policy Policy, logger *slog.Logger) *EventStream {
+	return &EventStream{outbox: outbox, policy: policy, logger: logger, subs: make(map[*subscriber]struct{})}
+}
+
+// Run listens for events and hands them to the subscribers of their
+// workspace until ctx is done, then drops every subscriber. Whenever
+// listening fails, the subscribers are dropped too, so that they resume
+// without missing the events committed until Run listens again.
+func (s *EventStream) Run(ctx context.Context) error {
+	for {
+		err := s.outbox.Listen(ctx, s.ready, s.publish)
+		s.dropAll()
+		if ctx.Err() != nil {
+			return nil
+		}
+		s.logger.ErrorContext(ctx, "event stream stopped listening", slog.String("error", err.Error()))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(streamRetryDelay):
+		}
+	}
+}
+
+// Stream calls send with the events of the workspace in ctx as they are
+// committed, until ctx is done or send fails. If horizon is not 0, the
+// events written by transactions at or after horizon (domain.Event.Horizon)
+// are sent first, each with horizon as its own, since the subscriber has
+// yet to receive the events committed before them. Events may be sent
+// again on resuming; subscribers tell them apart by ID. It returns
+// ErrStreamClosed if the stream ends first, including when it is not
+// listening yet.
+func (s *EventStream) Stream(ctx context.Context, horizon int64, send func(*domain.Event) error) error {
+	return s.stream(ctx, horizon, nil, send)
+}
+
+// Follow calls send with the events of the workspace in ctx committed
+// from now on, like Stream, and calls subscribed as soon as those events
+// are on their way: whatever the caller reads from then on is not older
+// than the events it is sent next.
+func (s *EventStream) Follow(ctx context.Context, subscribed func(), send func(*domain.Event) error) error {
+	return s.stream(ctx, 0, subscribed, send)
+}
+
+func (s *EventStream) stream(ctx context.Context, horizon int64, subscribed func(), send func(*domain.Event) error) error {
+	if err := s.policy.Authorize(ctx, domain.ActionRead, nil); err != nil {
+		return err
+	}
+	workspace, ok := tenant.WorkspaceID(ctx)
+	if !ok {
+		return tenant.ErrNoWorkspace
+	}
+
+	// Subscribing before reading the missed events buffers those committed
+	// meanwhile; the ones read with them are skipped. Events are committed
+	// out of outbox order, so those are told apart by ID.
+	sub, err := s.subscribe(workspace)
+	if err != nil {
+		return err
+	}
+	defer s.unsubscribe(sub)
+	if subscribed != nil {
+		subscribed()
+	}
+
+	replayed := make(map[uuid.UUID]struct{})
+	for after := int64(0); horizon > 0; {
+		events, err := s.outbox.ListSince(ctx, horizon, after, streamReplayBatchSize)
+		if err != nil {
+			return fmt.Errorf("list missed events: %w", err)
+		}
+		for i := range events {
+			events[i].Horizon = horizon
+			if err := send(&events[i]); err != nil {
+				return err
+			}
+			replayed[events[i].ID] = struct{}{}
+			after = events[i].Seq
+		}
+		if len(events) < streamReplayBatchSize {
+			break
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-sub.events:
+			if !ok {
+				return ErrStreamClosed
+			}
+			if _, ok := replayed[e.ID]; ok {
+				delete(replayed, e.ID)
+				continue
+			}
+			if err := send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *EventStream) ready() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listening = true
+}
+
+func (s *EventStream) subscribe(workspace uuid.UUID) (*subscriber, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.listening {
+		return nil, ErrStreamClosed
+	}
+	sub := &subscriber{workspace: workspace, events: make(chan *domain.Event, streamBufferSize)}
+	s.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+func (s *EventStream) unsubscribe(sub *subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs, sub)
+}
+
+func (s *EventStream) publish(e *domain.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subs {
+		if sub.workspace != e.WorkspaceID {
+			continue
+		}
+		select {
+		case sub.events <- e:
+		default:
+			// Rather than hold up the others, the subscriber that fell
+			// behind resumes later.
+			delete(s.subs, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (s *EventStream) dropAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listening = false
+	for sub := range s.subs {
+		delete(s.subs, sub)
+		close(sub.events)
+	}
+}
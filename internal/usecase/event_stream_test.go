@@ -0,0 +1,200 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/tenant"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// feed stands in for the outbox notifications the event stream listens to.
+type feed struct {
+	listening chan struct{}
+	// publish hands an event to the stream once it is listening.
+	publish func(*domain.Event)
+	lost    chan struct{}
+	stop    context.CancelFunc
+}
+
+// runEventStream runs an event stream on outbox, whose Listen listens
+// until the stream stops or the feed is lost, and waits until it listens.
+func runEventStream(t *testing.T, outbox *mocks.OutboxRepository) (*usecase.EventStream, *feed) {
+	t.Helper()
+	f := &feed{listening: make(chan struct{}), lost: make(chan struct{})}
+	var once sync.Once
+	outbox.On("Listen", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, ready func(), publish func(*domain.Event)) error {
+			ready()
+			once.Do(func() {
+				f.publish = publish
+				close(f.listening)
+			})
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-f.lost:
+				return errors.New("connection lost")
+			}
+		})
+
+	stream := usecase.NewEventStream(outbox, usecase.NewRolePolicy(mocks.NewUserRepository(t)), slog.New(slog.DiscardHandler))
+	ctx, cancel := context.WithCancel(serviceContext())
+	done := make(chan struct{})
+	go func() {
+		_ = stream.Run(ctx)
+		close(done)
+	}()
+	f.stop = cancel
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	<-f.listening
+	return stream, f
+}
+
+// subscribed makes ListSince(horizon) return missed and closes the returned
+// channel once it has, by which time the stream has subscribed.
+func subscribed(outbox *mocks.OutboxRepository, horizon int64, missed []domain.Event) chan struct{} {
+	ch := make(chan struct{})
+	outbox.On("ListSince", mock.Anything, horizon, int64(0), mock.Anything).Run(func(mock.Arguments) { close(ch) }).Return(missed, nil).Once()
+	return ch
+}
+
+func TestEventStream_Stream(t *testing.T) {
+	workspace := uuid.New()
+	newEvent := func(seq int64, workspace uuid.UUID) *domain.Event {
+		return &domain.Event{ID: uuid.New(), Seq: seq, Type: domain.EventTodoUpdated, WorkspaceID: workspace}
+	}
+
+	t.Run("resumes with the missed events, then streams new ones", func(t *testing.T) {
+		outbox := mocks.NewOutboxRepository(t)
+		stream, f := runEventStream(t, outbox)
+		missed := newEvent(6, workspace)
+		missed.Horizon = 9
+		ready := subscribed(outbox, 4, []domain.Event{*newEvent(5, workspace), *missed})
+
+		ctx, cancel := context.WithCancel(tenant.WithWorkspace(serviceContext(), workspace))
+		received := make(chan *domain.Event, 10)
+		errc := make(chan error, 1)
+		go func() {
+			errc <- stream.Stream(ctx, 4, func(e *domain.Event) error {
+				received <- e
+				return nil
+			})
+		}()
+
+		<-ready
+		f.publish(missed)
+		f.publish(newEvent(3, workspace))
+		f.publish(newEvent(7, uuid.New()))
+		f.publish(newEvent(8, workspace))
+		var seqs []int64
+		for i := range 4 {
+			e := <-received
+			seqs = append(seqs, e.Seq)
+			if i < 2 {
+				assert.Equal(t, int64(4), e.Horizon, "missed events resume from where the subscriber asked to")
+			}
+		}
+		assert.Equal(t, []int64{5, 6, 3, 8}, seqs, "events already sent and those of other workspaces are skipped, but not those committed late")
+
+		cancel()
+		assert.NoError(t, <-errc)
+	})
+
+	t.Run("ends when the stream stops listening", func(t *testing.T) {
+		outbox := mocks.NewOutboxRepository(t)
+		stream, f := runEventStream(t, outbox)
+		ready := subscribed(outbox, 1, nil)
+
+		errc := make(chan error, 1)
+		go func() {
+			errc <- stream.Stream(tenant.WithWorkspace(serviceContext(), workspace), 1, func(*domain.Event) error { return nil })
+		}()
+		<-ready
+		close(f.lost)
+		assert.ErrorIs(t, <-errc, usecase.ErrStreamClosed)
+	})
+
+	t.Run("ends when the stream shuts down", func(t *testing.T) {
+		outbox := mocks.NewOutboxRepository(t)
+		stream, f := runEventStream(t, outbox)
+		ready := subscribed(outbox, 1, nil)
+
+		errc := make(chan error, 1)
+		go func() {
+			errc <- stream.Stream(tenant.WithWorkspace(serviceContext(), workspace), 1, func(*domain.Event) error { return nil })
+		}()
+		<-ready
+		f.stop()
+		assert.ErrorIs(t, <-errc, usecase.ErrStreamClosed)
+
+		err := stream.Stream(tenant.WithWorkspace(serviceContext(), workspace), 0, func(*domain.Event) error { return nil })
+		assert.ErrorIs(t, err, usecase.ErrStreamClosed, "no one may subscribe once it is shut down")
+	})
+
+	t.Run("drops subscribers that fall behind", func(t *testing.T) {
+		outbox := mocks.NewOutboxRepository(t)
+		stream, f := runEventStream(t, outbox)
+		ready := subscribed(outbox, 1, nil)
+
+		blocked := make(chan struct{})
+		var sent int
+		errc := make(chan error, 1)
+		go func() {
+			errc <- stream.Stream(tenant.WithWorkspace(serviceContext(), workspace), 1, func(*domain.Event) error {
+				<-blocked
+				sent++
+				return nil
+			})
+		}()
+		<-ready
+		for seq := range int64(1000) {
+			f.publish(newEvent(seq+2, workspace))
+		}
+		close(blocked)
+		assert.ErrorIs(t, <-errc, usecase.ErrStreamClosed)
+		assert.Less(t, sent, 1000)
+	})
+
+	t.Run("follows the events committed once subscribed", func(t *testing.T) {
+		outbox := mocks.NewOutboxRepository(t)
+		stream, f := runEventStream(t, outbox)
+
+		ctx, cancel := context.WithCancel(tenant.WithWorkspace(serviceContext(), workspace))
+		ready := make(chan struct{})
+		received := make(chan int64, 10)
+		errc := make(chan error, 1)
+		go func() {
+			errc <- stream.Follow(ctx, func() { close(ready) }, func(e *domain.Event) error {
+				received <- e.Seq
+				return nil
+			})
+		}()
+
+		<-ready
+		f.publish(newEvent(3, workspace))
+		assert.Equal(t, int64(3), <-received)
+		cancel()
+		assert.NoError(t, <-errc)
+		outbox.AssertNotCalled(t, "ListSince", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires a workspace", func(t *testing.T) {
+		outbox := mocks.NewOutboxRepository(t)
+		stream, _ := runEventStream(t, outbox)
+		err := stream.Stream(serviceContext(), 0, func(*domain.Event) error { return nil })
+		require.ErrorIs(t, err, tenant.ErrNoWorkspace)
+	})
+}
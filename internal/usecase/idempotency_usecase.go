@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// IdempotencyUseCase lets a client retry a request with the same
+// idempotency key without running it twice: the first request runs and its
+// response is stored, and retries are answered with that response.
+type IdempotencyUseCase struct {
+	repo   IdempotencyRepository
+	logger *slog.Logger
+}
+
+func NewIdempotencyUseCase(repo IdempotencyRepository, logger *slog.Logger) *IdempotencyUseCase {
+	return &IdempotencyUseCase{repo: repo, logger: logger}
+}
+
+// Begin reserves key for the request identified by fingerprint. It returns
+// nil if the request is to run, in which case Finish must be called with
+// its response, or the stored response to an earlier request with key.
+//
+// It returns a validation error if the earlier request is another request,
+// and an error wrapping domain.ErrConflict if it is still in progress.
+func (uc *IdempotencyUseCase) Begin(ctx context.Context, key, fingerprint string) (*domain.StoredResponse, error) {
+	record, err := domain.NewIdempotencyRecord(key, fingerprint, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	held, err := uc.repo.Reserve(ctx, record)
+	if err != nil {
+		return nil, fmt.Errorf("reserve idempotency key: %w", err)
+	}
+	switch {
+	case held == nil:
+		return nil, nil
+	case !held.Matches(fingerprint):
+		return nil, domain.NewValidationError("Idempotency-Key", "was already used for another request")
+	case held.Response == nil:
+		return nil, fmt.Errorf("request with idempotency key is in progress: %w", domain.ErrConflict)
+	}
+	return held.Response, nil
+}
+
+// Finish stores the response to the request begun with key, to replay it
+// to retries. A nil response, for a request that failed to answer, or a
+// server error is not stored: the key is released for a retry to run again.
+func (uc *IdempotencyUseCase) Finish(ctx context.Context, key string, response *domain.StoredResponse) error {
+	if response == nil || response.Status >= 500 {
+		if err := uc.repo.Release(ctx, key); err != nil {
+			return fmt.Errorf("release idempotency key: %w", err)
+		}
+		return nil
+	}
+	if err := uc.repo.Complete(ctx, key, response); err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpired removes the expired idempotency keys of every workspace.
+func (uc *IdempotencyUseCase) PurgeExpired(ctx context.Context) (int64, error) {
+	count, err := uc.repo.DeleteExpired(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("purge idempotency keys: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "idempotency keys purged", slog.Int64("count", count))
+	return count, nil
+}
@@ -0,0 +1,98 @@
+package usecase_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIdempotencyUseCase(repo *mocks.IdempotencyRepository) *usecase.IdempotencyUseCase {
+	return usecase.NewIdempotencyUseCase(repo, slog.New(slog.DiscardHandler))
+}
+
+func TestIdempotencyBegin(t *testing.T) {
+	ctx := context.Background()
+	held := func(fingerprint string, response *domain.StoredResponse) *domain.IdempotencyRecord {
+		return &domain.IdempotencyRecord{Key: "k", Fingerprint: fingerprint, Response: response}
+	}
+
+	t.Run("new key", func(t *testing.T) {
+		repo := mocks.NewIdempotencyRepository(t)
+		repo.On("Reserve", mock.Anything, mock.MatchedBy(func(r *domain.IdempotencyRecord) bool {
+			return r.Key == "k" && r.Fingerprint == "fp" && r.Response == nil
+		})).Return(nil, nil)
+
+		stored, err := newTestIdempotencyUseCase(repo).Begin(ctx, "k", "fp")
+		require.NoError(t, err)
+		assert.Nil(t, stored)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		response := &domain.StoredResponse{Status: 200, Body: []byte(`{}`)}
+		repo := mocks.NewIdempotencyRepository(t)
+		repo.On("Reserve", mock.Anything, mock.Anything).Return(held("fp", response), nil)
+
+		stored, err := newTestIdempotencyUseCase(repo).Begin(ctx, "k", "fp")
+		require.NoError(t, err)
+		assert.Equal(t, response, stored)
+	})
+
+	t.Run("another request", func(t *testing.T) {
+		repo := mocks.NewIdempotencyRepository(t)
+		repo.On("Reserve", mock.Anything, mock.Anything).Return(held("other", &domain.StoredResponse{Status: 200}), nil)
+
+		_, err := newTestIdempotencyUseCase(repo).Begin(ctx, "k", "fp")
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		repo := mocks.NewIdempotencyRepository(t)
+		repo.On("Reserve", mock.Anything, mock.Anything).Return(held("fp", nil), nil)
+
+		_, err := newTestIdempotencyUseCase(repo).Begin(ctx, "k", "fp")
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, err := newTestIdempotencyUseCase(mocks.NewIdempotencyRepository(t)).Begin(ctx, "a key", "fp")
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestIdempotencyFinish(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores the response", func(t *testing.T) {
+		response := &domain.StoredResponse{Status: 422}
+		repo := mocks.NewIdempotencyRepository(t)
+		repo.On("Complete", mock.Anything, "k", response).Return(nil)
+		require.NoError(t, newTestIdempotencyUseCase(repo).Finish(ctx, "k", response))
+	})
+
+	t.Run("releases the key on server errors", func(t *testing.T) {
+		repo := mocks.NewIdempotencyRepository(t)
+		repo.On("Release", mock.Anything, "k").Return(nil).Twice()
+		uc := newTestIdempotencyUseCase(repo)
+		require.NoError(t, uc.Finish(ctx, "k", &domain.StoredResponse{Status: 503}))
+		require.NoError(t, uc.Finish(ctx, "k", nil))
+	})
+}
+
+func TestIdempotencyPurgeExpired(t *testing.T) {
+	repo := mocks.NewIdempotencyRepository(t)
+	repo.On("DeleteExpired", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) < time.Minute
+	})).Return(int64(3), nil)
+
+	count, err := newTestIdempotencyUseCase(repo).PurgeExpired(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
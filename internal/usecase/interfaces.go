@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/knjname/go-todo-api/internal/domain"
@@ -9,10 +10,218 @@ import (
 
 //go:generate go run github.com/vektra/mockery/v2 --name=TodoRepository --output=./mocks --outpkg=mocks
 type TodoRepository interface {
+	// Create stores a new todo. It returns domain.ErrAlreadyExists if the
+	// todo's series already has an occurrence on the same due date.
 	Create(ctx context.Context, todo *domain.Todo) error
+	// GetByID returns a live todo; trashed todos are reported as domain.ErrNotFound.
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Todo, error)
-	List(ctx context.Context) ([]domain.Todo, error)
+	// GetTrashedByID returns a todo in the trash.
+	GetTrashedByID(ctx context.Context, id uuid.UUID) (*domain.Todo, error)
+	List(ctx context.Context, filter domain.TodoFilter, page domain.PageRequest) ([]domain.Todo, error)
+	// Search returns the todos matching a full-text search, best ranked
+	// first, without highlights.
+	Search(ctx context.Context, search *domain.TodoSearch) ([]domain.SearchHit, error)
+	// Update stores todo, including its trash state, if it is still at
+	// todo.Version, then increments todo.Version. It returns
+	// domain.ErrConflict on a concurrent modification.
 	Update(ctx context.Context, todo *domain.Todo) error
+	// UpdateMany stores several todos atomically, with the same version
+	// checks as Update, and creates the todos created, such as the next
+	// occurrences of completed recurring todos, in the same transaction.
+	// A created todo whose series already has an occurrence on the same due
+	// date is skipped.
+	UpdateMany(ctx context.Context, todos, created []*domain.Todo) error
+	// ListDescendants returns the live subtasks of a todo at any depth.
+	ListDescendants(ctx context.Context, id uuid.UUID) ([]domain.Todo, error)
+	// ListDescendantsOfAll returns the live subtasks at any depth of any of
+	// the todos ids.
+	ListDescendantsOfAll(ctx context.Context, ids []uuid.UUID) ([]domain.Todo, error)
+	// ListByIDs returns the live todos among ids, leaving the others out.
+	ListByIDs(ctx context.Context, ids []uuid.UUID) ([]domain.Todo, error)
+	// UpdateBulk stores groups of todos at once, writing the fields bulk
+	// actions change: status, trash state, parent, project and tags. A group
+	// whose todos are not all at todo.Version is not stored and gets the
+	// error domain.ErrNotFound or domain.ErrConflict. If atomic, no group is
+	// stored unless all can be. The todos created[i] are created along with
+	// group i, as by UpdateMany. It returns the error of each group.
+	UpdateBulk(ctx context.Context, groups, created [][]*domain.Todo, atomic bool) ([]error, error)
+	// ListRecurring returns the latest occurrence, trashed or not, of every
+	// series that still recurs.
+	ListRecurring(ctx context.Context) ([]domain.Todo, error)
+	// PurgeTrash permanently removes the todos trashed before the given time.
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+	// CompleteAll marks every open or in-progress todo of a project as done,
+	// or those of every project if projectID is nil, and records user as
+	// their last editor.
+	CompleteAll(ctx context.Context, projectID, user *uuid.UUID) (int64, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2 --name=TagRepository --output=./mocks --outpkg=mocks
+type TagRepository interface {
+	// Create stores a new tag. It returns domain.ErrAlreadyExists if the name is taken.
+	Create(ctx context.Context, tag *domain.Tag) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Tag, error)
+	List(ctx context.Context) ([]domain.Tag, error)
+	// Update renames a tag. It returns domain.ErrAlreadyExists if the name is taken.
+	Update(ctx context.Context, tag *domain.Tag) error
+	// Delete removes a tag from every todo and deletes it.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2 --name=ProjectRepository --output=./mocks --outpkg=mocks
+type ProjectRepository interface {
+	// Create stores a new project. It returns domain.ErrAlreadyExists if the name is taken.
+	Create(ctx context.Context, project *domain.Project) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Project, error)
+	List(ctx context.Context) ([]domain.Project, error)
+	// Update renames a project. It returns domain.ErrAlreadyExists if the name is taken.
+	Update(ctx context.Context, project *domain.Project) error
+	// Delete removes a project; its todos are kept outside any project.
 	Delete(ctx context.Context, id uuid.UUID) error
-	CompleteAll(ctx context.Context) (int64, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2 --name=APIKeyRepository --output=./mocks --outpkg=mocks
+type APIKeyRepository interface {
+	// Create stores a new API key in the workspace of ctx and sets its WorkspaceID.
+	Create(ctx context.Context, key *domain.APIKey) error
+	// List returns the API keys of the workspace of ctx, newest first.
+	List(ctx context.Context) ([]domain.APIKey, error)
+	// Revoke revokes an API key; a revoked key keeps its revocation time.
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+	// Lookup returns an API key of any workspace, to authenticate a
+	// request before its workspace is known.
+	Lookup(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	// MarkUsed records when an API key of any workspace was last used.
+	MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2 --name=UserRepository --output=./mocks --outpkg=mocks
+type UserRepository interface {
+	// Create stores a new user. It returns domain.ErrAlreadyExists if the
+	// ID or email is taken.
+	Create(ctx context.Context, user *domain.User) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	List(ctx context.Context) ([]domain.User, error)
+	// Update stores the user's name, email and role. It returns
+	// domain.ErrNotFound if the user does not exist and
+	// domain.ErrAlreadyExists if the email is taken.
+	Update(ctx context.Context, user *domain.User) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2 --name=AuditRepository --output=./mocks --outpkg=mocks
+type AuditRepository interface {
+	// ListByTodo returns the audit entries of a todo, oldest first.
+	ListByTodo(ctx context.Context, todoID uuid.UUID) ([]domain.AuditEntry, error)
+	// Export calls fn with every entry recorded since the given time,
+	// oldest first, stopping at the first error fn returns.
+	Export(ctx context.Context, since time.Time, fn func(*domain.AuditEntry) error) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2 --name=OutboxRepository --output=./mocks --outpkg=mocks
+type OutboxRepository interface {
+	// Dispatch calls deliver with up to limit events of any workspace that
+	// are due for delivery, oldest first, skipping events a concurrent
+	// relay is delivering. Events deliver accepts are marked delivered; the
+	// others are retried after domain.RetryDelay. It returns how many
+	// events were delivered and how many failed.
+	Dispatch(ctx context.Context, limit int, deliver func(context.Context, *domain.Event) error) (delivered, failed int, err error)
+	// ListSince returns up to limit events of the workspace in ctx written
+	// by transactions at or after horizon (domain.Event.Horizon), from
+	// after the event at position after (domain.Event.Seq) on, in the
+	// order they were written.
+	ListSince(ctx context.Context, horizon, after int64, limit int) ([]domain.Event, error)
+	// Listen calls publish with the events of every workspace as they are
+	// committed, in any process, until ctx is done or listening fails. It
+	// calls ready once it is listening.
+	Listen(ctx context.Context, ready func(), publish func(*domain.Event)) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2 --name=PresenceRepository --output=./mocks --outpkg=mocks
+
+// PresenceRepository passes the presences of board connections between
+// the API processes.
+type PresenceRepository interface {
+	// Announce tells every process listening, including this one, about p.
+	Announce(ctx context.Context, p *domain.Presence) error
+	// Listen calls announced with the presences of every workspace as they
+	// are announced, in any process, until ctx is done or listening fails.
+	Listen(ctx context.Context, announced func(*domain.Presence)) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2 --name=EventSink --output=./mocks --outpkg=mocks
+
+// EventSink is a destination the outbox relay delivers events to.
+type EventSink interface {
+	// Deliver hands an event over to the sink. It returns nil only once
+	// the sink has accepted the event; otherwise the event is delivered
+	// again later.
+	Deliver(ctx context.Context, event *domain.Event) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2 --name=WebhookRepository --output=./mocks --outpkg=mocks
+type WebhookRepository interface {
+	Create(ctx context.Context, hook *domain.Webhook) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error)
+	// List returns every webhook of the workspace, oldest first.
+	List(ctx context.Context) ([]domain.Webhook, error)
+	// ListSubscribed returns the webhooks that subscribe to events of type t.
+	ListSubscribed(ctx context.Context, t domain.EventType) ([]domain.Webhook, error)
+	Update(ctx context.Context, hook *domain.Webhook) error
+	// Delete removes a webhook with its deliveries.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2 --name=WebhookDeliveryRepository --output=./mocks --outpkg=mocks
+type WebhookDeliveryRepository interface {
+	// Enqueue stores new deliveries, ignoring those of an event already
+	// queued for the same webhook.
+	Enqueue(ctx context.Context, deliveries ...*domain.WebhookDelivery) error
+	// ListByWebhook returns up to limit deliveries of a webhook, newest first.
+	ListByWebhook(ctx context.Context, webhookID uuid.UUID, limit int) ([]domain.WebhookDelivery, error)
+	// Dispatch calls send with up to limit deliveries of any workspace that
+	// are due for an attempt, skipping deliveries a concurrent worker is
+	// sending, and records each outcome with
+	// domain.WebhookDelivery.RecordAttempt. send returns the HTTP status of
+	// the response, 0 if there was none, and an error unless the receiver
+	// accepted the delivery. Dispatch returns how many deliveries
+	// succeeded and how many failed.
+	Dispatch(ctx context.Context, limit int, send func(context.Context, *domain.Webhook, *domain.WebhookDelivery) (int, error)) (succeeded, failed int, err error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2 --name=WebhookSender --output=./mocks --outpkg=mocks
+
+// WebhookSender makes a single, signed attempt at a webhook delivery.
+type WebhookSender interface {
+	// Send posts the delivery's payload to the webhook. It returns the HTTP
+	// status of the response, 0 if there was none, and an error unless the
+	// status is 2xx.
+	Send(ctx context.Context, hook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2 --name=IdempotencyRepository --output=./mocks --outpkg=mocks
+
+// IdempotencyRepository keeps the requests made with an idempotency key by
+// the caller in ctx, with their responses.
+type IdempotencyRepository interface {
+	// Reserve stores record as a request in progress, unless the caller
+	// holds its key. It then returns the record holding the key instead,
+	// and nil otherwise.
+	Reserve(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error)
+	// Complete stores the response to the request in progress with key.
+	Complete(ctx context.Context, key string, response *domain.StoredResponse) error
+	// Release removes the request in progress with key.
+	Release(ctx context.Context, key string) error
+	// DeleteExpired removes the keys of any workspace that expired before
+	// the given time.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2 --name=Policy --output=./mocks --outpkg=mocks
+
+// Policy decides what the caller of a use case may do.
+type Policy interface {
+	// Authorize returns an error wrapping domain.ErrForbidden unless the
+	// caller in ctx may perform action on todo. todo is nil for actions
+	// that are not about a single todo.
+	Authorize(ctx context.Context, action domain.Action, todo *domain.Todo) error
 }
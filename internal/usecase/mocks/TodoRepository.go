@@ -8,6 +8,8 @@ import (
 	domain "github.com/knjname/go-todo-api/internal/domain"
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -16,9 +18,9 @@ type TodoRepository struct {
 	mock.Mock
 }
 
-// CompleteAll provides a mock function with given fields: ctx
-func (_m *TodoRepository) CompleteAll(ctx context.Context) (int64, error) {
-	ret := _m.Called(ctx)
+// CompleteAll provides a mock function with given fields: ctx, projectID, user
+func (_m *TodoRepository) CompleteAll(ctx context.Context, projectID *uuid.UUID, user *uuid.UUID) (int64, error) {
+	ret := _m.Called(ctx, projectID, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CompleteAll")
@@ -26,17 +28,17 @@ func (_m *TodoRepository) CompleteAll(ctx context.Context) (int64, error) {
 
 	var r0 int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
-		return rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, *uuid.UUID, *uuid.UUID) (int64, error)); ok {
+		return rf(ctx, projectID, user)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, *uuid.UUID, *uuid.UUID) int64); ok {
+		r0 = rf(ctx, projectID, user)
 	} else {
 		r0 = ret.Get(0).(int64)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, *uuid.UUID, *uuid.UUID) error); ok {
+		r1 = rf(ctx, projectID, user)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -62,30 +64,42 @@ func (_m *TodoRepository) Create(ctx context.Context, todo *domain.Todo) error {
 	return r0
 }
 
-// Delete provides a mock function with given fields: ctx, id
-func (_m *TodoRepository) Delete(ctx context.Context, id uuid.UUID) error {
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *TodoRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
 	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Delete")
+		panic("no return value specified for GetByID")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+	var r0 *domain.Todo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*domain.Todo, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *domain.Todo); ok {
 		r0 = rf(ctx, id)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Todo)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// GetByID provides a mock function with given fields: ctx, id
-func (_m *TodoRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
+// GetTrashedByID provides a mock function with given fields: ctx, id
+func (_m *TodoRepository) GetTrashedByID(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
 	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetByID")
+		panic("no return value specified for GetTrashedByID")
 	}
 
 	var r0 *domain.Todo
@@ -110,14 +124,134 @@ func (_m *TodoRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.To
 	return r0, r1
 }
 
-// List provides a mock function with given fields: ctx
-func (_m *TodoRepository) List(ctx context.Context) ([]domain.Todo, error) {
-	ret := _m.Called(ctx)
+// List provides a mock function with given fields: ctx, filter, page
+func (_m *TodoRepository) List(ctx context.Context, filter domain.TodoFilter, page domain.PageRequest) ([]domain.Todo, error) {
+	ret := _m.Called(ctx, filter, page)
 
 	if len(ret) == 0 {
 		panic("no return value specified for List")
 	}
 
+	var r0 []domain.Todo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TodoFilter, domain.PageRequest) ([]domain.Todo, error)); ok {
+		return rf(ctx, filter, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TodoFilter, domain.PageRequest) []domain.Todo); ok {
+		r0 = rf(ctx, filter, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Todo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.TodoFilter, domain.PageRequest) error); ok {
+		r1 = rf(ctx, filter, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListByIDs provides a mock function with given fields: ctx, ids
+func (_m *TodoRepository) ListByIDs(ctx context.Context, ids []uuid.UUID) ([]domain.Todo, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByIDs")
+	}
+
+	var r0 []domain.Todo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) ([]domain.Todo, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) []domain.Todo); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Todo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDescendants provides a mock function with given fields: ctx, id
+func (_m *TodoRepository) ListDescendants(ctx context.Context, id uuid.UUID) ([]domain.Todo, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDescendants")
+	}
+
+	var r0 []domain.Todo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]domain.Todo, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []domain.Todo); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Todo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDescendantsOfAll provides a mock function with given fields: ctx, ids
+func (_m *TodoRepository) ListDescendantsOfAll(ctx context.Context, ids []uuid.UUID) ([]domain.Todo, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDescendantsOfAll")
+	}
+
+	var r0 []domain.Todo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) ([]domain.Todo, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) []domain.Todo); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Todo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListRecurring provides a mock function with given fields: ctx
+func (_m *TodoRepository) ListRecurring(ctx context.Context) ([]domain.Todo, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRecurring")
+	}
+
 	var r0 []domain.Todo
 	var r1 error
 	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.Todo, error)); ok {
@@ -140,6 +274,64 @@ func (_m *TodoRepository) List(ctx context.Context) ([]domain.Todo, error) {
 	return r0, r1
 }
 
+// PurgeTrash provides a mock function with given fields: ctx, before
+func (_m *TodoRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeTrash")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, search
+func (_m *TodoRepository) Search(ctx context.Context, search *domain.TodoSearch) ([]domain.SearchHit, error) {
+	ret := _m.Called(ctx, search)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 []domain.SearchHit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TodoSearch) ([]domain.SearchHit, error)); ok {
+		return rf(ctx, search)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TodoSearch) []domain.SearchHit); ok {
+		r0 = rf(ctx, search)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SearchHit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.TodoSearch) error); ok {
+		r1 = rf(ctx, search)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, todo
 func (_m *TodoRepository) Update(ctx context.Context, todo *domain.Todo) error {
 	ret := _m.Called(ctx, todo)
@@ -158,6 +350,54 @@ func (_m *TodoRepository) Update(ctx context.Context, todo *domain.Todo) error {
 	return r0
 }
 
+// UpdateBulk provides a mock function with given fields: ctx, groups, created, atomic
+func (_m *TodoRepository) UpdateBulk(ctx context.Context, groups [][]*domain.Todo, created [][]*domain.Todo, atomic bool) ([]error, error) {
+	ret := _m.Called(ctx, groups, created, atomic)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateBulk")
+	}
+
+	var r0 []error
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, [][]*domain.Todo, [][]*domain.Todo, bool) ([]error, error)); ok {
+		return rf(ctx, groups, created, atomic)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, [][]*domain.Todo, [][]*domain.Todo, bool) []error); ok {
+		r0 = rf(ctx, groups, created, atomic)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, [][]*domain.Todo, [][]*domain.Todo, bool) error); ok {
+		r1 = rf(ctx, groups, created, atomic)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateMany provides a mock function with given fields: ctx, todos, created
+func (_m *TodoRepository) UpdateMany(ctx context.Context, todos []*domain.Todo, created []*domain.Todo) error {
+	ret := _m.Called(ctx, todos, created)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateMany")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*domain.Todo, []*domain.Todo) error); ok {
+		r0 = rf(ctx, todos, created)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewTodoRepository creates a new instance of TodoRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewTodoRepository(t interface {
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// OutboxRelay delivers the events of the outbox to sinks. Each event is
+// delivered at least once to every sink: an event that any sink fails is
+// retried later with all of them.
+type OutboxRelay struct {
+	outbox OutboxRepository
+	sinks  []EventSink
+	logger *slog.Logger
+}
+
+func NewOutboxRelay(outbox OutboxRepository, sinks []EventSink, logger *slog.Logger) *OutboxRelay {
+	return &OutboxRelay{outbox: outbox, sinks: sinks, logger: logger}
+}
+
+// RelayBatch delivers up to limit events that are due for delivery. It
+// returns how many were delivered and how many failed and were scheduled
+// for a retry.
+func (r *OutboxRelay) RelayBatch(ctx context.Context, limit int) (delivered, failed int, err error) {
+	delivered, failed, err = r.outbox.Dispatch(ctx, limit, r.deliver)
+	if err != nil {
+		return 0, 0, fmt.Errorf("dispatch events: %w", err)
+	}
+	if delivered > 0 || failed > 0 {
+		r.logger.InfoContext(ctx, "outbox events relayed", slog.Int("delivered", delivered), slog.Int("failed", failed))
+	}
+	return delivered, failed, nil
+}
+
+// Run relays batches of up to limit events until ctx is done. Whenever no
+// event is left to deliver, or the outbox cannot be read, it waits for
+// interval before looking again.
+func (r *OutboxRelay) Run(ctx context.Context, limit int, interval time.Duration) error {
+	for {
+		delivered, failed, err := r.RelayBatch(ctx, limit)
+		if err != nil && ctx.Err() == nil {
+			r.logger.ErrorContext(ctx, "outbox relay failed", slog.String("error", err.Error()))
+		}
+		if err == nil && delivered+failed == limit {
+			// More events may be due.
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (r *OutboxRelay) deliver(ctx context.Context, event *domain.Event) error {
+	var errs []error
+	for _, sink := range r.sinks {
+		if err := sink.Deliver(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		r.logger.WarnContext(ctx, "event delivery failed",
+			slog.String("id", event.ID.String()), slog.String("type", string(event.Type)),
+			slog.Int("attempts", event.Attempts+1), slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// dispatching returns a Dispatch implementation that hands events to the
+// relay and records which of them it accepted.
+func dispatching(events []domain.Event, accepted *[]uuid.UUID) func(context.Context, int, func(context.Context, *domain.Event) error) (int, int, error) {
+	return func(ctx context.Context, limit int, deliver func(context.Context, *domain.Event) error) (int, int, error) {
+		var delivered, failed int
+		for i := range events[:min(limit, len(events))] {
+			if err := deliver(ctx, &events[i]); err != nil {
+				failed++
+				continue
+			}
+			delivered++
+			*accepted = append(*accepted, events[i].ID)
+		}
+		return delivered, failed, nil
+	}
+}
+
+func TestOutboxRelay_RelayBatch(t *testing.T) {
+	events := []domain.Event{
+		{ID: uuid.New(), Type: domain.EventTodoCreated},
+		{ID: uuid.New(), Type: domain.EventTodoCompleted},
+	}
+
+	t.Run("every sink receives every event", func(t *testing.T) {
+		outbox := mocks.NewOutboxRepository(t)
+		first, second := mocks.NewEventSink(t), mocks.NewEventSink(t)
+		var accepted []uuid.UUID
+		outbox.On("Dispatch", mock.Anything, 10, mock.Anything).Return(dispatching(events, &accepted))
+		first.On("Deliver", mock.Anything, mock.Anything).Return(nil).Twice()
+		second.On("Deliver", mock.Anything, mock.Anything).Return(nil).Twice()
+
+		relay := usecase.NewOutboxRelay(outbox, []usecase.EventSink{first, second}, slog.New(slog.DiscardHandler))
+		delivered, failed, err := relay.RelayBatch(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, delivered)
+		assert.Equal(t, 0, failed)
+		assert.Equal(t, []uuid.UUID{events[0].ID, events[1].ID}, accepted)
+	})
+
+	t.Run("an event any sink fails is retried", func(t *testing.T) {
+		outbox := mocks.NewOutboxRepository(t)
+		ok, flaky := mocks.NewEventSink(t), mocks.NewEventSink(t)
+		var accepted []uuid.UUID
+		outbox.On("Dispatch", mock.Anything, 10, mock.Anything).Return(dispatching(events, &accepted))
+		ok.On("Deliver", mock.Anything, mock.Anything).Return(nil)
+		flaky.On("Deliver", mock.Anything, &events[0]).Return(errors.New("connection refused"))
+		flaky.On("Deliver", mock.Anything, &events[1]).Return(nil)
+
+		relay := usecase.NewOutboxRelay(outbox, []usecase.EventSink{ok, flaky}, slog.New(slog.DiscardHandler))
+		delivered, failed, err := relay.RelayBatch(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, delivered)
+		assert.Equal(t, 1, failed)
+		assert.Equal(t, []uuid.UUID{events[1].ID}, accepted)
+	})
+
+	t.Run("outbox error", func(t *testing.T) {
+		outbox := mocks.NewOutboxRepository(t)
+		outbox.On("Dispatch", mock.Anything, 10, mock.Anything).Return(0, 0, errors.New("connection reset"))
+
+		relay := usecase.NewOutboxRelay(outbox, nil, slog.New(slog.DiscardHandler))
+		_, _, err := relay.RelayBatch(context.Background(), 10)
+		assert.Error(t, err)
+	})
+}
+
+func TestOutboxRelay_Run(t *testing.T) {
+	outbox := mocks.NewOutboxRepository(t)
+	sink := mocks.NewEventSink(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	events := []domain.Event{{ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}}
+	var accepted []uuid.UUID
+	// A full batch is followed by another one at once; an idle outbox
+	// stops the relay here.
+	outbox.On("Dispatch", mock.Anything, 2, mock.Anything).Return(dispatching(events[:2], &accepted)).Once()
+	outbox.On("Dispatch", mock.Anything, 2, mock.Anything).Return(dispatching(events[2:], &accepted)).Once()
+	outbox.On("Dispatch", mock.Anything, 2, mock.Anything).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(0, 0, nil).Once()
+	sink.On("Deliver", mock.Anything, mock.Anything).Return(nil)
+
+	relay := usecase.NewOutboxRelay(outbox, []usecase.EventSink{sink}, slog.New(slog.DiscardHandler))
+	done := make(chan error)
+	go func() { done <- relay.Run(ctx, 2, time.Millisecond) }()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("relay did not stop")
+	}
+	assert.Len(t, accepted, 3)
+}
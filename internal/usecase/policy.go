@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// RolePolicy authorizes the acting user by the role they hold in the
+// workspace. Services, such as API key clients and batch jobs, are not
+// restricted by roles; their API key scopes limit them instead. Any other
+// caller that acts as no user is refused.
+type RolePolicy struct {
+	users UserRepository
+}
+
+func NewRolePolicy(users UserRepository) *RolePolicy {
+	return &RolePolicy{users: users}
+}
+
+func (p *RolePolicy) Authorize(ctx context.Context, action domain.Action, todo *domain.Todo) error {
+	id, ok := actor.UserID(ctx)
+	if !ok {
+		if actor.IsService(ctx) {
+			return nil
+		}
+		return fmt.Errorf("%w: no acting user", domain.ErrForbidden)
+	}
+	user, err := p.users.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("%w: user %s is not a member of the workspace", domain.ErrForbidden, id)
+	}
+	if err != nil {
+		return fmt.Errorf("get acting user: %w", err)
+	}
+	return user.Authorize(action, todo)
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/tenant"
+)
+
+// PresenceTracker keeps track of who views the boards of each workspace,
+// over the connections of every API process. Each process announces the
+// connections it holds again every domain.PresenceInterval, so that those
+// of a process that died expire.
+type PresenceTracker struct {
+	repo   PresenceRepository
+	policy Policy
+	logger *slog.Logger
+
+	mu sync.Mutex
+	// local holds the presences of the connections of this process.
+	local map[uuid.UUID]domain.Presence
+	// viewers holds the presences of every process by workspace, then by
+	// connection. At is when the presence was last heard of.
+	viewers map[uuid.UUID]map[uuid.UUID]domain.Presence
+	// gone holds when the connections that left did so, for
+	// domain.PresenceTTL, so that announcements arriving late do not bring
+	// them back.
+	gone     map[uuid.UUID]time.Time
+	watchers map[uuid.UUID]map[chan struct{}]struct{}
+}
+
+func NewPresenceTracker(repo PresenceRepository, policy Policy, logger *slog.Logger) *PresenceTracker {
+	return &PresenceTracker{
+		repo: repo, policy: policy, logger: logger,
+		local:    make(map[uuid.UUID]domain.Presence),
+		viewers:  make(map[uuid.UUID]map[uuid.UUID]domain.Presence),
+		gone:     make(map[uuid.UUID]time.Time),
+		watchers: make(map[uuid.UUID]map[chan struct{}]struct{}),
+	}
+}
+
+// Run listens for the presences announced by every process, announces
+// those of this process again every domain.PresenceInterval and expires
+// the ones not announced again in time, until ctx is done.
+func (t *PresenceTracker) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	wg.Go(func() { t.refresh(ctx) })
+
+	for {
+		err := t.repo.Listen(ctx, t.heard)
+		if ctx.Err() != nil {
+			return nil
+		}
+		// Meanwhile, presences may be missed; those stay or go stale until
+		// they are announced again or expire.
+		t.logger.ErrorContext(ctx, "presence tracker stopped listening", slog.String("error", err.Error()))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(streamRetryDelay):
+		}
+	}
+}
+
+// Join announces that the caller in ctx views the board of its workspace
+// over connectionID, showing the todos of projectIDs. Joining again with
+// the same connection replaces its projects.
+func (t *PresenceTracker) Join(ctx context.Context, connectionID uuid.UUID, projectIDs []uuid.UUID) error {
+	if err := t.policy.Authorize(ctx, domain.ActionRead, nil); err != nil {
+		return err
+	}
+	workspace, ok := tenant.WorkspaceID(ctx)
+	if !ok {
+		return tenant.ErrNoWorkspace
+	}
+	if projectIDs == nil {
+		projectIDs = []uuid.UUID{}
+	}
+	p := domain.Presence{
+		ConnectionID: connectionID, WorkspaceID: workspace, UserID: actingUser(ctx),
+		ProjectIDs: projectIDs, At: time.Now().UTC(),
+	}
+
+	t.mu.Lock()
+	t.local[connectionID] = p
+	t.set(p)
+	t.mu.Unlock()
+
+	if err := t.repo.Announce(ctx, &p); err != nil {
+		return fmt.Errorf("announce presence: %w", err)
+	}
+	return nil
+}
+
+// Leave announces that connectionID has closed. Leaving a connection that
+// has not joined does nothing.
+func (t *PresenceTracker) Leave(ctx context.Context, connectionID uuid.UUID) error {
+	t.mu.Lock()
+	p, ok := t.local[connectionID]
+	if ok {
+		delete(t.local, connectionID)
+		p.Left, p.At = true, time.Now().UTC()
+		t.set(p)
+	}
+	t.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	if err := t.repo.Announce(ctx, &p); err != nil {
+		return fmt.Errorf("announce presence: %w", err)
+	}
+	return nil
+}
+
+// Viewers returns the presences on the board of the workspace in ctx,
+// ordered by connection.
+func (t *PresenceTracker) Viewers(ctx context.Context) ([]domain.Presence, error) {
+	workspace, ok := tenant.WorkspaceID(ctx)
+	if !ok {
+		return nil, tenant.ErrNoWorkspace
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	viewers := make([]domain.Presence, 0, len(t.viewers[workspace]))
+	for _, p := range t.viewers[workspace] {
+		viewers = append(viewers, p)
+	}
+	slices.SortFunc(viewers, func(a, b domain.Presence) int {
+		return bytes.Compare(a.ConnectionID[:], b.ConnectionID[:])
+	})
+	return viewers, nil
+}
+
+// Watch returns a channel that receives a value whenever the viewers of
+// the workspace in ctx change, until stop is called. Changes in quick
+// succession may be signalled once.
+func (t *PresenceTracker) Watch(ctx context.Context) (changes <-chan struct{}, stop func(), err error) {
+	workspace, ok := tenant.WorkspaceID(ctx)
+	if !ok {
+		return nil, nil, tenant.ErrNoWorkspace
+	}
+	ch := make(chan struct{}, 1)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.watchers[workspace] == nil {
+		t.watchers[workspace] = make(map[chan struct{}]struct{})
+	}
+	t.watchers[workspace][ch] = struct{}{}
+	return ch, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.watchers[workspace], ch)
+		if len(t.watchers[workspace]) == 0 {
+			delete(t.watchers, workspace)
+		}
+	}, nil
+}
+
+// heard records a presence announced by any process, this one included.
+func (t *PresenceTracker) heard(p *domain.Presence) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.gone[p.ConnectionID]; ok && !p.Left {
+		return
+	}
+	// Expiry goes by this process' clock rather than the announcer's.
+	q := *p
+	q.At = time.Now().UTC()
+	t.set(q)
+}
+
+// refresh announces the presences of this process again and expires
+// those that were not, every domain.PresenceInterval until ctx is done.
+func (t *PresenceTracker) refresh(ctx context.Context) {
+	ticker := time.NewTicker(domain.PresenceInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, p := range t.renew(now.UTC()) {
+				if err := t.repo.Announce(ctx, &p); err != nil && ctx.Err() == nil {
+					t.logger.ErrorContext(ctx, "failed to announce presence", slog.String("connection", p.ConnectionID.String()), slog.String("error", err.Error()))
+				}
+			}
+			t.expire(now)
+		}
+	}
+}
+
+// renew dates the presences of this process at now and returns them.
+func (t *PresenceTracker) renew(now time.Time) []domain.Presence {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	renewed := make([]domain.Presence, 0, len(t.local))
+	for id, p := range t.local {
+		p.At = now
+		t.local[id] = p
+		if q, ok := t.viewers[p.WorkspaceID][id]; ok {
+			q.At = now
+			t.viewers[p.WorkspaceID][id] = q
+		}
+		renewed = append(renewed, p)
+	}
+	return renewed
+}
+
+func (t *PresenceTracker) expire(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, at := range t.gone {
+		if now.Sub(at) > domain.PresenceTTL {
+			delete(t.gone, id)
+		}
+	}
+	for workspace, viewers := range t.viewers {
+		changed := false
+		for id, p := range viewers {
+			if _, ok := t.local[id]; !ok && p.Expired(now) {
+				delete(viewers, id)
+				changed = true
+			}
+		}
+		if len(viewers) == 0 {
+			delete(t.viewers, workspace)
+		}
+		if changed {
+			t.notify(workspace)
+		}
+	}
+}
+
+// set records p among the viewers and tells the watchers of its workspace
+// if that changed who views what. t.mu must be held.
+func (t *PresenceTracker) set(p domain.Presence) {
+	viewers := t.viewers[p.WorkspaceID]
+	old, known := viewers[p.ConnectionID]
+	if p.Left {
+		t.gone[p.ConnectionID] = p.At
+		if !known {
+			return
+		}
+		delete(viewers, p.ConnectionID)
+		if len(viewers) == 0 {
+			delete(t.viewers, p.WorkspaceID)
+		}
+		t.notify(p.WorkspaceID)
+		return
+	}
+
+	if viewers == nil {
+		viewers = make(map[uuid.UUID]domain.Presence)
+		t.viewers[p.WorkspaceID] = viewers
+	}
+	viewers[p.ConnectionID] = p
+	if !known || !slices.Equal(old.ProjectIDs, p.ProjectIDs) {
+		t.notify(p.WorkspaceID)
+	}
+}
+
+// notify signals the watchers of workspace. t.mu must be held.
+func (t *PresenceTracker) notify(workspace uuid.UUID) {
+	for ch := range t.watchers[workspace] {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// A change is already pending.
+		}
+	}
+}
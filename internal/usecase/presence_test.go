@@ -0,0 +1,140 @@
+package usecase_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/tenant"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// runPresenceTracker runs a presence tracker whose announcements are
+// heard back, as from the database, and returns a function announcing a
+// presence as another process would.
+func runPresenceTracker(t *testing.T) (*usecase.PresenceTracker, func(*domain.Presence)) {
+	t.Helper()
+	repo := mocks.NewPresenceRepository(t)
+	var mu sync.Mutex
+	var announced func(*domain.Presence)
+	listening := make(chan struct{})
+	repo.On("Listen", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(*domain.Presence)) error {
+		mu.Lock()
+		announced = fn
+		mu.Unlock()
+		close(listening)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	announce := func(p *domain.Presence) {
+		mu.Lock()
+		defer mu.Unlock()
+		announced(p)
+	}
+	repo.On("Announce", mock.Anything, mock.Anything).Return(func(_ context.Context, p *domain.Presence) error {
+		announce(p)
+		return nil
+	}).Maybe()
+
+	users := mocks.NewUserRepository(t)
+	users.On("GetByID", mock.Anything, mock.Anything).Return(&domain.User{Role: domain.RoleViewer}, nil).Maybe()
+	tracker := usecase.NewPresenceTracker(repo, usecase.NewRolePolicy(users), slog.New(slog.DiscardHandler))
+	ctx, cancel := context.WithCancel(serviceContext())
+	done := make(chan struct{})
+	go func() {
+		_ = tracker.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	<-listening
+	return tracker, announce
+}
+
+// changed reports whether changes received a signal.
+func changed(changes <-chan struct{}) bool {
+	select {
+	case <-changes:
+		return true
+	case <-time.After(50 * time.Millisecond):
+		return false
+	}
+}
+
+func TestPresenceTracker(t *testing.T) {
+	workspace, user, project := uuid.New(), uuid.New(), uuid.New()
+	ctx := actor.WithUser(tenant.WithWorkspace(serviceContext(), workspace), user)
+
+	t.Run("join, change projects and leave", func(t *testing.T) {
+		tracker, _ := runPresenceTracker(t)
+		changes, stop, err := tracker.Watch(ctx)
+		require.NoError(t, err)
+		defer stop()
+
+		conn := uuid.New()
+		require.NoError(t, tracker.Join(ctx, conn, nil))
+		assert.True(t, changed(changes))
+		viewers, err := tracker.Viewers(ctx)
+		require.NoError(t, err)
+		require.Len(t, viewers, 1)
+		assert.Equal(t, conn, viewers[0].ConnectionID)
+		assert.Equal(t, &user, viewers[0].UserID)
+		assert.Empty(t, viewers[0].ProjectIDs)
+
+		require.NoError(t, tracker.Join(ctx, conn, []uuid.UUID{project}))
+		assert.True(t, changed(changes))
+		require.NoError(t, tracker.Join(ctx, conn, []uuid.UUID{project}))
+		assert.False(t, changed(changes), "announcing the same projects again changes nothing")
+		viewers, err = tracker.Viewers(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{project}, viewers[0].ProjectIDs)
+
+		require.NoError(t, tracker.Leave(ctx, conn))
+		assert.True(t, changed(changes))
+		viewers, err = tracker.Viewers(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, viewers)
+	})
+
+	t.Run("hears other processes", func(t *testing.T) {
+		tracker, announce := runPresenceTracker(t)
+		changes, stop, err := tracker.Watch(ctx)
+		require.NoError(t, err)
+		defer stop()
+
+		remote := &domain.Presence{ConnectionID: uuid.New(), WorkspaceID: workspace, ProjectIDs: []uuid.UUID{}, At: time.Now()}
+		announce(remote)
+		announce(&domain.Presence{ConnectionID: uuid.New(), WorkspaceID: uuid.New(), At: time.Now()})
+		assert.True(t, changed(changes))
+		viewers, err := tracker.Viewers(ctx)
+		require.NoError(t, err)
+		require.Len(t, viewers, 1, "only the viewers of the workspace are listed")
+		assert.Equal(t, remote.ConnectionID, viewers[0].ConnectionID)
+
+		left := *remote
+		left.Left = true
+		announce(&left)
+		announce(remote)
+		viewers, err = tracker.Viewers(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, viewers, "announcements arriving after the leave are ignored")
+	})
+
+	t.Run("requires a workspace", func(t *testing.T) {
+		tracker, _ := runPresenceTracker(t)
+		assert.ErrorIs(t, tracker.Join(serviceContext(), uuid.New(), nil), tenant.ErrNoWorkspace)
+		_, err := tracker.Viewers(serviceContext())
+		assert.ErrorIs(t, err, tenant.ErrNoWorkspace)
+	})
+}
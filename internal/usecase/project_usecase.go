@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+type ProjectUseCase struct {
+	repo   ProjectRepository
+	logger *slog.Logger
+}
+
+func NewProjectUseCase(repo ProjectRepository, logger *slog.Logger) *ProjectUseCase {
+	return &ProjectUseCase{repo: repo, logger: logger}
+}
+
+func (uc *ProjectUseCase) CreateProject(ctx context.Context, name, description string) (*domain.Project, error) {
+	project, err := domain.NewProject(name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Create(ctx, project); err != nil {
+		return nil, fmt.Errorf("create project: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "project created", slog.String("id", project.ID.String()), slog.String("name", project.Name))
+	return project, nil
+}
+
+func (uc *ProjectUseCase) GetProject(ctx context.Context, id uuid.UUID) (*domain.Project, error) {
+	project, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get project: %w", err)
+	}
+	return project, nil
+}
+
+func (uc *ProjectUseCase) ListProjects(ctx context.Context) ([]domain.Project, error) {
+	projects, err := uc.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	return projects, nil
+}
+
+func (uc *ProjectUseCase) UpdateProject(ctx context.Context, id uuid.UUID, name, description string) (*domain.Project, error) {
+	project, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get project for update: %w", err)
+	}
+
+	if err := project.Update(name, description); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Update(ctx, project); err != nil {
+		return nil, fmt.Errorf("update project: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "project updated", slog.String("id", id.String()))
+	return project, nil
+}
+
+// DeleteProject deletes a project. Its todos are kept outside any project.
+func (uc *ProjectUseCase) DeleteProject(ctx context.Context, id uuid.UUID) error {
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("delete project: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "project deleted", slog.String("id", id.String()))
+	return nil
+}
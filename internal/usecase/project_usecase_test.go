@@ -0,0 +1,73 @@
+package usecase_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProjectUseCase(repo *mocks.ProjectRepository) *usecase.ProjectUseCase {
+	return usecase.NewProjectUseCase(repo, slog.New(slog.DiscardHandler))
+}
+
+func TestCreateProject(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := mocks.NewProjectRepository(t)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Project")).Return(nil)
+		uc := newTestProjectUseCase(repo)
+
+		project, err := uc.CreateProject(context.Background(), " Website ", "relaunch")
+		require.NoError(t, err)
+		assert.Equal(t, "Website", project.Name)
+		assert.Equal(t, "relaunch", project.Description)
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		repo := mocks.NewProjectRepository(t)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Project")).Return(domain.ErrAlreadyExists)
+		uc := newTestProjectUseCase(repo)
+
+		_, err := uc.CreateProject(context.Background(), "Website", "")
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		repo := mocks.NewProjectRepository(t)
+		uc := newTestProjectUseCase(repo)
+
+		_, err := uc.CreateProject(context.Background(), " ", "")
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestUpdateProject(t *testing.T) {
+	repo := mocks.NewProjectRepository(t)
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Project{ID: id, Name: "Website"}, nil)
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(p *domain.Project) bool {
+		return p.ID == id && p.Name == "Web shop" && p.Description == "new scope"
+	})).Return(nil)
+	uc := newTestProjectUseCase(repo)
+
+	project, err := uc.UpdateProject(context.Background(), id, "Web shop", "new scope")
+	require.NoError(t, err)
+	assert.Equal(t, "Web shop", project.Name)
+}
+
+func TestDeleteProject_NotFound(t *testing.T) {
+	repo := mocks.NewProjectRepository(t)
+	id := uuid.New()
+	repo.On("Delete", mock.Anything, id).Return(domain.ErrNotFound)
+	uc := newTestProjectUseCase(repo)
+
+	err := uc.DeleteProject(context.Background(), id)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
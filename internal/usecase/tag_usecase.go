@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+type TagUseCase struct {
+	repo   TagRepository
+	logger *slog.Logger
+}
+
+func NewTagUseCase(repo TagRepository, logger *slog.Logger) *TagUseCase {
+	return &TagUseCase{repo: repo, logger: logger}
+}
+
+func (uc *TagUseCase) CreateTag(ctx context.Context, name string) (*domain.Tag, error) {
+	tag, err := domain.NewTag(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Create(ctx, tag); err != nil {
+		return nil, fmt.Errorf("create tag: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "tag created", slog.String("id", tag.ID.String()), slog.String("name", tag.Name))
+	return tag, nil
+}
+
+func (uc *TagUseCase) GetTag(ctx context.Context, id uuid.UUID) (*domain.Tag, error) {
+	tag, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get tag: %w", err)
+	}
+	return tag, nil
+}
+
+func (uc *TagUseCase) ListTags(ctx context.Context) ([]domain.Tag, error) {
+	tags, err := uc.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	return tags, nil
+}
+
+// RenameTag renames a tag; every todo labelled with it follows the new name.
+func (uc *TagUseCase) RenameTag(ctx context.Context, id uuid.UUID, name string) (*domain.Tag, error) {
+	tag, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get tag for rename: %w", err)
+	}
+
+	if err := tag.Rename(name); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Update(ctx, tag); err != nil {
+		return nil, fmt.Errorf("rename tag: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "tag renamed", slog.String("id", id.String()), slog.String("name", tag.Name))
+	return tag, nil
+}
+
+// DeleteTag deletes a tag and removes it from every todo.
+func (uc *TagUseCase) DeleteTag(ctx context.Context, id uuid.UUID) error {
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("delete tag: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "tag deleted", slog.String("id", id.String()))
+	return nil
+}
@@ -0,0 +1,72 @@
+package usecase_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTagUseCase(repo *mocks.TagRepository) *usecase.TagUseCase {
+	return usecase.NewTagUseCase(repo, slog.New(slog.DiscardHandler))
+}
+
+func TestCreateTag(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := mocks.NewTagRepository(t)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Tag")).Return(nil)
+		uc := newTestTagUseCase(repo)
+
+		tag, err := uc.CreateTag(context.Background(), "Backend")
+		require.NoError(t, err)
+		assert.Equal(t, "backend", tag.Name)
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		repo := mocks.NewTagRepository(t)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Tag")).Return(domain.ErrAlreadyExists)
+		uc := newTestTagUseCase(repo)
+
+		_, err := uc.CreateTag(context.Background(), "backend")
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		repo := mocks.NewTagRepository(t)
+		uc := newTestTagUseCase(repo)
+
+		_, err := uc.CreateTag(context.Background(), " ")
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestRenameTag(t *testing.T) {
+	repo := mocks.NewTagRepository(t)
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.Tag{ID: id, Name: "backend"}, nil)
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(tag *domain.Tag) bool {
+		return tag.ID == id && tag.Name == "server"
+	})).Return(nil)
+	uc := newTestTagUseCase(repo)
+
+	tag, err := uc.RenameTag(context.Background(), id, "Server")
+	require.NoError(t, err)
+	assert.Equal(t, "server", tag.Name)
+}
+
+func TestDeleteTag_NotFound(t *testing.T) {
+	repo := mocks.NewTagRepository(t)
+	id := uuid.New()
+	repo.On("Delete", mock.Anything, id).Return(domain.ErrNotFound)
+	uc := newTestTagUseCase(repo)
+
+	err := uc.DeleteTag(context.Background(), id)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// AssigneeMe is the ListTodosParams.Assignee value that stands for the
+// acting user.
+const AssigneeMe = "me"
+
+// AssignTodo makes a user responsible for a todo, or unassigns it if
+// assigneeID is uuid.Nil. Non-empty expectedVersions make the change
+// conditional on the todo's current version being one of them.
+func (uc *TodoUseCase) AssignTodo(ctx context.Context, id, assigneeID uuid.UUID, expectedVersions []int64) (*domain.Todo, error) {
+	todo, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get todo for assign: %w", err)
+	}
+	if err := uc.policy.Authorize(ctx, domain.ActionEdit, todo); err != nil {
+		return nil, err
+	}
+	if err := todo.CheckVersion(expectedVersions); err != nil {
+		return nil, err
+	}
+
+	if assigneeID == uuid.Nil {
+		todo.Assign(nil)
+	} else {
+		if err := uc.checkAssignee(ctx, assigneeID); err != nil {
+			return nil, err
+		}
+		todo.Assign(&assigneeID)
+	}
+
+	if err := uc.update(ctx, todo); err != nil {
+		return nil, fmt.Errorf("assign todo: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "todo assigned", slog.String("id", id.String()), slog.String("assignee", assigneeID.String()))
+	return todo, nil
+}
+
+// checkAssignee verifies that a todo can be assigned to userID.
+func (uc *TodoUseCase) checkAssignee(ctx context.Context, userID uuid.UUID) error {
+	_, err := uc.users.GetByID(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.NewValidationError("assigneeId", "user not found")
+	}
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	return nil
+}
+
+// parseAssignee resolves the assignee of a list request: a user ID or
+// AssigneeMe. An empty value does not filter by assignee.
+func parseAssignee(ctx context.Context, assignee string) (*uuid.UUID, error) {
+	switch assignee {
+	case "":
+		return nil, nil
+	case AssigneeMe:
+		id, ok := actor.UserID(ctx)
+		if !ok {
+			return nil, actor.ErrNoUser
+		}
+		return &id, nil
+	}
+	id, err := uuid.Parse(assignee)
+	if err != nil {
+		return nil, domain.NewValidationError("assignee", `must be a user ID or "me"`)
+	}
+	return &id, nil
+}
+
+// actingUser returns the user ctx acts as, or nil for service clients and
+// batch jobs.
+func actingUser(ctx context.Context) *uuid.UUID {
+	if id, ok := actor.UserID(ctx); ok {
+		return &id
+	}
+	return nil
+}
+
+// update stores a changed todo on behalf of the acting user.
+func (uc *TodoUseCase) update(ctx context.Context, todo *domain.Todo) error {
+	todo.RecordEditor(actingUser(ctx))
+	return uc.repo.Update(ctx, todo)
+}
+
+// updateMany stores several changed todos atomically on behalf of the
+// acting user, creating the todos created in the same transaction.
+func (uc *TodoUseCase) updateMany(ctx context.Context, todos, created []*domain.Todo) error {
+	user := actingUser(ctx)
+	for _, t := range todos {
+		t.RecordEditor(user)
+	}
+	return uc.repo.UpdateMany(ctx, todos, created)
+}
+
+// authorizeAll checks that the policy allows action on every todo, such as
+// the subtasks an operation changes along with a todo.
+func (uc *TodoUseCase) authorizeAll(ctx context.Context, action domain.Action, todos []*domain.Todo) error {
+	for _, t := range todos {
+		if err := uc.policy.Authorize(ctx, action, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
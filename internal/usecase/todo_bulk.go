@@ -0,0 +1,352 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// BulkTodosParams describes an action applied to several todos at once.
+type BulkTodosParams struct {
+	Action string
+	IDs    []uuid.UUID
+	// Atomic applies the action to every todo or, if it fails for any, to
+	// none. Otherwise each todo succeeds or fails on its own.
+	Atomic bool
+	// Cascade makes complete and delete apply to the subtasks of the todos
+	// as well, as it does for a single todo.
+	Cascade bool
+	// AddTags and RemoveTags are the tags retag adds and removes.
+	AddTags    []string
+	RemoveTags []string
+	// ProjectID is the project move puts the todos in; nil moves them out
+	// of their project.
+	ProjectID *uuid.UUID
+}
+
+// BulkResult is the outcome of a bulk action for one todo: the todo after
+// the action, or the error that left it unchanged.
+type BulkResult struct {
+	ID   uuid.UUID
+	Todo *domain.Todo
+	Err  error
+}
+
+// BulkTodos applies an action to the todos params.IDs and returns the
+// outcome for each, in the same order. The todos are written together, in a
+// single transaction. Errors about the request as a whole, such as an
+// unknown action, are returned instead of results.
+//
+// complete and delete treat subtasks as CompleteTodo and DeleteTodo do. A
+// todo whose subtasks are among params.IDs is completed with them, as they
+// are no longer open once the action is applied.
+func (uc *TodoUseCase) BulkTodos(ctx context.Context, params BulkTodosParams) ([]BulkResult, error) {
+	action, err := domain.ParseBulkAction(params.Action)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.ValidateBulkIDs(params.IDs); err != nil {
+		return nil, err
+	}
+	apply, err := uc.bulkChange(ctx, action, params)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := uc.repo.ListByIDs(ctx, params.IDs)
+	if err != nil {
+		return nil, fmt.Errorf("get todos for bulk %s: %w", action, err)
+	}
+	permission := domain.ActionEdit
+	if action == domain.BulkDelete {
+		permission = domain.ActionDelete
+	}
+	b := &bulkPlan{
+		cascade:   params.Cascade,
+		nodes:     make(map[uuid.UUID]*domain.Todo, len(found)),
+		requested: make(map[uuid.UUID]int, len(params.IDs)),
+		applied:   make(map[uuid.UUID]int),
+		authorize: func(t *domain.Todo) error { return uc.policy.Authorize(ctx, permission, t) },
+	}
+	for i := range found {
+		b.nodes[found[i].ID] = &found[i]
+	}
+
+	results := make([]BulkResult, len(params.IDs))
+	b.results = results
+	changed := make([]bool, len(params.IDs))
+	for i, id := range params.IDs {
+		results[i].ID = id
+		b.requested[id] = i
+		todo, ok := b.nodes[id]
+		if !ok {
+			results[i].Err = domain.ErrNotFound
+			continue
+		}
+		if err := b.authorize(todo); err != nil {
+			if !errors.Is(err, domain.ErrForbidden) {
+				return nil, err
+			}
+			results[i].Err = err
+			continue
+		}
+		// The action is applied to a copy, the tree of subtasks keeping the
+		// todos as they are stored.
+		after := *todo
+		if changed[i], err = apply(&after); err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Todo = &after
+		b.applied[id] = i
+	}
+
+	groups, err := uc.bulkGroups(ctx, action, b, results)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		written [][]*domain.Todo
+		created [][]*domain.Todo
+		owners  []int
+	)
+	if !params.Atomic || !bulkFailed(results) {
+		user := actingUser(ctx)
+		for i, group := range groups {
+			if results[i].Err != nil || !changed[i] {
+				continue
+			}
+			for _, t := range group {
+				t.RecordEditor(user)
+			}
+			var next []*domain.Todo
+			if action == domain.BulkComplete {
+				if next, err = nextOccurrences(ctx, group); err != nil {
+					return nil, err
+				}
+			}
+			written = append(written, group)
+			created = append(created, next)
+			owners = append(owners, i)
+		}
+	}
+	if len(written) > 0 {
+		errs, err := uc.repo.UpdateBulk(ctx, written, created, params.Atomic)
+		if err != nil {
+			return nil, fmt.Errorf("bulk %s todos: %w", action, err)
+		}
+		for j, err := range errs {
+			if err != nil {
+				results[owners[j]] = BulkResult{ID: results[owners[j]].ID, Err: err}
+			}
+		}
+	}
+	if params.Atomic && bulkFailed(results) {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i] = BulkResult{ID: results[i].ID, Err: domain.ErrBulkAborted}
+			}
+		}
+	} else {
+		for j, next := range created {
+			if results[owners[j]].Err == nil {
+				uc.logOccurrences(ctx, next)
+			}
+		}
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	uc.logger.InfoContext(ctx, "bulk action applied",
+		slog.String("action", string(action)), slog.Int("succeeded", len(results)-failed), slog.Int("failed", failed),
+		slog.Bool("atomic", params.Atomic))
+	return results, nil
+}
+
+// bulkChange returns the function applying action to a todo, which reports
+// whether the todo changed.
+func (uc *TodoUseCase) bulkChange(ctx context.Context, action domain.BulkAction, params BulkTodosParams) (func(*domain.Todo) (bool, error), error) {
+	switch action {
+	case domain.BulkComplete:
+		return func(t *domain.Todo) (bool, error) { return true, t.MarkComplete() }, nil
+	case domain.BulkDelete:
+		return func(t *domain.Todo) (bool, error) {
+			t.MoveToTrash()
+			return true, nil
+		}, nil
+	case domain.BulkRetag:
+		change, err := domain.NewTagChange(params.AddTags, params.RemoveTags)
+		if err != nil {
+			return nil, err
+		}
+		return change.Apply, nil
+	default: // domain.BulkMove
+		if params.ProjectID != nil {
+			if err := uc.checkProject(ctx, *params.ProjectID); err != nil {
+				return nil, err
+			}
+		}
+		return func(t *domain.Todo) (bool, error) {
+			if t.ProjectID == nil && params.ProjectID == nil ||
+				t.ProjectID != nil && params.ProjectID != nil && *t.ProjectID == *params.ProjectID {
+				return false, nil
+			}
+			t.SetProject(params.ProjectID)
+			return true, nil
+		}, nil
+	}
+}
+
+// bulkGroups returns, for each result, the todos to write for it: the todo
+// after the action, followed by the subtasks the action changes. Results
+// the subtasks make fail get their error.
+func (uc *TodoUseCase) bulkGroups(ctx context.Context, action domain.BulkAction, b *bulkPlan, results []BulkResult) ([][]*domain.Todo, error) {
+	groups := make([][]*domain.Todo, len(results))
+	for i, r := range results {
+		if r.Err == nil {
+			groups[i] = []*domain.Todo{r.Todo}
+		}
+	}
+	if action != domain.BulkComplete && action != domain.BulkDelete || len(b.applied) == 0 {
+		return groups, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(b.nodes))
+	for _, r := range results {
+		if _, ok := b.nodes[r.ID]; ok {
+			ids = append(ids, r.ID)
+		}
+	}
+	descendants, err := uc.repo.ListDescendantsOfAll(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("list subtasks for bulk %s: %w", action, err)
+	}
+	for i := range descendants {
+		if _, ok := b.nodes[descendants[i].ID]; !ok {
+			b.nodes[descendants[i].ID] = &descendants[i]
+		}
+	}
+	b.children = make(map[uuid.UUID][]*domain.Todo)
+	for _, t := range b.nodes {
+		if t.ParentID != nil {
+			b.children[*t.ParentID] = append(b.children[*t.ParentID], t)
+		}
+	}
+
+	change := b.complete
+	if action == domain.BulkDelete {
+		change = b.trash
+	}
+	// Failing to apply the action to a todo leaves it as it is, which may
+	// keep the todos above it from changing in turn.
+	for blocked := true; blocked; {
+		blocked = false
+		for id, i := range b.applied {
+			group, err := change(id, groups[i][:1])
+			if err != nil {
+				if !errors.Is(err, domain.ErrForbidden) && !errors.Is(err, domain.ErrInvalidTransition) {
+					return nil, err
+				}
+				results[i] = BulkResult{ID: id, Err: err}
+				delete(b.applied, id)
+				blocked = true
+				continue
+			}
+			groups[i] = group
+		}
+	}
+	return groups, nil
+}
+
+// bulkPlan is the tree of todos a bulk action applies to and their
+// subtasks, as stored.
+type bulkPlan struct {
+	cascade  bool
+	nodes    map[uuid.UUID]*domain.Todo
+	children map[uuid.UUID][]*domain.Todo
+	// requested maps the todos the action was asked for to their result.
+	requested map[uuid.UUID]int
+	results   []BulkResult
+	// applied maps the todos the action applies to, so far, to their result.
+	applied map[uuid.UUID]int
+	// authorize checks that the acting user may apply the action to a todo,
+	// requested or changed along with one.
+	authorize func(*domain.Todo) error
+}
+
+// complete appends to group the subtasks of id completed with it. It fails
+// if an open subtask would be left, one that fails or that cascade does not
+// complete, or if a subtask to complete may not be edited.
+func (b *bulkPlan) complete(id uuid.UUID, group []*domain.Todo) ([]*domain.Todo, error) {
+	for _, child := range b.children[id] {
+		if _, ok := b.applied[child.ID]; ok {
+			continue
+		}
+		if child.Status.IsActive() {
+			if _, ok := b.requested[child.ID]; !b.cascade || ok {
+				return nil, fmt.Errorf("%w: todo has open subtasks", domain.ErrInvalidTransition)
+			}
+			if err := b.authorize(child); err != nil {
+				return nil, err
+			}
+			after := *child
+			if err := after.MarkComplete(); err != nil {
+				return nil, err
+			}
+			group = append(group, &after)
+		}
+		var err error
+		if group, err = b.complete(child.ID, group); err != nil {
+			return nil, err
+		}
+	}
+	return group, nil
+}
+
+// trash appends to group the subtasks of id that change when it moves to
+// the trash: with cascade, they follow it; otherwise its direct subtasks
+// become top-level todos. Subtasks the action applies to are left to their
+// own result. trash fails if a subtask to change may not be deleted, or,
+// with cascade, with the error of a requested subtask that failed.
+func (b *bulkPlan) trash(id uuid.UUID, group []*domain.Todo) ([]*domain.Todo, error) {
+	for _, child := range b.children[id] {
+		if _, ok := b.applied[child.ID]; ok {
+			continue
+		}
+		if i, ok := b.requested[child.ID]; ok && b.cascade {
+			return nil, b.results[i].Err
+		}
+		if err := b.authorize(child); err != nil {
+			return nil, err
+		}
+		after := *child
+		if !b.cascade {
+			_ = after.SetParent(nil)
+			group = append(group, &after)
+			continue
+		}
+		after.MoveToTrash()
+		var err error
+		if group, err = b.trash(child.ID, append(group, &after)); err != nil {
+			return nil, err
+		}
+	}
+	return group, nil
+}
+
+func bulkFailed(results []BulkResult) bool {
+	for _, r := range results {
+		if r.Err != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// ListChildren lists one page of the direct subtasks of a live todo.
+func (uc *TodoUseCase) ListChildren(ctx context.Context, id uuid.UUID, params ListTodosParams) (*TodoPage, error) {
+	if _, err := uc.repo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("get parent todo: %w", err)
+	}
+	params.ParentID = &id
+	params.Trashed = false
+	return uc.ListTodos(ctx, params)
+}
+
+// moveTodo makes an existing todo a subtask of parentID, or a top-level todo
+// if parentID is uuid.Nil.
+func (uc *TodoUseCase) moveTodo(ctx context.Context, todo *domain.Todo, parentID uuid.UUID) error {
+	if parentID == uuid.Nil {
+		if todo.ParentID == nil {
+			return nil
+		}
+		return todo.SetParent(nil)
+	}
+	if todo.ParentID != nil && *todo.ParentID == parentID {
+		return nil
+	}
+
+	descendants, err := uc.repo.ListDescendants(ctx, todo.ID)
+	if err != nil {
+		return fmt.Errorf("list subtasks: %w", err)
+	}
+	if _, err := uc.checkParent(ctx, todo.ID, parentID, subtreeHeight(todo.ID, descendants)); err != nil {
+		return err
+	}
+	return todo.SetParent(&parentID)
+}
+
+// checkParent verifies that the todo id, whose subtree is height levels
+// deep, can become a subtask of parentID without creating a cycle or
+// nesting deeper than domain.MaxTodoDepth. It returns the parent.
+func (uc *TodoUseCase) checkParent(ctx context.Context, id, parentID uuid.UUID, height int) (*domain.Todo, error) {
+	var parent *domain.Todo
+	depth := height
+	for ancestorID := &parentID; ancestorID != nil; {
+		if *ancestorID == id {
+			return nil, domain.NewValidationError("parentId", "a todo cannot become a subtask of its own subtask")
+		}
+		depth++
+		if depth > domain.MaxTodoDepth {
+			return nil, domain.NewValidationError("parentId",
+				fmt.Sprintf("subtasks must not be nested more than %d levels deep", domain.MaxTodoDepth))
+		}
+
+		ancestor, err := uc.repo.GetByID(ctx, *ancestorID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.NewValidationError("parentId", "parent todo not found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get parent todo: %w", err)
+		}
+		if parent == nil {
+			parent = ancestor
+		}
+		ancestorID = ancestor.ParentID
+	}
+	return parent, nil
+}
+
+// subtreeHeight counts the levels of the hierarchy rooted at root, including
+// root itself, given all of root's descendants.
+func subtreeHeight(root uuid.UUID, descendants []domain.Todo) int {
+	children := make(map[uuid.UUID][]uuid.UUID)
+	for _, d := range descendants {
+		children[*d.ParentID] = append(children[*d.ParentID], d.ID)
+	}
+
+	height := 0
+	for level := []uuid.UUID{root}; len(level) > 0; height++ {
+		var next []uuid.UUID
+		for _, id := range level {
+			next = append(next, children[id]...)
+		}
+		level = next
+	}
+	return height
+}
+
+// optionalID maps uuid.Nil to nil.
+func optionalID(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
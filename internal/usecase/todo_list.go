@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// ListTodosParams is a list request as received from a client. ListTodos
+// translates it into a domain.TodoFilter and a domain.PageRequest.
+type ListTodosParams struct {
+	// Statuses keeps todos in any of the named states; empty keeps all.
+	Statuses            []string
+	TitleContains       string
+	DescriptionContains string
+	CreatedAfter        *time.Time
+	CreatedBefore       *time.Time
+	UpdatedAfter        *time.Time
+	UpdatedBefore       *time.Time
+	DueAfter            *time.Time
+	DueBefore           *time.Time
+	Overdue             bool
+	// Tags keeps todos labelled with the named tags.
+	Tags []string
+	// TagMatch is "all" (the default) to require every tag or "any" to
+	// require at least one.
+	TagMatch string
+	// ParentID keeps the direct subtasks of the todo.
+	ParentID *uuid.UUID
+	// ProjectID keeps the todos of the project.
+	ProjectID *uuid.UUID
+	// Assignee keeps the todos assigned to a user, given by ID or as
+	// AssigneeMe for the acting user.
+	Assignee string
+	// Trashed lists the trash instead of the live todos.
+	Trashed bool
+
+	// Sort is a comma separated list of fields, "-" prefixed for descending order.
+	Sort string
+	// Cursor is the opaque Next value of the previous page.
+	Cursor string
+	// Limit is the page size; it defaults to domain.DefaultPageLimit and is
+	// capped at domain.MaxPageLimit.
+	Limit int
+}
+
+// TodoPage is one page of a todo listing.
+type TodoPage struct {
+	Todos []domain.Todo
+	// Next is the opaque cursor of the following page, or empty on the last page.
+	Next string
+}
+
+func (uc *TodoUseCase) ListTodos(ctx context.Context, params ListTodosParams) (*TodoPage, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionRead, nil); err != nil {
+		return nil, err
+	}
+	filter, err := listFilter(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := newPageRequest(params.Sort, params.Cursor, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	limit := page.Limit
+
+	// Fetch one extra row to learn whether another page follows.
+	page.Limit++
+	todos, err := uc.repo.List(ctx, filter, page)
+	if err != nil {
+		return nil, fmt.Errorf("list todos: %w", err)
+	}
+
+	result := &TodoPage{Todos: todos}
+	if len(todos) > limit {
+		result.Todos = todos[:limit]
+		result.Next = page.Sort.CursorAfter(&result.Todos[limit-1]).Encode()
+	}
+	return result, nil
+}
+
+// listFilter translates the filter of a list request.
+func listFilter(ctx context.Context, params ListTodosParams) (domain.TodoFilter, error) {
+	statuses, err := parseStatuses(params.Statuses)
+	if err != nil {
+		return domain.TodoFilter{}, err
+	}
+	assignee, err := parseAssignee(ctx, params.Assignee)
+	if err != nil {
+		return domain.TodoFilter{}, err
+	}
+	filter := domain.TodoFilter{
+		Statuses:            statuses,
+		TitleContains:       params.TitleContains,
+		DescriptionContains: params.DescriptionContains,
+		CreatedAfter:        params.CreatedAfter,
+		CreatedBefore:       params.CreatedBefore,
+		UpdatedAfter:        params.UpdatedAfter,
+		UpdatedBefore:       params.UpdatedBefore,
+		DueAfter:            params.DueAfter,
+		DueBefore:           params.DueBefore,
+		Overdue:             params.Overdue,
+		ParentID:            params.ParentID,
+		ProjectID:           params.ProjectID,
+		AssigneeID:          assignee,
+		Trashed:             params.Trashed,
+	}
+	if err := setTagFilter(&filter, params.Tags, params.TagMatch); err != nil {
+		return domain.TodoFilter{}, err
+	}
+	return filter, nil
+}
+
+func newPageRequest(sortSpec, cursor string, limit int) (domain.PageRequest, error) {
+	sort, err := domain.ParseTodoSort(sortSpec)
+	if err != nil {
+		return domain.PageRequest{}, err
+	}
+
+	page := domain.PageRequest{Sort: sort, Limit: limit}
+	if page.Limit <= 0 {
+		page.Limit = domain.DefaultPageLimit
+	}
+	if page.Limit > domain.MaxPageLimit {
+		page.Limit = domain.MaxPageLimit
+	}
+
+	if cursor != "" {
+		after, err := domain.ParseCursor(cursor)
+		if err != nil {
+			return domain.PageRequest{}, err
+		}
+		if after.Sort != sort.String() || len(after.Values) != len(sort) {
+			return domain.PageRequest{}, domain.NewValidationError("cursor", "cursor was issued for a different sort order")
+		}
+		for i, k := range sort {
+			if err := domain.CheckSortValue(k.Field, after.Values[i]); err != nil {
+				return domain.PageRequest{}, err
+			}
+		}
+		page.After = after
+	}
+	return page, nil
+}
+
+func parseStatuses(names []string) ([]domain.Status, error) {
+	var statuses []domain.Status
+	for _, name := range names {
+		st, err := domain.ParseStatus(name)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+// setTagFilter restricts filter to the named tags, matched according to match.
+func setTagFilter(filter *domain.TodoFilter, names []string, match string) error {
+	tagMatch := domain.TagMatch(match)
+	if tagMatch == "" {
+		tagMatch = domain.TagMatchAll
+	}
+	if tagMatch != domain.TagMatchAll && tagMatch != domain.TagMatchAny {
+		return domain.NewValidationError("tagMatch", fmt.Sprintf("must be %q or %q", domain.TagMatchAll, domain.TagMatchAny))
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	tags, err := domain.NormalizeTagNames(names)
+	if err != nil {
+		return err
+	}
+	filter.Tags, filter.TagMatch = tags, tagMatch
+	return nil
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// ListProjectTodos lists one page of the live todos in a project.
+func (uc *TodoUseCase) ListProjectTodos(ctx context.Context, projectID uuid.UUID, params ListTodosParams) (*TodoPage, error) {
+	if _, err := uc.projects.GetByID(ctx, projectID); err != nil {
+		return nil, fmt.Errorf("get project: %w", err)
+	}
+	params.ProjectID = &projectID
+	params.Trashed = false
+	return uc.ListTodos(ctx, params)
+}
+
+// moveToProject moves an existing todo to projectID, or out of its project
+// if projectID is uuid.Nil. Its subtasks stay where they are.
+func (uc *TodoUseCase) moveToProject(ctx context.Context, todo *domain.Todo, projectID uuid.UUID) error {
+	if projectID == uuid.Nil {
+		if todo.ProjectID != nil {
+			todo.SetProject(nil)
+		}
+		return nil
+	}
+	if todo.ProjectID != nil && *todo.ProjectID == projectID {
+		return nil
+	}
+	if err := uc.checkProject(ctx, projectID); err != nil {
+		return err
+	}
+	todo.SetProject(&projectID)
+	return nil
+}
+
+// checkProject verifies that a todo can be put in projectID.
+func (uc *TodoUseCase) checkProject(ctx context.Context, projectID uuid.UUID) error {
+	_, err := uc.projects.GetByID(ctx, projectID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.NewValidationError("projectId", "project not found")
+	}
+	if err != nil {
+		return fmt.Errorf("get project: %w", err)
+	}
+	return nil
+}
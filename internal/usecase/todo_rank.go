@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// MoveTodo ranks a todo right after the todo afterID and before the todo
+// beforeID, as when it is dragged between them on a board. A nil afterID
+// moves it to the start and a nil beforeID to the end. Only the moved
+// todo changes. Non-empty expectedVersions make the move conditional on
+// the todo's current version being one of them.
+func (uc *TodoUseCase) MoveTodo(ctx context.Context, id uuid.UUID, afterID, beforeID *uuid.UUID, expectedVersions []int64) (*domain.Todo, error) {
+	todo, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get todo for move: %w", err)
+	}
+	if err := uc.policy.Authorize(ctx, domain.ActionEdit, todo); err != nil {
+		return nil, err
+	}
+	if err := todo.CheckVersion(expectedVersions); err != nil {
+		return nil, err
+	}
+
+	after, err := uc.neighbour(ctx, "afterId", afterID)
+	if err != nil {
+		return nil, err
+	}
+	before, err := uc.neighbour(ctx, "beforeId", beforeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := todo.MoveBetween(after, before); err != nil {
+		return nil, err
+	}
+
+	if err := uc.update(ctx, todo); err != nil {
+		return nil, fmt.Errorf("move todo: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "todo moved", slog.String("id", id.String()), slog.String("rank", todo.Rank))
+	return todo, nil
+}
+
+// neighbour returns the todo a moved todo is put next to, or nil if id is
+// nil. field names the parameter giving id.
+func (uc *TodoUseCase) neighbour(ctx context.Context, field string, id *uuid.UUID) (*domain.Todo, error) {
+	if id == nil {
+		return nil, nil
+	}
+	t, err := uc.repo.GetByID(ctx, *id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.NewValidationError(field, "todo not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get todo %s: %w", field, err)
+	}
+	return t, nil
+}
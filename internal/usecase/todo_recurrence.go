@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// MaterializeRecurrences creates the occurrences of every recurring series
+// that are due within horizon from now, so that upcoming todos show up
+// before their predecessor is completed. Occurrences that already exist,
+// including trashed ones, are not created again, and occurrences whose
+// deadline has passed are skipped. It returns the number of todos created.
+func (uc *TodoUseCase) MaterializeRecurrences(ctx context.Context, horizon time.Duration) (int, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionMaterialize, nil); err != nil {
+		return 0, err
+	}
+	if horizon < 0 {
+		return 0, domain.NewValidationError("horizon", "must not be negative")
+	}
+
+	latest, err := uc.repo.ListRecurring(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list recurring todos: %w", err)
+	}
+
+	now := time.Now()
+	until := now.Add(horizon)
+	created := 0
+	for i := range latest {
+		next, err := nextOccurrence(&latest[i], now)
+		for ; err == nil && next != nil && !next.Due.At.After(until); next, err = next.NextOccurrence(time.Time{}) {
+			err = uc.repo.Create(ctx, next)
+			if errors.Is(err, domain.ErrAlreadyExists) {
+				err = nil
+				continue
+			}
+			if err == nil {
+				created++
+			}
+		}
+		if err != nil {
+			return created, fmt.Errorf("materialize series %s: %w", latest[i].SeriesID, err)
+		}
+	}
+
+	uc.logger.InfoContext(ctx, "recurring todos materialized",
+		slog.Int("count", created), slog.Duration("horizon", horizon))
+	return created, nil
+}
+
+// nextOccurrences returns the occurrences that follow the recurring todos
+// among todos, which are being completed, to be created on behalf of the
+// acting user in the same write.
+func nextOccurrences(ctx context.Context, todos []*domain.Todo) ([]*domain.Todo, error) {
+	user := actingUser(ctx)
+	var created []*domain.Todo
+	for _, t := range todos {
+		next, err := nextOccurrence(t, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("next occurrence of %s: %w", t.ID, err)
+		}
+		if next != nil {
+			next.RecordEditor(user)
+			created = append(created, next)
+		}
+	}
+	return created, nil
+}
+
+func (uc *TodoUseCase) logOccurrences(ctx context.Context, created []*domain.Todo) {
+	for _, next := range created {
+		uc.logger.InfoContext(ctx, "next occurrence created",
+			slog.String("id", next.ID.String()), slog.String("series", next.SeriesID.String()))
+	}
+}
+
+// nextOccurrence returns the first occurrence after todo whose deadline has
+// not passed by now, or nil if the series has ended.
+func nextOccurrence(todo *domain.Todo, now time.Time) (*domain.Todo, error) {
+	// Skip ahead to the last day; an all-day occurrence that started up to
+	// a day ago may still be open.
+	next, err := todo.NextOccurrence(now.AddDate(0, 0, -1))
+	for err == nil && next != nil && !next.Due.Deadline().After(now) {
+		next, err = next.NextOccurrence(time.Time{})
+	}
+	return next, err
+}
+
+// newRecurrence builds an optional recurrence. The time zone defaults to
+// that of the due date.
+func newRecurrence(rule, timeZone string, due *domain.Due) (*domain.Recurrence, error) {
+	if rule == "" {
+		return nil, nil
+	}
+	if timeZone == "" && due != nil {
+		timeZone = due.TimeZone
+	}
+	return domain.NewRecurrence(rule, timeZone)
+}
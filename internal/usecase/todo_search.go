@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// SearchTodosParams is a full-text search request as received from a
+// client.
+type SearchTodosParams struct {
+	// Query is the text to search the titles and descriptions for.
+	Query string
+	// Mode is "auto" (the default), "simple" or "ngram"; see domain.SearchMode.
+	Mode string
+	// Statuses keeps todos in any of the named states; empty keeps all.
+	Statuses []string
+	// Tags and TagMatch keep todos labelled with the named tags, as in
+	// ListTodosParams.
+	Tags     []string
+	TagMatch string
+	// ProjectID keeps the todos of the project.
+	ProjectID *uuid.UUID
+	// Limit is the maximum number of hits; it defaults to
+	// domain.DefaultPageLimit and is capped at domain.MaxPageLimit.
+	Limit int
+}
+
+// SearchTodos searches the live todos for params.Query and returns the
+// best ranked hits, with the matches highlighted.
+func (uc *TodoUseCase) SearchTodos(ctx context.Context, params SearchTodosParams) ([]domain.SearchHit, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionRead, nil); err != nil {
+		return nil, err
+	}
+	mode, err := domain.ParseSearchMode(params.Mode)
+	if err != nil {
+		return nil, err
+	}
+	search, err := domain.NewTodoSearch(params.Query, mode)
+	if err != nil {
+		return nil, err
+	}
+	search.Filter, err = listFilter(ctx, ListTodosParams{
+		Statuses: params.Statuses, Tags: params.Tags, TagMatch: params.TagMatch, ProjectID: params.ProjectID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	search.Limit = min(params.Limit, domain.MaxPageLimit)
+	if search.Limit <= 0 {
+		search.Limit = domain.DefaultPageLimit
+	}
+
+	hits, err := uc.repo.Search(ctx, search)
+	if err != nil {
+		return nil, fmt.Errorf("search todos: %w", err)
+	}
+	h := search.Highlighter()
+	for i := range hits {
+		hits[i].Title = h.Mark(hits[i].Todo.Title)
+		hits[i].Snippet = h.Snippet(hits[i].Todo.Description, domain.SearchSnippetLength)
+	}
+	return hits, nil
+}
@@ -2,27 +2,152 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/knjname/go-todo-api/internal/domain"
 )
 
+// TodoUseCase manages todos on behalf of the user in the context, if any:
+// it records that user as the creator and last editor of the todos it
+// writes, and asks its Policy before every operation whether the caller
+// may perform it.
 type TodoUseCase struct {
-	repo   TodoRepository
-	logger *slog.Logger
+	repo     TodoRepository
+	projects ProjectRepository
+	users    UserRepository
+	policy   Policy
+	logger   *slog.Logger
 }
 
-func NewTodoUseCase(repo TodoRepository, logger *slog.Logger) *TodoUseCase {
-	return &TodoUseCase{repo: repo, logger: logger}
+func NewTodoUseCase(repo TodoRepository, projects ProjectRepository, users UserRepository, policy Policy, logger *slog.Logger) *TodoUseCase {
+	return &TodoUseCase{repo: repo, projects: projects, users: users, policy: policy, logger: logger}
 }
 
-func (uc *TodoUseCase) CreateTodo(ctx context.Context, title, description string) (*domain.Todo, error) {
-	todo, err := domain.NewTodo(title, description)
+// CreateTodoParams holds the attributes of a new todo.
+type CreateTodoParams struct {
+	Title       string
+	Description string
+	// DueDate is "YYYY-MM-DD"; empty means no deadline.
+	DueDate string
+	// DueTime is an optional "HH:MM" wall clock time on DueDate.
+	DueTime string
+	// DueTimeZone is the IANA zone of DueDate and DueTime, UTC by default.
+	DueTimeZone string
+	// Priority is one of domain.PriorityNames; empty means none.
+	Priority string
+	// Tags names the todo's tags; unknown tags are created on first use.
+	Tags []string
+	// ParentID makes the todo a subtask of another todo; uuid.Nil creates a
+	// top-level todo.
+	ParentID uuid.UUID
+	// ProjectID puts the todo in a project. With uuid.Nil, a subtask joins
+	// its parent's project and a top-level todo stays outside any project.
+	ProjectID uuid.UUID
+	// RecurrenceRule is an RFC 5545 RRULE value; empty means the todo does
+	// not recur. A recurring todo needs a due date.
+	RecurrenceRule string
+	// RecurrenceTimeZone is the IANA zone the rule is evaluated in; it
+	// defaults to DueTimeZone.
+	RecurrenceTimeZone string
+}
+
+// UpdateTodoParams holds the full replacement attributes of a todo.
+type UpdateTodoParams struct {
+	Title       string
+	Description string
+	DueDate     string
+	DueTime     string
+	DueTimeZone string
+	Priority    string
+	Tags        []string
+	ParentID    uuid.UUID
+	// ProjectID moves the todo to another project; uuid.Nil takes it out of
+	// its project.
+	ProjectID uuid.UUID
+	// RecurrenceRule and RecurrenceTimeZone replace the recurrence; an
+	// empty rule stops the todo recurring.
+	RecurrenceRule     string
+	RecurrenceTimeZone string
+	// ExpectedVersions makes the update conditional on the todo's current
+	// version being one of them; empty updates unconditionally.
+	ExpectedVersions []int64
+}
+
+// TodoPatch lists the attributes of a todo to change. Nil fields are left
+// untouched.
+type TodoPatch struct {
+	Title       *string
+	Description *string
+	// DueDate replaces the due date; an empty string removes the deadline.
+	DueDate *string
+	// DueTime replaces the due time; an empty string makes the deadline all-day.
+	DueTime *string
+	// DueTimeZone replaces the deadline's time zone; an empty string resets it to UTC.
+	DueTimeZone *string
+	// Priority replaces the priority; an empty string resets it to none.
+	Priority *string
+	// Tags replaces the todo's tags; an empty slice removes them all.
+	Tags *[]string
+	// ParentID moves the todo under another todo; uuid.Nil makes it a
+	// top-level todo.
+	ParentID *uuid.UUID
+	// ProjectID moves the todo to another project; uuid.Nil takes it out of
+	// its project.
+	ProjectID *uuid.UUID
+	// RecurrenceRule replaces the recurrence rule; an empty string stops the
+	// todo recurring.
+	RecurrenceRule *string
+	// RecurrenceTimeZone replaces the zone the rule is evaluated in; an empty
+	// string resets it to the due date's zone.
+	RecurrenceTimeZone *string
+	// ExpectedVersions makes the patch conditional on the todo's current
+	// version being one of them; empty patches unconditionally.
+	ExpectedVersions []int64
+}
+
+func (uc *TodoUseCase) CreateTodo(ctx context.Context, params CreateTodoParams) (*domain.Todo, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionCreate, nil); err != nil {
+		return nil, err
+	}
+	due, err := newDue(params.DueDate, params.DueTime, params.DueTimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	priority, err := domain.ParsePriority(params.Priority)
+	if err != nil {
+		return nil, err
+	}
+	recurrence, err := newRecurrence(params.RecurrenceRule, params.RecurrenceTimeZone, due)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := domain.NewTodo(params.Title, params.Description,
+		domain.WithDue(due), domain.WithRecurrence(recurrence), domain.WithPriority(priority),
+		domain.WithTags(params.Tags), domain.WithParent(optionalID(params.ParentID)),
+		domain.WithProject(optionalID(params.ProjectID)), domain.WithCreator(actingUser(ctx)))
 	if err != nil {
 		return nil, err
 	}
+	if todo.ProjectID != nil {
+		if err := uc.checkProject(ctx, *todo.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+	if todo.ParentID != nil {
+		parent, err := uc.checkParent(ctx, todo.ID, *todo.ParentID, 1)
+		if err != nil {
+			return nil, err
+		}
+		if todo.ProjectID == nil {
+			todo.ProjectID = parent.ProjectID
+		}
+	}
 
 	if err := uc.repo.Create(ctx, todo); err != nil {
 		return nil, fmt.Errorf("create todo: %w", err)
@@ -33,6 +158,9 @@ func (uc *TodoUseCase) CreateTodo(ctx context.Context, title, description string
 }
 
 func (uc *TodoUseCase) GetTodo(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionRead, nil); err != nil {
+		return nil, err
+	}
 	todo, err := uc.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get todo: %w", err)
@@ -40,26 +168,51 @@ func (uc *TodoUseCase) GetTodo(ctx context.Context, id uuid.UUID) (*domain.Todo,
 	return todo, nil
 }
 
-func (uc *TodoUseCase) ListTodos(ctx context.Context) ([]domain.Todo, error) {
-	todos, err := uc.repo.List(ctx)
+func (uc *TodoUseCase) UpdateTodo(ctx context.Context, id uuid.UUID, params UpdateTodoParams) (*domain.Todo, error) {
+	due, err := newDue(params.DueDate, params.DueTime, params.DueTimeZone)
+	if err != nil {
+		return nil, err
+	}
+	priority, err := domain.ParsePriority(params.Priority)
 	if err != nil {
-		return nil, fmt.Errorf("list todos: %w", err)
+		return nil, err
+	}
+	recurrence, err := newRecurrence(params.RecurrenceRule, params.RecurrenceTimeZone, due)
+	if err != nil {
+		return nil, err
 	}
-	return todos, nil
-}
 
-func (uc *TodoUseCase) UpdateTodo(ctx context.Context, id uuid.UUID, title, description string) (*domain.Todo, error) {
 	todo, err := uc.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get todo for update: %w", err)
 	}
+	if err := uc.policy.Authorize(ctx, domain.ActionEdit, todo); err != nil {
+		return nil, err
+	}
+	if err := todo.CheckVersion(params.ExpectedVersions); err != nil {
+		return nil, err
+	}
 
-	if err := todo.UpdateTitle(title); err != nil {
+	if err := todo.UpdateTitle(params.Title); err != nil {
+		return nil, err
+	}
+	todo.UpdateDescription(params.Description)
+	todo.SetDue(due)
+	if err := todo.SetRecurrence(recurrence); err != nil {
+		return nil, err
+	}
+	todo.SetPriority(priority)
+	if err := todo.SetTags(params.Tags); err != nil {
+		return nil, err
+	}
+	if err := uc.moveTodo(ctx, todo, params.ParentID); err != nil {
+		return nil, err
+	}
+	if err := uc.moveToProject(ctx, todo, params.ProjectID); err != nil {
 		return nil, err
 	}
-	todo.UpdateDescription(description)
 
-	if err := uc.repo.Update(ctx, todo); err != nil {
+	if err := uc.update(ctx, todo); err != nil {
 		return nil, fmt.Errorf("update todo: %w", err)
 	}
 
@@ -67,41 +220,345 @@ func (uc *TodoUseCase) UpdateTodo(ctx context.Context, id uuid.UUID, title, desc
 	return todo, nil
 }
 
-func (uc *TodoUseCase) DeleteTodo(ctx context.Context, id uuid.UUID) error {
-	if _, err := uc.repo.GetByID(ctx, id); err != nil {
+// PatchTodo changes only the attributes set in patch, enforcing the same
+// invariants as a full update.
+func (uc *TodoUseCase) PatchTodo(ctx context.Context, id uuid.UUID, patch TodoPatch) (*domain.Todo, error) {
+	todo, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get todo for patch: %w", err)
+	}
+	if err := uc.policy.Authorize(ctx, domain.ActionEdit, todo); err != nil {
+		return nil, err
+	}
+	if err := todo.CheckVersion(patch.ExpectedVersions); err != nil {
+		return nil, err
+	}
+
+	if patch.Title != nil {
+		if err := todo.UpdateTitle(*patch.Title); err != nil {
+			return nil, err
+		}
+	}
+	if patch.Description != nil {
+		todo.UpdateDescription(*patch.Description)
+	}
+	if patch.DueDate != nil || patch.DueTime != nil || patch.DueTimeZone != nil {
+		due, err := patchDue(todo.Due, patch)
+		if err != nil {
+			return nil, err
+		}
+		todo.SetDue(due)
+	}
+	if patch.DueDate != nil || patch.RecurrenceRule != nil || patch.RecurrenceTimeZone != nil {
+		// Re-applied after a due date change, too, as a recurring todo
+		// must keep a due date.
+		recurrence, err := patchRecurrence(todo.Recurrence, todo.Due, patch)
+		if err != nil {
+			return nil, err
+		}
+		if err := todo.SetRecurrence(recurrence); err != nil {
+			return nil, err
+		}
+	}
+	if patch.Priority != nil {
+		priority, err := domain.ParsePriority(*patch.Priority)
+		if err != nil {
+			return nil, err
+		}
+		todo.SetPriority(priority)
+	}
+	if patch.Tags != nil {
+		if err := todo.SetTags(*patch.Tags); err != nil {
+			return nil, err
+		}
+	}
+	if patch.ParentID != nil {
+		if err := uc.moveTodo(ctx, todo, *patch.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	if patch.ProjectID != nil {
+		if err := uc.moveToProject(ctx, todo, *patch.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.update(ctx, todo); err != nil {
+		return nil, fmt.Errorf("patch todo: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "todo patched", slog.String("id", id.String()))
+	return todo, nil
+}
+
+// DeleteTodo moves a todo to the trash. Its subtasks follow it into the trash
+// if cascade is set; otherwise they stay and its direct subtasks become
+// top-level todos. The acting user must be allowed to delete every subtask
+// that changes. Non-empty expectedVersions make the deletion conditional
+// on the todo's current version being one of them.
+func (uc *TodoUseCase) DeleteTodo(ctx context.Context, id uuid.UUID, expectedVersions []int64, cascade bool) error {
+	todo, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
 		return fmt.Errorf("get todo for delete: %w", err)
 	}
+	if err := uc.policy.Authorize(ctx, domain.ActionDelete, todo); err != nil {
+		return err
+	}
+	if err := todo.CheckVersion(expectedVersions); err != nil {
+		return err
+	}
+	descendants, err := uc.repo.ListDescendants(ctx, id)
+	if err != nil {
+		return fmt.Errorf("list subtasks for delete: %w", err)
+	}
 
-	if err := uc.repo.Delete(ctx, id); err != nil {
+	todo.MoveToTrash()
+	todos := []*domain.Todo{todo}
+	for i := range descendants {
+		d := &descendants[i]
+		switch {
+		case cascade:
+			d.MoveToTrash()
+		case *d.ParentID == id:
+			if err := d.SetParent(nil); err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+		todos = append(todos, d)
+	}
+	if err := uc.authorizeAll(ctx, domain.ActionDelete, todos[1:]); err != nil {
+		return err
+	}
+
+	if err := uc.updateMany(ctx, todos, nil); err != nil {
 		return fmt.Errorf("delete todo: %w", err)
 	}
 
-	uc.logger.InfoContext(ctx, "todo deleted", slog.String("id", id.String()))
+	uc.logger.InfoContext(ctx, "todo moved to trash",
+		slog.String("id", id.String()), slog.Int("subtasks", len(todos)-1), slog.Bool("cascade", cascade))
 	return nil
 }
 
-func (uc *TodoUseCase) CompleteTodo(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
+// RestoreTodo takes a todo out of the trash. A subtask whose parent is no
+// longer live is restored as a top-level todo. Non-empty expectedVersions
+// make the restore conditional on the todo's current version being one of
+// them.
+func (uc *TodoUseCase) RestoreTodo(ctx context.Context, id uuid.UUID, expectedVersions []int64) (*domain.Todo, error) {
+	todo, err := uc.repo.GetTrashedByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get todo for restore: %w", err)
+	}
+	if err := uc.policy.Authorize(ctx, domain.ActionEdit, todo); err != nil {
+		return nil, err
+	}
+	if err := todo.CheckVersion(expectedVersions); err != nil {
+		return nil, err
+	}
+
+	todo.Restore()
+	if todo.ParentID != nil {
+		_, err := uc.repo.GetByID(ctx, *todo.ParentID)
+		if errors.Is(err, domain.ErrNotFound) {
+			err = todo.SetParent(nil)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get parent todo for restore: %w", err)
+		}
+	}
+
+	if err := uc.update(ctx, todo); err != nil {
+		return nil, fmt.Errorf("restore todo: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "todo restored", slog.String("id", id.String()))
+	return todo, nil
+}
+
+// PurgeTrash permanently removes the todos that have been in the trash for
+// longer than olderThan.
+func (uc *TodoUseCase) PurgeTrash(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionPurge, nil); err != nil {
+		return 0, err
+	}
+	if olderThan < 0 {
+		return 0, domain.NewValidationError("olderThan", "must not be negative")
+	}
+
+	count, err := uc.repo.PurgeTrash(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, fmt.Errorf("purge trash: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "trash purged", slog.Int64("count", count))
+	return count, nil
+}
+
+// CompleteTodo moves an open or in-progress todo to done. A todo with open
+// or in-progress subtasks can only be completed with cascade, which completes
+// the subtasks as well, provided the acting user may edit each of them.
+// Completing a recurring todo creates its next occurrence. Non-empty
+// expectedVersions make the change conditional on the todo's current
+// version being one of them.
+func (uc *TodoUseCase) CompleteTodo(ctx context.Context, id uuid.UUID, expectedVersions []int64, cascade bool) (*domain.Todo, error) {
 	todo, err := uc.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get todo for complete: %w", err)
 	}
+	if err := uc.policy.Authorize(ctx, domain.ActionEdit, todo); err != nil {
+		return nil, err
+	}
+	if err := todo.CheckVersion(expectedVersions); err != nil {
+		return nil, err
+	}
+	if err := todo.MarkComplete(); err != nil {
+		return nil, err
+	}
 
-	todo.MarkComplete()
+	descendants, err := uc.repo.ListDescendants(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list subtasks for complete: %w", err)
+	}
+	todos := []*domain.Todo{todo}
+	for i := range descendants {
+		d := &descendants[i]
+		if !d.Status.IsActive() {
+			continue
+		}
+		if !cascade {
+			return nil, fmt.Errorf("%w: todo has open subtasks", domain.ErrInvalidTransition)
+		}
+		if err := d.MarkComplete(); err != nil {
+			return nil, err
+		}
+		todos = append(todos, d)
+	}
+	if err := uc.authorizeAll(ctx, domain.ActionEdit, todos[1:]); err != nil {
+		return nil, err
+	}
 
-	if err := uc.repo.Update(ctx, todo); err != nil {
+	created, err := nextOccurrences(ctx, todos)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.updateMany(ctx, todos, created); err != nil {
 		return nil, fmt.Errorf("complete todo: %w", err)
 	}
+	uc.logOccurrences(ctx, created)
 
-	uc.logger.InfoContext(ctx, "todo completed", slog.String("id", id.String()))
+	uc.logger.InfoContext(ctx, "todo status changed",
+		slog.String("id", id.String()), slog.String("status", string(todo.Status)),
+		slog.Int("subtasks", len(todos)-1))
 	return todo, nil
 }
 
-func (uc *TodoUseCase) CompleteAllTodos(ctx context.Context) (int64, error) {
-	count, err := uc.repo.CompleteAll(ctx)
+// StartTodo moves an open todo to in progress.
+func (uc *TodoUseCase) StartTodo(ctx context.Context, id uuid.UUID, expectedVersions []int64) (*domain.Todo, error) {
+	return uc.transitionTodo(ctx, id, expectedVersions, "start", (*domain.Todo).Start)
+}
+
+// CancelTodo abandons an open or in-progress todo.
+func (uc *TodoUseCase) CancelTodo(ctx context.Context, id uuid.UUID, expectedVersions []int64) (*domain.Todo, error) {
+	return uc.transitionTodo(ctx, id, expectedVersions, "cancel", (*domain.Todo).Cancel)
+}
+
+// ReopenTodo moves a done or cancelled todo back to open.
+func (uc *TodoUseCase) ReopenTodo(ctx context.Context, id uuid.UUID, expectedVersions []int64) (*domain.Todo, error) {
+	return uc.transitionTodo(ctx, id, expectedVersions, "reopen", (*domain.Todo).Reopen)
+}
+
+// transitionTodo applies the status change transition to a todo and saves it.
+// Transitions the lifecycle forbids fail with domain.ErrInvalidTransition.
+func (uc *TodoUseCase) transitionTodo(ctx context.Context, id uuid.UUID, expectedVersions []int64, action string, transition func(*domain.Todo) error) (*domain.Todo, error) {
+	todo, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get todo for %s: %w", action, err)
+	}
+	if err := uc.policy.Authorize(ctx, domain.ActionEdit, todo); err != nil {
+		return nil, err
+	}
+	if err := todo.CheckVersion(expectedVersions); err != nil {
+		return nil, err
+	}
+
+	if err := transition(todo); err != nil {
+		return nil, err
+	}
+
+	if err := uc.update(ctx, todo); err != nil {
+		return nil, fmt.Errorf("%s todo: %w", action, err)
+	}
+
+	uc.logger.InfoContext(ctx, "todo status changed",
+		slog.String("id", id.String()), slog.String("status", string(todo.Status)))
+	return todo, nil
+}
+
+// CompleteAllTodos marks every open or in-progress todo of a project as
+// done, or those of every project if projectID is nil. Cancelled todos are
+// left alone. Being a bulk administrative operation, it is reserved for
+// admins and does not check who owns the todos.
+func (uc *TodoUseCase) CompleteAllTodos(ctx context.Context, projectID *uuid.UUID) (int64, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionCompleteAll, nil); err != nil {
+		return 0, err
+	}
+	if projectID != nil {
+		if _, err := uc.projects.GetByID(ctx, *projectID); err != nil {
+			return 0, fmt.Errorf("get project: %w", err)
+		}
+	}
+
+	count, err := uc.repo.CompleteAll(ctx, projectID, actingUser(ctx))
 	if err != nil {
 		return 0, fmt.Errorf("complete all todos: %w", err)
 	}
 
-	uc.logger.InfoContext(ctx, "all todos completed", slog.Int64("count", count))
+	uc.logger.InfoContext(ctx, "active todos completed", slog.Int64("count", count))
 	return count, nil
 }
+
+// newDue builds an optional deadline. A time or zone without a date is rejected.
+func newDue(date, clock, timeZone string) (*domain.Due, error) {
+	if date == "" && clock == "" && timeZone == "" {
+		return nil, nil
+	}
+	return domain.NewDue(date, clock, timeZone)
+}
+
+// patchRecurrence applies the recurrence fields of patch on top of the
+// current recurrence.
+func patchRecurrence(current *domain.Recurrence, due *domain.Due, patch TodoPatch) (*domain.Recurrence, error) {
+	var rule, timeZone string
+	if current != nil {
+		rule, timeZone = current.Rule, current.TimeZone
+	}
+	if patch.RecurrenceRule != nil {
+		rule = *patch.RecurrenceRule
+	}
+	if patch.RecurrenceTimeZone != nil {
+		timeZone = *patch.RecurrenceTimeZone
+	}
+	return newRecurrence(rule, timeZone, due)
+}
+
+// patchDue applies the due fields of patch on top of the current deadline.
+func patchDue(current *domain.Due, patch TodoPatch) (*domain.Due, error) {
+	var date, clock, timeZone string
+	if current != nil {
+		date, clock, timeZone = current.Date(), current.Clock(), current.TimeZone
+	}
+	if patch.DueDate != nil {
+		if *patch.DueDate == "" {
+			return nil, nil
+		}
+		date = *patch.DueDate
+	}
+	if patch.DueTime != nil {
+		clock = *patch.DueTime
+	}
+	if patch.DueTimeZone != nil {
+		timeZone = *patch.DueTimeZone
+	}
+	return domain.NewDue(date, clock, timeZone)
+}
@@ -2,10 +2,13 @@ package usecase_test
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
 	"github.com/knjname/go-todo-api/internal/domain"
 	"github.com/knjname/go-todo-api/internal/usecase"
 	"github.com/knjname/go-todo-api/internal/usecase/mocks"
@@ -14,9 +17,39 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// serviceContext returns a context acting for a service, which roles do
+// not restrict.
+func serviceContext() context.Context {
+	return actor.AsService(context.Background())
+}
+
 func newTestUseCase(repo *mocks.TodoRepository) *usecase.TodoUseCase {
+	return newTestUseCaseWithProjects(repo, &mocks.ProjectRepository{})
+}
+
+func newTestUseCaseWithProjects(repo *mocks.TodoRepository, projects *mocks.ProjectRepository) *usecase.TodoUseCase {
+	logger := slog.New(slog.DiscardHandler)
+	users := &mocks.UserRepository{}
+	return usecase.NewTodoUseCase(repo, projects, users, usecase.NewRolePolicy(users), logger)
+}
+
+// newTestUseCaseWithUsers authorizes the acting user by the role users
+// report for them.
+func newTestUseCaseWithUsers(repo *mocks.TodoRepository, users *mocks.UserRepository) *usecase.TodoUseCase {
+	logger := slog.New(slog.DiscardHandler)
+	return usecase.NewTodoUseCase(repo, &mocks.ProjectRepository{}, users, usecase.NewRolePolicy(users), logger)
+}
+
+func newTestUseCaseWithPolicy(repo *mocks.TodoRepository, policy *mocks.Policy) *usecase.TodoUseCase {
 	logger := slog.New(slog.DiscardHandler)
-	return usecase.NewTodoUseCase(repo, logger)
+	return usecase.NewTodoUseCase(repo, &mocks.ProjectRepository{}, &mocks.UserRepository{}, policy, logger)
+}
+
+// withMembers makes users know ids as members of the workspace.
+func withMembers(users *mocks.UserRepository, ids ...uuid.UUID) {
+	for _, id := range ids {
+		users.On("GetByID", mock.Anything, id).Return(&domain.User{ID: id, Role: domain.RoleMember}, nil).Maybe()
+	}
 }
 
 func TestCreateTodo(t *testing.T) {
@@ -25,22 +58,64 @@ func TestCreateTodo(t *testing.T) {
 		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
 		uc := newTestUseCase(repo)
 
-		todo, err := uc.CreateTodo(context.Background(), "Test", "Description")
+		todo, err := uc.CreateTodo(serviceContext(), usecase.CreateTodoParams{
+			Title: "Test", Description: "Description",
+		})
 		require.NoError(t, err)
 		assert.Equal(t, "Test", todo.Title)
 		assert.Equal(t, "Description", todo.Description)
-		assert.False(t, todo.Completed)
+		assert.Equal(t, domain.StatusOpen, todo.Status)
+		assert.Nil(t, todo.Due)
 		repo.AssertExpectations(t)
 	})
 
+	t.Run("with priority", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+		uc := newTestUseCase(repo)
+
+		todo, err := uc.CreateTodo(serviceContext(), usecase.CreateTodoParams{Title: "Test", Priority: "urgent"})
+		require.NoError(t, err)
+		assert.Equal(t, domain.PriorityUrgent, todo.Priority)
+
+		_, err = uc.CreateTodo(serviceContext(), usecase.CreateTodoParams{Title: "Test", Priority: "[P1]"})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("with due date", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+		uc := newTestUseCase(repo)
+
+		todo, err := uc.CreateTodo(serviceContext(), usecase.CreateTodoParams{
+			Title: "Test", DueDate: "2026-03-01", DueTime: "09:30", DueTimeZone: "Asia/Tokyo",
+		})
+		require.NoError(t, err)
+		require.NotNil(t, todo.Due)
+		assert.Equal(t, "2026-03-01", todo.Due.Date())
+		assert.Equal(t, "09:30", todo.Due.Clock())
+	})
+
 	t.Run("validation error", func(t *testing.T) {
 		repo := mocks.NewTodoRepository(t)
 		uc := newTestUseCase(repo)
 
-		_, err := uc.CreateTodo(context.Background(), "", "Description")
+		_, err := uc.CreateTodo(serviceContext(), usecase.CreateTodoParams{
+			Title: "", Description: "Description",
+		})
 		require.Error(t, err)
 		assert.ErrorIs(t, err, domain.ErrValidation)
 	})
+
+	t.Run("due time without date", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		uc := newTestUseCase(repo)
+
+		_, err := uc.CreateTodo(serviceContext(), usecase.CreateTodoParams{
+			Title: "Test", DueTime: "09:30",
+		})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
 }
 
 func TestGetTodo(t *testing.T) {
@@ -51,7 +126,7 @@ func TestGetTodo(t *testing.T) {
 		repo.On("GetByID", mock.Anything, id).Return(expected, nil)
 		uc := newTestUseCase(repo)
 
-		todo, err := uc.GetTodo(context.Background(), id)
+		todo, err := uc.GetTodo(serviceContext(), id)
 		require.NoError(t, err)
 		assert.Equal(t, expected, todo)
 	})
@@ -62,21 +137,156 @@ func TestGetTodo(t *testing.T) {
 		repo.On("GetByID", mock.Anything, id).Return(nil, domain.ErrNotFound)
 		uc := newTestUseCase(repo)
 
-		_, err := uc.GetTodo(context.Background(), id)
+		_, err := uc.GetTodo(serviceContext(), id)
 		require.Error(t, err)
 		assert.ErrorIs(t, err, domain.ErrNotFound)
 	})
 }
 
 func TestListTodos(t *testing.T) {
-	repo := mocks.NewTodoRepository(t)
-	expected := []domain.Todo{{Title: "A"}, {Title: "B"}}
-	repo.On("List", mock.Anything).Return(expected, nil)
-	uc := newTestUseCase(repo)
+	t.Run("single page", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		expected := []domain.Todo{{Title: "A"}, {Title: "B"}}
+		filter := domain.TodoFilter{
+			Statuses:      []domain.Status{domain.StatusOpen, domain.StatusInProgress},
+			TitleContains: "a", Overdue: true,
+		}
+		repo.On("List", mock.Anything, filter, domain.PageRequest{
+			Sort: domain.DefaultTodoSort, Limit: domain.DefaultPageLimit + 1,
+		}).Return(expected, nil)
+		uc := newTestUseCase(repo)
 
-	todos, err := uc.ListTodos(context.Background())
-	require.NoError(t, err)
-	assert.Len(t, todos, 2)
+		page, err := uc.ListTodos(serviceContext(), usecase.ListTodosParams{
+			Statuses: []string{"open", "in_progress"}, TitleContains: "a", Overdue: true,
+		})
+		require.NoError(t, err)
+		assert.Len(t, page.Todos, 2)
+		assert.Empty(t, page.Next)
+	})
+
+	t.Run("tag filter", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		filter := domain.TodoFilter{Tags: []string{"backend", "urgent"}, TagMatch: domain.TagMatchAll}
+		repo.On("List", mock.Anything, filter, mock.Anything).Return([]domain.Todo{}, nil)
+		uc := newTestUseCase(repo)
+
+		_, err := uc.ListTodos(serviceContext(), usecase.ListTodosParams{Tags: []string{"Urgent", "backend"}})
+		require.NoError(t, err)
+
+		_, err = uc.ListTodos(serviceContext(), usecase.ListTodosParams{Tags: []string{"a"}, TagMatch: "some"})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		uc := newTestUseCase(repo)
+
+		_, err := uc.ListTodos(serviceContext(), usecase.ListTodosParams{Statuses: []string{"paused"}})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("more pages", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		sort := domain.TodoSort{{Field: domain.SortByTitle}}
+		second := domain.Todo{ID: uuid.New(), Title: "B"}
+		repo.On("List", mock.Anything, domain.TodoFilter{}, domain.PageRequest{Sort: sort, Limit: 3}).
+			Return([]domain.Todo{{ID: uuid.New(), Title: "A"}, second, {ID: uuid.New(), Title: "C"}}, nil)
+		uc := newTestUseCase(repo)
+
+		page, err := uc.ListTodos(serviceContext(), usecase.ListTodosParams{Sort: "title", Limit: 2})
+		require.NoError(t, err)
+		assert.Len(t, page.Todos, 2)
+		require.NotEmpty(t, page.Next)
+
+		next, err := domain.ParseCursor(page.Next)
+		require.NoError(t, err)
+		assert.Equal(t, second.ID, next.ID)
+		assert.Equal(t, []string{"B"}, next.Values)
+
+		repo.On("List", mock.Anything, domain.TodoFilter{}, domain.PageRequest{Sort: sort, Limit: 3, After: next}).
+			Return([]domain.Todo{{ID: uuid.New(), Title: "C"}}, nil)
+		page, err = uc.ListTodos(serviceContext(), usecase.ListTodosParams{Sort: "title", Limit: 2, Cursor: page.Next})
+		require.NoError(t, err)
+		assert.Len(t, page.Todos, 1)
+		assert.Empty(t, page.Next)
+	})
+
+	t.Run("cursor from another sort order", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		uc := newTestUseCase(repo)
+		cursor := domain.DefaultTodoSort.CursorAfter(&domain.Todo{ID: uuid.New()}).Encode()
+
+		_, err := uc.ListTodos(serviceContext(), usecase.ListTodosParams{Sort: "title", Cursor: cursor})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("tampered cursor values", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		uc := newTestUseCase(repo)
+		cursor := &domain.Cursor{Sort: "-priority,due,-created_at", Values: []string{"high", "infinity", "now"}, ID: uuid.New()}
+
+		_, err := uc.ListTodos(serviceContext(), usecase.ListTodosParams{Cursor: cursor.Encode()})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("unknown sort field", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		uc := newTestUseCase(repo)
+
+		_, err := uc.ListTodos(serviceContext(), usecase.ListTodosParams{Sort: "-password"})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestSearchTodos(t *testing.T) {
+	t.Run("highlights hits", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		project := uuid.New()
+		repo.On("Search", mock.Anything, &domain.TodoSearch{
+			Query: "週次 会議", Mode: domain.SearchNgram, Terms: []string{"会議", "週次"},
+			Filter: domain.TodoFilter{Statuses: []domain.Status{domain.StatusOpen}, ProjectID: &project},
+			Limit:  domain.DefaultPageLimit,
+		}).Return([]domain.SearchHit{
+			{Todo: domain.Todo{Title: "週次会議", Description: "議題: <予算>"}, Rank: 0.8},
+			{Todo: domain.Todo{Title: "定例", Description: "週次の会議を行う"}, Rank: 0.3},
+		}, nil)
+		uc := newTestUseCase(repo)
+
+		hits, err := uc.SearchTodos(serviceContext(), usecase.SearchTodosParams{
+			Query: " 週次 会議 ", Statuses: []string{"open"}, ProjectID: &project,
+		})
+		require.NoError(t, err)
+		require.Len(t, hits, 2)
+		assert.Equal(t, "<mark>週次</mark><mark>会議</mark>", hits[0].Title)
+		assert.Empty(t, hits[0].Snippet, "the description does not match")
+		assert.Equal(t, "定例", hits[1].Title)
+		assert.Equal(t, "<mark>週次</mark>の<mark>会議</mark>を行う", hits[1].Snippet)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		repo.On("Search", mock.Anything, mock.MatchedBy(func(s *domain.TodoSearch) bool {
+			return s.Mode == domain.SearchSimple && s.Limit == domain.MaxPageLimit
+		})).Return(nil, nil)
+		uc := newTestUseCase(repo)
+
+		_, err := uc.SearchTodos(serviceContext(), usecase.SearchTodosParams{Query: "report", Mode: "simple", Limit: 1000})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		uc := newTestUseCase(repo)
+
+		for _, params := range []usecase.SearchTodosParams{
+			{Query: ""},
+			{Query: "report", Mode: "fuzzy"},
+			{Query: "report", Statuses: []string{"paused"}},
+		} {
+			_, err := uc.SearchTodos(serviceContext(), params)
+			assert.ErrorIs(t, err, domain.ErrValidation, "%+v", params)
+		}
+	})
 }
 
 func TestUpdateTodo(t *testing.T) {
@@ -88,10 +298,14 @@ func TestUpdateTodo(t *testing.T) {
 		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
 		uc := newTestUseCase(repo)
 
-		todo, err := uc.UpdateTodo(context.Background(), id, "New", "New desc")
+		todo, err := uc.UpdateTodo(serviceContext(), id, usecase.UpdateTodoParams{
+			Title: "New", Description: "New desc", DueDate: "2026-03-01",
+		})
 		require.NoError(t, err)
 		assert.Equal(t, "New", todo.Title)
 		assert.Equal(t, "New desc", todo.Description)
+		require.NotNil(t, todo.Due)
+		assert.True(t, todo.Due.AllDay)
 	})
 
 	t.Run("not found", func(t *testing.T) {
@@ -100,44 +314,1115 @@ func TestUpdateTodo(t *testing.T) {
 		repo.On("GetByID", mock.Anything, id).Return(nil, domain.ErrNotFound)
 		uc := newTestUseCase(repo)
 
-		_, err := uc.UpdateTodo(context.Background(), id, "New", "desc")
+		_, err := uc.UpdateTodo(serviceContext(), id, usecase.UpdateTodoParams{
+			Title: "New", Description: "desc",
+		})
 		require.Error(t, err)
 		assert.ErrorIs(t, err, domain.ErrNotFound)
 	})
+
+	t.Run("version mismatch", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		id := uuid.New()
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Old", Version: 3}, nil)
+		uc := newTestUseCase(repo)
+
+		_, err := uc.UpdateTodo(serviceContext(), id, usecase.UpdateTodoParams{
+			Title: "New", ExpectedVersions: []int64{2},
+		})
+		assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+	})
+}
+
+func TestPatchTodo(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+
+	t.Run("only supplied fields change", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		id := uuid.New()
+		existing := &domain.Todo{ID: id, Title: "Old", Description: "Old desc"}
+		repo.On("GetByID", mock.Anything, id).Return(existing, nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+		uc := newTestUseCase(repo)
+
+		todo, err := uc.PatchTodo(serviceContext(), id, usecase.TodoPatch{Title: ptr("New")})
+		require.NoError(t, err)
+		assert.Equal(t, "New", todo.Title)
+		assert.Equal(t, "Old desc", todo.Description)
+	})
+
+	t.Run("due fields merge with the current deadline", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		id := uuid.New()
+		due, err := domain.NewDue("2026-03-01", "", "Asia/Tokyo")
+		require.NoError(t, err)
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Task", Due: due}, nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+		uc := newTestUseCase(repo)
+
+		todo, err := uc.PatchTodo(serviceContext(), id, usecase.TodoPatch{DueTime: ptr("18:00")})
+		require.NoError(t, err)
+		require.NotNil(t, todo.Due)
+		assert.Equal(t, "2026-03-01", todo.Due.Date())
+		assert.Equal(t, "18:00", todo.Due.Clock())
+		assert.Equal(t, "Asia/Tokyo", todo.Due.TimeZone)
+	})
+
+	t.Run("empty due date removes the deadline", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		id := uuid.New()
+		due, err := domain.NewDue("2026-03-01", "", "")
+		require.NoError(t, err)
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Task", Due: due}, nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+		uc := newTestUseCase(repo)
+
+		todo, err := uc.PatchTodo(serviceContext(), id, usecase.TodoPatch{DueDate: ptr("")})
+		require.NoError(t, err)
+		assert.Nil(t, todo.Due)
+	})
+
+	t.Run("invalid title", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		id := uuid.New()
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Task"}, nil)
+		uc := newTestUseCase(repo)
+
+		_, err := uc.PatchTodo(serviceContext(), id, usecase.TodoPatch{Title: ptr("")})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("recurrence uses the due time zone", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		id := uuid.New()
+		due, err := domain.NewDue("2026-03-02", "09:00", "Asia/Tokyo")
+		require.NoError(t, err)
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Task", Due: due}, nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+		uc := newTestUseCase(repo)
+
+		todo, err := uc.PatchTodo(serviceContext(), id, usecase.TodoPatch{RecurrenceRule: ptr("FREQ=WEEKLY")})
+		require.NoError(t, err)
+		require.NotNil(t, todo.Recurrence)
+		assert.Equal(t, "Asia/Tokyo", todo.Recurrence.TimeZone)
+		assert.Equal(t, id, *todo.SeriesID)
+	})
+
+	t.Run("recurring todo keeps its due date", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		id := uuid.New()
+		due, err := domain.NewDue("2026-03-02", "", "")
+		require.NoError(t, err)
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{
+			ID: id, Title: "Task", Due: due, Recurrence: &domain.Recurrence{Rule: "FREQ=DAILY", TimeZone: "UTC"},
+		}, nil)
+		uc := newTestUseCase(repo)
+
+		_, err = uc.PatchTodo(serviceContext(), id, usecase.TodoPatch{DueDate: ptr("")})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestTodoHierarchy(t *testing.T) {
+	// chain returns todos linked top-down as parent and subtask, registered
+	// with repo.
+	chain := func(repo *mocks.TodoRepository, n int) []*domain.Todo {
+		todos := make([]*domain.Todo, n)
+		for i := range todos {
+			todos[i] = &domain.Todo{ID: uuid.New(), Title: fmt.Sprintf("Level %d", i+1)}
+			if i > 0 {
+				todos[i].ParentID = &todos[i-1].ID
+			}
+			repo.On("GetByID", mock.Anything, todos[i].ID).Return(todos[i], nil).Maybe()
+		}
+		return todos
+	}
+
+	t.Run("subtask within the depth limit", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		todos := chain(repo, domain.MaxTodoDepth-1)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+		todo, err := newTestUseCase(repo).CreateTodo(serviceContext(), usecase.CreateTodoParams{
+			Title: "Leaf", ParentID: todos[len(todos)-1].ID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, todos[len(todos)-1].ID, *todo.ParentID)
+	})
+
+	t.Run("subtask beyond the depth limit", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		todos := chain(repo, domain.MaxTodoDepth)
+
+		_, err := newTestUseCase(repo).CreateTodo(serviceContext(), usecase.CreateTodoParams{
+			Title: "Too deep", ParentID: todos[len(todos)-1].ID,
+		})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("missing parent", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		parentID := uuid.New()
+		repo.On("GetByID", mock.Anything, parentID).Return(nil, domain.ErrNotFound)
+
+		_, err := newTestUseCase(repo).CreateTodo(serviceContext(), usecase.CreateTodoParams{
+			Title: "Orphan", ParentID: parentID,
+		})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("moving a todo under its own subtask", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		todos := chain(repo, 3)
+		repo.On("ListDescendants", mock.Anything, todos[0].ID).
+			Return([]domain.Todo{*todos[1], *todos[2]}, nil)
+
+		_, err := newTestUseCase(repo).PatchTodo(serviceContext(), todos[0].ID, usecase.TodoPatch{
+			ParentID: &todos[2].ID,
+		})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("moving a subtree counts its height", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		target := chain(repo, domain.MaxTodoDepth-1)
+		moved := chain(repo, 2)
+		repo.On("ListDescendants", mock.Anything, moved[0].ID).Return([]domain.Todo{*moved[1]}, nil)
+
+		_, err := newTestUseCase(repo).PatchTodo(serviceContext(), moved[0].ID, usecase.TodoPatch{
+			ParentID: &target[len(target)-1].ID,
+		})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("nil parent makes a top-level todo", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		todos := chain(repo, 2)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+		todo, err := newTestUseCase(repo).PatchTodo(serviceContext(), todos[1].ID, usecase.TodoPatch{
+			ParentID: &uuid.Nil,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, todo.ParentID)
+	})
 }
 
 func TestDeleteTodo(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		repo := mocks.NewTodoRepository(t)
 		id := uuid.New()
-		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id}, nil)
-		repo.On("Delete", mock.Anything, id).Return(nil)
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Version: 2}, nil)
+		repo.On("ListDescendants", mock.Anything, id).Return(nil, nil)
+		repo.On("UpdateMany", mock.Anything, mock.MatchedBy(func(todos []*domain.Todo) bool {
+			return len(todos) == 1 && todos[0].ID == id && todos[0].IsTrashed()
+		}), mock.Anything).Return(nil)
 		uc := newTestUseCase(repo)
 
-		err := uc.DeleteTodo(context.Background(), id)
+		err := uc.DeleteTodo(serviceContext(), id, nil, false)
 		require.NoError(t, err)
 	})
+
+	t.Run("version mismatch", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		id := uuid.New()
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Version: 2}, nil)
+		uc := newTestUseCase(repo)
+
+		err := uc.DeleteTodo(serviceContext(), id, []int64{1}, false)
+		assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+	})
+}
+
+func TestDeleteTodo_Subtasks(t *testing.T) {
+	id := uuid.New()
+	childID, grandchildID := uuid.New(), uuid.New()
+	setup := func(t *testing.T) *mocks.TodoRepository {
+		repo := mocks.NewTodoRepository(t)
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Parent"}, nil)
+		repo.On("ListDescendants", mock.Anything, id).Return([]domain.Todo{
+			{ID: childID, Title: "Child", ParentID: &id},
+			{ID: grandchildID, Title: "Grandchild", ParentID: &childID},
+		}, nil)
+		return repo
+	}
+
+	t.Run("direct subtasks become top-level todos", func(t *testing.T) {
+		repo := setup(t)
+		repo.On("UpdateMany", mock.Anything, mock.MatchedBy(func(todos []*domain.Todo) bool {
+			return len(todos) == 2 && todos[1].ID == childID && todos[1].ParentID == nil && !todos[1].IsTrashed()
+		}), mock.Anything).Return(nil)
+
+		require.NoError(t, newTestUseCase(repo).DeleteTodo(serviceContext(), id, nil, false))
+	})
+
+	t.Run("cascade trashes the whole subtree", func(t *testing.T) {
+		repo := setup(t)
+		repo.On("UpdateMany", mock.Anything, mock.MatchedBy(func(todos []*domain.Todo) bool {
+			return len(todos) == 3 && todos[1].IsTrashed() && todos[2].IsTrashed() && todos[2].ParentID != nil
+		}), mock.Anything).Return(nil)
+
+		require.NoError(t, newTestUseCase(repo).DeleteTodo(serviceContext(), id, nil, true))
+	})
+}
+
+func TestRestoreTodo(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		id := uuid.New()
+		deletedAt := time.Now().Add(-time.Hour)
+		repo.On("GetTrashedByID", mock.Anything, id).Return(&domain.Todo{ID: id, Version: 3, DeletedAt: &deletedAt}, nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+		uc := newTestUseCase(repo)
+
+		todo, err := uc.RestoreTodo(serviceContext(), id, []int64{3})
+		require.NoError(t, err)
+		assert.False(t, todo.IsTrashed())
+	})
+
+	t.Run("subtask of a trashed parent becomes top-level", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		id, parentID := uuid.New(), uuid.New()
+		deletedAt := time.Now()
+		repo.On("GetTrashedByID", mock.Anything, id).
+			Return(&domain.Todo{ID: id, ParentID: &parentID, DeletedAt: &deletedAt}, nil)
+		repo.On("GetByID", mock.Anything, parentID).Return(nil, domain.ErrNotFound)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+		uc := newTestUseCase(repo)
+
+		todo, err := uc.RestoreTodo(serviceContext(), id, nil)
+		require.NoError(t, err)
+		assert.Nil(t, todo.ParentID)
+	})
+
+	t.Run("not in trash", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		id := uuid.New()
+		repo.On("GetTrashedByID", mock.Anything, id).Return(nil, domain.ErrNotFound)
+		uc := newTestUseCase(repo)
+
+		_, err := uc.RestoreTodo(serviceContext(), id, nil)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestPurgeTrash(t *testing.T) {
+	repo := mocks.NewTodoRepository(t)
+	cutoff := time.Now().Add(-30 * 24 * time.Hour)
+	repo.On("PurgeTrash", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return before.Sub(cutoff).Abs() < time.Minute
+	})).Return(int64(4), nil)
+	uc := newTestUseCase(repo)
+
+	count, err := uc.PurgeTrash(serviceContext(), 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+
+	_, err = uc.PurgeTrash(serviceContext(), -time.Hour)
+	assert.ErrorIs(t, err, domain.ErrValidation)
 }
 
 func TestCompleteTodo(t *testing.T) {
 	repo := mocks.NewTodoRepository(t)
 	id := uuid.New()
-	existing := &domain.Todo{ID: id, Title: "Task", Completed: false}
+	existing := &domain.Todo{ID: id, Title: "Task", Status: domain.StatusInProgress}
+	repo.On("GetByID", mock.Anything, id).Return(existing, nil)
+	repo.On("ListDescendants", mock.Anything, id).Return(nil, nil)
+	repo.On("UpdateMany", mock.Anything, mock.AnythingOfType("[]*domain.Todo"), mock.Anything).Return(nil)
+	uc := newTestUseCase(repo)
+
+	todo, err := uc.CompleteTodo(serviceContext(), id, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusDone, todo.Status)
+}
+
+func TestCompleteTodo_Subtasks(t *testing.T) {
+	id := uuid.New()
+	setup := func(t *testing.T) *mocks.TodoRepository {
+		repo := mocks.NewTodoRepository(t)
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Parent", Status: domain.StatusOpen}, nil)
+		repo.On("ListDescendants", mock.Anything, id).Return([]domain.Todo{
+			{ID: uuid.New(), Title: "Open", Status: domain.StatusInProgress, ParentID: &id},
+			{ID: uuid.New(), Title: "Cancelled", Status: domain.StatusCancelled, ParentID: &id},
+		}, nil)
+		return repo
+	}
+
+	t.Run("open subtasks block completion", func(t *testing.T) {
+		_, err := newTestUseCase(setup(t)).CompleteTodo(serviceContext(), id, nil, false)
+		assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+	})
+
+	t.Run("cascade completes the active subtasks", func(t *testing.T) {
+		repo := setup(t)
+		repo.On("UpdateMany", mock.Anything, mock.MatchedBy(func(todos []*domain.Todo) bool {
+			return len(todos) == 2 && todos[1].Title == "Open" && todos[1].Status == domain.StatusDone
+		}), mock.Anything).Return(nil)
+
+		todo, err := newTestUseCase(repo).CompleteTodo(serviceContext(), id, nil, true)
+		require.NoError(t, err)
+		assert.Equal(t, domain.StatusDone, todo.Status)
+	})
+}
+
+func TestCompleteTodo_Recurring(t *testing.T) {
+	rule, err := domain.NewRecurrence("FREQ=DAILY", "")
+	require.NoError(t, err)
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(domain.DueDateLayout)
+	due, err := domain.NewDue(tomorrow, "", "")
+	require.NoError(t, err)
+
+	t.Run("next occurrence is created with the completion", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		existing, err := domain.NewTodo("Standup", "", domain.WithDue(due), domain.WithRecurrence(rule))
+		require.NoError(t, err)
+		repo.On("GetByID", mock.Anything, existing.ID).Return(existing, nil)
+		repo.On("ListDescendants", mock.Anything, existing.ID).Return(nil, nil)
+		repo.On("UpdateMany", mock.Anything, []*domain.Todo{existing}, mock.MatchedBy(func(created []*domain.Todo) bool {
+			return len(created) == 1 && created[0].ID != existing.ID && *created[0].SeriesID == existing.ID &&
+				created[0].Due.Date() == due.At.AddDate(0, 0, 1).Format(domain.DueDateLayout)
+		})).Return(nil)
+
+		todo, err := newTestUseCase(repo).CompleteTodo(serviceContext(), existing.ID, nil, false)
+		require.NoError(t, err)
+		assert.Equal(t, domain.StatusDone, todo.Status)
+	})
+
+	t.Run("failed completion creates no occurrence", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		existing, err := domain.NewTodo("Standup", "", domain.WithDue(due), domain.WithRecurrence(rule))
+		require.NoError(t, err)
+		repo.On("GetByID", mock.Anything, existing.ID).Return(existing, nil)
+		repo.On("ListDescendants", mock.Anything, existing.ID).Return(nil, nil)
+		repo.On("UpdateMany", mock.Anything, mock.Anything, mock.Anything).Return(domain.ErrConflict)
+
+		_, err = newTestUseCase(repo).CompleteTodo(serviceContext(), existing.ID, nil, false)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestMaterializeRecurrences(t *testing.T) {
+	rule, err := domain.NewRecurrence("FREQ=DAILY", "")
+	require.NoError(t, err)
+	today := time.Now().UTC().Format(domain.DueDateLayout)
+	due, err := domain.NewDue(today, "", "")
+	require.NoError(t, err)
+	latest, err := domain.NewTodo("Standup", "", domain.WithDue(due), domain.WithRecurrence(rule))
+	require.NoError(t, err)
+
+	repo := mocks.NewTodoRepository(t)
+	repo.On("ListRecurring", mock.Anything).Return([]domain.Todo{*latest}, nil)
+	var dates []string
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Run(func(args mock.Arguments) {
+		dates = append(dates, args.Get(1).(*domain.Todo).Due.Date())
+	}).Return(domain.ErrAlreadyExists).Once()
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Run(func(args mock.Arguments) {
+		dates = append(dates, args.Get(1).(*domain.Todo).Due.Date())
+	}).Return(nil)
+	uc := newTestUseCase(repo)
+
+	count, err := uc.MaterializeRecurrences(serviceContext(), 3*24*time.Hour)
+	require.NoError(t, err)
+	// Tomorrow exists already; the two days after are created.
+	assert.Equal(t, 2, count)
+	require.Len(t, dates, 3)
+	assert.Equal(t, due.At.AddDate(0, 0, 1).Format(domain.DueDateLayout), dates[0])
+
+	_, err = uc.MaterializeRecurrences(serviceContext(), -time.Hour)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	member := uuid.New()
+	users := mocks.NewUserRepository(t)
+	withMembers(users, member)
+	_, err = newTestUseCaseWithUsers(repo, users).MaterializeRecurrences(actor.WithUser(context.Background(), member), time.Hour)
+	assert.ErrorIs(t, err, domain.ErrForbidden, "only admins create occurrences across the workspace")
+}
+
+func TestStartTodo(t *testing.T) {
+	repo := mocks.NewTodoRepository(t)
+	id := uuid.New()
+	existing := &domain.Todo{ID: id, Title: "Task", Status: domain.StatusOpen}
 	repo.On("GetByID", mock.Anything, id).Return(existing, nil)
 	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
 	uc := newTestUseCase(repo)
 
-	todo, err := uc.CompleteTodo(context.Background(), id)
+	todo, err := uc.StartTodo(serviceContext(), id, nil)
 	require.NoError(t, err)
-	assert.True(t, todo.Completed)
+	assert.Equal(t, domain.StatusInProgress, todo.Status)
+}
+
+func TestReopenTodo(t *testing.T) {
+	t.Run("done todo is reopened", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		id := uuid.New()
+		existing := &domain.Todo{ID: id, Title: "Task", Status: domain.StatusDone}
+		repo.On("GetByID", mock.Anything, id).Return(existing, nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+		uc := newTestUseCase(repo)
+
+		todo, err := uc.ReopenTodo(serviceContext(), id, nil)
+		require.NoError(t, err)
+		assert.Equal(t, domain.StatusOpen, todo.Status)
+	})
+
+	t.Run("open todo cannot be reopened", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		id := uuid.New()
+		existing := &domain.Todo{ID: id, Title: "Task", Status: domain.StatusOpen}
+		repo.On("GetByID", mock.Anything, id).Return(existing, nil)
+		uc := newTestUseCase(repo)
+
+		_, err := uc.ReopenTodo(serviceContext(), id, nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+	})
+}
+
+func TestCancelTodo(t *testing.T) {
+	repo := mocks.NewTodoRepository(t)
+	id := uuid.New()
+	existing := &domain.Todo{ID: id, Title: "Task", Status: domain.StatusInProgress}
+	repo.On("GetByID", mock.Anything, id).Return(existing, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+	uc := newTestUseCase(repo)
+
+	todo, err := uc.CancelTodo(serviceContext(), id, nil)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusCancelled, todo.Status)
+}
+
+func TestCompleteTodo_Conflict(t *testing.T) {
+	repo := mocks.NewTodoRepository(t)
+	id := uuid.New()
+	existing := &domain.Todo{ID: id, Title: "Task", Status: domain.StatusOpen, Version: 5}
+	repo.On("GetByID", mock.Anything, id).Return(existing, nil)
+	repo.On("ListDescendants", mock.Anything, id).Return(nil, nil)
+	repo.On("UpdateMany", mock.Anything, mock.AnythingOfType("[]*domain.Todo"), mock.Anything).Return(domain.ErrConflict)
+	uc := newTestUseCase(repo)
+
+	_, err := uc.CompleteTodo(serviceContext(), id, []int64{5}, false)
+	assert.ErrorIs(t, err, domain.ErrConflict)
 }
 
 func TestCompleteAllTodos(t *testing.T) {
 	repo := mocks.NewTodoRepository(t)
-	repo.On("CompleteAll", mock.Anything).Return(int64(5), nil)
+	repo.On("CompleteAll", mock.Anything, (*uuid.UUID)(nil), (*uuid.UUID)(nil)).Return(int64(5), nil)
 	uc := newTestUseCase(repo)
 
-	count, err := uc.CompleteAllTodos(context.Background())
+	count, err := uc.CompleteAllTodos(serviceContext(), nil)
 	require.NoError(t, err)
 	assert.Equal(t, int64(5), count)
 }
+
+func TestTodoProjects(t *testing.T) {
+	project := &domain.Project{ID: uuid.New(), Name: "Website"}
+	withProject := func(projects *mocks.ProjectRepository) {
+		projects.On("GetByID", mock.Anything, project.ID).Return(project, nil)
+	}
+
+	t.Run("create in a project", func(t *testing.T) {
+		repo, projects := mocks.NewTodoRepository(t), mocks.NewProjectRepository(t)
+		withProject(projects)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+		todo, err := newTestUseCaseWithProjects(repo, projects).CreateTodo(serviceContext(), usecase.CreateTodoParams{
+			Title: "Launch", ProjectID: project.ID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &project.ID, todo.ProjectID)
+	})
+
+	t.Run("create in an unknown project", func(t *testing.T) {
+		repo, projects := mocks.NewTodoRepository(t), mocks.NewProjectRepository(t)
+		missing := uuid.New()
+		projects.On("GetByID", mock.Anything, missing).Return(nil, domain.ErrNotFound)
+
+		_, err := newTestUseCaseWithProjects(repo, projects).CreateTodo(serviceContext(), usecase.CreateTodoParams{
+			Title: "Launch", ProjectID: missing,
+		})
+		var ve *domain.ValidationError
+		require.ErrorAs(t, err, &ve)
+		assert.Equal(t, "projectId", ve.Field)
+	})
+
+	t.Run("subtasks join the parent's project", func(t *testing.T) {
+		repo, projects := mocks.NewTodoRepository(t), mocks.NewProjectRepository(t)
+		parent := &domain.Todo{ID: uuid.New(), Title: "Parent", ProjectID: &project.ID}
+		repo.On("GetByID", mock.Anything, parent.ID).Return(parent, nil)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+		todo, err := newTestUseCaseWithProjects(repo, projects).CreateTodo(serviceContext(), usecase.CreateTodoParams{
+			Title: "Child", ParentID: parent.ID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &project.ID, todo.ProjectID)
+	})
+
+	t.Run("move between projects", func(t *testing.T) {
+		repo, projects := mocks.NewTodoRepository(t), mocks.NewProjectRepository(t)
+		withProject(projects)
+		other := uuid.New()
+		id := uuid.New()
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Todo{ID: id, Title: "Launch", ProjectID: &other, Version: 1}, nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+		uc := newTestUseCaseWithProjects(repo, projects)
+
+		todo, err := uc.PatchTodo(serviceContext(), id, usecase.TodoPatch{ProjectID: &project.ID})
+		require.NoError(t, err)
+		assert.Equal(t, &project.ID, todo.ProjectID)
+
+		none := uuid.Nil
+		todo, err = uc.PatchTodo(serviceContext(), id, usecase.TodoPatch{ProjectID: &none})
+		require.NoError(t, err)
+		assert.Nil(t, todo.ProjectID)
+	})
+
+	t.Run("list project todos", func(t *testing.T) {
+		repo, projects := mocks.NewTodoRepository(t), mocks.NewProjectRepository(t)
+		withProject(projects)
+		repo.On("List", mock.Anything, mock.MatchedBy(func(f domain.TodoFilter) bool {
+			return f.ProjectID != nil && *f.ProjectID == project.ID && !f.Trashed
+		}), mock.Anything).Return([]domain.Todo{{ID: uuid.New(), ProjectID: &project.ID}}, nil)
+
+		page, err := newTestUseCaseWithProjects(repo, projects).ListProjectTodos(
+			serviceContext(), project.ID, usecase.ListTodosParams{Trashed: true})
+		require.NoError(t, err)
+		assert.Len(t, page.Todos, 1)
+	})
+
+	t.Run("complete all in a project", func(t *testing.T) {
+		repo, projects := mocks.NewTodoRepository(t), mocks.NewProjectRepository(t)
+		withProject(projects)
+		repo.On("CompleteAll", mock.Anything, &project.ID, (*uuid.UUID)(nil)).Return(int64(2), nil)
+
+		count, err := newTestUseCaseWithProjects(repo, projects).CompleteAllTodos(serviceContext(), &project.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("complete all in an unknown project", func(t *testing.T) {
+		repo, projects := mocks.NewTodoRepository(t), mocks.NewProjectRepository(t)
+		missing := uuid.New()
+		projects.On("GetByID", mock.Anything, missing).Return(nil, domain.ErrNotFound)
+
+		_, err := newTestUseCaseWithProjects(repo, projects).CompleteAllTodos(serviceContext(), &missing)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestTodoOwnership(t *testing.T) {
+	owner, assignee, other := uuid.New(), uuid.New(), uuid.New()
+	id := uuid.New()
+	existing := func() *domain.Todo {
+		return &domain.Todo{ID: id, Title: "Task", Status: domain.StatusOpen, CreatedBy: &owner, AssigneeID: &assignee}
+	}
+
+	t.Run("create records the owner", func(t *testing.T) {
+		repo, users := mocks.NewTodoRepository(t), mocks.NewUserRepository(t)
+		withMembers(users, owner)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+		todo, err := newTestUseCaseWithUsers(repo, users).CreateTodo(actor.WithUser(context.Background(), owner),
+			usecase.CreateTodoParams{Title: "Mine"})
+		require.NoError(t, err)
+		assert.Equal(t, &owner, todo.CreatedBy)
+		assert.Equal(t, &owner, todo.UpdatedBy)
+	})
+
+	t.Run("owner and assignee may edit", func(t *testing.T) {
+		for _, user := range []uuid.UUID{owner, assignee} {
+			repo, users := mocks.NewTodoRepository(t), mocks.NewUserRepository(t)
+			withMembers(users, user)
+			repo.On("GetByID", mock.Anything, id).Return(existing(), nil)
+			repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+			todo, err := newTestUseCaseWithUsers(repo, users).StartTodo(actor.WithUser(context.Background(), user), id, nil)
+			require.NoError(t, err)
+			assert.Equal(t, &user, todo.UpdatedBy)
+		}
+	})
+
+	t.Run("other members may not edit", func(t *testing.T) {
+		repo, users := mocks.NewTodoRepository(t), mocks.NewUserRepository(t)
+		withMembers(users, other)
+		repo.On("GetByID", mock.Anything, id).Return(existing(), nil)
+		uc := newTestUseCaseWithUsers(repo, users)
+		ctx := actor.WithUser(context.Background(), other)
+
+		_, err := uc.UpdateTodo(ctx, id, usecase.UpdateTodoParams{Title: "Mine now"})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		err = uc.DeleteTodo(ctx, id, nil, false)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		_, err = uc.AssignTodo(ctx, id, other, nil)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		// A stale version does not tell them the todo's current one.
+		_, err = uc.StartTodo(ctx, id, []int64{99})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		_, err = uc.CompleteTodo(ctx, id, []int64{99}, false)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("subtasks of others do not change with their parent", func(t *testing.T) {
+		repo, users := mocks.NewTodoRepository(t), mocks.NewUserRepository(t)
+		withMembers(users, owner)
+		parent := &domain.Todo{ID: id, Title: "Task", Status: domain.StatusOpen, CreatedBy: &owner}
+		child := domain.Todo{ID: uuid.New(), Title: "Theirs", Status: domain.StatusOpen, ParentID: &id, CreatedBy: &other}
+		repo.On("GetByID", mock.Anything, id).Return(func(context.Context, uuid.UUID) (*domain.Todo, error) {
+			p := *parent
+			return &p, nil
+		})
+		repo.On("ListDescendants", mock.Anything, id).Return([]domain.Todo{child}, nil)
+		uc := newTestUseCaseWithUsers(repo, users)
+		ctx := actor.WithUser(context.Background(), owner)
+
+		_, err := uc.CompleteTodo(ctx, id, nil, true)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		err = uc.DeleteTodo(ctx, id, nil, true)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		// Nor are they moved to the top level.
+		err = uc.DeleteTodo(ctx, id, nil, false)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("admins may edit any todo", func(t *testing.T) {
+		repo, users := mocks.NewTodoRepository(t), mocks.NewUserRepository(t)
+		users.On("GetByID", mock.Anything, other).Return(&domain.User{ID: other, Role: domain.RoleAdmin}, nil)
+		repo.On("GetByID", mock.Anything, id).Return(existing(), nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+		_, err := newTestUseCaseWithUsers(repo, users).StartTodo(actor.WithUser(context.Background(), other), id, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown users may not act", func(t *testing.T) {
+		repo, users := mocks.NewTodoRepository(t), mocks.NewUserRepository(t)
+		users.On("GetByID", mock.Anything, other).Return(nil, domain.ErrNotFound)
+
+		_, err := newTestUseCaseWithUsers(repo, users).ListTodos(actor.WithUser(context.Background(), other), usecase.ListTodosParams{})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("services are not restricted", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		repo.On("GetByID", mock.Anything, id).Return(existing(), nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+		todo, err := newTestUseCase(repo).StartTodo(serviceContext(), id, nil)
+		require.NoError(t, err)
+		assert.Nil(t, todo.UpdatedBy)
+	})
+
+	t.Run("other callers without a user may not act", func(t *testing.T) {
+		uc := newTestUseCase(mocks.NewTodoRepository(t))
+
+		_, err := uc.CompleteAllTodos(context.Background(), nil)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		_, err = uc.ListTodos(context.Background(), usecase.ListTodosParams{})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+}
+
+func TestTodoPolicy(t *testing.T) {
+	forbidden := fmt.Errorf("%w: test", domain.ErrForbidden)
+
+	t.Run("complete all needs the policy's consent", func(t *testing.T) {
+		repo, policy := mocks.NewTodoRepository(t), mocks.NewPolicy(t)
+		policy.On("Authorize", mock.Anything, domain.ActionCompleteAll, (*domain.Todo)(nil)).Return(forbidden)
+
+		_, err := newTestUseCaseWithPolicy(repo, policy).CompleteAllTodos(context.Background(), nil)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("edits are checked against the todo", func(t *testing.T) {
+		repo, policy := mocks.NewTodoRepository(t), mocks.NewPolicy(t)
+		id := uuid.New()
+		existing := &domain.Todo{ID: id, Title: "Task", Status: domain.StatusOpen}
+		repo.On("GetByID", mock.Anything, id).Return(existing, nil)
+		policy.On("Authorize", mock.Anything, domain.ActionEdit, existing).Return(forbidden)
+
+		_, err := newTestUseCaseWithPolicy(repo, policy).CancelTodo(context.Background(), id, nil)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("reads", func(t *testing.T) {
+		repo, policy := mocks.NewTodoRepository(t), mocks.NewPolicy(t)
+		policy.On("Authorize", mock.Anything, domain.ActionRead, (*domain.Todo)(nil)).Return(nil)
+		repo.On("List", mock.Anything, mock.Anything, mock.Anything).Return([]domain.Todo{}, nil)
+
+		_, err := newTestUseCaseWithPolicy(repo, policy).ListTodos(context.Background(), usecase.ListTodosParams{})
+		require.NoError(t, err)
+	})
+}
+
+func TestAssignTodo(t *testing.T) {
+	id, owner, assignee := uuid.New(), uuid.New(), uuid.New()
+	existing := func() *domain.Todo {
+		return &domain.Todo{ID: id, Title: "Task", Status: domain.StatusOpen, CreatedBy: &owner, Version: 3}
+	}
+	ctx := actor.WithUser(context.Background(), owner)
+
+	t.Run("assign and unassign", func(t *testing.T) {
+		repo, users := mocks.NewTodoRepository(t), mocks.NewUserRepository(t)
+		withMembers(users, owner, assignee)
+		repo.On("GetByID", mock.Anything, id).Return(existing(), nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil)
+		uc := newTestUseCaseWithUsers(repo, users)
+
+		todo, err := uc.AssignTodo(ctx, id, assignee, []int64{3})
+		require.NoError(t, err)
+		assert.Equal(t, &assignee, todo.AssigneeID)
+
+		todo, err = uc.AssignTodo(ctx, id, uuid.Nil, nil)
+		require.NoError(t, err)
+		assert.Nil(t, todo.AssigneeID)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		repo, users := mocks.NewTodoRepository(t), mocks.NewUserRepository(t)
+		withMembers(users, owner)
+		repo.On("GetByID", mock.Anything, id).Return(existing(), nil)
+		users.On("GetByID", mock.Anything, assignee).Return(nil, domain.ErrNotFound)
+
+		_, err := newTestUseCaseWithUsers(repo, users).AssignTodo(ctx, id, assignee, nil)
+		var ve *domain.ValidationError
+		require.ErrorAs(t, err, &ve)
+		assert.Equal(t, "assigneeId", ve.Field)
+	})
+
+	t.Run("version mismatch", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		repo.On("GetByID", mock.Anything, id).Return(existing(), nil)
+
+		_, err := newTestUseCase(repo).AssignTodo(serviceContext(), id, assignee, []int64{2})
+		assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+	})
+
+	t.Run("list my todos", func(t *testing.T) {
+		repo, users := mocks.NewTodoRepository(t), mocks.NewUserRepository(t)
+		withMembers(users, owner)
+		repo.On("List", mock.Anything, domain.TodoFilter{AssigneeID: &owner}, mock.Anything).Return([]domain.Todo{}, nil)
+		uc := newTestUseCaseWithUsers(repo, users)
+
+		_, err := uc.ListTodos(ctx, usecase.ListTodosParams{Assignee: usecase.AssigneeMe})
+		require.NoError(t, err)
+
+		_, err = uc.ListTodos(serviceContext(), usecase.ListTodosParams{Assignee: usecase.AssigneeMe})
+		assert.ErrorIs(t, err, actor.ErrNoUser)
+	})
+}
+
+func TestMoveTodo(t *testing.T) {
+	id, afterID, beforeID := uuid.New(), uuid.New(), uuid.New()
+	existing := func() *domain.Todo {
+		return &domain.Todo{ID: id, Title: "Task", Status: domain.StatusOpen, Rank: "a", Version: 2}
+	}
+	after := &domain.Todo{ID: afterID, Title: "After", Rank: "M"}
+	before := &domain.Todo{ID: beforeID, Title: "Before", Rank: "N"}
+
+	t.Run("between two todos", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		repo.On("GetByID", mock.Anything, id).Return(existing(), nil)
+		repo.On("GetByID", mock.Anything, afterID).Return(after, nil)
+		repo.On("GetByID", mock.Anything, beforeID).Return(before, nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
+
+		todo, err := newTestUseCase(repo).MoveTodo(serviceContext(), id, &afterID, &beforeID, []int64{2})
+		require.NoError(t, err)
+		assert.Less(t, after.Rank, todo.Rank)
+		assert.Less(t, todo.Rank, before.Rank)
+	})
+
+	t.Run("to the start", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		repo.On("GetByID", mock.Anything, id).Return(existing(), nil)
+		repo.On("GetByID", mock.Anything, afterID).Return(after, nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Todo")).Return(nil).Once()
+
+		todo, err := newTestUseCase(repo).MoveTodo(serviceContext(), id, nil, &afterID, nil)
+		require.NoError(t, err)
+		assert.Less(t, todo.Rank, after.Rank)
+	})
+
+	t.Run("next to an unknown todo", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		repo.On("GetByID", mock.Anything, id).Return(existing(), nil)
+		repo.On("GetByID", mock.Anything, beforeID).Return(nil, domain.ErrNotFound)
+
+		_, err := newTestUseCase(repo).MoveTodo(serviceContext(), id, nil, &beforeID, nil)
+		var ve *domain.ValidationError
+		require.ErrorAs(t, err, &ve)
+		assert.Equal(t, "beforeId", ve.Field)
+	})
+
+	t.Run("version mismatch", func(t *testing.T) {
+		repo := mocks.NewTodoRepository(t)
+		repo.On("GetByID", mock.Anything, id).Return(existing(), nil)
+
+		_, err := newTestUseCase(repo).MoveTodo(serviceContext(), id, nil, nil, []int64{1})
+		assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+	})
+}
+
+func TestOpenView(t *testing.T) {
+	project := uuid.New()
+	shown := domain.Todo{ID: uuid.New(), Title: "Shown", Status: domain.StatusOpen, ProjectID: &project}
+
+	repo, projects := mocks.NewTodoRepository(t), mocks.NewProjectRepository(t)
+	projects.On("GetByID", mock.Anything, project).Return(&domain.Project{ID: project}, nil)
+	repo.On("List", mock.Anything, domain.TodoFilter{ProjectID: &project}, mock.Anything).Return([]domain.Todo{shown}, nil)
+	uc := newTestUseCaseWithProjects(repo, projects)
+
+	view, page, err := uc.OpenView(serviceContext(), usecase.ListTodosParams{ProjectID: &project, Sort: "rank"})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Todo{shown}, page.Todos)
+	assert.Equal(t, &project, view.ProjectID())
+
+	added := domain.Todo{ID: uuid.New(), Title: "Added", Status: domain.StatusOpen, ProjectID: &project}
+	assert.Equal(t, usecase.ViewUpserted, view.Apply(&added))
+
+	moved := shown
+	moved.ProjectID = nil
+	assert.Equal(t, usecase.ViewRemoved, view.Apply(&moved))
+	assert.Equal(t, usecase.ViewUnchanged, view.Apply(&moved), "the client was already told it left")
+
+	trashed := added
+	trashed.MoveToTrash()
+	assert.Equal(t, usecase.ViewRemoved, view.Apply(&trashed))
+}
+
+func TestBulkTodos(t *testing.T) {
+	ctx := serviceContext()
+	todo := func(status domain.Status, parent *uuid.UUID, tags ...string) domain.Todo {
+		return domain.Todo{ID: uuid.New(), Title: "Bulk", Status: status, ParentID: parent, Tags: tags, Version: 1}
+	}
+	// written captures the groups passed to UpdateBulk.
+	written := func(repo *mocks.TodoRepository, errs ...error) *[][]*domain.Todo {
+		var groups [][]*domain.Todo
+		repo.On("UpdateBulk", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { groups = args.Get(1).([][]*domain.Todo) }).
+			Return(func(_ context.Context, g, _ [][]*domain.Todo, _ bool) ([]error, error) {
+				if errs == nil {
+					return make([]error, len(g)), nil
+				}
+				return errs, nil
+			}).Once()
+		return &groups
+	}
+
+	t.Run("best effort", func(t *testing.T) {
+		open, done, missing := todo(domain.StatusOpen, nil), todo(domain.StatusDone, nil), uuid.New()
+		repo := mocks.NewTodoRepository(t)
+		repo.On("ListByIDs", mock.Anything, []uuid.UUID{open.ID, done.ID, missing}).Return([]domain.Todo{open, done}, nil)
+		repo.On("ListDescendantsOfAll", mock.Anything, []uuid.UUID{open.ID, done.ID}).Return(nil, nil)
+		groups := written(repo)
+
+		results, err := newTestUseCase(repo).BulkTodos(ctx, usecase.BulkTodosParams{
+			Action: "complete", IDs: []uuid.UUID{open.ID, done.ID, missing},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		require.NoError(t, results[0].Err)
+		assert.Equal(t, domain.StatusDone, results[0].Todo.Status)
+		assert.ErrorIs(t, results[1].Err, domain.ErrInvalidTransition)
+		assert.Equal(t, missing, results[2].ID)
+		assert.ErrorIs(t, results[2].Err, domain.ErrNotFound)
+		require.Len(t, *groups, 1)
+		assert.Equal(t, open.ID, (*groups)[0][0].ID)
+	})
+
+	t.Run("atomic", func(t *testing.T) {
+		open, done := todo(domain.StatusOpen, nil), todo(domain.StatusDone, nil)
+		repo := mocks.NewTodoRepository(t)
+		repo.On("ListByIDs", mock.Anything, mock.Anything).Return([]domain.Todo{open, done}, nil)
+		repo.On("ListDescendantsOfAll", mock.Anything, mock.Anything).Return(nil, nil)
+
+		results, err := newTestUseCase(repo).BulkTodos(ctx, usecase.BulkTodosParams{
+			Action: "complete", IDs: []uuid.UUID{open.ID, done.ID}, Atomic: true,
+		})
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, domain.ErrBulkAborted)
+		assert.Nil(t, results[0].Todo)
+		assert.ErrorIs(t, results[1].Err, domain.ErrInvalidTransition)
+		repo.AssertNotCalled(t, "UpdateBulk", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		a, b := todo(domain.StatusOpen, nil, "x"), todo(domain.StatusOpen, nil)
+		repo := mocks.NewTodoRepository(t)
+		repo.On("ListByIDs", mock.Anything, mock.Anything).Return([]domain.Todo{a, b}, nil)
+		written(repo, domain.ErrConflict, nil)
+
+		results, err := newTestUseCase(repo).BulkTodos(ctx, usecase.BulkTodosParams{
+			Action: "retag", IDs: []uuid.UUID{a.ID, b.ID}, AddTags: []string{"Y"},
+		})
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, domain.ErrConflict)
+		require.NoError(t, results[1].Err)
+		assert.Equal(t, []string{"y"}, results[1].Todo.Tags)
+	})
+
+	t.Run("complete with subtasks", func(t *testing.T) {
+		parent := todo(domain.StatusOpen, nil)
+		child := todo(domain.StatusInProgress, &parent.ID)
+		closed := todo(domain.StatusCancelled, &parent.ID)
+
+		repo := mocks.NewTodoRepository(t)
+		repo.On("ListByIDs", mock.Anything, []uuid.UUID{parent.ID}).Return([]domain.Todo{parent}, nil)
+		repo.On("ListDescendantsOfAll", mock.Anything, []uuid.UUID{parent.ID}).Return([]domain.Todo{child, closed}, nil)
+		results, err := newTestUseCase(repo).BulkTodos(ctx, usecase.BulkTodosParams{
+			Action: "complete", IDs: []uuid.UUID{parent.ID},
+		})
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, domain.ErrInvalidTransition, "without cascade")
+
+		groups := written(repo)
+		results, err = newTestUseCase(repo).BulkTodos(ctx, usecase.BulkTodosParams{
+			Action: "complete", IDs: []uuid.UUID{parent.ID}, Cascade: true,
+		})
+		require.NoError(t, err)
+		require.NoError(t, results[0].Err)
+		require.Len(t, *groups, 1)
+		require.Len(t, (*groups)[0], 2)
+		assert.Equal(t, child.ID, (*groups)[0][1].ID)
+		assert.Equal(t, domain.StatusDone, (*groups)[0][1].Status)
+	})
+
+	t.Run("complete selected subtasks", func(t *testing.T) {
+		parent := todo(domain.StatusOpen, nil)
+		child := todo(domain.StatusOpen, &parent.ID)
+		repo := mocks.NewTodoRepository(t)
+		repo.On("ListByIDs", mock.Anything, mock.Anything).Return([]domain.Todo{parent, child}, nil)
+		repo.On("ListDescendantsOfAll", mock.Anything, mock.Anything).Return([]domain.Todo{child}, nil)
+		groups := written(repo)
+
+		results, err := newTestUseCase(repo).BulkTodos(ctx, usecase.BulkTodosParams{
+			Action: "complete", IDs: []uuid.UUID{parent.ID, child.ID},
+		})
+		require.NoError(t, err)
+		require.NoError(t, results[0].Err)
+		require.NoError(t, results[1].Err)
+		assert.Len(t, *groups, 2)
+	})
+
+	t.Run("delete promotes subtasks", func(t *testing.T) {
+		parent := todo(domain.StatusOpen, nil)
+		child := todo(domain.StatusOpen, &parent.ID)
+		grandchild := todo(domain.StatusOpen, &child.ID)
+		repo := mocks.NewTodoRepository(t)
+		repo.On("ListByIDs", mock.Anything, mock.Anything).Return([]domain.Todo{parent}, nil)
+		repo.On("ListDescendantsOfAll", mock.Anything, mock.Anything).Return([]domain.Todo{child, grandchild}, nil)
+		groups := written(repo)
+
+		results, err := newTestUseCase(repo).BulkTodos(ctx, usecase.BulkTodosParams{
+			Action: "delete", IDs: []uuid.UUID{parent.ID},
+		})
+		require.NoError(t, err)
+		require.NoError(t, results[0].Err)
+		assert.True(t, results[0].Todo.IsTrashed())
+		require.Len(t, (*groups)[0], 2)
+		assert.Equal(t, child.ID, (*groups)[0][1].ID)
+		assert.Nil(t, (*groups)[0][1].ParentID)
+		assert.False(t, (*groups)[0][1].IsTrashed())
+	})
+
+	t.Run("subtasks of others", func(t *testing.T) {
+		owner, other := uuid.New(), uuid.New()
+		parent := todo(domain.StatusOpen, nil)
+		parent.CreatedBy = &owner
+		child := todo(domain.StatusOpen, &parent.ID)
+		child.CreatedBy = &other
+		repo, users := mocks.NewTodoRepository(t), mocks.NewUserRepository(t)
+		withMembers(users, owner)
+		repo.On("ListByIDs", mock.Anything, []uuid.UUID{parent.ID}).Return([]domain.Todo{parent}, nil)
+		repo.On("ListByIDs", mock.Anything, []uuid.UUID{parent.ID, child.ID}).Return([]domain.Todo{parent, child}, nil)
+		repo.On("ListDescendantsOfAll", mock.Anything, mock.Anything).Return([]domain.Todo{child}, nil)
+		uc := newTestUseCaseWithUsers(repo, users)
+		ctx := actor.WithUser(context.Background(), owner)
+
+		for _, action := range []string{"complete", "delete"} {
+			results, err := uc.BulkTodos(ctx, usecase.BulkTodosParams{
+				Action: action, IDs: []uuid.UUID{parent.ID}, Cascade: true,
+			})
+			require.NoError(t, err)
+			assert.ErrorIs(t, results[0].Err, domain.ErrForbidden, action)
+		}
+
+		// Requesting the subtask as well leaves it live, so the parent may
+		// neither take it to the trash nor leave it behind.
+		for _, cascade := range []bool{false, true} {
+			results, err := uc.BulkTodos(ctx, usecase.BulkTodosParams{
+				Action: "delete", IDs: []uuid.UUID{parent.ID, child.ID}, Cascade: cascade,
+			})
+			require.NoError(t, err)
+			assert.ErrorIs(t, results[0].Err, domain.ErrForbidden, "cascade %t", cascade)
+			assert.ErrorIs(t, results[1].Err, domain.ErrForbidden, "cascade %t", cascade)
+		}
+		repo.AssertNotCalled(t, "UpdateBulk", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("delete with a subtask that fails", func(t *testing.T) {
+		owner, other := uuid.New(), uuid.New()
+		parent, kept := todo(domain.StatusOpen, nil), todo(domain.StatusOpen, nil)
+		parent.CreatedBy, kept.CreatedBy = &owner, &owner
+		child := todo(domain.StatusOpen, &parent.ID)
+		child.CreatedBy = &owner
+		grandchild := todo(domain.StatusOpen, &child.ID)
+		grandchild.CreatedBy = &other
+		repo, users := mocks.NewTodoRepository(t), mocks.NewUserRepository(t)
+		withMembers(users, owner)
+		repo.On("ListByIDs", mock.Anything, mock.Anything).Return([]domain.Todo{parent, child, kept}, nil)
+		repo.On("ListDescendantsOfAll", mock.Anything, mock.Anything).Return([]domain.Todo{child, grandchild}, nil)
+		groups := written(repo)
+
+		// The subtask fails for its own subtask, after its parent was
+		// planned with it.
+		results, err := newTestUseCaseWithUsers(repo, users).BulkTodos(actor.WithUser(context.Background(), owner), usecase.BulkTodosParams{
+			Action: "delete", IDs: []uuid.UUID{parent.ID, child.ID, kept.ID}, Cascade: true,
+		})
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, domain.ErrForbidden)
+		assert.ErrorIs(t, results[1].Err, domain.ErrForbidden)
+		require.NoError(t, results[2].Err)
+		require.Len(t, *groups, 1)
+		assert.Equal(t, kept.ID, (*groups)[0][0].ID)
+	})
+
+	t.Run("move", func(t *testing.T) {
+		project := uuid.New()
+		moved, there := todo(domain.StatusOpen, nil), todo(domain.StatusOpen, nil)
+		there.ProjectID = &project
+		repo, projects := mocks.NewTodoRepository(t), mocks.NewProjectRepository(t)
+		projects.On("GetByID", mock.Anything, project).Return(&domain.Project{ID: project}, nil)
+		repo.On("ListByIDs", mock.Anything, mock.Anything).Return([]domain.Todo{moved, there}, nil)
+		groups := written(repo)
+
+		results, err := newTestUseCaseWithProjects(repo, projects).BulkTodos(ctx, usecase.BulkTodosParams{
+			Action: "move", IDs: []uuid.UUID{moved.ID, there.ID}, ProjectID: &project,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &project, results[0].Todo.ProjectID)
+		assert.Equal(t, &project, results[1].Todo.ProjectID)
+		require.Len(t, *groups, 1, "todos already in the project are not written")
+		assert.Equal(t, moved.ID, (*groups)[0][0].ID)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		project := uuid.New()
+		projects := mocks.NewProjectRepository(t)
+		projects.On("GetByID", mock.Anything, project).Return(nil, domain.ErrNotFound)
+		uc := newTestUseCaseWithProjects(mocks.NewTodoRepository(t), projects)
+		id := uuid.New()
+
+		for _, params := range []usecase.BulkTodosParams{
+			{Action: "archive", IDs: []uuid.UUID{id}},
+			{Action: "complete"},
+			{Action: "complete", IDs: []uuid.UUID{id, id}},
+			{Action: "retag", IDs: []uuid.UUID{id}},
+			{Action: "retag", IDs: []uuid.UUID{id}, AddTags: []string{"a"}, RemoveTags: []string{"A"}},
+			{Action: "move", IDs: []uuid.UUID{id}, ProjectID: &project},
+		} {
+			_, err := uc.BulkTodos(ctx, params)
+			assert.ErrorIs(t, err, domain.ErrValidation, params.Action)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// ViewChange tells how a change to a todo affects a TodoView.
+type ViewChange int
+
+const (
+	// ViewUnchanged means the todo neither belongs nor belonged to the view.
+	ViewUnchanged ViewChange = iota
+	// ViewUpserted means the todo belongs to the view, as a new or changed entry.
+	ViewUpserted
+	// ViewRemoved means the todo belonged to the view but no longer does.
+	ViewRemoved
+)
+
+// TodoView is a live todo list: the first page of a list request, kept up
+// to date with the todos that change afterwards. It is not safe for
+// concurrent use.
+type TodoView struct {
+	uc     *TodoUseCase
+	params ListTodosParams
+	filter domain.TodoFilter
+	// shown holds the todos the client was told belong to the view.
+	shown map[uuid.UUID]struct{}
+}
+
+// OpenView lists the first page of params, the todos of a project if
+// params.ProjectID is set, and returns a view that follows the list.
+func (uc *TodoUseCase) OpenView(ctx context.Context, params ListTodosParams) (*TodoView, *TodoPage, error) {
+	if params.ProjectID != nil {
+		params.Trashed = false
+	}
+	params.Cursor = ""
+	filter, err := listFilter(ctx, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := &TodoView{uc: uc, params: params, filter: filter}
+	page, err := v.Reload(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, page, nil
+}
+
+// Reload lists the first page of the view again, which replaces what the
+// client was shown. It is needed after changes that are not announced
+// todo by todo, such as completing all todos.
+func (v *TodoView) Reload(ctx context.Context) (*TodoPage, error) {
+	var page *TodoPage
+	var err error
+	if v.params.ProjectID != nil {
+		page, err = v.uc.ListProjectTodos(ctx, *v.params.ProjectID, v.params)
+	} else {
+		page, err = v.uc.ListTodos(ctx, v.params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	v.shown = make(map[uuid.UUID]struct{}, len(page.Todos))
+	for _, t := range page.Todos {
+		v.shown[t.ID] = struct{}{}
+	}
+	return page, nil
+}
+
+// ProjectID returns the project the view lists the todos of, if any.
+func (v *TodoView) ProjectID() *uuid.UUID {
+	return v.params.ProjectID
+}
+
+// Apply tells how t, as it is after a change, affects the view. Todos that
+// belong to the view are always upserted, even those beyond its first
+// page, while removals are only reported for todos the client was shown.
+func (v *TodoView) Apply(t *domain.Todo) ViewChange {
+	if v.filter.Matches(t, time.Now()) {
+		v.shown[t.ID] = struct{}{}
+		return ViewUpserted
+	}
+	if _, ok := v.shown[t.ID]; ok {
+		delete(v.shown, t.ID)
+		return ViewRemoved
+	}
+	return ViewUnchanged
+}
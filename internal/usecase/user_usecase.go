@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// UserUseCase manages the users of a workspace. Only admins may create
+// users and change their roles.
+type UserUseCase struct {
+	repo   UserRepository
+	policy Policy
+	logger *slog.Logger
+}
+
+func NewUserUseCase(repo UserRepository, policy Policy, logger *slog.Logger) *UserUseCase {
+	return &UserUseCase{repo: repo, policy: policy, logger: logger}
+}
+
+// CreateUser creates a user with the given ID, or a new one if id is
+// uuid.Nil. role is one of viewer, member and admin; empty means member.
+func (uc *UserUseCase) CreateUser(ctx context.Context, id uuid.UUID, name, email, role string) (*domain.User, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionManageUsers, nil); err != nil {
+		return nil, err
+	}
+	r, err := domain.ParseRole(role)
+	if err != nil {
+		return nil, err
+	}
+	user, err := domain.NewUser(id, name, email, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "user created", slog.String("id", user.ID.String()), slog.String("role", string(user.Role)))
+	return user, nil
+}
+
+func (uc *UserUseCase) GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	user, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	return user, nil
+}
+
+// SetUserRole changes the role a user holds in the workspace.
+func (uc *UserUseCase) SetUserRole(ctx context.Context, id uuid.UUID, role string) (*domain.User, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionManageUsers, nil); err != nil {
+		return nil, err
+	}
+	r, err := domain.ParseRole(role)
+	if err != nil {
+		return nil, err
+	}
+	user, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get user for role change: %w", err)
+	}
+
+	user.Role = r
+	if err := uc.repo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("set user role: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "user role changed", slog.String("id", id.String()), slog.String("role", string(r)))
+	return user, nil
+}
+
+// CurrentUser returns the user ctx acts as. It fails with actor.ErrNoUser
+// if ctx does not act as a user.
+func (uc *UserUseCase) CurrentUser(ctx context.Context) (*domain.User, error) {
+	id, ok := actor.UserID(ctx)
+	if !ok {
+		return nil, actor.ErrNoUser
+	}
+	return uc.GetUser(ctx, id)
+}
+
+func (uc *UserUseCase) ListUsers(ctx context.Context) ([]domain.User, error) {
+	users, err := uc.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	return users, nil
+}
@@ -0,0 +1,99 @@
+package usecase_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUserUseCase(repo *mocks.UserRepository) *usecase.UserUseCase {
+	return usecase.NewUserUseCase(repo, usecase.NewRolePolicy(repo), slog.New(slog.DiscardHandler))
+}
+
+func TestCreateUser(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := mocks.NewUserRepository(t)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+		id := uuid.New()
+
+		user, err := newTestUserUseCase(repo).CreateUser(serviceContext(), id, " Alice ", "Alice@Example.com", "")
+		require.NoError(t, err)
+		assert.Equal(t, id, user.ID)
+		assert.Equal(t, "Alice", user.Name)
+		assert.Equal(t, "alice@example.com", user.Email)
+		assert.Equal(t, domain.RoleMember, user.Role)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		repo := mocks.NewUserRepository(t)
+
+		_, err := newTestUserUseCase(repo).CreateUser(serviceContext(), uuid.Nil, "Alice", "", "owner")
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("members may not create users", func(t *testing.T) {
+		repo := mocks.NewUserRepository(t)
+		member := uuid.New()
+		repo.On("GetByID", mock.Anything, member).Return(&domain.User{ID: member, Role: domain.RoleMember}, nil)
+
+		_, err := newTestUserUseCase(repo).CreateUser(actor.WithUser(context.Background(), member), uuid.Nil, "Alice", "", "admin")
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		repo := mocks.NewUserRepository(t)
+
+		_, err := newTestUserUseCase(repo).CreateUser(serviceContext(), uuid.Nil, "Alice", "not an email", "")
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("duplicate email", func(t *testing.T) {
+		repo := mocks.NewUserRepository(t)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.User")).Return(domain.ErrAlreadyExists)
+
+		_, err := newTestUserUseCase(repo).CreateUser(serviceContext(), uuid.Nil, "Alice", "alice@example.com", "")
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	})
+}
+
+func TestSetUserRole(t *testing.T) {
+	repo := mocks.NewUserRepository(t)
+	admin, id := uuid.New(), uuid.New()
+	repo.On("GetByID", mock.Anything, admin).Return(&domain.User{ID: admin, Role: domain.RoleAdmin}, nil)
+	repo.On("GetByID", mock.Anything, id).Return(&domain.User{ID: id, Role: domain.RoleMember}, nil)
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+		return u.ID == id && u.Role == domain.RoleViewer
+	})).Return(nil)
+	uc := newTestUserUseCase(repo)
+	ctx := actor.WithUser(context.Background(), admin)
+
+	user, err := uc.SetUserRole(ctx, id, "viewer")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleViewer, user.Role)
+
+	_, err = uc.SetUserRole(ctx, id, "owner")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+func TestCurrentUser(t *testing.T) {
+	repo := mocks.NewUserRepository(t)
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(&domain.User{ID: id, Name: "Alice"}, nil)
+	uc := newTestUserUseCase(repo)
+
+	user, err := uc.CurrentUser(actor.WithUser(context.Background(), id))
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", user.Name)
+
+	_, err = uc.CurrentUser(serviceContext())
+	assert.ErrorIs(t, err, actor.ErrNoUser)
+}
@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/tenant"
+)
+
+// DefaultDeliveryLimit is the number of deliveries ListDeliveries returns
+// by default.
+const DefaultDeliveryLimit = 50
+
+// WebhookUseCase manages the webhooks of a workspace. It is also the
+// EventSink that queues a delivery of every outbox event to the webhooks
+// subscribing to it, which WebhookWorker then sends.
+type WebhookUseCase struct {
+	repo       WebhookRepository
+	deliveries WebhookDeliveryRepository
+	policy     Policy
+	logger     *slog.Logger
+}
+
+func NewWebhookUseCase(repo WebhookRepository, deliveries WebhookDeliveryRepository, policy Policy, logger *slog.Logger) *WebhookUseCase {
+	return &WebhookUseCase{repo: repo, deliveries: deliveries, policy: policy, logger: logger}
+}
+
+// CreateWebhook subscribes url to the given types of events, or to every
+// event if there are none. A secret is generated if secret is empty.
+func (uc *WebhookUseCase) CreateWebhook(ctx context.Context, url, secret string, events []domain.EventType) (*domain.Webhook, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionManageWebhooks, nil); err != nil {
+		return nil, err
+	}
+	hook, err := domain.NewWebhook(url, secret, events)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Create(ctx, hook); err != nil {
+		return nil, fmt.Errorf("create webhook: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "webhook created", slog.String("id", hook.ID.String()), slog.String("url", hook.URL))
+	return hook, nil
+}
+
+func (uc *WebhookUseCase) GetWebhook(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionManageWebhooks, nil); err != nil {
+		return nil, err
+	}
+	hook, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get webhook: %w", err)
+	}
+	return hook, nil
+}
+
+func (uc *WebhookUseCase) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	if err := uc.policy.Authorize(ctx, domain.ActionManageWebhooks, nil); err != nil {
+		return nil, err
+	}
+	hooks, err := uc.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// UpdateWebhook replaces the URL, secret and event types of a webhook. An
+// empty secret keeps the current one.
+func (uc *WebhookUseCase) UpdateWebhook(ctx context.Context, id uuid.UUID, url, secret string, events []domain.EventType) (*domain.Webhook, error) {
+	hook, err := uc.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := hook.Update(url, secret, events); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Update(ctx, hook); err != nil {
+		return nil, fmt.Errorf("update webhook: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "webhook updated", slog.String("id", id.String()))
+	return hook, nil
+}
+
+// DeleteWebhook deletes a webhook with its deliveries, including those
+// not yet sent.
+func (uc *WebhookUseCase) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	if err := uc.policy.Authorize(ctx, domain.ActionManageWebhooks, nil); err != nil {
+		return err
+	}
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "webhook deleted", slog.String("id", id.String()))
+	return nil
+}
+
+// ListDeliveries returns up to limit of the latest deliveries of a webhook,
+// newest first, or DefaultDeliveryLimit if limit is 0.
+func (uc *WebhookUseCase) ListDeliveries(ctx context.Context, id uuid.UUID, limit int) ([]domain.WebhookDelivery, error) {
+	if _, err := uc.GetWebhook(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = DefaultDeliveryLimit
+	}
+	deliveries, err := uc.deliveries.ListByWebhook(ctx, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Deliver queues a delivery of event to every webhook of its workspace that
+// subscribes to it. Queuing an event twice has no effect.
+func (uc *WebhookUseCase) Deliver(ctx context.Context, event *domain.Event) error {
+	ctx = tenant.WithWorkspace(ctx, event.WorkspaceID)
+	hooks, err := uc.repo.ListSubscribed(ctx, event.Type)
+	if err != nil {
+		return fmt.Errorf("list subscribed webhooks: %w", err)
+	}
+	deliveries := make([]*domain.WebhookDelivery, len(hooks))
+	for i := range hooks {
+		if deliveries[i], err = domain.NewWebhookDelivery(&hooks[i], event); err != nil {
+			return err
+		}
+	}
+	if err := uc.deliveries.Enqueue(ctx, deliveries...); err != nil {
+		return fmt.Errorf("queue webhook deliveries: %w", err)
+	}
+	return nil
+}
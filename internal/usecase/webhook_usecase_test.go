@@ -0,0 +1,203 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/actor"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/eventsink"
+	"github.com/knjname/go-todo-api/internal/tenant"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWebhookUseCase(repo *mocks.WebhookRepository, deliveries *mocks.WebhookDeliveryRepository, users *mocks.UserRepository) *usecase.WebhookUseCase {
+	return usecase.NewWebhookUseCase(repo, deliveries, usecase.NewRolePolicy(users), slog.New(slog.DiscardHandler))
+}
+
+func TestCreateWebhook(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := mocks.NewWebhookRepository(t)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Webhook")).Return(nil)
+
+		hook, err := newTestWebhookUseCase(repo, nil, mocks.NewUserRepository(t)).
+			CreateWebhook(serviceContext(), "https://example.com/hook", "", []domain.EventType{domain.EventTodoCompleted})
+		require.NoError(t, err)
+		assert.NotEmpty(t, hook.Secret)
+		assert.Equal(t, []domain.EventType{domain.EventTodoCompleted}, hook.Events)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		_, err := newTestWebhookUseCase(mocks.NewWebhookRepository(t), nil, mocks.NewUserRepository(t)).
+			CreateWebhook(serviceContext(), "example.com", "", nil)
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("members may not manage webhooks", func(t *testing.T) {
+		users := mocks.NewUserRepository(t)
+		member := uuid.New()
+		users.On("GetByID", mock.Anything, member).Return(&domain.User{ID: member, Role: domain.RoleMember}, nil)
+
+		_, err := newTestWebhookUseCase(mocks.NewWebhookRepository(t), nil, users).
+			CreateWebhook(actor.WithUser(context.Background(), member), "https://example.com/hook", "", nil)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("callers without a user may not manage webhooks", func(t *testing.T) {
+		uc := newTestWebhookUseCase(mocks.NewWebhookRepository(t), nil, mocks.NewUserRepository(t))
+
+		_, err := uc.CreateWebhook(context.Background(), "https://example.com/hook", "", nil)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		_, err = uc.ListWebhooks(context.Background())
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+}
+
+func TestUpdateWebhook(t *testing.T) {
+	repo := mocks.NewWebhookRepository(t)
+	hook, err := domain.NewWebhook("https://example.com/hook", "", nil)
+	require.NoError(t, err)
+	secret := hook.Secret
+	repo.On("GetByID", mock.Anything, hook.ID).Return(hook, nil)
+	repo.On("Update", mock.Anything, hook).Return(nil)
+
+	updated, err := newTestWebhookUseCase(repo, nil, mocks.NewUserRepository(t)).
+		UpdateWebhook(serviceContext(), hook.ID, "https://example.org/hook", "", []domain.EventType{domain.EventTodoDeleted})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.org/hook", updated.URL)
+	assert.Equal(t, secret, updated.Secret)
+	assert.Equal(t, []domain.EventType{domain.EventTodoDeleted}, updated.Events)
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	t.Run("default limit", func(t *testing.T) {
+		repo := mocks.NewWebhookRepository(t)
+		deliveries := mocks.NewWebhookDeliveryRepository(t)
+		id := uuid.New()
+		repo.On("GetByID", mock.Anything, id).Return(&domain.Webhook{ID: id}, nil)
+		deliveries.On("ListByWebhook", mock.Anything, id, usecase.DefaultDeliveryLimit).
+			Return([]domain.WebhookDelivery{{WebhookID: id}}, nil)
+
+		got, err := newTestWebhookUseCase(repo, deliveries, mocks.NewUserRepository(t)).ListDeliveries(serviceContext(), id, 0)
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+
+	t.Run("unknown webhook", func(t *testing.T) {
+		repo := mocks.NewWebhookRepository(t)
+		repo.On("GetByID", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound)
+
+		_, err := newTestWebhookUseCase(repo, mocks.NewWebhookDeliveryRepository(t), mocks.NewUserRepository(t)).
+			ListDeliveries(serviceContext(), uuid.New(), 10)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestWebhookUseCase_Deliver(t *testing.T) {
+	repo := mocks.NewWebhookRepository(t)
+	deliveries := mocks.NewWebhookDeliveryRepository(t)
+	workspace := uuid.New()
+	first, err := domain.NewWebhook("https://example.com/a", "", nil)
+	require.NoError(t, err)
+	second, err := domain.NewWebhook("https://example.com/b", "", nil)
+	require.NoError(t, err)
+	event, err := domain.NewAllCompletedEvent(nil, []uuid.UUID{uuid.New()})
+	require.NoError(t, err)
+	event.WorkspaceID = workspace
+
+	inWorkspace := mock.MatchedBy(func(ctx context.Context) bool {
+		id, ok := tenant.WorkspaceID(ctx)
+		return ok && id == workspace
+	})
+	repo.On("ListSubscribed", inWorkspace, domain.EventAllCompleted).Return([]domain.Webhook{*first, *second}, nil)
+	deliveries.On("Enqueue", inWorkspace, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			for i, hook := range []*domain.Webhook{first, second} {
+				d := args.Get(i + 1).(*domain.WebhookDelivery)
+				assert.Equal(t, hook.ID, d.WebhookID)
+				assert.Equal(t, event.ID, d.EventID)
+				assert.Equal(t, domain.DeliveryPending, d.Status)
+			}
+		}).
+		Return(nil)
+
+	uc := newTestWebhookUseCase(repo, deliveries, mocks.NewUserRepository(t))
+	require.NoError(t, uc.Deliver(serviceContext(), event))
+}
+
+func TestWebhookWorker_SendBatch(t *testing.T) {
+	// The worker sends to a local receiver, which verifies the signature.
+	hook, err := domain.NewWebhook("https://example.com/hook", "", nil)
+	require.NoError(t, err)
+	var received []domain.Event
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		unix, _ := strconv.ParseInt(r.Header.Get("X-Webhook-Timestamp"), 10, 64)
+		var e domain.Event
+		if json.Unmarshal(body, &e) != nil ||
+			r.Header.Get("X-Webhook-Signature") != domain.SignWebhook(hook.Secret, time.Unix(unix, 0), body) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if e.Type == domain.EventTodoDeleted {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		received = append(received, e)
+	}))
+	defer receiver.Close()
+
+	hook.URL = receiver.URL
+	todo, err := domain.NewTodo("Ship it", "")
+	require.NoError(t, err)
+	created, err := domain.NewTodoEvent(nil, todo)
+	require.NoError(t, err)
+	trashed := *todo
+	trashed.MoveToTrash()
+	deleted, err := domain.NewTodoEvent(todo, &trashed)
+	require.NoError(t, err)
+	var pending []*domain.WebhookDelivery
+	for _, e := range []*domain.Event{created, deleted} {
+		d, err := domain.NewWebhookDelivery(hook, e)
+		require.NoError(t, err)
+		pending = append(pending, d)
+	}
+
+	deliveries := mocks.NewWebhookDeliveryRepository(t)
+	var statuses []int
+	deliveries.On("Dispatch", mock.Anything, 10, mock.Anything).Return(
+		func(ctx context.Context, _ int, send func(context.Context, *domain.Webhook, *domain.WebhookDelivery) (int, error)) (int, int, error) {
+			var succeeded, failed int
+			for _, d := range pending {
+				status, err := send(ctx, hook, d)
+				statuses = append(statuses, status)
+				if err != nil {
+					failed++
+				} else {
+					succeeded++
+				}
+			}
+			return succeeded, failed, nil
+		})
+
+	worker := usecase.NewWebhookWorker(deliveries, eventsink.NewWebhookSender(receiver.Client()), slog.New(slog.DiscardHandler))
+	succeeded, failed, err := worker.SendBatch(serviceContext(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, []int{http.StatusOK, http.StatusInternalServerError}, statuses)
+	require.Len(t, received, 1)
+	assert.Equal(t, created.ID, received[0].ID)
+}
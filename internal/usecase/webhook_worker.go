@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/knjname/go-todo-api/internal/domain"
+)
+
+// WebhookWorker sends the queued webhook deliveries of every workspace,
+// retrying failed ones until they succeed or are dead.
+type WebhookWorker struct {
+	deliveries WebhookDeliveryRepository
+	sender     WebhookSender
+	logger     *slog.Logger
+}
+
+func NewWebhookWorker(deliveries WebhookDeliveryRepository, sender WebhookSender, logger *slog.Logger) *WebhookWorker {
+	return &WebhookWorker{deliveries: deliveries, sender: sender, logger: logger}
+}
+
+// SendBatch makes an attempt at up to limit deliveries that are due. It
+// returns how many succeeded and how many failed.
+func (w *WebhookWorker) SendBatch(ctx context.Context, limit int) (succeeded, failed int, err error) {
+	succeeded, failed, err = w.deliveries.Dispatch(ctx, limit, w.send)
+	if err != nil {
+		return 0, 0, fmt.Errorf("dispatch webhook deliveries: %w", err)
+	}
+	if succeeded > 0 || failed > 0 {
+		w.logger.InfoContext(ctx, "webhook deliveries sent", slog.Int("succeeded", succeeded), slog.Int("failed", failed))
+	}
+	return succeeded, failed, nil
+}
+
+// Run sends batches of up to limit deliveries until ctx is done. Whenever
+// no delivery is due, or the deliveries cannot be read, it waits for
+// interval before looking again.
+func (w *WebhookWorker) Run(ctx context.Context, limit int, interval time.Duration) error {
+	for {
+		succeeded, failed, err := w.SendBatch(ctx, limit)
+		if err != nil && ctx.Err() == nil {
+			w.logger.ErrorContext(ctx, "webhook worker failed", slog.String("error", err.Error()))
+		}
+		if err == nil && succeeded+failed == limit {
+			// More deliveries may be due.
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (w *WebhookWorker) send(ctx context.Context, hook *domain.Webhook, d *domain.WebhookDelivery) (int, error) {
+	status, err := w.sender.Send(ctx, hook, d)
+	if err != nil {
+		w.logger.WarnContext(ctx, "webhook delivery failed",
+			slog.String("id", d.ID.String()), slog.String("webhook", hook.ID.String()),
+			slog.Int("attempts", d.Attempts+1), slog.Int("status", status), slog.String("error", err.Error()))
+	}
+	return status, err
+}
@@ -0,0 +1,131 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/knjname/go-todo-api/internal/domain"
+	"github.com/knjname/go-todo-api/internal/usecase"
+	"github.com/knjname/go-todo-api/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sendingDeliveries returns a Dispatch implementation that hands deliveries
+// of hook to the worker and records each outcome at the given time, as the
+// repository does.
+func sendingDeliveries(hook *domain.Webhook, deliveries []*domain.WebhookDelivery, at time.Time) func(context.Context, int, func(context.Context, *domain.Webhook, *domain.WebhookDelivery) (int, error)) (int, int, error) {
+	return func(ctx context.Context, limit int, send func(context.Context, *domain.Webhook, *domain.WebhookDelivery) (int, error)) (int, int, error) {
+		var succeeded, failed int
+		for _, d := range deliveries[:min(limit, len(deliveries))] {
+			status, err := send(ctx, hook, d)
+			d.RecordAttempt(at, status, err)
+			if err != nil {
+				failed++
+				continue
+			}
+			succeeded++
+		}
+		return succeeded, failed, nil
+	}
+}
+
+func TestWebhookWorker_Retries(t *testing.T) {
+	hook := &domain.Webhook{ID: uuid.New(), URL: "https://example.com/hooks"}
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	delivery := func(attempts int) *domain.WebhookDelivery {
+		return &domain.WebhookDelivery{ID: uuid.New(), WebhookID: hook.ID, Status: domain.DeliveryPending, Attempts: attempts}
+	}
+
+	t.Run("accepted delivery succeeds", func(t *testing.T) {
+		deliveries, sender := mocks.NewWebhookDeliveryRepository(t), mocks.NewWebhookSender(t)
+		d := delivery(0)
+		deliveries.On("Dispatch", mock.Anything, 10, mock.Anything).Return(sendingDeliveries(hook, []*domain.WebhookDelivery{d}, at))
+		sender.On("Send", mock.Anything, hook, d).Return(204, nil).Once()
+
+		worker := usecase.NewWebhookWorker(deliveries, sender, slog.New(slog.DiscardHandler))
+		succeeded, failed, err := worker.SendBatch(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, succeeded)
+		assert.Equal(t, 0, failed)
+		assert.Equal(t, domain.DeliverySucceeded, d.Status)
+		assert.Nil(t, d.NextAttemptAt)
+	})
+
+	t.Run("failed send is rescheduled", func(t *testing.T) {
+		deliveries, sender := mocks.NewWebhookDeliveryRepository(t), mocks.NewWebhookSender(t)
+		d := delivery(0)
+		deliveries.On("Dispatch", mock.Anything, 10, mock.Anything).Return(sendingDeliveries(hook, []*domain.WebhookDelivery{d}, at))
+		sender.On("Send", mock.Anything, hook, d).Return(503, errors.New("unexpected status 503")).Once()
+
+		worker := usecase.NewWebhookWorker(deliveries, sender, slog.New(slog.DiscardHandler))
+		succeeded, failed, err := worker.SendBatch(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, succeeded)
+		assert.Equal(t, 1, failed)
+		assert.Equal(t, domain.DeliveryPending, d.Status)
+		assert.Equal(t, 1, d.Attempts)
+		assert.Equal(t, 503, d.ResponseStatus)
+		assert.Equal(t, "unexpected status 503", d.LastError)
+		require.NotNil(t, d.NextAttemptAt)
+		assert.Equal(t, at.Add(domain.RetryDelay(1)), *d.NextAttemptAt)
+	})
+
+	t.Run("last failed attempt is dead", func(t *testing.T) {
+		deliveries, sender := mocks.NewWebhookDeliveryRepository(t), mocks.NewWebhookSender(t)
+		d := delivery(domain.WebhookMaxAttempts - 1)
+		deliveries.On("Dispatch", mock.Anything, 10, mock.Anything).Return(sendingDeliveries(hook, []*domain.WebhookDelivery{d}, at))
+		sender.On("Send", mock.Anything, hook, d).Return(0, errors.New("connection refused")).Once()
+
+		worker := usecase.NewWebhookWorker(deliveries, sender, slog.New(slog.DiscardHandler))
+		_, failed, err := worker.SendBatch(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, failed)
+		assert.Equal(t, domain.DeliveryDead, d.Status)
+		assert.Equal(t, domain.WebhookMaxAttempts, d.Attempts)
+		assert.Zero(t, d.ResponseStatus)
+		assert.Nil(t, d.NextAttemptAt, "dead deliveries are not retried")
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		deliveries := mocks.NewWebhookDeliveryRepository(t)
+		deliveries.On("Dispatch", mock.Anything, 10, mock.Anything).Return(0, 0, errors.New("connection reset"))
+
+		worker := usecase.NewWebhookWorker(deliveries, mocks.NewWebhookSender(t), slog.New(slog.DiscardHandler))
+		_, _, err := worker.SendBatch(context.Background(), 10)
+		assert.Error(t, err)
+	})
+}
+
+func TestWebhookWorker_Run(t *testing.T) {
+	hook := &domain.Webhook{ID: uuid.New()}
+	deliveries, sender := mocks.NewWebhookDeliveryRepository(t), mocks.NewWebhookSender(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	due := []*domain.WebhookDelivery{{ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}}
+	// A full batch is followed by another one at once; an idle queue stops
+	// the worker here.
+	deliveries.On("Dispatch", mock.Anything, 2, mock.Anything).Return(sendingDeliveries(hook, due[:2], time.Now())).Once()
+	deliveries.On("Dispatch", mock.Anything, 2, mock.Anything).Return(sendingDeliveries(hook, due[2:], time.Now())).Once()
+	deliveries.On("Dispatch", mock.Anything, 2, mock.Anything).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(0, 0, nil).Once()
+	sender.On("Send", mock.Anything, hook, mock.Anything).Return(200, nil).Times(3)
+
+	worker := usecase.NewWebhookWorker(deliveries, sender, slog.New(slog.DiscardHandler))
+	done := make(chan error)
+	go func() { done <- worker.Run(ctx, 2, time.Millisecond) }()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not stop")
+	}
+	for _, d := range due {
+		assert.Equal(t, domain.DeliverySucceeded, d.Status)
+	}
+}
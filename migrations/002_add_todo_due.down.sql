@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_todos_due_at;
+
+ALTER TABLE todos
+    DROP COLUMN IF EXISTS due_timezone,
+    DROP COLUMN IF EXISTS due_all_day,
+    DROP COLUMN IF EXISTS due_at;
@@ -0,0 +1,6 @@
+ALTER TABLE todos
+    ADD COLUMN due_at       TIMESTAMPTZ,
+    ADD COLUMN due_all_day  BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN due_timezone TEXT NOT NULL DEFAULT 'UTC';
+
+CREATE INDEX idx_todos_due_at ON todos (due_at) WHERE due_at IS NOT NULL;
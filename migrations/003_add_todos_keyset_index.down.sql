@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_todos_created_at_id;
+
+CREATE INDEX idx_todos_created_at ON todos (created_at DESC);
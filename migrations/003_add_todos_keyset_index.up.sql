@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_todos_created_at;
+
+CREATE INDEX idx_todos_created_at_id ON todos (created_at DESC, id DESC);
@@ -0,0 +1,9 @@
+ALTER TABLE todos ADD COLUMN completed BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- In-progress and cancelled todos collapse into "not completed".
+UPDATE todos SET completed = TRUE WHERE status = 'done';
+
+DROP INDEX IF EXISTS idx_todos_status;
+ALTER TABLE todos DROP COLUMN status;
+
+CREATE INDEX idx_todos_completed ON todos (completed);
@@ -0,0 +1,9 @@
+ALTER TABLE todos ADD COLUMN status TEXT NOT NULL DEFAULT 'open'
+    CHECK (status IN ('open', 'in_progress', 'done', 'cancelled'));
+
+UPDATE todos SET status = 'done' WHERE completed;
+
+DROP INDEX IF EXISTS idx_todos_completed;
+ALTER TABLE todos DROP COLUMN completed;
+
+CREATE INDEX idx_todos_status ON todos (status);
@@ -0,0 +1,5 @@
+-- Rolling back permanently removes the todos in the trash.
+DELETE FROM todos WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_todos_deleted_at;
+ALTER TABLE todos DROP COLUMN deleted_at;
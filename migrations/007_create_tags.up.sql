@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id         UUID PRIMARY KEY,
+    name       VARCHAR(50) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS todo_tags (
+    todo_id UUID NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    tag_id  UUID NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX idx_todo_tags_tag_id ON todo_tags (tag_id);
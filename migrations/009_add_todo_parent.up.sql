@@ -0,0 +1,4 @@
+-- Purging a parent from the trash turns its remaining subtasks into top-level todos.
+ALTER TABLE todos ADD COLUMN parent_id UUID REFERENCES todos (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_todos_parent_id ON todos (parent_id) WHERE parent_id IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_todos_series_due;
+ALTER TABLE todos
+    DROP COLUMN series_id,
+    DROP COLUMN recurrence_timezone,
+    DROP COLUMN recurrence_rule;
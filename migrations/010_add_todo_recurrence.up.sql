@@ -0,0 +1,8 @@
+ALTER TABLE todos
+    ADD COLUMN recurrence_rule     TEXT,
+    ADD COLUMN recurrence_timezone TEXT,
+    ADD COLUMN series_id           UUID;
+
+-- One todo per occurrence: spawning and materializing never duplicate an
+-- occurrence, even one that was moved to the trash.
+CREATE UNIQUE INDEX idx_todos_series_due ON todos (series_id, due_at) WHERE series_id IS NOT NULL;
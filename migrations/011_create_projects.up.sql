@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS projects (
+    id          UUID PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Deleting a project keeps its todos, outside any project.
+ALTER TABLE todos ADD COLUMN project_id UUID REFERENCES projects (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_todos_project_id ON todos (project_id) WHERE project_id IS NOT NULL;
@@ -0,0 +1,26 @@
+ALTER DEFAULT PRIVILEGES REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM todo_app;
+REVOKE ALL ON todos, tags, todo_tags, projects FROM todo_app;
+
+DROP POLICY IF EXISTS projects_workspace ON projects;
+DROP POLICY IF EXISTS todo_tags_workspace ON todo_tags;
+DROP POLICY IF EXISTS tags_workspace ON tags;
+DROP POLICY IF EXISTS todos_workspace ON todos;
+
+ALTER TABLE projects DISABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_tags DISABLE ROW LEVEL SECURITY;
+ALTER TABLE tags DISABLE ROW LEVEL SECURITY;
+ALTER TABLE todos DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX IF EXISTS idx_todos_workspace_id;
+
+ALTER TABLE projects DROP CONSTRAINT projects_workspace_id_name_key;
+ALTER TABLE projects ADD CONSTRAINT projects_name_key UNIQUE (name);
+ALTER TABLE tags DROP CONSTRAINT tags_workspace_id_name_key;
+ALTER TABLE tags ADD CONSTRAINT tags_name_key UNIQUE (name);
+
+ALTER TABLE projects DROP COLUMN workspace_id;
+ALTER TABLE todo_tags DROP COLUMN workspace_id;
+ALTER TABLE tags DROP COLUMN workspace_id;
+ALTER TABLE todos DROP COLUMN workspace_id;
+
+DROP FUNCTION IF EXISTS current_workspace_id();
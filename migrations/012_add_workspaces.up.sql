@@ -0,0 +1,62 @@
+-- Every tenant table is isolated by workspace with row-level security. The
+-- repositories run each transaction as todo_app with app.workspace_id set
+-- to the request's workspace; todo_app only ever sees and writes the rows
+-- of that workspace, whatever the query says.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'todo_app') THEN
+        CREATE ROLE todo_app NOLOGIN;
+    END IF;
+END
+$$;
+
+GRANT todo_app TO CURRENT_USER;
+
+-- current_workspace_id returns the workspace of the current transaction,
+-- or NULL if none is set, in which case no row is visible.
+CREATE FUNCTION current_workspace_id() RETURNS UUID
+    LANGUAGE sql STABLE
+    AS $$ SELECT NULLIF(current_setting('app.workspace_id', true), '')::uuid $$;
+
+-- Existing rows are moved to the workspace 00000000-0000-0000-0000-000000000001.
+ALTER TABLE todos ADD COLUMN workspace_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE tags ADD COLUMN workspace_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE todo_tags ADD COLUMN workspace_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE projects ADD COLUMN workspace_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+
+-- New rows belong to the workspace of the transaction.
+ALTER TABLE todos ALTER COLUMN workspace_id SET DEFAULT current_workspace_id();
+ALTER TABLE tags ALTER COLUMN workspace_id SET DEFAULT current_workspace_id();
+ALTER TABLE todo_tags ALTER COLUMN workspace_id SET DEFAULT current_workspace_id();
+ALTER TABLE projects ALTER COLUMN workspace_id SET DEFAULT current_workspace_id();
+
+-- Names are unique within a workspace.
+ALTER TABLE tags DROP CONSTRAINT tags_name_key;
+ALTER TABLE tags ADD CONSTRAINT tags_workspace_id_name_key UNIQUE (workspace_id, name);
+ALTER TABLE projects DROP CONSTRAINT projects_name_key;
+ALTER TABLE projects ADD CONSTRAINT projects_workspace_id_name_key UNIQUE (workspace_id, name);
+
+CREATE INDEX idx_todos_workspace_id ON todos (workspace_id);
+
+ALTER TABLE todos ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE todo_tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE projects ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY todos_workspace ON todos TO todo_app
+    USING (workspace_id = current_workspace_id())
+    WITH CHECK (workspace_id = current_workspace_id());
+CREATE POLICY tags_workspace ON tags TO todo_app
+    USING (workspace_id = current_workspace_id())
+    WITH CHECK (workspace_id = current_workspace_id());
+CREATE POLICY todo_tags_workspace ON todo_tags TO todo_app
+    USING (workspace_id = current_workspace_id())
+    WITH CHECK (workspace_id = current_workspace_id());
+CREATE POLICY projects_workspace ON projects TO todo_app
+    USING (workspace_id = current_workspace_id())
+    WITH CHECK (workspace_id = current_workspace_id());
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON todos, tags, todo_tags, projects TO todo_app;
+-- Tables of later migrations are reachable by todo_app as well; each must
+-- enable its own row-level security.
+ALTER DEFAULT PRIVILEGES GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO todo_app;
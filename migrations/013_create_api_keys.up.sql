@@ -0,0 +1,23 @@
+-- API keys authenticate service clients. A request is authenticated before
+-- its workspace is known, so the lookup by ID runs as the table owner,
+-- which row-level security does not apply to; managing keys runs as
+-- todo_app like every other tenant query.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           UUID PRIMARY KEY,
+    workspace_id UUID NOT NULL DEFAULT current_workspace_id(),
+    name         VARCHAR(100) NOT NULL,
+    scopes       TEXT[] NOT NULL,
+    secret_hash  BYTEA NOT NULL,
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_keys_workspace_id ON api_keys (workspace_id);
+
+ALTER TABLE api_keys ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY api_keys_workspace ON api_keys TO todo_app
+    USING (workspace_id = current_workspace_id())
+    WITH CHECK (workspace_id = current_workspace_id());
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_todos_assignee_id;
+
+ALTER TABLE todos
+    DROP COLUMN IF EXISTS assignee_id,
+    DROP COLUMN IF EXISTS updated_by,
+    DROP COLUMN IF EXISTS created_by;
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS users (
+    id           UUID PRIMARY KEY,
+    workspace_id UUID NOT NULL DEFAULT current_workspace_id(),
+    name         VARCHAR(100) NOT NULL,
+    -- email is optional, so uniqueness is only enforced on non-NULL values.
+    email        VARCHAR(254),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT users_workspace_id_email_key UNIQUE (workspace_id, email)
+);
+
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY users_workspace ON users TO todo_app
+    USING (workspace_id = current_workspace_id())
+    WITH CHECK (workspace_id = current_workspace_id());
+
+-- created_by and updated_by record who acted and are kept when the user is
+-- deleted; an assignment ends with the assignee.
+ALTER TABLE todos
+    ADD COLUMN created_by  UUID,
+    ADD COLUMN updated_by  UUID,
+    ADD COLUMN assignee_id UUID REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_todos_assignee_id ON todos (assignee_id) WHERE assignee_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Users created before roles existed keep being able to do what they did.
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(10) NOT NULL DEFAULT 'member'
+        CONSTRAINT users_role_check CHECK (role IN ('viewer', 'member', 'admin'));
@@ -0,0 +1,28 @@
+-- todo_audit is the append-only trail of every change to a todo, written in
+-- the transaction of the change. Entries have no foreign key to todos, so
+-- that they outlive purged todos, and todo_app may not change or remove
+-- them.
+CREATE TABLE IF NOT EXISTS todo_audit (
+    id           BIGSERIAL PRIMARY KEY,
+    workspace_id UUID NOT NULL DEFAULT current_workspace_id(),
+    todo_id      UUID NOT NULL,
+    action       VARCHAR(10) NOT NULL,
+    user_id      UUID,
+    subject      TEXT,
+    request_id   TEXT,
+    before       JSONB,
+    after        JSONB,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_todo_audit_todo_id ON todo_audit (todo_id, id);
+CREATE INDEX idx_todo_audit_created_at ON todo_audit (workspace_id, created_at, id);
+
+ALTER TABLE todo_audit ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY todo_audit_workspace ON todo_audit TO todo_app
+    USING (workspace_id = current_workspace_id())
+    WITH CHECK (workspace_id = current_workspace_id());
+
+REVOKE UPDATE, DELETE, TRUNCATE ON todo_audit FROM todo_app;
+GRANT USAGE ON SEQUENCE todo_audit_id_seq TO todo_app;
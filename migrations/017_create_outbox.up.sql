@@ -0,0 +1,27 @@
+-- outbox holds the domain events of todo changes, written in the
+-- transaction of the change, until the relay has delivered them. The relay
+-- serves every workspace, so it runs as the table owner, which row-level
+-- security does not apply to. Delivered events are kept.
+CREATE TABLE IF NOT EXISTS outbox (
+    id              BIGSERIAL PRIMARY KEY,
+    event_id        UUID NOT NULL UNIQUE,
+    workspace_id    UUID NOT NULL DEFAULT current_workspace_id(),
+    type            VARCHAR(30) NOT NULL,
+    todo_id         UUID,
+    data            JSONB NOT NULL,
+    occurred_at     TIMESTAMPTZ NOT NULL,
+    attempts        INT NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at    TIMESTAMPTZ
+);
+
+CREATE INDEX idx_outbox_pending ON outbox (next_attempt_at, id) WHERE delivered_at IS NULL;
+
+ALTER TABLE outbox ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY outbox_workspace ON outbox TO todo_app
+    USING (workspace_id = current_workspace_id())
+    WITH CHECK (workspace_id = current_workspace_id());
+
+GRANT USAGE ON SEQUENCE outbox_id_seq TO todo_app;
@@ -0,0 +1,46 @@
+-- webhooks subscribe URLs to the events of a workspace. The outbox relay
+-- queues a delivery for every subscribed webhook; the delivery worker
+-- serves every workspace, so it runs as the table owner, like the relay.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id           UUID PRIMARY KEY,
+    workspace_id UUID NOT NULL DEFAULT current_workspace_id(),
+    url          TEXT NOT NULL,
+    secret       TEXT NOT NULL,
+    events       TEXT[] NOT NULL DEFAULT '{}',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhooks_workspace_id ON webhooks (workspace_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              UUID PRIMARY KEY,
+    workspace_id    UUID NOT NULL DEFAULT current_workspace_id(),
+    webhook_id      UUID NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id        UUID NOT NULL,
+    event_type      VARCHAR(30) NOT NULL,
+    payload         JSONB NOT NULL,
+    status          VARCHAR(10) NOT NULL DEFAULT 'pending'
+                    CHECK (status IN ('pending', 'succeeded', 'dead')),
+    attempts        INT NOT NULL DEFAULT 0,
+    response_status INT,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ,
+    last_attempt_at TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- The relay delivers events at least once; an event is queued once.
+    UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, created_at DESC);
+CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+ALTER TABLE webhooks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY webhooks_workspace ON webhooks TO todo_app
+    USING (workspace_id = current_workspace_id())
+    WITH CHECK (workspace_id = current_workspace_id());
+CREATE POLICY webhook_deliveries_workspace ON webhook_deliveries TO todo_app
+    USING (workspace_id = current_workspace_id())
+    WITH CHECK (workspace_id = current_workspace_id());
@@ -0,0 +1,10 @@
+-- rank orders todos by hand on boards (see domain.RankBetween). Existing
+-- todos are ranked by creation, like domain.InitialRank ranks new ones.
+-- Ranks compare byte by byte, whatever the database collation.
+ALTER TABLE todos ADD COLUMN rank TEXT COLLATE "C";
+
+UPDATE todos SET rank = lpad(to_hex((extract(epoch FROM created_at) * 1000000)::bigint), 14, '0') || 'V';
+
+ALTER TABLE todos ALTER COLUMN rank SET NOT NULL;
+
+CREATE INDEX idx_todos_rank ON todos (rank, id);
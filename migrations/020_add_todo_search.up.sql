@@ -0,0 +1,15 @@
+-- search_vector indexes the words of titles and descriptions for the
+-- simple search mode (see domain.SearchSimple), titles weighing more.
+ALTER TABLE todos ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', title), 'A') || setweight(to_tsvector('simple', description), 'B')
+) STORED;
+
+CREATE INDEX idx_todos_search_vector ON todos USING GIN (search_vector);
+
+-- The "simple" configuration cannot split Japanese text into words, so the
+-- ngram search mode (see domain.SearchNgram) matches substrings instead,
+-- with ILIKE backed by trigram indexes.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_todos_title_trgm ON todos USING GIN (title gin_trgm_ops);
+CREATE INDEX idx_todos_description_trgm ON todos USING GIN (description gin_trgm_ops);
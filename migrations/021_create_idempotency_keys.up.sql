@@ -0,0 +1,27 @@
+-- idempotency_keys holds the requests made with an Idempotency-Key and
+-- their responses, so that a retry is answered with the stored response
+-- instead of running again. Keys are scoped to the caller that sent them;
+-- the gc batch command removes expired keys of every workspace, running
+-- as the table owner.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    workspace_id UUID NOT NULL DEFAULT current_workspace_id(),
+    caller       TEXT NOT NULL,
+    key          VARCHAR(255) NOT NULL,
+    fingerprint  TEXT NOT NULL,
+    -- The response columns are NULL while the request is in progress.
+    status       INT,
+    header       JSONB,
+    body         BYTEA,
+    created_at   TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (workspace_id, caller, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+
+ALTER TABLE idempotency_keys ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY idempotency_keys_workspace ON idempotency_keys TO todo_app
+    USING (workspace_id = current_workspace_id())
+    WITH CHECK (workspace_id = current_workspace_id());
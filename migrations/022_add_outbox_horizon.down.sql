@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_outbox_xid;
+ALTER TABLE outbox DROP COLUMN IF EXISTS horizon, DROP COLUMN IF EXISTS xid;
@@ -0,0 +1,15 @@
+-- outbox.id is taken when an event is written, not when it is committed,
+-- so events are committed out of id order. xid is the transaction that
+-- wrote an event and horizon the oldest transaction still running then:
+-- every event committed after it was written by a transaction at or after
+-- its horizon, which makes the horizon a position to resume a stream from
+-- without missing events. Events written before are at 0.
+ALTER TABLE outbox
+    ADD COLUMN xid     XID8 NOT NULL DEFAULT '0',
+    ADD COLUMN horizon XID8 NOT NULL DEFAULT '0';
+
+ALTER TABLE outbox
+    ALTER COLUMN xid SET DEFAULT pg_current_xact_id(),
+    ALTER COLUMN horizon SET DEFAULT pg_snapshot_xmin(pg_current_snapshot());
+
+CREATE INDEX idx_outbox_xid ON outbox (xid, id);